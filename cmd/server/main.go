@@ -1,15 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"todo-backend/internal/api"
 	"todo-backend/internal/config"
 	"todo-backend/internal/database"
+	"todo-backend/internal/jobs"
 	"todo-backend/internal/llm"
 	"todo-backend/internal/middleware"
+	"todo-backend/internal/notify"
 	"todo-backend/internal/repositories"
 	"todo-backend/internal/services"
+	"todo-backend/internal/storage"
+	"todo-backend/internal/stt"
+
+	_ "time/tzdata" // user time zones must resolve even on images without zoneinfo
 )
 
 func main() {
@@ -28,14 +35,103 @@ func main() {
 	// Initialize Repositories
 	userRepo := repositories.NewUserRepository(db)
 	taskRepo := repositories.NewTaskRepository(db)
+	audioRepo := repositories.NewAudioUploadRepository(db)
+	settingsRepo := repositories.NewUserSettingsRepository(db)
+	reminderRepo := repositories.NewReminderRepository(db)
+	tagRepo := repositories.NewTagRepository(db)
+	projectRepo := repositories.NewProjectRepository(db)
+	commentRepo := repositories.NewCommentRepository(db)
+	attachmentRepo := repositories.NewAttachmentRepository(db)
 
 	// Set up LLM service
-	llmService := llm.NewOpenAIExtractor(cfg)
+	llmService, err := llm.NewExtractor(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up LLM extractor: %v", err)
+	}
 	
+	// Set up user settings, used to resolve dates in each user's time zone
+	settingsService := services.NewSettingsService(settingsRepo)
+	api.SetSettingsService(settingsService)
+
 	// Set up Task service
 	taskService := services.NewTaskService(taskRepo, llmService)
+	taskService.SetSettingsService(settingsService)
 	api.SetTaskService(taskService)
 
+	// Set up reminders and the scheduler that delivers them
+	notifier, err := notify.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up notifier: %v", err)
+	}
+	reminderService := services.NewReminderService(reminderRepo, taskService, userRepo)
+	taskService.SetReminderService(reminderService)
+	api.SetReminderService(reminderService)
+
+	reminderScheduler := services.NewReminderScheduler(reminderService, notifier)
+	reminderScheduler.Interval = cfg.ReminderInterval
+
+	// Set up tags, which extraction may also put on the tasks it creates
+	tagService := services.NewTagService(tagRepo, taskService)
+	taskService.SetTagService(tagService)
+	api.SetTagService(tagService)
+
+	// Set up projects, the lists tasks are grouped into
+	projectService := services.NewProjectService(projectRepo, userRepo, taskService)
+	taskService.SetProjectService(projectService)
+	api.SetProjectService(projectService)
+
+	// Set up comments, and the activity streams the task service records into
+	commentService := services.NewCommentService(commentRepo, taskService)
+	api.SetCommentService(commentService)
+
+	// Set up delta sync for offline-first clients
+	syncService := services.NewSyncService(taskRepo, taskService)
+	api.SetSyncService(syncService)
+
+	// Set up blob storage for audio files and attachments
+	blobStore, err := storage.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up blob storage: %v", err)
+	}
+	api.SetBlobStore(blobStore)
+
+	// Set up files attached to tasks, which are removed with the tasks purged from the trash
+	attachmentService := services.NewAttachmentService(attachmentRepo, blobStore, taskService)
+	taskService.SetAttachmentService(attachmentService)
+	api.SetAttachmentService(attachmentService)
+
+	// Set up speech-to-text and the audio pipeline
+	transcriber := stt.NewTranscriber(cfg)
+	audioService := services.NewAudioService(audioRepo, blobStore, transcriber, taskService)
+	api.SetAudioService(audioService)
+
+	// Set up the background job queue and its workers
+	jobQueue, err := jobs.NewQueue(cfg, db)
+	if err != nil {
+		log.Fatalf("Failed to set up job queue: %v", err)
+	}
+	jobService := services.NewJobService(jobQueue, taskService, audioService, cfg.JobMaxAttempts)
+	api.SetJobService(jobService)
+
+	workerPool := jobs.NewPool(jobQueue, cfg.JobWorkers)
+	jobService.RegisterHandlers(workerPool)
+
+	// Start the background loops only once every service is wired, since
+	// they call into the task service from their own goroutines
+	reminderScheduler.Start(context.Background())
+	defer reminderScheduler.Stop()
+
+	// Purge tasks that have been in the trash longer than the retention period
+	if cfg.TrashRetention > 0 {
+		trashSweeper := services.NewTrashSweeper(taskService, cfg.TrashRetention)
+		trashSweeper.Interval = cfg.TrashSweepInterval
+		trashSweeper.Start(context.Background())
+		defer trashSweeper.Stop()
+	}
+
+	workerPool.Start(context.Background())
+	defer workerPool.Stop()
+
 	// Initialize Auth Service
 	authService := services.NewAuthService(userRepo)
 	api.SetAuthService(authService)
@@ -4,15 +4,26 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
 	"testing"
 	"time"
 	"todo-backend/internal/config"
+	"todo-backend/internal/jobs"
 	"todo-backend/internal/llm"
 	"todo-backend/internal/models"
+	"todo-backend/internal/notify"
 	"todo-backend/internal/repositories"
 	"todo-backend/internal/services"
+	"todo-backend/internal/storage"
+	"todo-backend/internal/stt"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -27,14 +38,35 @@ type MockLLMExtractor struct {
 	mock.Mock
 }
 
-func (m *MockLLMExtractor) ExtractTasks(ctx context.Context, text string) ([]llm.Task, error) {
-	args := m.Called(ctx, text)
+func (m *MockLLMExtractor) ExtractTasks(ctx context.Context, text string, opts llm.ExtractOptions) ([]llm.Task, error) {
+	args := m.Called(ctx, text, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]llm.Task), args.Error(1)
 }
 
+// FakeTranscriber is a fake stt.Transcriber that returns a fixed transcript without any network calls
+type FakeTranscriber struct {
+	Text string
+	Err  error
+}
+
+func (f *FakeTranscriber) Transcribe(ctx context.Context, audio io.Reader, filename string) (*stt.Transcript, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if _, err := io.ReadAll(audio); err != nil {
+		return nil, err
+	}
+	return &stt.Transcript{Text: f.Text, Language: "english", Duration: 2.5}, nil
+}
+
+var fakeTranscriber = &FakeTranscriber{Text: "Buy groceries tomorrow"}
+
+// jobPool runs queued jobs on demand; tests call ProcessNext instead of starting background workers
+var jobPool *jobs.Pool
+
 // setupTestEnvironment sets up an in-memory SQLite database and all services/repositories for testing
 func setupTestEnvironment() (*gin.Engine, *gorm.DB, error) {
 	// 1. Setup in-memory SQLite database
@@ -44,7 +76,10 @@ func setupTestEnvironment() (*gin.Engine, *gorm.DB, error) {
 	}
 
 	// Migrate schema
-	db.AutoMigrate(&models.User{}, &models.Task{})
+	db.AutoMigrate(&models.User{}, &models.UserSettings{}, &models.Task{}, &models.TaskEvent{}, &models.TaskChangeCounter{}, &models.AudioUpload{}, &models.Reminder{}, &models.Tag{}, &models.TaskTag{}, &models.Project{}, &models.TaskDependency{}, &models.ProjectMember{}, &models.ProjectInvitation{}, &models.Comment{}, &models.Attachment{})
+	if err := repositories.SetupTaskSearch(db); err != nil {
+		return nil, nil, err
+	}
 
 	// 2. Load test config (or mock it)
 	cfg := &config.Config{
@@ -56,11 +91,18 @@ func setupTestEnvironment() (*gin.Engine, *gorm.DB, error) {
 	// 3. Initialize Repositories
 	userRepo := repositories.NewUserRepository(db)
 	taskRepo := repositories.NewTaskRepository(db)
+	audioRepo := repositories.NewAudioUploadRepository(db)
+	settingsRepo := repositories.NewUserSettingsRepository(db)
+	reminderRepo := repositories.NewReminderRepository(db)
+	tagRepo := repositories.NewTagRepository(db)
+	projectRepo := repositories.NewProjectRepository(db)
+	commentRepo := repositories.NewCommentRepository(db)
+	attachmentRepo := repositories.NewAttachmentRepository(db)
 
 	// 4. Initialize LLM Service (mock if needed, for integration test, we might use a dummy or real)
 	// For API integration tests, we can use a mock LLM Extractor
 	mockLLMExtractor := &MockLLMExtractor{}
-	mockLLMExtractor.On("ExtractTasks", mock.AnythingOfType("context.backgroundCtx"), mock.AnythingOfType("string")).Return([]llm.Task{
+	mockLLMExtractor.On("ExtractTasks", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("llm.ExtractOptions")).Return([]llm.Task{
 		{
 			Title:       "Buy groceries",
 			Description: "Buy milk and eggs",
@@ -73,12 +115,48 @@ func setupTestEnvironment() (*gin.Engine, *gorm.DB, error) {
 	// 5. Initialize Services
 	authService := services.NewAuthService(userRepo)
 	userService := services.NewUserService(userRepo)
+	settingsService := services.NewSettingsService(settingsRepo)
 	taskService := services.NewTaskService(taskRepo, mockLLMExtractor)
+	taskService.SetSettingsService(settingsService)
+	blobDir, err := os.MkdirTemp("", "todo-backend-blobs")
+	if err != nil {
+		return nil, nil, err
+	}
+	blobStore, err := storage.NewLocalStore(blobDir, "http://localhost:8080/blobs", []byte(cfg.JWTSecret))
+	if err != nil {
+		return nil, nil, err
+	}
+	reminderService := services.NewReminderService(reminderRepo, taskService, userRepo)
+	taskService.SetReminderService(reminderService)
+	attachmentService := services.NewAttachmentService(attachmentRepo, blobStore, taskService)
+	taskService.SetAttachmentService(attachmentService)
+	tagService := services.NewTagService(tagRepo, taskService)
+	taskService.SetTagService(tagService)
+	projectService := services.NewProjectService(projectRepo, userRepo, taskService)
+	taskService.SetProjectService(projectService)
+	commentService := services.NewCommentService(commentRepo, taskService)
+	syncService := services.NewSyncService(taskRepo, taskService)
+	audioService := services.NewAudioService(audioRepo, blobStore, fakeTranscriber, taskService)
+	jobQueue := jobs.NewMemoryQueue()
+	jobService := services.NewJobService(jobQueue, taskService, audioService, 3)
+	jobPool = jobs.NewPool(jobQueue, 1)
+	jobPool.Backoff = func(int) time.Duration { return 0 }
+	jobService.RegisterHandlers(jobPool)
 
 	// 6. Inject services into API handlers
 	SetAuthService(authService)
 	SetUserService(userService)
+	SetSettingsService(settingsService)
 	SetTaskService(taskService)
+	SetSyncService(syncService)
+	SetAudioService(audioService)
+	SetBlobStore(blobStore)
+	SetJobService(jobService)
+	SetReminderService(reminderService)
+	SetTagService(tagService)
+	SetProjectService(projectService)
+	SetCommentService(commentService)
+	SetAttachmentService(attachmentService)
 
 	// 7. Setup router
 	router := SetupRouter()
@@ -226,6 +304,22 @@ func TestTaskEndpoints(t *testing.T) {
 		assert.Equal(t, userID, taskResponse.UserID)
 	})
 
+	t.Run("POST /tasks should accept natural-language due dates", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/", `{"title": "Dated Task", "due_date": "tomorrow at 5pm"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var taskResponse models.Task
+		json.Unmarshal(w.Body.Bytes(), &taskResponse)
+		tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+		expected := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 17, 0, 0, 0, time.UTC)
+		if assert.NotNil(t, taskResponse.DueDate) {
+			assert.True(t, expected.Equal(*taskResponse.DueDate), "got %v", taskResponse.DueDate)
+		}
+
+		w = performRequest(router, "PUT", "/tasks/"+taskResponse.ID.String(), `{"title": "Dated Task", "due_date": "whenever"}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid due_date format")
+	})
+
 	t.Run("GET /tasks should return all tasks for the user", func(t *testing.T) {
 		// Create a task first
 		taskToCreate := models.Task{
@@ -344,3 +438,2888 @@ func TestTaskEndpoints(t *testing.T) {
 		assert.Equal(t, "Buy groceries", tasksResponse[0].Title)
 	})
 }
+
+// registerAndLogin registers a user with the given email and returns a JWT for it
+func registerAndLogin(t *testing.T, router *gin.Engine, email string) string {
+	w := httptest.NewRecorder()
+	reqBody := bytes.NewBufferString(`{"email": "` + email + `", "password": "password123"}`)
+	req, _ := http.NewRequest("POST", "/auth/register", reqBody)
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	reqBody = bytes.NewBufferString(`{"email": "` + email + `", "password": "password123"}`)
+	req, _ = http.NewRequest("POST", "/auth/login", reqBody)
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var loginResponse map[string]string
+	json.Unmarshal(w.Body.Bytes(), &loginResponse)
+	return loginResponse["token"]
+}
+
+// newAudioUploadRequest builds a multipart POST /audio request carrying the given file
+func newAudioUploadRequest(filename string, content []byte, authToken string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", filename)
+	part.Write(content)
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/audio/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	return req
+}
+
+// wavHeader is enough of a RIFF/WAVE header for content sniffing to recognise the upload as audio
+var wavHeader = []byte("RIFF\x24\x00\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00")
+
+func TestAudioEndpoints(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "audiouser@example.com")
+
+	var uploadID uuid.UUID
+	t.Run("POST /audio should transcribe audio and create tasks", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newAudioUploadRequest("note.wav", wavHeader, authToken))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response models.AudioUpload
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NotEmpty(t, response.ID)
+		assert.Equal(t, models.AudioStatusDone, response.Status)
+		assert.Equal(t, "note.wav", response.Filename)
+		assert.Equal(t, "audio/wave", response.MimeType)
+		assert.Equal(t, "Buy groceries tomorrow", response.Transcript)
+		assert.Equal(t, 2.5, response.DurationSeconds)
+		assert.Len(t, response.Tasks, 1)
+		assert.Equal(t, "Buy groceries", response.Tasks[0].Title)
+		assert.Equal(t, "Buy groceries tomorrow", response.Tasks[0].RawText)
+		assert.Equal(t, []uuid.UUID{response.Tasks[0].ID}, response.TaskIDs)
+		assert.Regexp(t, `^audio/[0-9a-f-]{36}/[0-9a-f-]{36}\.wav$`, response.StorageKey)
+		uploadID = response.ID
+	})
+
+	t.Run("GET /audio/:id should return the transcript and produced tasks", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/audio/"+uploadID.String(), nil)
+		req.Header.Set("Authorization", "Bearer "+authToken)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.AudioUpload
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, uploadID, response.ID)
+		assert.Equal(t, models.AudioStatusDone, response.Status)
+		assert.Equal(t, "Buy groceries tomorrow", response.Transcript)
+		assert.Len(t, response.Tasks, 1)
+		assert.Equal(t, "Buy groceries", response.Tasks[0].Title)
+		assert.NotEmpty(t, response.AudioURL)
+	})
+
+	t.Run("GET /blobs should serve the stored audio through its presigned URL", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/audio/"+uploadID.String(), nil)
+		req.Header.Set("Authorization", "Bearer "+authToken)
+		router.ServeHTTP(w, req)
+		var response models.AudioUpload
+		json.Unmarshal(w.Body.Bytes(), &response)
+
+		audioURL, err := url.Parse(response.AudioURL)
+		assert.NoError(t, err)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", audioURL.RequestURI(), nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, wavHeader, w.Body.Bytes())
+
+		query := audioURL.Query()
+		query.Set("signature", "tampered")
+		audioURL.RawQuery = query.Encode()
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", audioURL.RequestURI(), nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("GET /audio should list the user's uploads", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/audio/", nil)
+		req.Header.Set("Authorization", "Bearer "+authToken)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response []models.AudioUpload
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Len(t, response, 1)
+		assert.Equal(t, uploadID, response[0].ID)
+		assert.Len(t, response[0].Tasks, 1)
+	})
+
+	t.Run("GET /audio/:id should return 404 for another user's upload", func(t *testing.T) {
+		otherToken := registerAndLogin(t, router, "otheraudiouser@example.com")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/audio/"+uploadID.String(), nil)
+		req.Header.Set("Authorization", "Bearer "+otherToken)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("POST /audio should return 400 when no file is sent", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/audio/", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+authToken)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("POST /audio should return 415 for an unsupported extension", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newAudioUploadRequest("notes.txt", []byte("buy milk"), authToken))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("POST /audio should return 415 when the content is not audio", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newAudioUploadRequest("note.mp3", []byte("<html><body>not audio</body></html>"), authToken))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("POST /audio should return 413 for files over the size limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		oversized := append(append([]byte{}, wavHeader...), make([]byte, maxAudioUploadBytes)...)
+		router.ServeHTTP(w, newAudioUploadRequest("note.wav", oversized, authToken))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("POST /audio should record a failed upload when transcription fails", func(t *testing.T) {
+		fakeTranscriber.Err = errors.New("stt unavailable")
+		defer func() { fakeTranscriber.Err = nil }()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newAudioUploadRequest("note.wav", wavHeader, authToken))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Contains(t, response["error"], "stt unavailable")
+
+		w = httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/audio/"+response["audio_id"].(string), nil)
+		req.Header.Set("Authorization", "Bearer "+authToken)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var upload models.AudioUpload
+		json.Unmarshal(w.Body.Bytes(), &upload)
+		assert.Equal(t, models.AudioStatusFailed, upload.Status)
+		assert.Contains(t, upload.ErrorMessage, "stt unavailable")
+		assert.Empty(t, upload.Tasks)
+	})
+
+	t.Run("POST /audio should return 401 for unauthenticated request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newAudioUploadRequest("note.wav", wavHeader, ""))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+// getJob fetches GET /jobs/:id and decodes the response body
+func getJob(t *testing.T, router *gin.Engine, jobID string, authToken string) (int, map[string]interface{}) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/"+jobID, nil)
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w.Code, response
+}
+
+func TestJobEndpoints(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "jobuser@example.com")
+
+	var extractJobID string
+	t.Run("POST /tasks/from-text with Prefer: respond-async should queue an extract job", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/tasks/from-text", bytes.NewBufferString(`{"text": "I need to buy groceries tomorrow"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+authToken)
+		req.Header.Set("Prefer", "respond-async")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "respond-async", w.Header().Get("Preference-Applied"))
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "queued", response["status"])
+		extractJobID = response["job_id"].(string)
+		assert.Equal(t, "/jobs/"+extractJobID, w.Header().Get("Location"))
+
+		code, job := getJob(t, router, extractJobID, authToken)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "queued", job["status"])
+		assert.Equal(t, "extract", job["type"])
+	})
+
+	t.Run("GET /jobs/:id should report the result once a worker has run the job", func(t *testing.T) {
+		processed, err := jobPool.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		code, job := getJob(t, router, extractJobID, authToken)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "succeeded", job["status"])
+		assert.EqualValues(t, 1, job["attempts"])
+		result := job["result"].(map[string]interface{})
+		assert.Len(t, result["task_ids"], 1)
+	})
+
+	t.Run("POST /audio with Prefer: respond-async should store the audio and queue a transcribe job", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := newAudioUploadRequest("note.wav", wavHeader, authToken)
+		req.Header.Set("Prefer", "respond-async")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		audioID := response["audio_id"].(string)
+		jobID := response["job_id"].(string)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/audio/"+audioID, nil)
+		req.Header.Set("Authorization", "Bearer "+authToken)
+		router.ServeHTTP(w, req)
+		var upload models.AudioUpload
+		json.Unmarshal(w.Body.Bytes(), &upload)
+		assert.Equal(t, models.AudioStatusUploaded, upload.Status)
+
+		processed, err := jobPool.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		code, job := getJob(t, router, jobID, authToken)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "succeeded", job["status"])
+		result := job["result"].(map[string]interface{})
+		assert.Equal(t, audioID, result["audio_id"])
+		assert.Len(t, result["task_ids"], 1)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/audio/"+audioID, nil)
+		req.Header.Set("Authorization", "Bearer "+authToken)
+		router.ServeHTTP(w, req)
+		json.Unmarshal(w.Body.Bytes(), &upload)
+		assert.Equal(t, models.AudioStatusDone, upload.Status)
+		assert.Len(t, upload.Tasks, 1)
+	})
+
+	t.Run("failing jobs should be retried and then dead-lettered", func(t *testing.T) {
+		fakeTranscriber.Err = errors.New("stt unavailable")
+		defer func() { fakeTranscriber.Err = nil }()
+
+		w := httptest.NewRecorder()
+		req := newAudioUploadRequest("note.wav", wavHeader, authToken)
+		req.Header.Set("Prefer", "respond-async")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		jobID := response["job_id"].(string)
+
+		for attempt := 1; attempt <= 3; attempt++ {
+			processed, err := jobPool.ProcessNext(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, processed)
+		}
+
+		code, job := getJob(t, router, jobID, authToken)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "dead", job["status"])
+		assert.EqualValues(t, 3, job["attempts"])
+		assert.Contains(t, job["last_error"], "stt unavailable")
+	})
+
+	t.Run("GET /jobs/:id should return 404 for another user's job", func(t *testing.T) {
+		otherToken := registerAndLogin(t, router, "otherjobuser@example.com")
+
+		code, _ := getJob(t, router, extractJobID, otherToken)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("GET /jobs/:id should return 400 for an invalid ID", func(t *testing.T) {
+		code, _ := getJob(t, router, "not-a-uuid", authToken)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+// performRequest sends a JSON request as the given user and returns the recorded response
+func performRequest(router *gin.Engine, method string, path string, body string, authToken string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	var reqBody io.Reader
+	if body != "" {
+		reqBody = bytes.NewBufferString(body)
+	}
+	req, _ := http.NewRequest(method, path, reqBody)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSubtaskEndpoints(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "subtaskuser@example.com")
+
+	w := performRequest(router, "POST", "/tasks/", `{"title": "Plan trip"}`, authToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var parent models.Task
+	json.Unmarshal(w.Body.Bytes(), &parent)
+	parentPath := "/tasks/" + parent.ID.String()
+
+	var subtaskIDs []uuid.UUID
+	t.Run("POST /tasks/:id/subtasks should append child tasks", func(t *testing.T) {
+		for _, title := range []string{"Book flights", "Reserve hotel", "Pack"} {
+			w := performRequest(router, "POST", parentPath+"/subtasks", `{"title": "`+title+`"}`, authToken)
+			assert.Equal(t, http.StatusCreated, w.Code)
+			var subtask models.Task
+			json.Unmarshal(w.Body.Bytes(), &subtask)
+			assert.Equal(t, &parent.ID, subtask.ParentID)
+			assert.Equal(t, len(subtaskIDs), subtask.Position)
+			subtaskIDs = append(subtaskIDs, subtask.ID)
+		}
+
+		w := performRequest(router, "POST", "/tasks/"+subtaskIDs[0].String()+"/subtasks", `{"title": "Compare prices"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("GET /tasks/:id should return the nested tree", func(t *testing.T) {
+		w := performRequest(router, "GET", parentPath, "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.Len(t, task.Subtasks, 3)
+		assert.Equal(t, "Book flights", task.Subtasks[0].Title)
+		assert.Len(t, task.Subtasks[0].Subtasks, 1)
+		assert.Equal(t, "Compare prices", task.Subtasks[0].Subtasks[0].Title)
+	})
+
+	t.Run("GET /tasks should list subtasks under their parent only", func(t *testing.T) {
+		w := performRequest(router, "GET", "/tasks/", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var tasks []models.Task
+		json.Unmarshal(w.Body.Bytes(), &tasks)
+		assert.Len(t, tasks, 1)
+		assert.Len(t, tasks[0].Subtasks, 3)
+	})
+
+	t.Run("PUT /tasks/:id/subtasks/:subtaskId should update and reorder a subtask", func(t *testing.T) {
+		w := performRequest(router, "PUT", parentPath+"/subtasks/"+subtaskIDs[2].String(), `{"title": "Pack bags", "position": 0}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var subtask models.Task
+		json.Unmarshal(w.Body.Bytes(), &subtask)
+		assert.Equal(t, "Pack bags", subtask.Title)
+		assert.Equal(t, 0, subtask.Position)
+
+		w = performRequest(router, "GET", parentPath+"/subtasks", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var subtasks []models.Task
+		json.Unmarshal(w.Body.Bytes(), &subtasks)
+		assert.Equal(t, []string{"Pack bags", "Book flights", "Reserve hotel"},
+			[]string{subtasks[0].Title, subtasks[1].Title, subtasks[2].Title})
+	})
+
+	t.Run("GET /tasks/:id/subtasks/:subtaskId should return 404 for a task under another parent", func(t *testing.T) {
+		w := performRequest(router, "GET", "/tasks/"+subtaskIDs[1].String()+"/subtasks/"+subtaskIDs[2].String(), "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("POST /tasks/:id/complete?cascade=true should complete the whole tree", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/"+subtaskIDs[1].String()+"/complete", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.True(t, task.Completed)
+
+		w = performRequest(router, "POST", parentPath+"/complete?cascade=true", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.True(t, task.Completed)
+		for _, subtask := range task.Subtasks {
+			assert.True(t, subtask.Completed, subtask.Title)
+		}
+		assert.True(t, task.Subtasks[1].Subtasks[0].Completed)
+	})
+
+	t.Run("DELETE /tasks/:id/subtasks/:subtaskId should delete the subtask and its children", func(t *testing.T) {
+		w := performRequest(router, "DELETE", parentPath+"/subtasks/"+subtaskIDs[0].String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		var remaining int64
+		db.Model(&models.Task{}).Where("parent_id = ?", subtaskIDs[0]).Count(&remaining)
+		assert.Zero(t, remaining)
+
+		w = performRequest(router, "GET", parentPath+"/subtasks", "", authToken)
+		var subtasks []models.Task
+		json.Unmarshal(w.Body.Bytes(), &subtasks)
+		assert.Len(t, subtasks, 2)
+	})
+
+	t.Run("subtask endpoints should return 404 for another user's task", func(t *testing.T) {
+		otherToken := registerAndLogin(t, router, "othersubtaskuser@example.com")
+
+		w := performRequest(router, "GET", parentPath+"/subtasks", "", otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = performRequest(router, "POST", parentPath+"/subtasks", `{"title": "Sneaky"}`, otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = performRequest(router, "POST", parentPath+"/complete", "", otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSettingsEndpoints(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "settingsuser@example.com")
+
+	t.Run("GET /auth/me/settings should return the defaults before anything is saved", func(t *testing.T) {
+		w := performRequest(router, "GET", "/auth/me/settings", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var settings models.UserSettings
+		json.Unmarshal(w.Body.Bytes(), &settings)
+		assert.Equal(t, "UTC", settings.Timezone)
+		assert.Equal(t, "en-US", settings.Locale)
+		assert.Equal(t, "monday", settings.WeekStart)
+		assert.Equal(t, "medium", settings.DefaultPriority)
+	})
+
+	t.Run("PATCH /auth/me/settings should change only the given fields", func(t *testing.T) {
+		w := performRequest(router, "PATCH", "/auth/me/settings", `{"timezone": "Pacific/Auckland", "week_start": "Sunday"}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = performRequest(router, "PATCH", "/auth/me/settings", `{"default_priority": "high"}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var settings models.UserSettings
+		json.Unmarshal(w.Body.Bytes(), &settings)
+		assert.Equal(t, "Pacific/Auckland", settings.Timezone)
+		assert.Equal(t, "sunday", settings.WeekStart)
+		assert.Equal(t, "high", settings.DefaultPriority)
+		assert.Equal(t, "en-US", settings.Locale)
+	})
+
+	t.Run("PATCH /auth/me/settings should reject invalid values", func(t *testing.T) {
+		for _, body := range []string{
+			`{"timezone": "Mars/Olympus_Mons"}`,
+			`{"locale": "not a locale"}`,
+			`{"week_start": "wednesday"}`,
+			`{"default_priority": "critical"}`,
+		} {
+			w := performRequest(router, "PATCH", "/auth/me/settings", body, authToken)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("tasks should use the user's time zone and default priority", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/", `{"title": "Call grandma", "due_date": "tomorrow at 9am"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.Equal(t, "high", task.Priority)
+
+		auckland, _ := time.LoadLocation("Pacific/Auckland")
+		tomorrow := time.Now().In(auckland).AddDate(0, 0, 1)
+		expected := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 9, 0, 0, 0, auckland)
+		if assert.NotNil(t, task.DueDate) {
+			assert.True(t, expected.Equal(*task.DueDate), "got %v, want %v", task.DueDate, expected)
+		}
+	})
+
+	t.Run("GET /auth/me/settings should return 401 for unauthenticated request", func(t *testing.T) {
+		w := performRequest(router, "GET", "/auth/me/settings", "", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestTaskListing(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "listinguser@example.com")
+
+	for _, body := range []string{
+		`{"title": "Alpha", "priority": "high", "due_date": "2030-01-10T09:00:00Z"}`,
+		`{"title": "Bravo", "priority": "low", "due_date": "2020-01-01T09:00:00Z"}`,
+		`{"title": "Charlie", "priority": "medium"}`,
+		`{"title": "Delta", "priority": "high", "due_date": "2030-01-05T09:00:00Z"}`,
+	} {
+		w := performRequest(router, "POST", "/tasks/", body, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		if task.Title == "Delta" {
+			w = performRequest(router, "POST", "/tasks/"+task.ID.String()+"/complete", "", authToken)
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+	}
+
+	listTitles := func(t *testing.T, path string) ([]string, string) {
+		w := performRequest(router, "GET", path, "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var tasks []models.Task
+		json.Unmarshal(w.Body.Bytes(), &tasks)
+		titles := []string{}
+		for _, task := range tasks {
+			titles = append(titles, task.Title)
+		}
+		return titles, w.Header().Get("X-Next-Cursor")
+	}
+
+	t.Run("GET /tasks should sort by due date with undated tasks last", func(t *testing.T) {
+		titles, _ := listTitles(t, "/tasks/?sort=due_date")
+		assert.Equal(t, []string{"Bravo", "Delta", "Alpha", "Charlie"}, titles)
+
+		titles, _ = listTitles(t, "/tasks/?sort=due_date&order=desc")
+		assert.Equal(t, []string{"Alpha", "Delta", "Bravo", "Charlie"}, titles)
+	})
+
+	t.Run("GET /tasks should sort by priority rank", func(t *testing.T) {
+		titles, _ := listTitles(t, "/tasks/?sort=priority&order=desc")
+		if assert.Len(t, titles, 4) {
+			assert.ElementsMatch(t, []string{"Alpha", "Delta"}, titles[:2])
+			assert.Equal(t, []string{"Charlie", "Bravo"}, titles[2:])
+		}
+	})
+
+	t.Run("GET /tasks should apply filters", func(t *testing.T) {
+		cases := map[string][]string{
+			"/tasks/?priority=high&sort=due_date":                {"Delta", "Alpha"},
+			"/tasks/?priority=low,medium&sort=due_date":          {"Bravo", "Charlie"},
+			"/tasks/?completed=false&sort=due_date":              {"Bravo", "Alpha", "Charlie"},
+			"/tasks/?completed=true":                             {"Delta"},
+			"/tasks/?overdue=true":                               {"Bravo"},
+			"/tasks/?due_before=2025-01-01":                      {"Bravo"},
+			"/tasks/?due_after=2030-01-06":                       {"Alpha"},
+			"/tasks/?due_after=2030-01-01&due_before=2030-01-08": {"Delta"},
+			"/tasks/?created_before=2000-01-01":                  {},
+		}
+		for path, expected := range cases {
+			titles, _ := listTitles(t, path)
+			assert.Equal(t, expected, titles, path)
+		}
+	})
+
+	t.Run("GET /tasks should page through results with the next cursor", func(t *testing.T) {
+		var all []string
+		path := "/tasks/?sort=due_date&limit=1"
+		for pages := 0; pages < 10; pages++ {
+			titles, next := listTitles(t, path)
+			all = append(all, titles...)
+			if next == "" {
+				break
+			}
+			path = "/tasks/?sort=due_date&limit=1&cursor=" + url.QueryEscape(next)
+		}
+		assert.Equal(t, []string{"Bravo", "Delta", "Alpha", "Charlie"}, all)
+
+		titles, next := listTitles(t, "/tasks/?sort=created_at&order=desc&limit=3")
+		assert.Len(t, titles, 3)
+		if assert.NotEmpty(t, next) {
+			rest, last := listTitles(t, "/tasks/?sort=created_at&order=desc&limit=3&cursor="+url.QueryEscape(next))
+			assert.Len(t, rest, 1)
+			assert.Empty(t, last)
+		}
+	})
+
+	t.Run("GET /tasks should return 400 for invalid parameters", func(t *testing.T) {
+		_, next := listTitles(t, "/tasks/?sort=due_date&limit=1")
+		for _, path := range []string{
+			"/tasks/?sort=title",
+			"/tasks/?order=sideways",
+			"/tasks/?priority=urgent",
+			"/tasks/?completed=maybe",
+			"/tasks/?limit=0",
+			"/tasks/?limit=1000",
+			"/tasks/?due_before=someday",
+			"/tasks/?cursor=not-a-cursor",
+			"/tasks/?sort=priority&cursor=" + url.QueryEscape(next),
+		} {
+			w := performRequest(router, "GET", path, "", authToken)
+			assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		}
+	})
+}
+
+func TestTaskSearch(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "searchuser@example.com")
+	otherToken := registerAndLogin(t, router, "othersearchuser@example.com")
+
+	createTask := func(body string, token string) models.Task {
+		w := performRequest(router, "POST", "/tasks/", body, token)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		return task
+	}
+	search := func(t *testing.T, query string, token string) []models.TaskSearchResult {
+		w := performRequest(router, "GET", "/tasks/search?q="+url.QueryEscape(query), "", token)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var results []models.TaskSearchResult
+		json.Unmarshal(w.Body.Bytes(), &results)
+		return results
+	}
+
+	dentist := createTask(`{"title": "Book dentist appointment"}`, authToken)
+	createTask(`{"title": "Quarterly report", "description": "Numbers for the board"}`, authToken)
+	createTask(`{"title": "Email Sam", "description": "Send the report draft for review"}`, authToken)
+	createTask(`{"title": "Dentist for the kids"}`, otherToken)
+
+	t.Run("GET /tasks/search should match titles with a highlighted snippet", func(t *testing.T) {
+		results := search(t, "dentist", authToken)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, dentist.ID, results[0].ID)
+			assert.Contains(t, results[0].Snippet, "<mark>dentist</mark>")
+			assert.Greater(t, results[0].Rank, 0.0)
+		}
+	})
+
+	t.Run("GET /tasks/search should escape the task text in snippets", func(t *testing.T) {
+		createTask(`{"title": "Fix <img src=x onerror=alert(1)> gallery & lightbox"}`, authToken)
+		results := search(t, "gallery", authToken)
+		if assert.Len(t, results, 1) {
+			assert.Contains(t, results[0].Snippet, "&lt;img src=x onerror=alert(1)&gt;")
+			assert.Contains(t, results[0].Snippet, "<mark>gallery</mark> &amp; lightbox")
+			assert.NotContains(t, results[0].Snippet, "<img")
+		}
+	})
+
+	t.Run("GET /tasks/search should match word prefixes", func(t *testing.T) {
+		results := search(t, "dent appoint", authToken)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, "Book dentist appointment", results[0].Title)
+		}
+	})
+
+	t.Run("GET /tasks/search should rank title matches above description matches", func(t *testing.T) {
+		results := search(t, "report", authToken)
+		if assert.Len(t, results, 2) {
+			assert.Equal(t, "Quarterly report", results[0].Title)
+			assert.Equal(t, "Email Sam", results[1].Title)
+			assert.Contains(t, results[1].Snippet, "<mark>report</mark>")
+		}
+	})
+
+	t.Run("GET /tasks/search should match the original text of extracted tasks", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/from-text", `{"text": "Pick up zucchini and eggs on the way home"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		results := search(t, "zucchini", authToken)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, "Buy groceries", results[0].Title)
+		}
+	})
+
+	t.Run("GET /tasks/search should follow updates and deletes", func(t *testing.T) {
+		w := performRequest(router, "PUT", "/tasks/"+dentist.ID.String(), `{"title": "Book orthodontist appointment"}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, search(t, "dentist", authToken))
+		assert.Len(t, search(t, "orthodontist", authToken), 1)
+
+		w = performRequest(router, "DELETE", "/tasks/"+dentist.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, search(t, "orthodontist", authToken))
+	})
+
+	t.Run("GET /tasks/search should ignore other users' tasks and stray punctuation", func(t *testing.T) {
+		assert.Empty(t, search(t, "kids", authToken))
+		assert.Len(t, search(t, "kids", otherToken), 1)
+		assert.Empty(t, search(t, `"(*`, authToken))
+	})
+
+	t.Run("GET /tasks/search should return 400 without a query", func(t *testing.T) {
+		w := performRequest(router, "GET", "/tasks/search?q=%20", "", authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "GET", "/tasks/search?q=report&limit=0", "", authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestTaskCompletion(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "completionuser@example.com")
+
+	w := performRequest(router, "POST", "/tasks/", `{"title": "Water the plants"}`, authToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.Task
+	json.Unmarshal(w.Body.Bytes(), &created)
+	taskPath := "/tasks/" + created.ID.String()
+
+	decodeTask := func(w *httptest.ResponseRecorder) models.Task {
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		return task
+	}
+
+	t.Run("POST /tasks/:id/complete should set completed_at", func(t *testing.T) {
+		w := performRequest(router, "POST", taskPath+"/complete", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		task := decodeTask(w)
+		assert.True(t, task.Completed)
+		assert.NotNil(t, task.CompletedAt)
+
+		// Completing a completed task changes nothing
+		w = performRequest(router, "POST", taskPath+"/complete", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("POST /tasks/:id/reopen should clear completed_at", func(t *testing.T) {
+		w := performRequest(router, "POST", taskPath+"/reopen", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		task := decodeTask(w)
+		assert.False(t, task.Completed)
+		assert.Nil(t, task.CompletedAt)
+	})
+
+	t.Run("PUT /tasks/:id should complete a task when completed is sent", func(t *testing.T) {
+		w := performRequest(router, "PUT", taskPath, `{"title": "Water the plants", "completed": true}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, decodeTask(w).Completed)
+
+		// Leaving completed out keeps the current state
+		w = performRequest(router, "PUT", taskPath, `{"title": "Water all the plants"}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = performRequest(router, "GET", taskPath, "", authToken)
+		task := decodeTask(w)
+		assert.Equal(t, "Water all the plants", task.Title)
+		assert.True(t, task.Completed)
+	})
+
+	t.Run("GET /tasks/:id/history should list completions and reopenings", func(t *testing.T) {
+		w := performRequest(router, "GET", taskPath+"/history", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var history models.TaskHistory
+		json.Unmarshal(w.Body.Bytes(), &history)
+		assert.Equal(t, 2, history.CompletionCount)
+		assert.NotNil(t, history.LastCompletedAt)
+		types := []string{}
+		for _, event := range history.Events {
+			types = append(types, event.Type)
+		}
+		assert.Equal(t, []string{"completed", "reopened", "completed"}, types)
+	})
+
+	t.Run("POST /tasks/:id/reopen?cascade=true should reopen the whole tree", func(t *testing.T) {
+		w := performRequest(router, "POST", taskPath+"/subtasks", `{"title": "Fern"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		subtask := decodeTask(w)
+		subtaskPath := taskPath + "/subtasks/" + subtask.ID.String()
+
+		w = performRequest(router, "PUT", subtaskPath, `{"title": "Fern", "completed": true}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, decodeTask(w).Completed)
+
+		w = performRequest(router, "POST", taskPath+"/reopen?cascade=true", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		task := decodeTask(w)
+		assert.False(t, task.Completed)
+		if assert.Len(t, task.Subtasks, 1) {
+			assert.False(t, task.Subtasks[0].Completed)
+		}
+	})
+
+	t.Run("completion endpoints should return 404 for another user's task", func(t *testing.T) {
+		otherToken := registerAndLogin(t, router, "othercompletionuser@example.com")
+		for _, request := range [][2]string{{"POST", taskPath + "/complete"}, {"POST", taskPath + "/reopen"}, {"GET", taskPath + "/history"}} {
+			w := performRequest(router, request[0], request[1], "", otherToken)
+			assert.Equal(t, http.StatusNotFound, w.Code, request[1])
+		}
+	})
+}
+
+func TestTaskPatch(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "patchuser@example.com")
+
+	w := performRequest(router, "POST", "/tasks/", `{"title": "Renew passport", "description": "Photos first", "priority": "high", "due_date": "2030-05-01T10:00:00Z"}`, authToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.Task
+	json.Unmarshal(w.Body.Bytes(), &created)
+	taskPath := "/tasks/" + created.ID.String()
+
+	patch := func(body string, contentType string) (*httptest.ResponseRecorder, models.Task) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PATCH", taskPath, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Authorization", "Bearer "+authToken)
+		router.ServeHTTP(w, req)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		return w, task
+	}
+
+	t.Run("PATCH /tasks/:id should leave absent fields unchanged", func(t *testing.T) {
+		w, task := patch(`{"description": "Photos and form"}`, "application/merge-patch+json")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Renew passport", task.Title)
+		assert.Equal(t, "Photos and form", task.Description)
+		assert.Equal(t, "high", task.Priority)
+		if assert.NotNil(t, task.DueDate) {
+			assert.True(t, time.Date(2030, 5, 1, 10, 0, 0, 0, time.UTC).Equal(*task.DueDate))
+		}
+	})
+
+	t.Run("PATCH /tasks/:id should clear fields set to null", func(t *testing.T) {
+		w, task := patch(`{"due_date": null, "description": null, "priority": null}`, "application/merge-patch+json")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Nil(t, task.DueDate)
+		assert.Empty(t, task.Description)
+		assert.Equal(t, "medium", task.Priority)
+		assert.Equal(t, "Renew passport", task.Title)
+	})
+
+	t.Run("PATCH /tasks/:id should set dates and completion", func(t *testing.T) {
+		w, task := patch(`{"due_date": "2030-06-01", "completed": true}`, "application/json")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotNil(t, task.DueDate)
+		assert.True(t, task.Completed)
+		assert.NotNil(t, task.CompletedAt)
+	})
+
+	t.Run("PATCH /tasks/:id should reject invalid patches", func(t *testing.T) {
+		for _, body := range []string{
+			`{"title": null}`,
+			`{"title": "  "}`,
+			`{"priority": "urgent"}`,
+			`{"due_date": "whenever"}`,
+			`{"completed": null}`,
+			`{"id": "6f1c1e3e-0000-0000-0000-000000000000"}`,
+			`["not", "an", "object"]`,
+			`not json`,
+		} {
+			w, _ := patch(body, "application/merge-patch+json")
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+
+		w, _ := patch(`{"title": "Plain"}`, "text/plain")
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("PATCH /tasks/:id should return 404 for another user's task", func(t *testing.T) {
+		otherToken := registerAndLogin(t, router, "otherpatchuser@example.com")
+		w := performRequest(router, "PATCH", taskPath, `{"title": "Mine now"}`, otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("PUT /tasks/:id should replace the whole task", func(t *testing.T) {
+		w := performRequest(router, "PUT", taskPath, `{"title": "Renew passport and ID"}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.Equal(t, "Renew passport and ID", task.Title)
+		assert.Nil(t, task.DueDate)
+		assert.Equal(t, "medium", task.Priority)
+		assert.Equal(t, created.ID, task.ID)
+		assert.False(t, task.CreatedAt.IsZero())
+	})
+
+	t.Run("PUT /tasks/:id should validate the task", func(t *testing.T) {
+		for _, body := range []string{
+			`{"description": "No title"}`,
+			`{"title": "Bad priority", "priority": "urgent"}`,
+		} {
+			w := performRequest(router, "PUT", taskPath, body, authToken)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+}
+
+func TestTaskConcurrency(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "etaguser@example.com")
+
+	w := performRequest(router, "POST", "/tasks/", `{"title": "Plan offsite"}`, authToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.Task
+	json.Unmarshal(w.Body.Bytes(), &created)
+	taskPath := "/tasks/" + created.ID.String()
+	createdETag := w.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(createdETag, `"1-`))
+
+	conditional := func(method string, path string, body string, header string, value string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		var reqBody io.Reader
+		if body != "" {
+			reqBody = bytes.NewBufferString(body)
+		}
+		req, _ := http.NewRequest(method, path, reqBody)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+authToken)
+		req.Header.Set(header, value)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	var etag string
+	t.Run("GET /tasks/:id should return an ETag and honour If-None-Match", func(t *testing.T) {
+		w := performRequest(router, "GET", taskPath, "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		etag = w.Header().Get("ETag")
+		assert.Equal(t, createdETag, etag, "the task as created is the task as read back")
+
+		w = conditional("GET", taskPath, "", "If-None-Match", etag)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+		w = conditional("GET", taskPath, "", "If-None-Match", `"0-0", W/`+etag)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("changing a subtask should change the parent's ETag", func(t *testing.T) {
+		w := performRequest(router, "POST", taskPath+"/subtasks", `{"title": "Book venue"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		w = conditional("GET", taskPath, "", "If-None-Match", etag)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.Equal(t, 1, task.Version, "the parent itself did not change")
+		assert.Len(t, task.Subtasks, 1)
+
+		w = conditional("PATCH", taskPath, `{"title": "Overwrite"}`, "If-Match", etag)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		etag = performRequest(router, "GET", taskPath, "", authToken).Header().Get("ETag")
+	})
+
+	var stale string
+	t.Run("PUT /tasks/:id should apply with a matching If-Match and change the ETag", func(t *testing.T) {
+		// If-Match takes a list of tags; any of them may match
+		w := conditional("PUT", taskPath, `{"title": "Plan team offsite"}`, "If-Match", `"0-0", `+etag)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, strings.HasPrefix(w.Header().Get("ETag"), `"2-`))
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.Equal(t, 2, task.Version)
+		stale, etag = etag, w.Header().Get("ETag")
+		assert.Equal(t, etag, performRequest(router, "GET", taskPath, "", authToken).Header().Get("ETag"))
+	})
+
+	t.Run("stale If-Match should return 412 on PUT, PATCH and DELETE", func(t *testing.T) {
+		w := conditional("PUT", taskPath, `{"title": "Overwrite"}`, "If-Match", stale)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		w = conditional("PATCH", taskPath, `{"title": "Overwrite"}`, "If-Match", stale)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		w = conditional("PATCH", taskPath, `{"title": "Overwrite"}`, "If-Match", "W/"+etag)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		w = conditional("PATCH", taskPath, `{"title": "Overwrite"}`, "If-Match", `"2"`)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		w = conditional("DELETE", taskPath, "", "If-Match", stale)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+		w = performRequest(router, "GET", taskPath, "", authToken)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.Equal(t, "Plan team offsite", task.Title)
+	})
+
+	t.Run("completing a task should change its ETag", func(t *testing.T) {
+		w := performRequest(router, "POST", taskPath+"/complete", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, strings.HasPrefix(w.Header().Get("ETag"), `"3-`))
+
+		w = conditional("PATCH", taskPath, `{"description": "Venue first"}`, "If-Match", w.Header().Get("ETag"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, strings.HasPrefix(w.Header().Get("ETag"), `"4-`))
+		etag = w.Header().Get("ETag")
+	})
+
+	t.Run("repository updates from a stale read should conflict", func(t *testing.T) {
+		repo := repositories.NewTaskRepository(db)
+		userID := created.UserID
+		first, err := repo.GetTaskByID(created.ID, userID)
+		assert.NoError(t, err)
+		second, err := repo.GetTaskByID(created.ID, userID)
+		assert.NoError(t, err)
+
+		first.Title = "First writer"
+		assert.NoError(t, repo.UpdateTask(first, userID, repositories.TaskPrecondition{}))
+		second.Title = "Second writer"
+		assert.ErrorIs(t, repo.UpdateTask(second, userID, repositories.TaskPrecondition{}), repositories.ErrVersionConflict)
+		assert.Equal(t, 4, second.Version)
+
+		// The precondition sees the task as stored when it is written, subtasks included
+		third, err := repo.GetTaskByID(created.ID, userID)
+		assert.NoError(t, err)
+		third.Title = "Third writer"
+		var subtasks int
+		refuse := repositories.TaskPrecondition{Match: func(task *models.Task) bool {
+			subtasks = len(task.Subtasks)
+			return task.Title != "First writer"
+		}}
+		assert.ErrorIs(t, repo.UpdateTask(third, userID, refuse), repositories.ErrVersionConflict)
+		assert.Equal(t, 1, subtasks)
+		stored, err := repo.GetTaskByID(created.ID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, "First writer", stored.Title)
+	})
+
+	t.Run("PUT /tasks/:id should change the task and its completion in a single write", func(t *testing.T) {
+		w := performRequest(router, "GET", taskPath, "", authToken)
+		var before models.Task
+		json.Unmarshal(w.Body.Bytes(), &before)
+		assert.True(t, before.Completed)
+
+		w = conditional("PUT", taskPath, `{"title": "Plan team offsite", "completed": false}`, "If-Match", w.Header().Get("ETag"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.Equal(t, before.Version+1, task.Version)
+		assert.False(t, task.Completed)
+		assert.Nil(t, task.CompletedAt)
+		assert.Equal(t, w.Header().Get("ETag"), performRequest(router, "GET", taskPath, "", authToken).Header().Get("ETag"))
+	})
+
+	t.Run("DELETE /tasks/:id should apply with a matching If-Match", func(t *testing.T) {
+		w := conditional("DELETE", taskPath, "", "If-Match", etag)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code, "the updates above changed the task")
+		w = conditional("DELETE", taskPath, "", "If-Match", performRequest(router, "GET", taskPath, "", authToken).Header().Get("ETag"))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
+
+func TestTaskSync(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "syncuser@example.com")
+	otherToken := registerAndLogin(t, router, "syncother@example.com")
+
+	pull := func(since string, token string) models.SyncChanges {
+		w := performRequest(router, "GET", "/sync?since="+since, "", token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var changes models.SyncChanges
+		json.Unmarshal(w.Body.Bytes(), &changes)
+		return changes
+	}
+	push := func(body string) []models.SyncResult {
+		w := performRequest(router, "POST", "/sync", body, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Results []models.SyncResult `json:"results"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response.Results
+	}
+
+	w := performRequest(router, "POST", "/tasks/", `{"title": "Water plants"}`, authToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var plants models.Task
+	json.Unmarshal(w.Body.Bytes(), &plants)
+	w = performRequest(router, "POST", "/tasks/"+plants.ID.String()+"/subtasks", `{"title": "Fill can"}`, authToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = performRequest(router, "POST", "/tasks/", `{"title": "Pay rent"}`, authToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var rent models.Task
+	json.Unmarshal(w.Body.Bytes(), &rent)
+	performRequest(router, "POST", "/tasks/", `{"title": "Someone else's task"}`, otherToken)
+
+	var token string
+	t.Run("GET /sync without since should return every task as created", func(t *testing.T) {
+		changes := pull("", authToken)
+		assert.Len(t, changes.Created, 3)
+		assert.Empty(t, changes.Updated)
+		assert.Empty(t, changes.Deleted)
+		assert.False(t, changes.HasMore)
+		assert.NotEmpty(t, changes.Token)
+		token = changes.Token
+
+		changes = pull(token, authToken)
+		assert.Empty(t, changes.Created)
+		assert.Equal(t, token, changes.Token)
+	})
+
+	t.Run("GET /sync should return updates and tombstones after the token", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/"+rent.ID.String()+"/complete", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = performRequest(router, "DELETE", "/tasks/"+plants.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		changes := pull(token, authToken)
+		assert.Empty(t, changes.Created)
+		if assert.Len(t, changes.Updated, 1) {
+			assert.Equal(t, rent.ID, changes.Updated[0].ID)
+			assert.True(t, changes.Updated[0].Completed)
+		}
+		assert.Len(t, changes.Deleted, 2, "the deleted task and its subtask")
+		token = changes.Token
+
+		// Deleted tasks are gone from the regular endpoints
+		w = performRequest(router, "GET", "/tasks/"+plants.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Len(t, pull("", authToken).Created, 1)
+	})
+
+	t.Run("GET /sync should page through changes with limit", func(t *testing.T) {
+		changes := pull("0&limit=1", authToken)
+		assert.Len(t, changes.Created, 1)
+		assert.True(t, changes.HasMore)
+	})
+
+	t.Run("GET /sync should reject malformed tokens", func(t *testing.T) {
+		w := performRequest(router, "GET", "/sync?since=abc", "", authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "GET", "/sync?limit=0", "", authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	newID := uuid.New()
+	t.Run("POST /sync should apply a batch and report each result", func(t *testing.T) {
+		results := push(`{"mutations": [
+			{"op": "create", "id": "` + newID.String() + `", "fields": {"title": "Offline task", "priority": "high"}},
+			{"op": "create", "id": "` + uuid.New().String() + `", "parent_id": "` + newID.String() + `", "fields": {"title": "Offline subtask"}},
+			{"op": "update", "id": "` + rent.ID.String() + `", "base_version": 2, "fields": {"title": "Pay rent and bills"}},
+			{"op": "update", "id": "` + plants.ID.String() + `", "fields": {"title": "Too late"}},
+			{"op": "create", "id": "` + uuid.New().String() + `", "fields": {"tags": ["x"]}}
+		]}`)
+		if !assert.Len(t, results, 5) {
+			return
+		}
+		assert.Equal(t, models.SyncApplied, results[0].Status)
+		if assert.NotNil(t, results[0].Task) {
+			assert.Equal(t, newID, results[0].Task.ID)
+			assert.Equal(t, "high", results[0].Task.Priority)
+		}
+		assert.Equal(t, models.SyncApplied, results[1].Status)
+		assert.Equal(t, models.SyncApplied, results[2].Status)
+		assert.Equal(t, models.SyncNotFound, results[3].Status)
+		assert.Equal(t, models.SyncInvalid, results[4].Status)
+
+		changes := pull(token, authToken)
+		assert.Len(t, changes.Created, 2)
+		assert.Len(t, changes.Updated, 1)
+		token = changes.Token
+	})
+
+	t.Run("POST /sync should accept a resent create", func(t *testing.T) {
+		results := push(`{"mutations": [{"op": "create", "id": "` + newID.String() + `", "fields": {"title": "Offline task"}}]}`)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, models.SyncApplied, results[0].Status)
+		}
+		assert.Empty(t, pull(token, authToken).Created)
+	})
+
+	t.Run("POST /sync should report stale changes as conflicts with the server copy", func(t *testing.T) {
+		results := push(`{"mutations": [
+			{"op": "update", "id": "` + rent.ID.String() + `", "base_version": 1, "fields": {"title": "Stale"}},
+			{"op": "delete", "id": "` + rent.ID.String() + `", "base_version": 1}
+		]}`)
+		if !assert.Len(t, results, 2) {
+			return
+		}
+		for _, result := range results {
+			assert.Equal(t, models.SyncConflict, result.Status)
+			if assert.NotNil(t, result.Task) {
+				assert.Equal(t, "Pay rent and bills", result.Task.Title)
+			}
+		}
+	})
+
+	t.Run("POST /sync should delete with the current version", func(t *testing.T) {
+		results := push(`{"mutations": [{"op": "delete", "id": "` + rent.ID.String() + `", "base_version": 3}]}`)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, models.SyncApplied, results[0].Status)
+		}
+		changes := pull(token, authToken)
+		if assert.Len(t, changes.Deleted, 1) {
+			assert.Equal(t, rent.ID, changes.Deleted[0].ID)
+		}
+	})
+
+	t.Run("POST /sync should not touch other users' tasks", func(t *testing.T) {
+		results := push(`{"mutations": [{"op": "delete", "id": "` + newID.String() + `"}]}`)
+		assert.Equal(t, models.SyncApplied, results[0].Status)
+
+		w := performRequest(router, "POST", "/sync", `{"mutations": [{"op": "update", "id": "`+newID.String()+`", "fields": {"title": "Hijack"}}]}`, otherToken)
+		assert.Contains(t, w.Body.String(), models.SyncNotFound)
+		assert.Empty(t, pull("", otherToken).Deleted)
+	})
+
+	t.Run("POST /sync should reject malformed batches", func(t *testing.T) {
+		w := performRequest(router, "POST", "/sync", `{"mutations": [{"op": "rename", "id": "`+newID.String()+`"}]}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "POST", "/sync", `{}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestTaskTrash(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "trashuser@example.com")
+	otherToken := registerAndLogin(t, router, "trashother@example.com")
+
+	createTask := func(path string, title string) models.Task {
+		w := performRequest(router, "POST", path, `{"title": "`+title+`"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		return task
+	}
+	getTrash := func(token string) []models.TrashedTask {
+		w := performRequest(router, "GET", "/tasks/trash", "", token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var trash []models.TrashedTask
+		json.Unmarshal(w.Body.Bytes(), &trash)
+		return trash
+	}
+
+	trip := createTask("/tasks/", "Plan trip")
+	flights := createTask("/tasks/"+trip.ID.String()+"/subtasks", "Book flights")
+	hotel := createTask("/tasks/"+trip.ID.String()+"/subtasks", "Reserve hotel")
+	groceries := createTask("/tasks/", "Buy groceries")
+
+	t.Run("DELETE should move tasks to the trash", func(t *testing.T) {
+		w := performRequest(router, "DELETE", "/tasks/"+trip.ID.String()+"/subtasks/"+hotel.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = performRequest(router, "DELETE", "/tasks/"+trip.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = performRequest(router, "GET", "/tasks/"+trip.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		trash := getTrash(authToken)
+		if assert.Len(t, trash, 2, "subtasks deleted with their parent are not listed") {
+			assert.Equal(t, trip.ID, trash[0].ID)
+			assert.Equal(t, hotel.ID, trash[1].ID)
+			assert.False(t, trash[0].DeletedAt.IsZero())
+		}
+		assert.Empty(t, getTrash(otherToken))
+	})
+
+	t.Run("POST /tasks/:id/restore should not restore a subtask before its parent", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/"+hotel.ID.String()+"/restore", "", authToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("POST /tasks/:id/restore should restore the subtasks deleted along with the task", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/"+trip.ID.String()+"/restore", "", otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = performRequest(router, "POST", "/tasks/"+trip.ID.String()+"/restore", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var restored models.Task
+		json.Unmarshal(w.Body.Bytes(), &restored)
+		if assert.Len(t, restored.Subtasks, 1, "the subtask deleted earlier stays in the trash") {
+			assert.Equal(t, flights.ID, restored.Subtasks[0].ID)
+		}
+		assert.True(t, strings.HasPrefix(w.Header().Get("ETag"), fmt.Sprintf(`"%d-`, restored.Version)))
+
+		trash := getTrash(authToken)
+		if assert.Len(t, trash, 1) {
+			assert.Equal(t, hotel.ID, trash[0].ID)
+		}
+
+		w = performRequest(router, "POST", "/tasks/"+groceries.ID.String()+"/restore", "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code, "only tasks in the trash can be restored")
+	})
+
+	t.Run("DELETE /tasks/trash/:id should purge a task for good", func(t *testing.T) {
+		w := performRequest(router, "DELETE", "/tasks/trash/"+groceries.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code, "only tasks in the trash can be purged")
+
+		w = performRequest(router, "DELETE", "/tasks/trash/"+hotel.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, getTrash(authToken))
+
+		w = performRequest(router, "POST", "/tasks/"+hotel.ID.String()+"/restore", "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("DELETE /tasks/trash should empty the trash", func(t *testing.T) {
+		performRequest(router, "DELETE", "/tasks/"+groceries.ID.String(), "", authToken)
+		performRequest(router, "DELETE", "/tasks/"+trip.ID.String(), "", authToken)
+
+		w := performRequest(router, "DELETE", "/tasks/trash", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"purged": 3}`, w.Body.String())
+		assert.Empty(t, getTrash(authToken))
+
+		var remaining int64
+		db.Unscoped().Model(&models.Task{}).Where("id IN ?", []uuid.UUID{trip.ID, flights.ID, groceries.ID}).Count(&remaining)
+		assert.Zero(t, remaining)
+	})
+
+	t.Run("the sweeper should purge tasks past the retention period", func(t *testing.T) {
+		w := performRequest(router, "GET", "/sync", "", authToken)
+		var changes models.SyncChanges
+		json.Unmarshal(w.Body.Bytes(), &changes)
+
+		old := createTask("/tasks/", "Old task")
+		performRequest(router, "DELETE", "/tasks/"+old.ID.String(), "", authToken)
+
+		taskService := services.NewTaskService(repositories.NewTaskRepository(db), nil)
+		purged, err := services.NewTrashSweeper(taskService, time.Hour).Sweep()
+		assert.NoError(t, err)
+		assert.Zero(t, purged, "deleted too recently")
+		purged, err = services.NewTrashSweeper(taskService, 0).Sweep()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+		assert.Empty(t, getTrash(authToken))
+
+		// The tombstone is gone, so clients that had not synced the deletion start over
+		w = performRequest(router, "GET", "/sync?since="+changes.Token, "", authToken)
+		assert.Equal(t, http.StatusGone, w.Code)
+	})
+}
+
+func TestTaskRecurrence(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "recurrenceuser@example.com")
+	w := performRequest(router, "PATCH", "/auth/me/settings", `{"timezone": "America/New_York"}`, authToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	decodeTask := func(w *httptest.ResponseRecorder) models.Task {
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		return task
+	}
+	listTasks := func() []models.Task {
+		w := performRequest(router, "GET", "/tasks/", "", authToken)
+		var tasks []models.Task
+		json.Unmarshal(w.Body.Bytes(), &tasks)
+		return tasks
+	}
+
+	t.Run("POST /tasks/ should reject an unsupported rule", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/", `{"title": "Taxes", "recurrence": "FREQ=YEARLY"}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	// Monday, 4 January 2100, 9:00 in New York
+	w = performRequest(router, "POST", "/tasks/", `{"title": "Standup notes", "due_date": "2100-01-04T09:00:00-05:00", "recurrence": "RRULE:FREQ=WEEKLY;BYDAY=TH,MO;COUNT=3"}`, authToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	first := decodeTask(w)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=3", first.Recurrence)
+	performRequest(router, "POST", "/tasks/"+first.ID.String()+"/subtasks", `{"title": "Collect updates"}`, authToken)
+
+	t.Run("completing an occurrence should create the next one", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/"+first.ID.String()+"/complete", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, decodeTask(w).Completed)
+
+		tasks := listTasks()
+		if !assert.Len(t, tasks, 2) {
+			return
+		}
+		next := tasks[1]
+		if tasks[0].ID != first.ID {
+			next = tasks[0]
+		}
+		assert.Equal(t, "Standup notes", next.Title)
+		assert.False(t, next.Completed)
+		assert.Equal(t, first.Recurrence, next.Recurrence)
+		assert.Equal(t, &first.ID, next.SeriesID)
+		assert.Equal(t, 1, next.RecurrenceIndex)
+		if assert.NotNil(t, next.DueDate) {
+			assert.True(t, time.Date(2100, time.January, 7, 14, 0, 0, 0, time.UTC).Equal(*next.DueDate), "Thursday 9:00 in New York")
+		}
+		if assert.Len(t, next.Subtasks, 1) {
+			assert.Equal(t, "Collect updates", next.Subtasks[0].Title)
+		}
+
+		// Reopening and completing again does not create a second successor
+		performRequest(router, "POST", "/tasks/"+first.ID.String()+"/reopen", "", authToken)
+		performRequest(router, "POST", "/tasks/"+first.ID.String()+"/complete", "", authToken)
+		assert.Len(t, listTasks(), 2)
+
+		// The third occurrence is the last one
+		performRequest(router, "POST", "/tasks/"+next.ID.String()+"/complete", "", authToken)
+		tasks = listTasks()
+		if !assert.Len(t, tasks, 3) {
+			return
+		}
+		var last models.Task
+		for _, task := range tasks {
+			if task.RecurrenceIndex == 2 {
+				last = task
+			}
+		}
+		if assert.NotNil(t, last.DueDate) {
+			assert.True(t, time.Date(2100, time.January, 11, 14, 0, 0, 0, time.UTC).Equal(*last.DueDate))
+		}
+		performRequest(router, "POST", "/tasks/"+last.ID.String()+"/complete", "", authToken)
+		assert.Len(t, listTasks(), 3)
+	})
+
+	t.Run("PATCH /tasks/:id should set and clear the rule", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/", `{"title": "Pay rent"}`, authToken)
+		task := decodeTask(w)
+		taskPath := "/tasks/" + task.ID.String()
+
+		w = performRequest(router, "PATCH", taskPath, `{"recurrence": "FREQ=MONTHLY;BYMONTHDAY=1"}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		task = decodeTask(w)
+		assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=1", task.Recurrence)
+		if assert.NotNil(t, task.DueDate) {
+			newYork, _ := time.LoadLocation("America/New_York")
+			assert.Equal(t, 1, task.DueDate.In(newYork).Day(), "the first occurrence becomes the due date")
+		}
+
+		w = performRequest(router, "PATCH", taskPath, `{"recurrence": "FREQ=MONTHLY;BYDAY=MO"}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = performRequest(router, "PATCH", taskPath, `{"recurrence": null}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, decodeTask(w).Recurrence)
+	})
+}
+
+// recordingNotifier records the reminders it is asked to deliver
+type recordingNotifier struct {
+	notifications []notify.Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func TestReminders(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "reminderuser@example.com")
+
+	decodeReminder := func(w *httptest.ResponseRecorder) models.Reminder {
+		var reminder models.Reminder
+		json.Unmarshal(w.Body.Bytes(), &reminder)
+		return reminder
+	}
+	listReminders := func(path string) []models.Reminder {
+		w := performRequest(router, "GET", path, "", authToken)
+		var reminders []models.Reminder
+		json.Unmarshal(w.Body.Bytes(), &reminders)
+		return reminders
+	}
+
+	due := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	w := performRequest(router, "POST", "/tasks/", `{"title": "Renew passport", "due_date": "`+due.Format(time.RFC3339)+`"}`, authToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var task models.Task
+	json.Unmarshal(w.Body.Bytes(), &task)
+	remindersPath := "/tasks/" + task.ID.String() + "/reminders"
+
+	var relative, absolute models.Reminder
+	t.Run("POST /tasks/:id/reminders should schedule a reminder before the due date", func(t *testing.T) {
+		w := performRequest(router, "POST", remindersPath, `{"minutes_before": 60}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		relative = decodeReminder(w)
+		assert.Equal(t, models.ReminderPending, relative.Status)
+		if assert.NotNil(t, relative.FireAt) {
+			assert.True(t, due.Add(-time.Hour).Equal(*relative.FireAt))
+		}
+	})
+
+	t.Run("POST /tasks/:id/reminders should schedule a reminder at a fixed time", func(t *testing.T) {
+		remindAt := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+		w := performRequest(router, "POST", remindersPath, `{"remind_at": "`+remindAt+`"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		absolute = decodeReminder(w)
+		assert.NotNil(t, absolute.FireAt)
+	})
+
+	t.Run("POST /tasks/:id/reminders should reject invalid reminders", func(t *testing.T) {
+		w := performRequest(router, "POST", remindersPath, `{}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "POST", remindersPath, `{"remind_at": "tomorrow", "minutes_before": 10}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "POST", "/tasks/"+uuid.New().String()+"/reminders", `{"minutes_before": 10}`, authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("GET /reminders should list upcoming reminders, soonest first", func(t *testing.T) {
+		reminders := listReminders("/reminders")
+		if assert.Len(t, reminders, 2) {
+			assert.Equal(t, absolute.ID, reminders[0].ID)
+			assert.Equal(t, relative.ID, reminders[1].ID)
+		}
+	})
+
+	t.Run("changing the due date should move relative reminders", func(t *testing.T) {
+		newDue := due.Add(24 * time.Hour)
+		w := performRequest(router, "PATCH", "/tasks/"+task.ID.String(), `{"due_date": "`+newDue.Format(time.RFC3339)+`"}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		for _, reminder := range listReminders(remindersPath) {
+			switch reminder.ID {
+			case relative.ID:
+				assert.True(t, newDue.Add(-time.Hour).Equal(*reminder.FireAt))
+			case absolute.ID:
+				assert.True(t, absolute.FireAt.Equal(*reminder.FireAt), "fixed reminders stay put")
+			}
+		}
+	})
+
+	t.Run("the scheduler should deliver due reminders exactly once", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		sent, err := services.NewReminderScheduler(reminderService, notifier).RunOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		if assert.Len(t, notifier.notifications, 1) {
+			assert.Equal(t, absolute.ID, notifier.notifications[0].ReminderID)
+			assert.Equal(t, "Renew passport", notifier.notifications[0].Title)
+			assert.Equal(t, "reminderuser@example.com", notifier.notifications[0].Email)
+		}
+
+		// Another scheduler, as after a restart, finds nothing left to send
+		sent, err = services.NewReminderScheduler(reminderService, notifier).RunOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Zero(t, sent)
+		assert.Len(t, notifier.notifications, 1)
+
+		for _, reminder := range listReminders(remindersPath) {
+			if reminder.ID == absolute.ID {
+				assert.Equal(t, models.ReminderSent, reminder.Status)
+				assert.NotNil(t, reminder.SentAt)
+			}
+		}
+	})
+
+	t.Run("POST /reminders/:id/snooze should make a reminder go off again", func(t *testing.T) {
+		w := performRequest(router, "POST", "/reminders/"+absolute.ID.String()+"/snooze", `{"minutes": 10}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		snoozed := decodeReminder(w)
+		assert.Equal(t, models.ReminderPending, snoozed.Status)
+		if assert.NotNil(t, snoozed.FireAt) {
+			assert.WithinDuration(t, time.Now().Add(10*time.Minute), *snoozed.FireAt, time.Minute)
+		}
+
+		w = performRequest(router, "POST", "/reminders/"+absolute.ID.String()+"/snooze", `{}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "POST", "/reminders/"+absolute.ID.String()+"/snooze", `{"minutes": -5}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "POST", "/reminders/"+uuid.New().String()+"/snooze", `{"minutes": 5}`, authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("POST /reminders/:id/dismiss should stop a reminder", func(t *testing.T) {
+		w := performRequest(router, "POST", "/reminders/"+absolute.ID.String()+"/dismiss", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, models.ReminderDismissed, decodeReminder(w).Status)
+
+		reminders := listReminders("/reminders")
+		if assert.Len(t, reminders, 1) {
+			assert.Equal(t, relative.ID, reminders[0].ID)
+		}
+	})
+
+	t.Run("reminders should be private to their owner", func(t *testing.T) {
+		otherToken := registerAndLogin(t, router, "otherreminderuser@example.com")
+		w := performRequest(router, "GET", remindersPath, "", otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = performRequest(router, "POST", "/reminders/"+relative.ID.String()+"/dismiss", "", otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("DELETE /tasks/:id/reminders/:reminderId should remove a reminder", func(t *testing.T) {
+		w := performRequest(router, "DELETE", remindersPath+"/"+relative.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = performRequest(router, "DELETE", remindersPath+"/"+relative.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Len(t, listReminders(remindersPath), 1)
+	})
+}
+
+func TestTags(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "taguser@example.com")
+
+	decodeTag := func(w *httptest.ResponseRecorder) models.Tag {
+		var tag models.Tag
+		json.Unmarshal(w.Body.Bytes(), &tag)
+		return tag
+	}
+	getTask := func(id uuid.UUID) models.Task {
+		w := performRequest(router, "GET", "/tasks/"+id.String(), "", authToken)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		return task
+	}
+	tagNames := func(task models.Task) []string {
+		names := []string{}
+		for _, tag := range task.Tags {
+			names = append(names, tag.Name)
+		}
+		return names
+	}
+	listTitles := func(path string) []string {
+		w := performRequest(router, "GET", path, "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		var tasks []models.Task
+		json.Unmarshal(w.Body.Bytes(), &tasks)
+		titles := []string{}
+		for _, task := range tasks {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+
+	tasks := map[string]models.Task{}
+	for _, title := range []string{"Alpha", "Bravo", "Charlie"} {
+		w := performRequest(router, "POST", "/tasks/", `{"title": "`+title+`"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		tasks[title] = task
+	}
+
+	var work models.Tag
+	t.Run("POST /tags should create a tag", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tags", `{"name": "  Work ", "color": "#1e90ff"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		work = decodeTag(w)
+		assert.Equal(t, "Work", work.Name)
+		assert.Equal(t, "#1e90ff", work.Color)
+	})
+
+	t.Run("POST /tags should reject invalid and duplicate names", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tags", `{"name": "work"}`, authToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		w = performRequest(router, "POST", "/tags", `{"name": "a,b"}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "POST", "/tags", `{"name": "Home", "color": "blue"}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("POST /tasks/:id/tags should tag a task, creating missing tags", func(t *testing.T) {
+		alpha := tasks["Alpha"]
+		w := performRequest(router, "POST", "/tasks/"+alpha.ID.String()+"/tags", `{"tags": ["work", "errands"]}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var tagged models.Task
+		json.Unmarshal(w.Body.Bytes(), &tagged)
+		assert.Equal(t, []string{"errands", "Work"}, tagNames(tagged))
+		assert.Equal(t, alpha.Version+1, tagged.Version)
+		assert.Equal(t, taskETag(&tagged), w.Header().Get("ETag"))
+
+		// Tags already on the task are left alone
+		w = performRequest(router, "POST", "/tasks/"+alpha.ID.String()+"/tags", `{"tags": ["Work"]}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, alpha.Version+1, getTask(alpha.ID).Version)
+
+		w = performRequest(router, "POST", "/tasks/"+tasks["Bravo"].ID.String()+"/tags", `{"tags": ["Work"]}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = performRequest(router, "POST", "/tasks/"+uuid.New().String()+"/tags", `{"tags": ["Work"]}`, authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = performRequest(router, "POST", "/tasks/"+alpha.ID.String()+"/tags", `{"tags": []}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("GET /tags should list tags by name", func(t *testing.T) {
+		w := performRequest(router, "GET", "/tags", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var tags []models.Tag
+		json.Unmarshal(w.Body.Bytes(), &tags)
+		if assert.Len(t, tags, 2) {
+			assert.Equal(t, "errands", tags[0].Name)
+			assert.Equal(t, "Work", tags[1].Name)
+		}
+	})
+
+	t.Run("GET /tasks should filter by tags", func(t *testing.T) {
+		cases := map[string][]string{
+			"/tasks/?tags_any=work":                  {"Alpha", "Bravo"},
+			"/tasks/?tags_any=errands,nope":          {"Alpha"},
+			"/tasks/?tags_all=work,ERRANDS":          {"Alpha"},
+			"/tasks/?tags_all=work&tags_all=work":    {"Alpha", "Bravo"},
+			"/tasks/?tags_all=work,nope":             {},
+			"/tasks/?tags_any=errands&tags_all=work": {"Alpha"},
+			"/tasks/?tags_any=work&completed=true":   {},
+		}
+		for path, expected := range cases {
+			assert.Equal(t, expected, listTitles(path), path)
+		}
+	})
+
+	t.Run("PATCH /tags/:id should rename a tag on its tasks", func(t *testing.T) {
+		before := getTask(tasks["Bravo"].ID).Version
+		w := performRequest(router, "PATCH", "/tags/"+work.ID.String(), `{"name": "Office"}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Office", decodeTag(w).Name)
+		assert.Equal(t, "#1e90ff", decodeTag(w).Color)
+
+		bravo := getTask(tasks["Bravo"].ID)
+		assert.Equal(t, []string{"Office"}, tagNames(bravo))
+		assert.Equal(t, before+1, bravo.Version)
+
+		w = performRequest(router, "PATCH", "/tags/"+work.ID.String(), `{"name": "Errands"}`, authToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("DELETE /tasks/:id/tags/:tagId should untag a task", func(t *testing.T) {
+		path := "/tasks/" + tasks["Bravo"].ID.String() + "/tags/" + work.ID.String()
+		w := performRequest(router, "DELETE", path, "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, getTask(tasks["Bravo"].ID).Tags)
+		w = performRequest(router, "DELETE", path, "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("tags should be private to their owner", func(t *testing.T) {
+		otherToken := registerAndLogin(t, router, "othertaguser@example.com")
+		w := performRequest(router, "GET", "/tags/"+work.ID.String(), "", otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = performRequest(router, "DELETE", "/tags/"+work.ID.String(), "", otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = performRequest(router, "POST", "/tags", `{"name": "Office"}`, otherToken)
+		assert.Equal(t, http.StatusCreated, w.Code, "names are unique per user")
+	})
+
+	t.Run("DELETE /tags/:id should take a tag off every task", func(t *testing.T) {
+		w := performRequest(router, "DELETE", "/tags/"+work.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, []string{"errands"}, tagNames(getTask(tasks["Alpha"].ID)))
+		w = performRequest(router, "GET", "/tags/"+work.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestProjects(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "projectuser@example.com")
+
+	decodeProject := func(w *httptest.ResponseRecorder) models.Project {
+		var project models.Project
+		json.Unmarshal(w.Body.Bytes(), &project)
+		return project
+	}
+	listProjects := func(path string) []models.Project {
+		w := performRequest(router, "GET", path, "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		var projects []models.Project
+		json.Unmarshal(w.Body.Bytes(), &projects)
+		return projects
+	}
+	listTitles := func(path string) []string {
+		w := performRequest(router, "GET", path, "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		var tasks []models.Task
+		json.Unmarshal(w.Body.Bytes(), &tasks)
+		titles := []string{}
+		for _, task := range tasks {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+	createTask := func(body string) models.Task {
+		w := performRequest(router, "POST", "/tasks/", body, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code, body)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		return task
+	}
+
+	var home, work models.Project
+	t.Run("POST /projects should create projects in order", func(t *testing.T) {
+		w := performRequest(router, "POST", "/projects", `{"name": "Home", "color": "#22aa44"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		home = decodeProject(w)
+		assert.Equal(t, "Home", home.Name)
+		assert.Equal(t, 0, home.Position)
+		assert.False(t, home.Archived)
+
+		w = performRequest(router, "POST", "/projects", `{"name": " Work "}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		work = decodeProject(w)
+		assert.Equal(t, "Work", work.Name)
+		assert.Equal(t, 1, work.Position)
+
+		w = performRequest(router, "POST", "/projects", `{"name": "   "}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "POST", "/projects", `{"name": "Garden", "color": "green"}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	past := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	var paint, mow, report, inbox models.Task
+	t.Run("POST /tasks/ should put a task in a project", func(t *testing.T) {
+		paint = createTask(`{"title": "Paint the fence", "project_id": "` + home.ID.String() + `"}`)
+		if assert.NotNil(t, paint.ProjectID) {
+			assert.Equal(t, home.ID, *paint.ProjectID)
+		}
+		mow = createTask(`{"title": "Mow the lawn", "due_date": "` + past + `"}`)
+		report = createTask(`{"title": "Write the report", "project_id": "` + work.ID.String() + `"}`)
+		inbox = createTask(`{"title": "Call mom"}`)
+		assert.Nil(t, inbox.ProjectID)
+
+		w := performRequest(router, "POST", "/tasks/", `{"title": "Lost", "project_id": "`+uuid.New().String()+`"}`, authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("moving tasks should change their project and version", func(t *testing.T) {
+		w := performRequest(router, "POST", "/projects/"+home.ID.String()+"/tasks", `{"task_ids": ["`+mow.ID.String()+`"]}`, authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = performRequest(router, "GET", "/tasks/"+mow.ID.String(), "", authToken)
+		var moved models.Task
+		json.Unmarshal(w.Body.Bytes(), &moved)
+		if assert.NotNil(t, moved.ProjectID) {
+			assert.Equal(t, home.ID, *moved.ProjectID)
+		}
+		assert.Equal(t, mow.Version+1, moved.Version)
+
+		w = performRequest(router, "PATCH", "/tasks/"+report.ID.String(), `{"project_id": "`+home.ID.String()+`"}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = performRequest(router, "PATCH", "/tasks/"+report.ID.String(), `{"project_id": "`+work.ID.String()+`"}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"Write the report"}, listTitles("/projects/"+work.ID.String()+"/tasks"))
+
+		w = performRequest(router, "PATCH", "/tasks/"+report.ID.String(), `{"project_id": "not-a-uuid"}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("subtasks should stay in their parent's project", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/"+paint.ID.String()+"/subtasks", `{"title": "Buy paint"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var subtask models.Task
+		json.Unmarshal(w.Body.Bytes(), &subtask)
+
+		w = performRequest(router, "POST", "/projects/"+work.ID.String()+"/tasks", `{"task_ids": ["`+subtask.ID.String()+`"]}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "PATCH", "/tasks/"+subtask.ID.String(), `{"project_id": "`+work.ID.String()+`"}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("GET /projects should count open and overdue tasks", func(t *testing.T) {
+		projects := listProjects("/projects")
+		if assert.Len(t, projects, 2) {
+			assert.Equal(t, home.ID, projects[0].ID)
+			assert.Equal(t, 2, projects[0].OpenCount)
+			assert.Equal(t, 1, projects[0].OverdueCount)
+			assert.Equal(t, 1, projects[1].OpenCount)
+			assert.Zero(t, projects[1].OverdueCount)
+		}
+
+		w := performRequest(router, "POST", "/tasks/"+mow.ID.String()+"/complete", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = performRequest(router, "GET", "/projects/"+home.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, decodeProject(w).OpenCount)
+		assert.Zero(t, decodeProject(w).OverdueCount)
+	})
+
+	t.Run("GET /tasks should filter by project", func(t *testing.T) {
+		cases := map[string][]string{
+			"/tasks/?project_id=" + home.ID.String() + "&sort=created_at": {"Paint the fence", "Mow the lawn"},
+			"/tasks/?project_id=none":                                      {"Call mom"},
+			"/projects/" + home.ID.String() + "/tasks?completed=false":     {"Paint the fence"},
+		}
+		for path, expected := range cases {
+			assert.Equal(t, expected, listTitles(path), path)
+		}
+		w := performRequest(router, "GET", "/tasks/?project_id=bogus", "", authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("archiving a project should hide its tasks from default views", func(t *testing.T) {
+		w := performRequest(router, "POST", "/projects/"+work.ID.String()+"/archive", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, decodeProject(w).Archived)
+
+		assert.NotContains(t, listTitles("/tasks/"), "Write the report")
+		assert.Contains(t, listTitles("/tasks/?include_archived=true"), "Write the report")
+		assert.Equal(t, []string{"Write the report"}, listTitles("/projects/"+work.ID.String()+"/tasks"))
+		assert.Len(t, listProjects("/projects"), 1)
+		assert.Len(t, listProjects("/projects?include_archived=true"), 2)
+
+		w = performRequest(router, "POST", "/projects/"+work.ID.String()+"/unarchive", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, listTitles("/tasks/"), "Write the report")
+	})
+
+	t.Run("PATCH /projects/:id should rename and reorder a project", func(t *testing.T) {
+		w := performRequest(router, "PATCH", "/projects/"+work.ID.String(), `{"name": "Office", "position": -1}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "PATCH", "/projects/"+work.ID.String(), `{"name": "Office"}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Office", decodeProject(w).Name)
+		assert.Equal(t, 1, decodeProject(w).Position)
+
+		w = performRequest(router, "PATCH", "/projects/"+home.ID.String(), `{"position": 5}`, authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		projects := listProjects("/projects")
+		if assert.Len(t, projects, 2) {
+			assert.Equal(t, work.ID, projects[0].ID)
+			assert.Equal(t, home.ID, projects[1].ID)
+		}
+	})
+
+	t.Run("projects should be private to their owner", func(t *testing.T) {
+		otherToken := registerAndLogin(t, router, "otherprojectuser@example.com")
+		w := performRequest(router, "GET", "/projects/"+home.ID.String(), "", otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = performRequest(router, "GET", "/projects/"+home.ID.String()+"/tasks", "", otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = performRequest(router, "POST", "/tasks/", `{"title": "Sneaky", "project_id": "`+home.ID.String()+`"}`, otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = performRequest(router, "POST", "/projects/"+home.ID.String()+"/tasks", `{"task_ids": ["`+inbox.ID.String()+`"]}`, otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("DELETE /projects/:id should move its tasks to the inbox", func(t *testing.T) {
+		w := performRequest(router, "DELETE", "/projects/"+home.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = performRequest(router, "GET", "/projects/"+home.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = performRequest(router, "GET", "/tasks/"+paint.ID.String(), "", authToken)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.Nil(t, task.ProjectID)
+		assert.Greater(t, task.Version, paint.Version)
+		assert.ElementsMatch(t, []string{"Call mom", "Paint the fence", "Mow the lawn"}, listTitles("/tasks/?project_id=none"))
+	})
+}
+
+func TestDependencies(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "dependencyuser@example.com")
+
+	decodeTask := func(w *httptest.ResponseRecorder) models.Task {
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		return task
+	}
+	createTask := func(body string) models.Task {
+		w := performRequest(router, "POST", "/tasks/", body, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code, body)
+		return decodeTask(w)
+	}
+	getTask := func(id uuid.UUID) models.Task {
+		w := performRequest(router, "GET", "/tasks/"+id.String(), "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		return decodeTask(w)
+	}
+	addBlockers := func(task models.Task, blockers ...models.Task) *httptest.ResponseRecorder {
+		ids := make([]string, len(blockers))
+		for i, blocker := range blockers {
+			ids[i] = `"` + blocker.ID.String() + `"`
+		}
+		return performRequest(router, "POST", "/tasks/"+task.ID.String()+"/blockers", `{"blocker_ids": [`+strings.Join(ids, ", ")+`]}`, authToken)
+	}
+
+	paint := createTask(`{"title": "Buy paint"}`)
+	fence := createTask(`{"title": "Paint the fence"}`)
+	photos := createTask(`{"title": "Take photos of the fence"}`)
+
+	t.Run("POST /tasks/:id/blockers should block a task", func(t *testing.T) {
+		w := addBlockers(fence, paint)
+		assert.Equal(t, http.StatusOK, w.Code)
+		task := decodeTask(w)
+		assert.Equal(t, []uuid.UUID{paint.ID}, task.BlockedBy)
+		assert.True(t, task.Blocked)
+		assert.Equal(t, fence.Version+1, task.Version)
+		assert.False(t, getTask(paint.ID).Blocked)
+
+		w = addBlockers(photos, fence)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("POST /tasks/:id/blockers should refuse cycles and invalid blockers", func(t *testing.T) {
+		w := addBlockers(paint, fence)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		w = addBlockers(paint, photos)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		w = addBlockers(paint, paint)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = addBlockers(paint, models.Task{ID: uuid.New()})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, getTask(paint.ID).BlockedBy)
+	})
+
+	t.Run("completing a blocked task should be refused", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/"+fence.ID.String()+"/complete", "", authToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		w = performRequest(router, "PATCH", "/tasks/"+fence.ID.String(), `{"title": "Paint it", "completed": true}`, authToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		w = performRequest(router, "PUT", "/tasks/"+fence.ID.String(), `{"title": "Paint it", "completed": true}`, authToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		task := getTask(fence.ID)
+		assert.Equal(t, "Paint the fence", task.Title)
+		assert.False(t, task.Completed)
+	})
+
+	t.Run("completing the blocker should unblock its dependents", func(t *testing.T) {
+		before := getTask(fence.ID)
+		w := performRequest(router, "POST", "/tasks/"+paint.ID.String()+"/complete", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		task := getTask(fence.ID)
+		assert.False(t, task.Blocked)
+		assert.Equal(t, []uuid.UUID{paint.ID}, task.BlockedBy)
+		assert.Greater(t, task.Version, before.Version)
+		assert.True(t, getTask(photos.ID).Blocked)
+	})
+
+	t.Run("?force=true should complete a blocked task", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/"+photos.ID.String()+"/complete?force=true", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, decodeTask(w).Completed)
+	})
+
+	t.Run("POST /tasks/ should accept blockers", func(t *testing.T) {
+		task := createTask(`{"title": "Hang the gate", "blocked_by": ["` + fence.ID.String() + `", "` + paint.ID.String() + `"]}`)
+		assert.ElementsMatch(t, []uuid.UUID{fence.ID, paint.ID}, task.BlockedBy)
+		assert.True(t, task.Blocked)
+
+		w := performRequest(router, "POST", "/tasks/", `{"title": "Oops", "blocked_by": ["`+uuid.New().String()+`"]}`, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("blockers in the trash should not block", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/"+fence.ID.String()+"/reopen", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, getTask(photos.ID).Blocked)
+
+		w = performRequest(router, "DELETE", "/tasks/"+fence.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		task := getTask(photos.ID)
+		assert.False(t, task.Blocked)
+		assert.Empty(t, task.BlockedBy)
+
+		w = performRequest(router, "POST", "/tasks/"+fence.ID.String()+"/restore", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, getTask(photos.ID).Blocked)
+	})
+
+	t.Run("DELETE /tasks/:id/blockers/:blockerId should unblock a task", func(t *testing.T) {
+		w := performRequest(router, "DELETE", "/tasks/"+photos.ID.String()+"/blockers/"+fence.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		task := getTask(photos.ID)
+		assert.False(t, task.Blocked)
+		assert.Empty(t, task.BlockedBy)
+
+		w = performRequest(router, "DELETE", "/tasks/"+photos.ID.String()+"/blockers/"+fence.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("tasks of other users should not be blockers", func(t *testing.T) {
+		otherToken := registerAndLogin(t, router, "otherdependencyuser@example.com")
+		w := performRequest(router, "POST", "/tasks/", `{"title": "Mine"}`, otherToken)
+		other := decodeTask(w)
+
+		w = performRequest(router, "POST", "/tasks/"+other.ID.String()+"/blockers", `{"blocker_ids": ["`+paint.ID.String()+`"]}`, otherToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "POST", "/tasks/"+paint.ID.String()+"/blockers", `{"blocker_ids": ["`+other.ID.String()+`"]}`, otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSharing(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	ownerToken := registerAndLogin(t, router, "sharingowner@example.com")
+	editorToken := registerAndLogin(t, router, "sharingeditor@example.com")
+	viewerToken := registerAndLogin(t, router, "sharingviewer@example.com")
+	outsiderToken := registerAndLogin(t, router, "sharingoutsider@example.com")
+
+	w := performRequest(router, "POST", "/projects", `{"name": "Household"}`, ownerToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var project models.Project
+	json.Unmarshal(w.Body.Bytes(), &project)
+	assert.Equal(t, models.ProjectRoleOwner, project.Role)
+	projectPath := "/projects/" + project.ID.String()
+
+	w = performRequest(router, "POST", "/tasks/", `{"title": "Clean the gutters", "project_id": "`+project.ID.String()+`"}`, ownerToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var gutters models.Task
+	json.Unmarshal(w.Body.Bytes(), &gutters)
+	taskPath := "/tasks/" + gutters.ID.String()
+	w = performRequest(router, "POST", taskPath+"/subtasks", `{"title": "Borrow a ladder"}`, ownerToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	invite := func(email string, role string) *httptest.ResponseRecorder {
+		return performRequest(router, "POST", projectPath+"/invitations", `{"email": "`+email+`", "role": "`+role+`"}`, ownerToken)
+	}
+	pendingInvitations := func(token string) []models.ProjectInvitation {
+		w := performRequest(router, "GET", "/invitations", "", token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var invitations []models.ProjectInvitation
+		json.Unmarshal(w.Body.Bytes(), &invitations)
+		return invitations
+	}
+	accept := func(token string) {
+		invitations := pendingInvitations(token)
+		if assert.Len(t, invitations, 1) {
+			w := performRequest(router, "POST", "/invitations/"+invitations[0].ID.String()+"/accept", "", token)
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+	}
+	members := func() []models.ProjectMember {
+		w := performRequest(router, "GET", projectPath+"/members", "", ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var members []models.ProjectMember
+		json.Unmarshal(w.Body.Bytes(), &members)
+		return members
+	}
+
+	t.Run("POST /projects/:id/invitations should invite registered users", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, invite("sharingeditor@example.com", "viewer").Code)
+		// Inviting again changes the pending invitation's role
+		assert.Equal(t, http.StatusCreated, invite("sharingeditor@example.com", "editor").Code)
+		assert.Equal(t, http.StatusCreated, invite("sharingviewer@example.com", "viewer").Code)
+		assert.Equal(t, http.StatusCreated, invite("sharingoutsider@example.com", "editor").Code)
+		assert.Equal(t, http.StatusBadRequest, invite("nobody@example.com", "viewer").Code)
+		assert.Equal(t, http.StatusBadRequest, invite("sharingviewer@example.com", "admin").Code)
+		assert.Equal(t, http.StatusBadRequest, invite("sharingowner@example.com", "viewer").Code)
+
+		w := performRequest(router, "GET", projectPath+"/invitations", "", ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var invitations []models.ProjectInvitation
+		json.Unmarshal(w.Body.Bytes(), &invitations)
+		assert.Len(t, invitations, 3)
+
+		invitations = pendingInvitations(editorToken)
+		if assert.Len(t, invitations, 1) {
+			assert.Equal(t, "Household", invitations[0].ProjectName)
+			assert.Equal(t, models.ProjectRoleEditor, invitations[0].Role)
+		}
+	})
+
+	t.Run("answering an invitation should add or leave out the member", func(t *testing.T) {
+		// Until they accept, invitees cannot see the project's tasks
+		w := performRequest(router, "GET", taskPath, "", editorToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		accept(editorToken)
+		accept(viewerToken)
+		invitations := pendingInvitations(outsiderToken)
+		if assert.Len(t, invitations, 1) {
+			w = performRequest(router, "POST", "/invitations/"+invitations[0].ID.String()+"/decline", "", outsiderToken)
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			// Answered invitations cannot be answered again
+			w = performRequest(router, "POST", "/invitations/"+invitations[0].ID.String()+"/accept", "", outsiderToken)
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		}
+		assert.Empty(t, pendingInvitations(outsiderToken))
+
+		roles := map[string]string{}
+		for _, member := range members() {
+			roles[member.Email] = member.Role
+		}
+		assert.Equal(t, map[string]string{
+			"sharingowner@example.com":  models.ProjectRoleOwner,
+			"sharingeditor@example.com": models.ProjectRoleEditor,
+			"sharingviewer@example.com": models.ProjectRoleViewer,
+		}, roles)
+	})
+
+	t.Run("members should see the project and its tasks", func(t *testing.T) {
+		w := performRequest(router, "GET", "/projects", "", viewerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var projects []models.Project
+		json.Unmarshal(w.Body.Bytes(), &projects)
+		if assert.Len(t, projects, 1) {
+			assert.Equal(t, models.ProjectRoleViewer, projects[0].Role)
+			assert.Equal(t, 1, projects[0].OpenCount)
+		}
+
+		w = performRequest(router, "GET", "/tasks/?project_id="+project.ID.String(), "", viewerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var tasks []models.Task
+		json.Unmarshal(w.Body.Bytes(), &tasks)
+		if assert.Len(t, tasks, 1) {
+			assert.Equal(t, gutters.ID, tasks[0].ID)
+			assert.Len(t, tasks[0].Subtasks, 1)
+		}
+
+		w = performRequest(router, "GET", taskPath, "", outsiderToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("roles should decide who may change what", func(t *testing.T) {
+		w := performRequest(router, "PATCH", taskPath, `{"title": "Clean all gutters"}`, editorToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = performRequest(router, "PATCH", taskPath, `{"title": "Skip the gutters"}`, viewerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = performRequest(router, "POST", taskPath+"/complete", "", viewerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = performRequest(router, "DELETE", taskPath, "", viewerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		// Only owners manage the project, and tasks cannot be taken out of it into a member's inbox
+		w = performRequest(router, "PATCH", projectPath, `{"name": "Our home"}`, editorToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = performRequest(router, "POST", projectPath+"/invitations", `{"email": "sharingoutsider@example.com", "role": "owner"}`, editorToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = performRequest(router, "PATCH", taskPath, `{"project_id": null}`, editorToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("tasks added by members should belong to the project's owner", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/", `{"title": "Buy bin bags", "project_id": "`+project.ID.String()+`"}`, editorToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.Equal(t, project.UserID, task.UserID)
+
+		w = performRequest(router, "POST", "/tasks/", `{"title": "Water the plants", "project_id": "`+project.ID.String()+`"}`, viewerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = performRequest(router, "GET", "/tasks/"+task.ID.String(), "", ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("members should sync the project's tasks and their deletions", func(t *testing.T) {
+		pull := func(since string) models.SyncChanges {
+			w := performRequest(router, "GET", "/sync?since="+since, "", editorToken)
+			assert.Equal(t, http.StatusOK, w.Code)
+			var changes models.SyncChanges
+			json.Unmarshal(w.Body.Bytes(), &changes)
+			return changes
+		}
+		changes := pull("")
+		ids := map[uuid.UUID]bool{}
+		for _, task := range changes.Created {
+			ids[task.ID] = true
+		}
+		assert.True(t, ids[gutters.ID])
+		assert.Len(t, changes.Created, 3, "the project's tasks and subtask")
+
+		w := performRequest(router, "POST", "/tasks/", `{"title": "Sweep the porch", "project_id": "`+project.ID.String()+`"}`, ownerToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var porch models.Task
+		json.Unmarshal(w.Body.Bytes(), &porch)
+		changes = pull(changes.Token)
+		if assert.Len(t, changes.Created, 1) {
+			assert.Equal(t, porch.ID, changes.Created[0].ID)
+		}
+
+		w = performRequest(router, "DELETE", "/tasks/"+porch.ID.String(), "", ownerToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		changes = pull(changes.Token)
+		if assert.Len(t, changes.Deleted, 1) {
+			assert.Equal(t, porch.ID, changes.Deleted[0].ID)
+		}
+		assert.Empty(t, pull(changes.Token).Deleted)
+
+		// A task taken out of the project leaves no tombstone, so the member syncs again from scratch
+		w = performRequest(router, "POST", "/tasks/", `{"title": "Paint the shed", "project_id": "`+project.ID.String()+`"}`, ownerToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var shed models.Task
+		json.Unmarshal(w.Body.Bytes(), &shed)
+		changes = pull(changes.Token)
+		assert.Len(t, changes.Created, 1)
+		w = performRequest(router, "PATCH", "/tasks/"+shed.ID.String(), `{"project_id": null}`, ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = performRequest(router, "GET", "/sync?since="+changes.Token, "", editorToken)
+		assert.Equal(t, http.StatusGone, w.Code)
+		for _, task := range pull("").Created {
+			assert.NotEqual(t, shed.ID, task.ID)
+		}
+		w = performRequest(router, "DELETE", "/tasks/"+shed.ID.String(), "", ownerToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("editors should restore the project's tasks from the trash", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/", `{"title": "Fix the fence", "project_id": "`+project.ID.String()+`"}`, editorToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var fence models.Task
+		json.Unmarshal(w.Body.Bytes(), &fence)
+		w = performRequest(router, "DELETE", "/tasks/"+fence.ID.String(), "", editorToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = performRequest(router, "GET", "/tasks/trash", "", editorToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var trash []models.TrashedTask
+		json.Unmarshal(w.Body.Bytes(), &trash)
+		if assert.NotEmpty(t, trash) {
+			assert.Equal(t, fence.ID, trash[0].ID, "most recently deleted first")
+		}
+		w = performRequest(router, "GET", "/tasks/trash", "", outsiderToken)
+		assert.Equal(t, "[]", w.Body.String())
+
+		w = performRequest(router, "POST", "/tasks/"+fence.ID.String()+"/restore", "", viewerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = performRequest(router, "POST", "/tasks/"+fence.ID.String()+"/restore", "", outsiderToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = performRequest(router, "POST", "/tasks/"+fence.ID.String()+"/restore", "", editorToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = performRequest(router, "GET", "/tasks/"+fence.ID.String(), "", ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("members should filter the project's tasks by the owner's tags", func(t *testing.T) {
+		w := performRequest(router, "POST", taskPath+"/tags", `{"tags": ["Outdoors", "weekend"]}`, ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		for _, query := range []string{"tags_any=outdoors", "tags_all=outdoors,Weekend"} {
+			w = performRequest(router, "GET", "/tasks/?project_id="+project.ID.String()+"&"+query, "", editorToken)
+			assert.Equal(t, http.StatusOK, w.Code)
+			var tasks []models.Task
+			json.Unmarshal(w.Body.Bytes(), &tasks)
+			if assert.Len(t, tasks, 1, query) {
+				assert.Equal(t, gutters.ID, tasks[0].ID)
+			}
+		}
+	})
+
+	t.Run("owners should manage roles and members should be able to leave", func(t *testing.T) {
+		var ownerID, viewerID uuid.UUID
+		for _, member := range members() {
+			switch member.Email {
+			case "sharingowner@example.com":
+				ownerID = member.UserID
+			case "sharingviewer@example.com":
+				viewerID = member.UserID
+			}
+		}
+
+		w := performRequest(router, "PATCH", projectPath+"/members/"+ownerID.String(), `{"role": "viewer"}`, ownerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "PATCH", projectPath+"/members/"+viewerID.String(), `{"role": "editor"}`, ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = performRequest(router, "PATCH", taskPath, `{"priority": "high"}`, viewerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = performRequest(router, "GET", "/sync", "", viewerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var changes models.SyncChanges
+		json.Unmarshal(w.Body.Bytes(), &changes)
+
+		w = performRequest(router, "DELETE", projectPath+"/members/"+ownerID.String(), "", viewerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = performRequest(router, "DELETE", projectPath+"/members/"+viewerID.String(), "", viewerToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = performRequest(router, "GET", taskPath, "", viewerToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Len(t, members(), 2)
+
+		// The project's tasks left with it, so the member syncs again from scratch
+		w = performRequest(router, "GET", "/sync?since="+changes.Token, "", viewerToken)
+		assert.Equal(t, http.StatusGone, w.Code)
+	})
+
+	t.Run("subtasks should follow their parent into a shared project", func(t *testing.T) {
+		w := performRequest(router, "POST", "/tasks/", `{"title": "Plan the garden"}`, ownerToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var garden models.Task
+		json.Unmarshal(w.Body.Bytes(), &garden)
+		w = performRequest(router, "POST", "/tasks/"+garden.ID.String()+"/subtasks", `{"title": "Pick seeds"}`, ownerToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		w = performRequest(router, "GET", "/tasks/"+garden.ID.String(), "", editorToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = performRequest(router, "PATCH", "/tasks/"+garden.ID.String(), `{"project_id": "`+project.ID.String()+`"}`, ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = performRequest(router, "GET", "/tasks/"+garden.ID.String(), "", editorToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		if assert.Len(t, task.Subtasks, 1) {
+			assert.Equal(t, &project.ID, task.Subtasks[0].ProjectID)
+		}
+	})
+}
+
+func TestAssignees(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	ownerToken := registerAndLogin(t, router, "assignowner@example.com")
+	memberToken := registerAndLogin(t, router, "assignmember@example.com")
+	outsiderToken := registerAndLogin(t, router, "assignoutsider@example.com")
+
+	w := performRequest(router, "POST", "/projects", `{"name": "Launch"}`, ownerToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var project models.Project
+	json.Unmarshal(w.Body.Bytes(), &project)
+	projectPath := "/projects/" + project.ID.String()
+
+	w = performRequest(router, "POST", projectPath+"/invitations", `{"email": "assignmember@example.com", "role": "editor"}`, ownerToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var invitation models.ProjectInvitation
+	json.Unmarshal(w.Body.Bytes(), &invitation)
+	w = performRequest(router, "POST", "/invitations/"+invitation.ID.String()+"/accept", "", memberToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	ownerID, memberID := project.UserID, invitation.InviteeID
+	w = performRequest(router, "GET", "/auth/me", "", outsiderToken)
+	var outsider models.User
+	json.Unmarshal(w.Body.Bytes(), &outsider)
+
+	createTask := func(body string, token string) (*httptest.ResponseRecorder, models.Task) {
+		w := performRequest(router, "POST", "/tasks/", body, token)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		return w, task
+	}
+	listTasks := func(query string, token string) []models.Task {
+		w := performRequest(router, "GET", "/tasks/?"+query, "", token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var tasks []models.Task
+		json.Unmarshal(w.Body.Bytes(), &tasks)
+		return tasks
+	}
+
+	var slides models.Task
+	t.Run("tasks should only be assigned to members of their project", func(t *testing.T) {
+		w, task := createTask(`{"title": "Send the slides", "project_id": "`+project.ID.String()+`", "assignee_id": "`+memberID.String()+`"}`, ownerToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, &memberID, task.AssigneeID)
+		slides = task
+
+		w, _ = createTask(`{"title": "Book the room", "project_id": "`+project.ID.String()+`", "assignee_id": "`+outsider.ID.String()+`"}`, ownerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		// Inbox tasks can only be assigned to their owner
+		w, _ = createTask(`{"title": "Draft the invite", "assignee_id": "`+memberID.String()+`"}`, ownerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w, task = createTask(`{"title": "Draft the invite", "assignee_id": "`+ownerID.String()+`"}`, ownerToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, &ownerID, task.AssigneeID)
+
+		w, _ = createTask(`{"title": "Order the banner", "project_id": "`+project.ID.String()+`"}`, memberToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("GET /tasks?assignee should list the tasks assigned to a user", func(t *testing.T) {
+		tasks := listTasks("assignee=me", memberToken)
+		if assert.Len(t, tasks, 1) {
+			assert.Equal(t, slides.ID, tasks[0].ID)
+		}
+		tasks = listTasks("assignee=me", ownerToken)
+		if assert.Len(t, tasks, 1) {
+			assert.Equal(t, "Draft the invite", tasks[0].Title)
+		}
+		tasks = listTasks("assignee="+memberID.String(), ownerToken)
+		assert.Len(t, tasks, 1)
+		tasks = listTasks("assignee=none&project_id="+project.ID.String(), memberToken)
+		if assert.Len(t, tasks, 1) {
+			assert.Equal(t, "Order the banner", tasks[0].Title)
+		}
+
+		w := performRequest(router, "GET", "/tasks/?assignee=someone", "", ownerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("reassigning a task should be logged in its history", func(t *testing.T) {
+		taskPath := "/tasks/" + slides.ID.String()
+		w := performRequest(router, "PATCH", taskPath, `{"assignee_id": "`+outsider.ID.String()+`"}`, memberToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "PATCH", taskPath, `{"assignee_id": "`+ownerID.String()+`"}`, memberToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = performRequest(router, "PATCH", taskPath, `{"assignee_id": null}`, memberToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.Nil(t, task.AssigneeID)
+		// Replacing the task leaves its assignee alone
+		w = performRequest(router, "PATCH", taskPath, `{"assignee_id": "`+memberID.String()+`"}`, memberToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = performRequest(router, "PUT", taskPath, `{"title": "Send the final slides"}`, memberToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = performRequest(router, "GET", taskPath+"/history", "", ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var history models.TaskHistory
+		json.Unmarshal(w.Body.Bytes(), &history)
+		assert.Equal(t, 0, history.CompletionCount)
+		if assert.Len(t, history.Events, 4) {
+			assert.Equal(t, models.TaskEventAssigned, history.Events[0].Type)
+			assert.Nil(t, history.Events[0].PreviousAssigneeID)
+			assert.Equal(t, &memberID, history.Events[0].AssigneeID)
+			assert.Equal(t, &memberID, history.Events[1].PreviousAssigneeID)
+			assert.Equal(t, &ownerID, history.Events[1].AssigneeID)
+			assert.Equal(t, &ownerID, history.Events[2].PreviousAssigneeID)
+			assert.Nil(t, history.Events[2].AssigneeID)
+			assert.Equal(t, &memberID, history.Events[3].AssigneeID)
+		}
+	})
+
+	t.Run("tasks should be unassigned when their assignee cannot follow them", func(t *testing.T) {
+		w, task := createTask(`{"title": "Print flyers", "project_id": "`+project.ID.String()+`", "assignee_id": "`+memberID.String()+`"}`, ownerToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		taskPath := "/tasks/" + task.ID.String()
+		w = performRequest(router, "POST", taskPath+"/subtasks", `{"title": "Pick paper", "assignee_id": "`+memberID.String()+`"}`, ownerToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		// Only the owner can be assigned tasks in their inbox
+		w = performRequest(router, "PATCH", taskPath, `{"project_id": null}`, ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.Nil(t, task.AssigneeID)
+		if assert.Len(t, task.Subtasks, 1) {
+			assert.Nil(t, task.Subtasks[0].AssigneeID)
+		}
+
+		// Members who leave a project leave its tasks unassigned
+		assert.Len(t, listTasks("assignee=me", memberToken), 1)
+		w = performRequest(router, "DELETE", projectPath+"/members/"+memberID.String(), "", memberToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		tasks := listTasks("assignee="+memberID.String(), ownerToken)
+		assert.Empty(t, tasks)
+		w = performRequest(router, "GET", "/tasks/"+slides.ID.String(), "", ownerToken)
+		json.Unmarshal(w.Body.Bytes(), &task)
+		assert.Nil(t, task.AssigneeID)
+	})
+}
+
+func TestComments(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	ownerToken := registerAndLogin(t, router, "commentowner@example.com")
+	viewerToken := registerAndLogin(t, router, "commentviewer@example.com")
+	outsiderToken := registerAndLogin(t, router, "commentoutsider@example.com")
+
+	w := performRequest(router, "POST", "/projects", `{"name": "Garden"}`, ownerToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var project models.Project
+	json.Unmarshal(w.Body.Bytes(), &project)
+	w = performRequest(router, "POST", "/projects/"+project.ID.String()+"/invitations", `{"email": "commentviewer@example.com", "role": "viewer"}`, ownerToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var invitation models.ProjectInvitation
+	json.Unmarshal(w.Body.Bytes(), &invitation)
+	w = performRequest(router, "POST", "/invitations/"+invitation.ID.String()+"/accept", "", viewerToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "POST", "/tasks/", `{"title": "Plant tulips", "project_id": "`+project.ID.String()+`"}`, ownerToken)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var task models.Task
+	json.Unmarshal(w.Body.Bytes(), &task)
+	commentsPath := "/tasks/" + task.ID.String() + "/comments"
+
+	var ownerComment, viewerComment models.Comment
+	t.Run("everyone who can see a task should be able to comment on it", func(t *testing.T) {
+		w := performRequest(router, "POST", commentsPath, `{"body": "Bulbs are in the **shed**"}`, ownerToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &ownerComment)
+		assert.Equal(t, "Bulbs are in the **shed**", ownerComment.Body)
+		assert.Equal(t, "commentowner@example.com", ownerComment.AuthorEmail)
+		assert.Nil(t, ownerComment.EditedAt)
+
+		w = performRequest(router, "POST", commentsPath, `{"body": "On it"}`, viewerToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &viewerComment)
+
+		w = performRequest(router, "POST", commentsPath, `{"body": "   "}`, viewerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = performRequest(router, "POST", commentsPath, `{"body": "Let me in"}`, outsiderToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = performRequest(router, "GET", commentsPath, "", viewerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var comments []models.Comment
+		json.Unmarshal(w.Body.Bytes(), &comments)
+		if assert.Len(t, comments, 2) {
+			assert.Equal(t, ownerComment.ID, comments[0].ID)
+			assert.Equal(t, "commentviewer@example.com", comments[1].AuthorEmail)
+		}
+	})
+
+	t.Run("only the author should be able to edit a comment", func(t *testing.T) {
+		w := performRequest(router, "PATCH", commentsPath+"/"+ownerComment.ID.String(), `{"body": "Hijacked"}`, viewerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = performRequest(router, "PATCH", commentsPath+"/"+viewerComment.ID.String(), `{"body": "On it, after lunch"}`, viewerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var comment models.Comment
+		json.Unmarshal(w.Body.Bytes(), &comment)
+		assert.Equal(t, "On it, after lunch", comment.Body)
+		assert.NotNil(t, comment.EditedAt)
+
+		w = performRequest(router, "PATCH", commentsPath+"/"+uuid.New().String(), `{"body": "Nobody"}`, viewerToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("authors and the project's owners should be able to delete comments", func(t *testing.T) {
+		w := performRequest(router, "DELETE", commentsPath+"/"+ownerComment.ID.String(), "", viewerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = performRequest(router, "DELETE", commentsPath+"/"+viewerComment.ID.String(), "", ownerToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = performRequest(router, "DELETE", commentsPath+"/"+ownerComment.ID.String(), "", ownerToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = performRequest(router, "GET", commentsPath, "", ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("GET /tasks/:id/activity should list what was done to the task", func(t *testing.T) {
+		taskPath := "/tasks/" + task.ID.String()
+		w := performRequest(router, "POST", taskPath+"/subtasks", `{"title": "Buy bulbs"}`, ownerToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var subtask models.Task
+		json.Unmarshal(w.Body.Bytes(), &subtask)
+		w = performRequest(router, "PATCH", taskPath, `{"title": "Plant tulips and crocuses", "due_date": "2030-03-01T09:00:00Z", "assignee_id": "`+project.UserID.String()+`"}`, ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = performRequest(router, "POST", taskPath+"/complete?cascade=true", "", ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = performRequest(router, "POST", taskPath+"/reopen", "", ownerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = performRequest(router, "GET", taskPath+"/activity", "", viewerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var activity []models.TaskEvent
+		json.Unmarshal(w.Body.Bytes(), &activity)
+		if assert.Len(t, activity, 6) {
+			assert.Equal(t, models.TaskEventCreated, activity[0].Type)
+			assert.Equal(t, "commentowner@example.com", activity[0].ActorEmail)
+			// The events of a single change share their time, so their order is not fixed
+			changes := make(map[string]models.TaskEvent)
+			for _, event := range activity[1:4] {
+				changes[event.Type] = event
+			}
+			assert.Equal(t, "Plant tulips", changes[models.TaskEventTitleChanged].From)
+			assert.Equal(t, "Plant tulips and crocuses", changes[models.TaskEventTitleChanged].To)
+			assert.Equal(t, "", changes[models.TaskEventDueDateChanged].From)
+			assert.Equal(t, "2030-03-01T09:00:00Z", changes[models.TaskEventDueDateChanged].To)
+			assert.Nil(t, changes[models.TaskEventAssigned].PreviousAssigneeID)
+			assert.Equal(t, &project.UserID, changes[models.TaskEventAssigned].AssigneeID)
+			assert.Equal(t, models.TaskEventCompleted, activity[4].Type)
+			assert.Equal(t, models.TaskEventReopened, activity[5].Type)
+		}
+
+		// Completing the task completed its subtask along with it
+		w = performRequest(router, "GET", "/tasks/"+subtask.ID.String()+"/activity", "", viewerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		activity = nil
+		json.Unmarshal(w.Body.Bytes(), &activity)
+		if assert.Len(t, activity, 2) {
+			assert.Equal(t, models.TaskEventCreated, activity[0].Type)
+			assert.Equal(t, models.TaskEventCompleted, activity[1].Type)
+			assert.Equal(t, "commentowner@example.com", activity[1].ActorEmail)
+		}
+
+		w = performRequest(router, "GET", taskPath+"/activity", "", outsiderToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// newAttachmentUploadRequest builds a multipart POST /tasks/:id/attachments request carrying the given file
+func newAttachmentUploadRequest(taskID uuid.UUID, filename string, content []byte, authToken string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", filename)
+	part.Write(content)
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/tasks/"+taskID.String()+"/attachments", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	return req
+}
+
+// pngHeader is enough of a PNG signature for content sniffing to recognise the upload as an image
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestAttachments(t *testing.T) {
+	router, db, err := setupTestEnvironment()
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	authToken := registerAndLogin(t, router, "attachuser@example.com")
+	outsiderToken := registerAndLogin(t, router, "attachoutsider@example.com")
+
+	createTask := func(title string) models.Task {
+		w := performRequest(router, "POST", "/tasks/", `{"title": "`+title+`"}`, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		return task
+	}
+	upload := func(taskID uuid.UUID, filename string, content []byte, token string) (*httptest.ResponseRecorder, models.Attachment) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newAttachmentUploadRequest(taskID, filename, content, token))
+		var attachment models.Attachment
+		json.Unmarshal(w.Body.Bytes(), &attachment)
+		return w, attachment
+	}
+	storageKey := func(id uuid.UUID) string {
+		var attachment models.Attachment
+		db.Where("id = ?", id).Take(&attachment)
+		return attachment.StorageKey
+	}
+	blobExists := func(key string) bool {
+		blob, err := blobStore.Get(context.Background(), key)
+		if err != nil {
+			return false
+		}
+		blob.Close()
+		return true
+	}
+
+	task := createTask("Fix the bike")
+	photo := append(append([]byte{}, pngHeader...), []byte("flat tyre")...)
+	var attachment models.Attachment
+	t.Run("POST /tasks/:id/attachments should store the file and record its sniffed type", func(t *testing.T) {
+		var w *httptest.ResponseRecorder
+		w, attachment = upload(task.ID, "tyre.png", photo, authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "tyre.png", attachment.Filename)
+		assert.Equal(t, "image/png", attachment.MimeType)
+		assert.Equal(t, int64(len(photo)), attachment.SizeBytes)
+		assert.True(t, blobExists(storageKey(attachment.ID)))
+
+		w, notes := upload(task.ID, "notes.md", []byte("# Parts\n- inner tube\n"), authToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "text/markdown; charset=utf-8", notes.MimeType)
+
+		w, _ = upload(task.ID, "page.png", []byte("<html><script>alert(1)</script></html>"), authToken)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		w, _ = upload(task.ID, "huge.png", make([]byte, maxAttachmentBytes+1), authToken)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		w, _ = upload(task.ID, "empty.png", []byte{}, authToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w, _ = upload(task.ID, "tyre.png", photo, outsiderToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("attachments should be listed and included in the task", func(t *testing.T) {
+		w := performRequest(router, "GET", "/tasks/"+task.ID.String()+"/attachments", "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var attachments []models.Attachment
+		json.Unmarshal(w.Body.Bytes(), &attachments)
+		if assert.Len(t, attachments, 2) {
+			assert.Equal(t, attachment.ID, attachments[0].ID)
+		}
+		assert.NotContains(t, w.Body.String(), "storage_key")
+
+		w = performRequest(router, "GET", "/tasks/"+task.ID.String(), "", authToken)
+		var fetched models.Task
+		json.Unmarshal(w.Body.Bytes(), &fetched)
+		if assert.Len(t, fetched.Attachments, 2) {
+			assert.Equal(t, "tyre.png", fetched.Attachments[0].Filename)
+		}
+		assert.Equal(t, task.Version+2, fetched.Version, "each attachment is a change to the task")
+	})
+
+	t.Run("GET /tasks/:id/attachments/:attachmentId should download the file", func(t *testing.T) {
+		path := "/tasks/" + task.ID.String() + "/attachments/" + attachment.ID.String()
+		w := performRequest(router, "GET", path, "", authToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, photo, w.Body.Bytes())
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename=tyre.png`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+
+		w = performRequest(router, "GET", path, "", outsiderToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		other := createTask("Oil the chain")
+		w = performRequest(router, "GET", "/tasks/"+other.ID.String()+"/attachments/"+attachment.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("DELETE /tasks/:id/attachments/:attachmentId should remove the stored file", func(t *testing.T) {
+		key := storageKey(attachment.ID)
+		path := "/tasks/" + task.ID.String() + "/attachments/" + attachment.ID.String()
+		w := performRequest(router, "DELETE", path, "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.False(t, blobExists(key))
+		w = performRequest(router, "GET", path, "", authToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = performRequest(router, "GET", "/tasks/"+task.ID.String(), "", authToken)
+		var fetched models.Task
+		json.Unmarshal(w.Body.Bytes(), &fetched)
+		assert.Equal(t, task.Version+3, fetched.Version)
+	})
+
+	t.Run("purging a task from the trash should remove its files", func(t *testing.T) {
+		w := performRequest(router, "GET", "/tasks/"+task.ID.String()+"/attachments", "", authToken)
+		var attachments []models.Attachment
+		json.Unmarshal(w.Body.Bytes(), &attachments)
+		if !assert.Len(t, attachments, 1) {
+			return
+		}
+		key := storageKey(attachments[0].ID)
+
+		w = performRequest(router, "DELETE", "/tasks/"+task.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.True(t, blobExists(key), "files stay while the task is in the trash")
+		w = performRequest(router, "DELETE", "/tasks/trash/"+task.ID.String(), "", authToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.False(t, blobExists(key))
+		var count int64
+		db.Model(&models.Attachment{}).Where("task_id = ?", task.ID).Count(&count)
+		assert.Zero(t, count)
+	})
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"errors"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxAttachmentBytes is the largest file that can be attached to a task
+const maxAttachmentBytes = 10 << 20
+
+// maxAttachmentFilenameLength is the longest filename kept for an attachment, in bytes
+const maxAttachmentFilenameLength = 255
+
+// zipAttachmentTypes maps the extensions of document formats that are zip archives inside, and
+// sniff as application/zip, to their MIME types
+var zipAttachmentTypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".odt":  "application/vnd.oasis.opendocument.text",
+	".ods":  "application/vnd.oasis.opendocument.spreadsheet",
+	".odp":  "application/vnd.oasis.opendocument.presentation",
+}
+
+// opaqueAttachmentTypes maps the extensions of image formats the sniffer does not recognise to
+// their MIME types
+var opaqueAttachmentTypes = map[string]string{
+	".heic": "image/heic",
+	".heif": "image/heif",
+}
+
+// textAttachmentTypes maps the extensions of plain-text formats to their MIME types
+var textAttachmentTypes = map[string]string{
+	".csv": "text/csv",
+	".md":  "text/markdown",
+}
+
+var attachmentService *services.AttachmentService // Will be initialized in main
+
+// SetAttachmentService sets the attachment service for the API handlers
+func SetAttachmentService(service *services.AttachmentService) {
+	attachmentService = service
+}
+
+// CreateAttachment handles uploading a file in the "file" form field and attaching it to a task
+func CreateAttachment(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Leave some headroom for the multipart envelope around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAttachmentBytes+(1<<20))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required in the 'file' form field"})
+		return
+	}
+
+	if fileHeader.Size > maxAttachmentBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+		return
+	}
+	if fileHeader.Size == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is empty"})
+		return
+	}
+	mimeType, err := detectAttachmentMimeType(fileHeader)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	attachment := &models.Attachment{
+		Filename:  attachmentFilename(fileHeader.Filename),
+		MimeType:  mimeType,
+		SizeBytes: fileHeader.Size,
+	}
+	if err := attachmentService.CreateAttachment(c.Request.Context(), taskID, file, attachment, userID); err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// GetAttachments handles listing the attachments of a task, oldest first
+func GetAttachments(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	attachments, err := attachmentService.GetAttachments(taskID, userID)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+	if attachments == nil {
+		attachments = []models.Attachment{}
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// DownloadAttachment handles streaming the content of an attachment
+func DownloadAttachment(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	attachmentID, ok := attachmentIDParam(c, "attachmentId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	attachment, content, err := attachmentService.OpenAttachment(c.Request.Context(), taskID, attachmentID, userID)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+	defer content.Close()
+
+	// Files are always downloaded rather than shown inline, and browsers must not second-guess
+	// their type
+	c.DataFromReader(http.StatusOK, attachment.SizeBytes, attachment.MimeType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment handles removing an attachment from a task
+func DeleteAttachment(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	attachmentID, ok := attachmentIDParam(c, "attachmentId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := attachmentService.DeleteAttachment(c.Request.Context(), taskID, attachmentID, userID); err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// detectAttachmentMimeType sniffs the content of an uploaded file and returns the MIME type to
+// record for it. Images, audio, video, PDFs, plain text and office documents are accepted; markup,
+// which browsers could run scripts from, and content that cannot be identified are not.
+func detectAttachmentMimeType(fileHeader *multipart.FileHeader) (string, error) {
+	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, _ := file.Read(head)
+	detected := http.DetectContentType(head[:n])
+	mediaType, _, _ := mime.ParseMediaType(detected)
+
+	switch {
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"),
+		mediaType == "application/pdf",
+		mediaType == "application/ogg":
+		return detected, nil
+	case mediaType == "text/plain":
+		if textType, ok := textAttachmentTypes[ext]; ok {
+			return strings.Replace(detected, mediaType, textType, 1), nil
+		}
+		return detected, nil
+	case mediaType == "application/zip":
+		if zipType, ok := zipAttachmentTypes[ext]; ok {
+			return zipType, nil
+		}
+		return detected, nil
+	case mediaType == "application/octet-stream":
+		if opaqueType, ok := opaqueAttachmentTypes[ext]; ok {
+			return opaqueType, nil
+		}
+		// Many audio containers are not recognised by the sniffer either
+		if audioType, ok := audioMimeTypes[ext]; ok {
+			return audioType, nil
+		}
+	}
+	return "", errors.New("unsupported file type: " + detected)
+}
+
+// attachmentFilename keeps the base name of an uploaded file, trimmed to a length the database holds
+func attachmentFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > maxAttachmentFilenameLength {
+		// Cutting through a multi-byte character leaves invalid UTF-8 at the end, which is dropped
+		name = strings.ToValidUTF8(name[:maxAttachmentFilenameLength], "")
+	}
+	return name
+}
+
+// attachmentIDParam parses an attachment ID path parameter, responding with 400 when it is malformed
+func attachmentIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondAttachmentError maps an AttachmentService error to 404 for missing attachments, 400 for
+// invalid ones and the task error statuses otherwise
+func respondAttachmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAttachmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidAttachment):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondTaskError(c, err)
+	}
+}
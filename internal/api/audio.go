@@ -0,0 +1,191 @@
+package api
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxAudioUploadBytes matches the file size limit of the Whisper API
+const maxAudioUploadBytes = 25 << 20
+
+// audioMimeTypes maps the audio formats accepted by POST /audio to their MIME types
+var audioMimeTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".mp4":  "audio/mp4",
+	".mpeg": "audio/mpeg",
+	".mpga": "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".wav":  "audio/wav",
+	".webm": "audio/webm",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".flac": "audio/flac",
+}
+
+var audioService *services.AudioService // Will be initialized in main
+
+// SetAudioService initializes the audioService
+func SetAudioService(service *services.AudioService) {
+	audioService = service
+}
+
+// UploadAudio handles uploading a voice note, transcribing it and creating tasks from it.
+// With "Prefer: respond-async" the audio is stored and a transcribe job is queued instead.
+func UploadAudio(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Leave some headroom for the multipart envelope around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAudioUploadBytes+(1<<20))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Audio file is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Audio file is required in the 'file' form field"})
+		return
+	}
+
+	if fileHeader.Size > maxAudioUploadBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Audio file is too large"})
+		return
+	}
+	if fileHeader.Size == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Audio file is empty"})
+		return
+	}
+	mimeType, err := detectAudioMimeType(fileHeader)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	upload := &models.AudioUpload{
+		UserID:    userID,
+		Filename:  filepath.Base(fileHeader.Filename),
+		MimeType:  mimeType,
+		SizeBytes: fileHeader.Size,
+	}
+
+	if wantsAsync(c) {
+		if err := audioService.StoreAudio(c.Request.Context(), file, upload); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		job, err := jobService.EnqueueTranscribe(c.Request.Context(), upload.ID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "audio_id": upload.ID})
+			return
+		}
+		respondAccepted(c, job, gin.H{"audio_id": upload.ID})
+		return
+	}
+
+	upload, err = audioService.ProcessAudio(c.Request.Context(), file, upload)
+	if err != nil {
+		response := gin.H{"error": err.Error()}
+		if upload != nil {
+			// The upload was recorded as failed and can still be inspected via GET /audio/:id
+			response["audio_id"] = upload.ID
+		}
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	c.JSON(http.StatusCreated, upload)
+}
+
+// GetAudioUploads handles fetching all audio uploads for the authenticated user
+func GetAudioUploads(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	uploads, err := audioService.GetAudioUploadsByUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, uploads)
+}
+
+// GetAudioUploadByID handles fetching a single audio upload with its transcript and tasks
+func GetAudioUploadByID(c *gin.Context) {
+	uploadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid audio upload ID"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	upload, err := audioService.GetAudioUploadByID(uploadID, userID)
+	if err != nil {
+		if err.Error() == "audio upload not found or unauthorized" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, upload)
+}
+
+// detectAudioMimeType checks the file extension and sniffs the content to reject non-audio uploads.
+// It returns the MIME type to record for the upload.
+func detectAudioMimeType(fileHeader *multipart.FileHeader) (string, error) {
+	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	mimeType, ok := audioMimeTypes[ext]
+	if !ok {
+		return "", errors.New("unsupported audio format: " + ext)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, _ := file.Read(head)
+	detected := http.DetectContentType(head[:n])
+
+	// Many audio containers are not recognised by the sniffer and come back as
+	// application/octet-stream, so only reject content that is clearly something else.
+	switch {
+	case strings.HasPrefix(detected, "audio/"):
+		return detected, nil
+	case strings.HasPrefix(detected, "video/"),
+		detected == "application/ogg",
+		detected == "application/octet-stream":
+		return mimeType, nil
+	}
+	return "", errors.New("file content does not look like audio: " + detected)
+}
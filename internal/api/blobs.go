@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"todo-backend/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+var blobStore storage.BlobStore // Will be initialized in main
+
+// SetBlobStore initializes the blobStore
+func SetBlobStore(store storage.BlobStore) {
+	blobStore = store
+}
+
+// signedURLVerifier is implemented by blob stores whose presigned URLs are served by this API
+type signedURLVerifier interface {
+	VerifySignedURL(key string, query url.Values) error
+}
+
+// ServeBlob handles downloads through presigned URLs issued by the local blob store.
+// Stores with their own URL scheme (such as S3) are never routed here.
+func ServeBlob(c *gin.Context) {
+	verifier, ok := blobStore.(signedURLVerifier)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := verifier.VerifySignedURL(key, c.Request.URL.Query()); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	blob, err := blobStore.Get(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer blob.Close()
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "application/octet-stream")
+	io.Copy(c.Writer, blob)
+}
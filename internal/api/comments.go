@@ -0,0 +1,167 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"todo-backend/internal/models"
+	"todo-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var commentService *services.CommentService // Will be initialized in main
+
+// SetCommentService sets the comment service for the API handlers
+func SetCommentService(service *services.CommentService) {
+	commentService = service
+}
+
+// CommentRequest is the body of POST /tasks/:id/comments and PATCH /tasks/:id/comments/:commentId:
+// the comment's text, in markdown
+type CommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// GetComments handles listing the comments on a task, oldest first
+func GetComments(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	comments, err := commentService.GetComments(taskID, userID)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+	if comments == nil {
+		comments = []models.Comment{}
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// CreateComment handles adding a comment to a task
+func CreateComment(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := commentService.CreateComment(taskID, req.Body, userID)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UpdateComment handles editing a comment the authenticated user wrote
+func UpdateComment(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	commentID, ok := commentIDParam(c, "commentId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := commentService.UpdateComment(taskID, commentID, req.Body, userID)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment handles removing a comment from a task
+func DeleteComment(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	commentID, ok := commentIDParam(c, "commentId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := commentService.DeleteComment(taskID, commentID, userID); err != nil {
+		respondCommentError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetTaskActivity handles listing the activity stream of a task, oldest first
+func GetTaskActivity(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	activity, err := taskService.GetTaskActivity(taskID, userID)
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	if activity == nil {
+		activity = []models.TaskEvent{}
+	}
+	c.JSON(http.StatusOK, activity)
+}
+
+// commentIDParam parses a comment ID path parameter, responding with 400 when it is malformed
+func commentIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondCommentError maps a CommentService error to 404 for missing comments, 400 for invalid
+// ones and the task error statuses otherwise
+func respondCommentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidComment):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondTaskError(c, err)
+	}
+}
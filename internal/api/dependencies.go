@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AddTaskBlockersRequest is the body of POST /tasks/:id/blockers: the tasks that have to be done
+// before this one
+type AddTaskBlockersRequest struct {
+	BlockerIDs []uuid.UUID `json:"blocker_ids" binding:"required"`
+}
+
+// AddTaskBlockers handles making a task wait on other tasks. Blockers that already wait on the
+// task, directly or through other tasks, are refused with 409.
+func AddTaskBlockers(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req AddTaskBlockersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := taskService.AddBlockers(taskID, userID, req.BlockerIDs)
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	respondTask(c, http.StatusOK, task)
+}
+
+// RemoveTaskBlocker handles stopping a task from waiting on one of its blockers
+func RemoveTaskBlocker(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	blockerID, ok := taskIDParam(c, "blockerId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := taskService.RemoveBlocker(taskID, blockerID, userID); err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// taskETag returns the entity tag of a task as served: its version followed by a digest of its
+// JSON. The subtask tree, tags, attachments and blocked flag are part of the response but can
+// change without the task's own version going up, so the version alone does not identify it.
+func taskETag(task *models.Task) string {
+	body, err := json.Marshal(task)
+	if err != nil {
+		return `"` + strconv.Itoa(task.Version) + `"`
+	}
+	digest := sha256.Sum256(body)
+	return `"` + strconv.Itoa(task.Version) + "-" + hex.EncodeToString(digest[:8]) + `"`
+}
+
+// ifMatch returns the precondition of the If-Match header: none when the header is absent or
+// "*", and otherwise that the task's current ETag is one of the listed tags. Weak tags never
+// match, as If-Match uses strong comparison, and a malformed list matches nothing. The
+// precondition is checked in the transaction that writes the task, so a change made after the
+// client read the task fails the request however close the two are.
+func ifMatch(c *gin.Context) repositories.TaskPrecondition {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return repositories.TaskPrecondition{}
+	}
+	tags, ok := parseETags(header)
+	return repositories.TaskPrecondition{Match: func(task *models.Task) bool {
+		if !ok {
+			return false
+		}
+		etag := taskETag(task)
+		for _, tag := range tags {
+			if tag == etag {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+// parseETags splits an If-Match or If-None-Match list into its entity tags, weak ones keeping
+// their W/ prefix. It reports false when the list is malformed.
+func parseETags(header string) ([]string, bool) {
+	var tags []string
+	for {
+		header = strings.TrimLeft(header, " \t,")
+		if header == "" {
+			return tags, len(tags) > 0
+		}
+		weak := strings.HasPrefix(header, "W/")
+		rest := strings.TrimPrefix(header, "W/")
+		if !strings.HasPrefix(rest, `"`) {
+			return nil, false
+		}
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return nil, false
+		}
+		tag := rest[:end+2]
+		if weak {
+			tag = "W/" + tag
+		}
+		tags = append(tags, tag)
+		header = rest[end+2:]
+		if trimmed := strings.TrimLeft(header, " \t"); trimmed != "" && trimmed[0] != ',' {
+			return nil, false
+		}
+	}
+}
+
+// respondTask writes a single task with its ETag header
+func respondTask(c *gin.Context, status int, task *models.Task) {
+	c.Header("ETag", taskETag(task))
+	c.JSON(status, task)
+}
+
+// respondTaskIfNoneMatch writes a task like respondTask, or 304 Not Modified when the client's
+// If-None-Match already names its current ETag. If-None-Match uses weak comparison.
+func respondTaskIfNoneMatch(c *gin.Context, task *models.Task) {
+	etag := taskETag(task)
+	header := strings.TrimSpace(c.GetHeader("If-None-Match"))
+	tags, _ := parseETags(header)
+	if header == "*" {
+		tags = []string{etag}
+	}
+	for _, tag := range tags {
+		if strings.TrimPrefix(tag, "W/") == etag {
+			c.Header("ETag", etag)
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+	respondTask(c, http.StatusOK, task)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
 	"todo-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -138,7 +139,7 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	if err := h.taskService.DeleteTask(userID, taskID); err != nil {
+	if err := h.taskService.DeleteTask(userID, taskID, repositories.TaskPrecondition{}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
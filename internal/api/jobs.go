@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var jobService *services.JobService // Will be initialized in main; nil disables asynchronous processing
+
+// SetJobService initializes the jobService
+func SetJobService(service *services.JobService) {
+	jobService = service
+}
+
+// GetJobByID handles fetching the status of a background job
+func GetJobByID(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if jobService == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found or unauthorized"})
+		return
+	}
+
+	job, err := jobService.GetJob(c.Request.Context(), jobID, userID)
+	if err != nil {
+		if err.Error() == "job not found or unauthorized" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// wantsAsync reports whether the client asked for the request to be processed in the
+// background with "Prefer: respond-async" (RFC 7240) and a job queue is available
+func wantsAsync(c *gin.Context) bool {
+	if jobService == nil {
+		return false
+	}
+	for _, header := range c.Request.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			if strings.EqualFold(strings.TrimSpace(preference), "respond-async") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// respondAccepted replies 202 with a pointer to the job that will do the work
+func respondAccepted(c *gin.Context, job *models.Job, extra gin.H) {
+	c.Header("Location", "/jobs/"+job.ID.String())
+	c.Header("Preference-Applied", "respond-async")
+
+	response := gin.H{"job_id": job.ID, "status": job.Status}
+	for key, value := range extra {
+		response[key] = value
+	}
+	c.JSON(http.StatusAccepted, response)
+}
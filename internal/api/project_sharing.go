@@ -0,0 +1,254 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"todo-backend/internal/models"
+	"todo-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UpdateProjectMemberRequest is the body of PATCH /projects/:id/members/:userId
+type UpdateProjectMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// CreateProjectInvitationRequest is the body of POST /projects/:id/invitations: the email of a
+// registered user and the role they get once they accept
+type CreateProjectInvitationRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role" binding:"required"`
+}
+
+// GetProjectMembers handles listing the members of a project with their roles
+func GetProjectMembers(c *gin.Context) {
+	id, ok := projectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	members, err := projectService.GetMembers(id, userID)
+	if err != nil {
+		respondSharingError(c, err)
+		return
+	}
+	if members == nil {
+		members = []models.ProjectMember{}
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// UpdateProjectMember handles giving a project member another role
+func UpdateProjectMember(c *gin.Context) {
+	id, ok := projectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := userIDParam(c, "userId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := projectService.UpdateMember(id, memberID, req.Role, userID)
+	if err != nil {
+		respondSharingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveProjectMember handles taking a user out of a project, or leaving it when the user is the
+// authenticated one
+func RemoveProjectMember(c *gin.Context) {
+	id, ok := projectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := userIDParam(c, "userId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := projectService.RemoveMember(id, memberID, userID); err != nil {
+		respondSharingError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetProjectInvitations handles listing the pending invitations to a project
+func GetProjectInvitations(c *gin.Context) {
+	id, ok := projectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := projectService.GetInvitations(id, userID)
+	if err != nil {
+		respondSharingError(c, err)
+		return
+	}
+	if invitations == nil {
+		invitations = []models.ProjectInvitation{}
+	}
+	c.JSON(http.StatusOK, invitations)
+}
+
+// CreateProjectInvitation handles inviting a registered user to a project by email
+func CreateProjectInvitation(c *gin.Context) {
+	id, ok := projectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateProjectInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := projectService.Invite(id, req.Email, req.Role, userID)
+	if err != nil {
+		respondSharingError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// RevokeProjectInvitation handles withdrawing an invitation that was not answered yet
+func RevokeProjectInvitation(c *gin.Context) {
+	id, ok := projectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	invitationID, ok := invitationIDParam(c, "invitationId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := projectService.RevokeInvitation(id, invitationID, userID); err != nil {
+		respondSharingError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetInvitations handles listing the invitations the authenticated user has not answered yet
+func GetInvitations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := projectService.GetUserInvitations(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if invitations == nil {
+		invitations = []models.ProjectInvitation{}
+	}
+	c.JSON(http.StatusOK, invitations)
+}
+
+// AcceptInvitation handles joining the project of an invitation; it responds with the project
+func AcceptInvitation(c *gin.Context) {
+	id, ok := invitationIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	project, err := projectService.AcceptInvitation(id, userID)
+	if err != nil {
+		respondSharingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// DeclineInvitation handles turning down an invitation
+func DeclineInvitation(c *gin.Context) {
+	id, ok := invitationIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := projectService.DeclineInvitation(id, userID); err != nil {
+		respondSharingError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// userIDParam parses a user ID path parameter, responding with 400 when it is malformed
+func userIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// invitationIDParam parses an invitation ID path parameter, responding with 400 when it is malformed
+func invitationIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondSharingError maps a sharing error to 404 for missing members and invitations, 400 for
+// invalid invitations and roles and the task error statuses otherwise
+func respondSharingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMemberNotFound), errors.Is(err, services.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidInvitation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondTaskError(c, err)
+	}
+}
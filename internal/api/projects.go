@@ -0,0 +1,232 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"todo-backend/internal/models"
+	"todo-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var projectService *services.ProjectService // Will be initialized in main
+
+// SetProjectService sets the project service for the API handlers
+func SetProjectService(service *services.ProjectService) {
+	projectService = service
+}
+
+// CreateProjectRequest is the body of POST /projects. Color is optional and looks like "#1e90ff".
+type CreateProjectRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color"`
+}
+
+// UpdateProjectRequest is the body of PATCH /projects/:id; omitted fields are left unchanged
+type UpdateProjectRequest struct {
+	Name     *string `json:"name"`
+	Color    *string `json:"color"`
+	Position *int    `json:"position"`
+}
+
+// MoveTasksRequest is the body of POST /projects/:id/tasks: the top-level tasks to put in the project
+type MoveTasksRequest struct {
+	TaskIDs []uuid.UUID `json:"task_ids" binding:"required"`
+}
+
+// GetProjects handles listing the authenticated user's projects with their task counts.
+// Archived projects are listed too with ?include_archived=true.
+func GetProjects(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	includeArchived, err := strconv.ParseBool(c.DefaultQuery("include_archived", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid include_archived value"})
+		return
+	}
+
+	projects, err := projectService.GetProjects(userID, includeArchived)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if projects == nil {
+		projects = []models.Project{}
+	}
+	c.JSON(http.StatusOK, projects)
+}
+
+// CreateProject handles creating a project
+func CreateProject(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project := models.Project{UserID: userID, Name: req.Name, Color: req.Color}
+	if err := projectService.CreateProject(&project); err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, project)
+}
+
+// GetProject handles retrieving a single project with its task counts
+func GetProject(c *gin.Context) {
+	id, ok := projectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	project, err := projectService.GetProject(id, userID)
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// UpdateProject handles renaming, recolouring or reordering a project
+func UpdateProject(c *gin.Context) {
+	id, ok := projectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := projectService.UpdateProject(id, userID, req.Name, req.Color, req.Position)
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// DeleteProject handles deleting a project; its tasks move back to the inbox
+func DeleteProject(c *gin.Context) {
+	id, ok := projectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := projectService.DeleteProject(id, userID); err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ArchiveProject handles archiving a project, which hides its tasks from task lists
+func ArchiveProject(c *gin.Context) {
+	handleProjectArchiving(c, true)
+}
+
+// UnarchiveProject handles bringing an archived project back
+func UnarchiveProject(c *gin.Context) {
+	handleProjectArchiving(c, false)
+}
+
+func handleProjectArchiving(c *gin.Context, archived bool) {
+	id, ok := projectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	project, err := projectService.SetArchived(id, userID, archived)
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// GetProjectTasks handles listing the tasks of a project, archived or not. It takes the query
+// parameters of GET /tasks.
+func GetProjectTasks(c *gin.Context) {
+	id, ok := projectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := projectService.GetProject(id, userID); err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	query, err := taskQueryFromRequest(c, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query.ProjectID = &id
+	query.Inbox = false
+
+	respondTaskPage(c, query)
+}
+
+// MoveProjectTasks handles moving tasks into a project
+func MoveProjectTasks(c *gin.Context) {
+	id, ok := projectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req MoveTasksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := projectService.MoveTasks(&id, req.TaskIDs, userID); err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// projectIDParam parses a project ID path parameter, responding with 400 when it is malformed
+func projectIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var reminderService *services.ReminderService // Will be initialized in main
+
+// SetReminderService sets the reminder service for the API handlers
+func SetReminderService(service *services.ReminderService) {
+	reminderService = service
+}
+
+// CreateReminderRequest is the body of POST /tasks/:id/reminders. Set either RemindAt, a date
+// in the same formats as a due date, or MinutesBefore the task's due date.
+type CreateReminderRequest struct {
+	RemindAt      *string `json:"remind_at"`
+	MinutesBefore *int    `json:"minutes_before"`
+}
+
+// SnoozeReminderRequest is the body of POST /reminders/:id/snooze: either a number of Minutes
+// from now or a date to snooze Until
+type SnoozeReminderRequest struct {
+	Minutes *int    `json:"minutes"`
+	Until   *string `json:"until"`
+}
+
+// GetTaskReminders handles listing the reminders of a task
+func GetTaskReminders(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	reminders, err := reminderService.GetTaskReminders(taskID, userID)
+	if err != nil {
+		respondReminderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reminders)
+}
+
+// CreateReminder handles adding a reminder to a task
+func CreateReminder(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reminder := models.Reminder{TaskID: taskID, UserID: userID, MinutesBefore: req.MinutesBefore}
+	if req.RemindAt != nil {
+		remindAt, err := parseDueDate(userID, *req.RemindAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid remind_at format"})
+			return
+		}
+		reminder.RemindAt = &remindAt
+	}
+
+	if err := reminderService.CreateReminder(&reminder); err != nil {
+		respondReminderError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, reminder)
+}
+
+// DeleteReminder handles removing a reminder from a task
+func DeleteReminder(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	reminderID, ok := reminderIDParam(c, "reminderId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := reminderService.DeleteReminder(taskID, reminderID, userID); err != nil {
+		respondReminderError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetUpcomingReminders handles listing the authenticated user's reminders that have yet to go
+// off, soonest first
+func GetUpcomingReminders(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	reminders, err := reminderService.GetUpcomingReminders(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reminders)
+}
+
+// SnoozeReminder handles making a reminder go off again later
+func SnoozeReminder(c *gin.Context) {
+	reminderID, ok := reminderIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req SnoozeReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var until time.Time
+	switch {
+	case req.Minutes != nil && req.Until == nil:
+		until = time.Now().Add(time.Duration(*req.Minutes) * time.Minute)
+	case req.Until != nil && req.Minutes == nil:
+		var err error
+		until, err = parseDueDate(userID, *req.Until)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid until format"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Set either minutes or until"})
+		return
+	}
+
+	reminder, err := reminderService.SnoozeReminder(reminderID, userID, until)
+	if err != nil {
+		respondReminderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reminder)
+}
+
+// DismissReminder handles stopping a reminder from going off
+func DismissReminder(c *gin.Context) {
+	reminderID, ok := reminderIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	reminder, err := reminderService.DismissReminder(reminderID, userID)
+	if err != nil {
+		respondReminderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reminder)
+}
+
+// reminderIDParam parses a reminder ID path parameter, responding with 400 when it is malformed
+func reminderIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reminder ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondReminderError maps a ReminderService error to 404 for missing reminders, 400 for
+// invalid ones and the task error statuses otherwise
+func respondReminderError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrReminderNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrInvalidReminder) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	respondTaskError(c, err)
+}
@@ -14,9 +14,9 @@ func SetupRouter() *gin.Engine {
 	// CORS Middleware
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, // Allow all origins for development
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Prefer", "If-Match", "If-None-Match"},
+		ExposeHeaders:    []string{"Content-Length", "Location", "Preference-Applied", "X-Next-Cursor", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -33,6 +33,8 @@ func SetupRouter() *gin.Engine {
 		auth.POST("/register", Register)
 		auth.POST("/login", Login)
 		auth.GET("/me", AuthMiddleware(), Me)
+		auth.GET("/me/settings", AuthMiddleware(), GetSettings)
+		auth.PATCH("/me/settings", AuthMiddleware(), UpdateSettings)
 	}
 
 	tasks := r.Group("/tasks")
@@ -40,11 +42,111 @@ func SetupRouter() *gin.Engine {
 	{
 		tasks.GET("/", GetTasks)
 		tasks.POST("/", CreateTask)
+		tasks.GET("/search", SearchTasks)
+		tasks.GET("/trash", GetTrash)
+		tasks.DELETE("/trash", EmptyTrash)
+		tasks.DELETE("/trash/:id", PurgeTask)
 		tasks.GET("/:id", GetTaskByID)
 		tasks.PUT("/:id", UpdateTask)
+		tasks.PATCH("/:id", PatchTask)
 		tasks.DELETE("/:id", DeleteTask)
+		tasks.POST("/:id/complete", CompleteTask)
+		tasks.POST("/:id/reopen", ReopenTask)
+		tasks.POST("/:id/restore", RestoreTask)
+		tasks.GET("/:id/history", GetTaskHistory)
+		tasks.GET("/:id/activity", GetTaskActivity)
+		tasks.GET("/:id/comments", GetComments)
+		tasks.POST("/:id/comments", CreateComment)
+		tasks.PATCH("/:id/comments/:commentId", UpdateComment)
+		tasks.DELETE("/:id/comments/:commentId", DeleteComment)
+		tasks.GET("/:id/attachments", GetAttachments)
+		tasks.POST("/:id/attachments", CreateAttachment)
+		tasks.GET("/:id/attachments/:attachmentId", DownloadAttachment)
+		tasks.DELETE("/:id/attachments/:attachmentId", DeleteAttachment)
+		tasks.GET("/:id/reminders", GetTaskReminders)
+		tasks.POST("/:id/reminders", CreateReminder)
+		tasks.DELETE("/:id/reminders/:reminderId", DeleteReminder)
+		tasks.POST("/:id/tags", AddTaskTags)
+		tasks.DELETE("/:id/tags/:tagId", RemoveTaskTag)
+		tasks.POST("/:id/blockers", AddTaskBlockers)
+		tasks.DELETE("/:id/blockers/:blockerId", RemoveTaskBlocker)
+		tasks.GET("/:id/subtasks", GetSubtasks)
+		tasks.POST("/:id/subtasks", CreateSubtask)
+		tasks.GET("/:id/subtasks/:subtaskId", GetSubtask)
+		tasks.PUT("/:id/subtasks/:subtaskId", UpdateSubtask)
+		tasks.DELETE("/:id/subtasks/:subtaskId", DeleteSubtask)
 		tasks.POST("/from-text", ExtractTasksFromText)
 	}
 
+	sync := r.Group("/sync")
+	sync.Use(AuthMiddleware())
+	{
+		sync.GET("", GetSyncChanges)
+		sync.POST("", PushSyncMutations)
+	}
+
+	reminders := r.Group("/reminders")
+	reminders.Use(AuthMiddleware())
+	{
+		reminders.GET("", GetUpcomingReminders)
+		reminders.POST("/:id/snooze", SnoozeReminder)
+		reminders.POST("/:id/dismiss", DismissReminder)
+	}
+
+	projects := r.Group("/projects")
+	projects.Use(AuthMiddleware())
+	{
+		projects.GET("", GetProjects)
+		projects.POST("", CreateProject)
+		projects.GET("/:id", GetProject)
+		projects.PATCH("/:id", UpdateProject)
+		projects.DELETE("/:id", DeleteProject)
+		projects.POST("/:id/archive", ArchiveProject)
+		projects.POST("/:id/unarchive", UnarchiveProject)
+		projects.GET("/:id/tasks", GetProjectTasks)
+		projects.POST("/:id/tasks", MoveProjectTasks)
+		projects.GET("/:id/members", GetProjectMembers)
+		projects.PATCH("/:id/members/:userId", UpdateProjectMember)
+		projects.DELETE("/:id/members/:userId", RemoveProjectMember)
+		projects.GET("/:id/invitations", GetProjectInvitations)
+		projects.POST("/:id/invitations", CreateProjectInvitation)
+		projects.DELETE("/:id/invitations/:invitationId", RevokeProjectInvitation)
+	}
+
+	invitations := r.Group("/invitations")
+	invitations.Use(AuthMiddleware())
+	{
+		invitations.GET("", GetInvitations)
+		invitations.POST("/:id/accept", AcceptInvitation)
+		invitations.POST("/:id/decline", DeclineInvitation)
+	}
+
+	tags := r.Group("/tags")
+	tags.Use(AuthMiddleware())
+	{
+		tags.GET("", GetTags)
+		tags.POST("", CreateTag)
+		tags.GET("/:id", GetTag)
+		tags.PATCH("/:id", UpdateTag)
+		tags.DELETE("/:id", DeleteTag)
+	}
+
+	audio := r.Group("/audio")
+	audio.Use(AuthMiddleware())
+	{
+		audio.GET("/", GetAudioUploads)
+		audio.POST("/", UploadAudio)
+		audio.GET("/:id", GetAudioUploadByID)
+	}
+
+	jobs := r.Group("/jobs")
+	jobs.Use(AuthMiddleware())
+	{
+		jobs.GET("/:id", GetJobByID)
+	}
+
+	// Presigned downloads for the local blob store; access is granted by the URL signature
+	r.GET("/blobs/*key", ServeBlob)
+
 	return r
 }
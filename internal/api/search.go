@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchTasks handles full-text search over the authenticated user's tasks, subtasks included.
+// The q parameter matches titles, descriptions and the original dictated or typed text.
+func SearchTasks(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	search := repositories.TaskSearch{UserID: userID, Text: text}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > repositories.MaxSearchLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(repositories.MaxSearchLimit)})
+			return
+		}
+		search.Limit = limit
+	}
+
+	results, err := taskService.SearchTasks(search)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if results == nil {
+		results = []models.TaskSearchResult{}
+	}
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"todo-backend/internal/dateparse"
+	"todo-backend/internal/models"
+	"todo-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var settingsService *services.SettingsService
+
+// SetSettingsService initializes the settingsService
+func SetSettingsService(service *services.SettingsService) {
+	settingsService = service
+}
+
+// GetSettings handles fetching the current user's settings
+func GetSettings(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := settingsService.GetSettings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings handles changing some of the current user's settings
+func UpdateSettings(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateUserSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := settingsService.UpdateSettings(userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSettings) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// userClock returns the current time in the user's time zone and a date parser for their week,
+// or server time with Monday weeks when no settings service is configured
+func userClock(userID uuid.UUID) (time.Time, *dateparse.Parser, error) {
+	if settingsService == nil {
+		return time.Now(), dateparse.New(), nil
+	}
+	return settingsService.Clock(userID)
+}
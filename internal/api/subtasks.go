@@ -0,0 +1,227 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+	"todo-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UpdateSubtaskRequest defines the request body for updating a subtask
+type UpdateSubtaskRequest struct {
+	UpdateTaskRequest
+	Position *int `json:"position"` // new index among the parent's subtasks, or nil to keep it
+}
+
+// GetSubtasks handles fetching the subtasks of a task, each with its own subtasks nested below it
+func GetSubtasks(c *gin.Context) {
+	parentID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	subtasks, err := taskService.GetSubtasks(parentID, userID)
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subtasks)
+}
+
+// CreateSubtask handles adding a subtask at the end of a task's subtask list
+func CreateSubtask(c *gin.Context) {
+	parentID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task := &models.Task{
+		ID:          uuid.New(),
+		Title:       req.Title,
+		Description: req.Description,
+		Priority:    req.Priority,
+		RawText:     req.RawText,
+		Recurrence:  req.Recurrence,
+		AssigneeID:  req.AssigneeID,
+	}
+	if req.DueDate != nil && *req.DueDate != "" {
+		parsedTime, err := parseDueDate(userID, *req.DueDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due_date format"})
+			return
+		}
+		task.DueDate = &parsedTime
+	}
+
+	if err := taskService.CreateSubtask(parentID, task, userID); err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	// The ETag is that of the subtask as it is read back, with its details
+	task, err := taskService.GetSubtask(parentID, task.ID, userID)
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+
+	respondTask(c, http.StatusCreated, task)
+}
+
+// GetSubtask handles fetching a single subtask of a task
+func GetSubtask(c *gin.Context) {
+	parentID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	subtaskID, ok := taskIDParam(c, "subtaskId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	subtask, err := taskService.GetSubtask(parentID, subtaskID, userID)
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+
+	respondTaskIfNoneMatch(c, subtask)
+}
+
+// UpdateSubtask handles updating a subtask and optionally moving it to a new position
+func UpdateSubtask(c *gin.Context) {
+	parentID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	subtaskID, ok := taskIDParam(c, "subtaskId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateSubtaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := taskService.GetSubtask(parentID, subtaskID, userID); err != nil {
+		respondTaskError(c, err)
+		return
+	}
+
+	patch, err := req.patch(userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due_date format"})
+		return
+	}
+	if _, err := taskService.PatchTask(subtaskID, userID, patch, ifMatch(c)); err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	if req.Position != nil {
+		if err := taskService.MoveSubtask(parentID, subtaskID, *req.Position, userID); err != nil {
+			respondTaskError(c, err)
+			return
+		}
+	}
+
+	subtask, err := taskService.GetSubtask(parentID, subtaskID, userID)
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	respondTask(c, http.StatusOK, subtask)
+}
+
+// DeleteSubtask handles deleting a subtask together with its own subtasks
+func DeleteSubtask(c *gin.Context) {
+	parentID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	subtaskID, ok := taskIDParam(c, "subtaskId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := taskService.GetSubtask(parentID, subtaskID, userID); err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	if err := taskService.DeleteTask(subtaskID, userID, ifMatch(c)); err != nil {
+		respondTaskError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// taskIDParam parses a task ID path parameter, responding 400 when it is not a UUID
+func taskIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondTaskError maps a TaskService error to 404 for missing tasks, projects and dependencies, 403
+// when the user's project role does not allow the change, 412 for failed If-Match preconditions,
+// 409 for blocked tasks and dependency cycles, 400 for invalid recurrence rules, project moves,
+// dependencies and assignees and 500 otherwise
+func respondTaskError(c *gin.Context, err error) {
+	if err.Error() == "task not found or unauthorized" || errors.Is(err, services.ErrProjectNotFound) || errors.Is(err, services.ErrDependencyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrPermissionDenied) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrTaskBlocked) || errors.Is(err, repositories.ErrDependencyCycle) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrInvalidRecurrence) || errors.Is(err, services.ErrInvalidProject) ||
+		errors.Is(err, services.ErrInvalidDependency) || errors.Is(err, services.ErrInvalidAssignee) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
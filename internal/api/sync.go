@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+	"todo-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSyncMutations caps the size of a pushed batch
+const maxSyncMutations = 500
+
+var syncService *services.SyncService // Will be initialized in main
+
+// SetSyncService sets the sync service for the API handlers
+func SetSyncService(service *services.SyncService) {
+	syncService = service
+}
+
+// GetSyncChanges handles pulling the changes to the user's tasks after the since token: tasks
+// created and updated, flat with subtasks linked by parent_id, and the IDs of deleted tasks.
+// Without since, every task is returned. The token in the response is the since of the next pull.
+func GetSyncChanges(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > repositories.MaxSyncLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(repositories.MaxSyncLimit)})
+			return
+		}
+	}
+
+	changes, err := syncService.Changes(userID, c.Query("since"), limit)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSyncToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrSyncTokenExpired) {
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// PushSyncMutations handles a batch of changes made on a client while offline. The mutations
+// are applied in order and each gets its own result, so the response is 200 even when some of
+// them conflict or fail.
+func PushSyncMutations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SyncPushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Mutations) > maxSyncMutations {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "at most " + strconv.Itoa(maxSyncMutations) + " mutations per request"})
+		return
+	}
+
+	results := make([]models.SyncResult, len(req.Mutations))
+	for i, m := range req.Mutations {
+		mutation := models.TaskMutation{Op: m.Op, ID: m.ID, BaseVersion: m.BaseVersion, ParentID: m.ParentID}
+		if len(m.Fields) > 0 && m.Op != models.SyncDelete {
+			patch, err := parseTaskPatch(userID, m.Fields)
+			if err != nil {
+				results[i] = models.SyncResult{ID: m.ID, Op: m.Op, Status: models.SyncInvalid, Error: err.Error()}
+				continue
+			}
+			mutation.Patch = patch
+		}
+		results[i] = syncService.ApplyMutation(userID, mutation)
+	}
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"todo-backend/internal/models"
+	"todo-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var tagService *services.TagService // Will be initialized in main
+
+// SetTagService sets the tag service for the API handlers
+func SetTagService(service *services.TagService) {
+	tagService = service
+}
+
+// CreateTagRequest is the body of POST /tags. Color is optional and looks like "#1e90ff".
+type CreateTagRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color"`
+}
+
+// UpdateTagRequest is the body of PATCH /tags/:id; omitted fields are left unchanged
+type UpdateTagRequest struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+// AddTaskTagsRequest is the body of POST /tasks/:id/tags: the names of the tags to put on the
+// task. Tags the user does not have yet are created.
+type AddTaskTagsRequest struct {
+	Tags []string `json:"tags" binding:"required"`
+}
+
+// GetTags handles listing the authenticated user's tags
+func GetTags(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	tags, err := tagService.GetTags(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if tags == nil {
+		tags = []models.Tag{}
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// CreateTag handles creating a tag
+func CreateTag(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag := models.Tag{UserID: userID, Name: req.Name, Color: req.Color}
+	if err := tagService.CreateTag(&tag); err != nil {
+		respondTagError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, tag)
+}
+
+// GetTag handles retrieving a single tag
+func GetTag(c *gin.Context) {
+	id, ok := tagIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	tag, err := tagService.GetTag(id, userID)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// UpdateTag handles renaming or recolouring a tag
+func UpdateTag(c *gin.Context) {
+	id, ok := tagIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := tagService.UpdateTag(id, userID, req.Name, req.Color)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag handles deleting a tag, which takes it off every task
+func DeleteTag(c *gin.Context) {
+	id, ok := tagIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := tagService.DeleteTag(id, userID); err != nil {
+		respondTagError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AddTaskTags handles putting tags on a task
+func AddTaskTags(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req AddTaskTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := tagService.AddTaskTags(taskID, userID, req.Tags)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+	respondTask(c, http.StatusOK, task)
+}
+
+// RemoveTaskTag handles taking a tag off a task
+func RemoveTaskTag(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	tagID, ok := tagIDParam(c, "tagId")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := tagService.RemoveTaskTag(taskID, tagID, userID); err != nil {
+		respondTagError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// tagIDParam parses a tag ID path parameter, responding with 400 when it is malformed
+func tagIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondTagError maps a TagService error to 404 for missing tags, 400 for invalid ones, 409 for
+// taken names and the task error statuses otherwise
+func respondTagError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTag):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTagExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondTaskError(c, err)
+	}
+}
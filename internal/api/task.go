@@ -1,10 +1,12 @@
 package api
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
 	"todo-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -23,48 +25,90 @@ type CreateTaskRequest struct {
 	Title       string    `json:"title" binding:"required"`
 	Description string    `json:"description"`
 	DueDate     *string   `json:"due_date"` // Use *string to allow null for omitempty
-	Priority    string    `json:"priority"`
+	Priority    string    `json:"priority" binding:"omitempty,oneof=low medium high"`
 	RawText     string    `json:"raw_text"`
+	Recurrence  string    `json:"recurrence"` // RRULE subset, e.g. "FREQ=WEEKLY;BYDAY=MO"
+	ProjectID   *uuid.UUID `json:"project_id"` // nil for the inbox
+	AssigneeID  *uuid.UUID `json:"assignee_id"` // a member of the project; nil if unassigned
+	BlockedBy   []uuid.UUID `json:"blocked_by"` // tasks that have to be done first
 }
 
-// UpdateTaskRequest defines the request body for updating a task
+// UpdateTaskRequest defines the request body for replacing a task. Fields left out are cleared;
+// an empty priority resets the task to the user's default. The project and assignee are left unchanged.
 type UpdateTaskRequest struct {
-	Title       string    `json:"title"`
+	Title       string    `json:"title" binding:"required"`
 	Description string    `json:"description"`
 	DueDate     *string   `json:"due_date"`
-	Priority    string    `json:"priority"`
+	Priority    string    `json:"priority" binding:"omitempty,oneof=low medium high"`
 	RawText     string    `json:"raw_text"`
+	Recurrence  string    `json:"recurrence"`
+	Completed   *bool     `json:"completed"` // nil leaves the completion state unchanged
+}
+
+// patch returns the changes that replace a task's fields with the request's, the due date
+// parsed in the user's time zone
+func (req UpdateTaskRequest) patch(userID uuid.UUID) (models.TaskPatch, error) {
+	patch := models.TaskPatch{
+		Title:        &req.Title,
+		Description:  &req.Description,
+		ClearDueDate: true,
+		Priority:     &req.Priority,
+		RawText:      &req.RawText,
+		Recurrence:   &req.Recurrence,
+		Completed:    req.Completed,
+	}
+	if req.DueDate != nil && *req.DueDate != "" {
+		parsedTime, err := parseDueDate(userID, *req.DueDate)
+		if err != nil {
+			return patch, err
+		}
+		patch.DueDate, patch.ClearDueDate = &parsedTime, false
+	}
+	return patch, nil
 }
 
 // ExtractTasksFromTextRequest defines the request body for extracting tasks from text
 type ExtractTasksFromTextRequest struct {
-	Text string `json:"text" binding:"required"`
+	Text      string     `json:"text" binding:"required"`
+	ProjectID *uuid.UUID `json:"project_id"` // creates the tasks in the project, assigned to the members the text names; nil for the inbox
 }
 
-// GetTasks handles fetching all tasks for the authenticated user
+// GetTasks handles listing the authenticated user's tasks. Query parameters filter, sort and
+// page the list; when more tasks follow, the X-Next-Cursor header carries the cursor for them.
 func GetTasks(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	userIDStr, ok := userID.(string)
+	userIDUUID, ok := currentUserID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type in context"})
 		return
 	}
-	userIDUUID, err := uuid.Parse(userIDStr)
+
+	query, err := taskQueryFromRequest(c, userIDUUID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format in context"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	tasks, err := taskService.GetTasksByUserID(userIDUUID)
+
+	respondTaskPage(c, query)
+}
+
+// respondTaskPage writes one page of the tasks matched by query, with the X-Next-Cursor header
+// when more tasks follow
+func respondTaskPage(c *gin.Context, query repositories.TaskQuery) {
+	tasks, next, err := taskService.ListTasks(query)
 	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
 
+	if next != "" {
+		c.Header("X-Next-Cursor", next)
+	}
 	c.JSON(http.StatusOK, tasks)
 }
 
@@ -100,10 +144,14 @@ func CreateTask(c *gin.Context) {
 		Description: req.Description,
 		Priority:    req.Priority,
 		RawText:     req.RawText,
+		Recurrence:  req.Recurrence,
+		ProjectID:   req.ProjectID,
+		AssigneeID:  req.AssigneeID,
+		BlockedBy:   req.BlockedBy,
 	}
 
 	if req.DueDate != nil && *req.DueDate != "" {
-		parsedTime, err := parseDueDate(*req.DueDate)
+		parsedTime, err := parseDueDate(userIDUUID, *req.DueDate)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due_date format"})
 			return
@@ -112,14 +160,20 @@ func CreateTask(c *gin.Context) {
 	}
 
 	if err := taskService.CreateTask(task); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondTaskError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, task)
+	// The ETag is that of the task as it is read back, with its details
+	created, err := taskService.GetTaskByID(task.ID, userIDUUID)
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	respondTask(c, http.StatusCreated, created)
 }
 
-// GetTaskByID handles fetching a single task by ID
+// GetTaskByID handles fetching a single task by ID, with its version as the ETag
 func GetTaskByID(c *gin.Context) {
 	taskIDStr := c.Param("id")
 	taskID, err := uuid.Parse(taskIDStr)
@@ -154,10 +208,11 @@ func GetTaskByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, task)
+	respondTaskIfNoneMatch(c, task)
 }
 
-// UpdateTask handles updating an existing task
+// UpdateTask handles replacing an existing task, completion included, in a single write; see
+// PatchTask for partial updates. An If-Match header makes the update conditional on the task's ETag.
 func UpdateTask(c *gin.Context) {
 	taskIDStr := c.Param("id")
 	taskID, err := uuid.Parse(taskIDStr)
@@ -189,37 +244,22 @@ func UpdateTask(c *gin.Context) {
 		return
 	}
 
-	task := &models.Task{
-		ID: taskID,
-		UserID: userIDUUID, // Important for authorization in service layer
-		Title:       req.Title,
-		Description: req.Description,
-		Priority:    req.Priority,
-		RawText:     req.RawText,
-	}
-
-	if req.DueDate != nil && *req.DueDate != "" {
-		parsedTime, err := parseDueDate(*req.DueDate)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due_date format"})
-			return
-		}
-		task.DueDate = &parsedTime
+	patch, err := req.patch(userIDUUID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due_date format"})
+		return
 	}
 
-	if err := taskService.UpdateTask(task, userIDUUID); err != nil {
-		if err.Error() == "task not found or unauthorized" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	updated, err := taskService.PatchTask(taskID, userIDUUID, patch, ifMatch(c))
+	if err != nil {
+		respondTaskError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, task)
+	respondTask(c, http.StatusOK, updated)
 }
 
-// DeleteTask handles deleting a task
+// DeleteTask handles deleting a task, conditionally on its ETag when If-Match is sent
 func DeleteTask(c *gin.Context) {
 	taskIDStr := c.Param("id")
 	taskID, err := uuid.Parse(taskIDStr)
@@ -245,19 +285,85 @@ func DeleteTask(c *gin.Context) {
 		return
 	}
 
-	if err := taskService.DeleteTask(taskID, userIDUUID); err != nil {
-		if err.Error() == "task not found or unauthorized" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := taskService.DeleteTask(taskID, userIDUUID, ifMatch(c)); err != nil {
+		respondTaskError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
 
-// ExtractTasksFromText handles extracting tasks from provided text using LLM
+// CompleteTask handles marking a task as completed. With ?cascade=true its subtasks are completed too.
+// A task waiting on open tasks is refused with 409 unless ?force=true is given.
+func CompleteTask(c *gin.Context) {
+	handleTaskCompletion(c, true)
+}
+
+// ReopenTask handles marking a completed task as not completed. With ?cascade=true its subtasks are reopened too.
+func ReopenTask(c *gin.Context) {
+	handleTaskCompletion(c, false)
+}
+
+func handleTaskCompletion(c *gin.Context, completed bool) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	cascade, err := strconv.ParseBool(c.DefaultQuery("cascade", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cascade value"})
+		return
+	}
+
+	force, err := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid force value"})
+		return
+	}
+
+	task, err := setTaskCompleted(taskID, userID, cascade, completed, force)
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+
+	respondTask(c, http.StatusOK, task)
+}
+
+func setTaskCompleted(taskID uuid.UUID, userID uuid.UUID, cascade bool, completed bool, force bool) (*models.Task, error) {
+	if completed {
+		return taskService.CompleteTask(taskID, userID, cascade, force)
+	}
+	return taskService.ReopenTask(taskID, userID, cascade)
+}
+
+// GetTaskHistory handles fetching the completion and assignment log of a task
+func GetTaskHistory(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	history, err := taskService.GetTaskHistory(taskID, userID)
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// ExtractTasksFromText handles extracting tasks from provided text using LLM.
+// With "Prefer: respond-async" an extract job is queued and 202 is returned instead.
 func ExtractTasksFromText(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -282,25 +388,32 @@ func ExtractTasksFromText(c *gin.Context) {
 		return
 	}
 
-	tasks, err := taskService.ExtractAndCreateTasks(c.Request.Context(), req.Text, userIDUUID)
+	if wantsAsync(c) {
+		job, err := jobService.EnqueueExtract(c.Request.Context(), req.Text, req.ProjectID, userIDUUID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		respondAccepted(c, job, nil)
+		return
+	}
+
+	tasks, err := taskService.ExtractAndCreateProjectTasks(c.Request.Context(), req.Text, userIDUUID, req.ProjectID)
 	if err != nil {
-		// Differentiate between LLM extraction error and database creation error if needed
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		// A project the user cannot add tasks to is a 404 or 403; extraction failures stay 500s
+		respondTaskError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, tasks)
 }
 
-// Helper to parse date strings from requests
-func parseDueDate(dateStr string) (time.Time, error) {
-	// Attempt to parse ISO 8601
-	parsedTime, err := time.Parse(time.RFC3339, dateStr)
-	if err == nil {
-		return parsedTime, nil
+// Helper to parse date strings from requests. Besides ISO 8601 it accepts natural-language
+// dates such as "tomorrow at 5pm" or "next Monday", resolved in the user's time zone.
+func parseDueDate(userID uuid.UUID, dateStr string) (time.Time, error) {
+	now, parser, err := userClock(userID)
+	if err != nil {
+		return time.Time{}, err
 	}
-
-	// Add more date formats here if necessary for user input flexibility
-	// For now, strict ISO 8601 is expected for API input
-	return time.Time{}, fmt.Errorf("unsupported date format: %s", dateStr)
+	return parser.Parse(dateStr, now)
 }
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"todo-backend/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// taskQueryFromRequest builds the repository query for GET /tasks from its query parameters.
+// Dates may be ISO 8601 or natural language and are resolved in the user's time zone.
+func taskQueryFromRequest(c *gin.Context, userID uuid.UUID) (repositories.TaskQuery, error) {
+	query := repositories.TaskQuery{UserID: userID, Cursor: c.Query("cursor")}
+
+	if value := c.Query("completed"); value != "" {
+		completed, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, fmt.Errorf("invalid completed value: %s", value)
+		}
+		query.Completed = &completed
+	}
+
+	for _, value := range c.QueryArray("priority") {
+		for _, priority := range strings.Split(value, ",") {
+			priority = strings.TrimSpace(priority)
+			if priority != "low" && priority != "medium" && priority != "high" {
+				return query, fmt.Errorf("invalid priority: %s", priority)
+			}
+			query.Priorities = append(query.Priorities, priority)
+		}
+	}
+
+	query.TagsAny = splitListParam(c, "tags_any")
+	query.TagsAll = splitListParam(c, "tags_all")
+
+	// "none" lists the inbox: tasks without a project
+	switch value := c.Query("project_id"); value {
+	case "":
+	case "none":
+		query.Inbox = true
+	default:
+		projectID, err := uuid.Parse(value)
+		if err != nil {
+			return query, fmt.Errorf("invalid project_id: %s", value)
+		}
+		query.ProjectID = &projectID
+	}
+	// "me" lists the tasks assigned to the user, "none" the unassigned ones
+	switch value := c.Query("assignee"); value {
+	case "":
+	case "me":
+		query.AssigneeID = &userID
+	case "none":
+		query.Unassigned = true
+	default:
+		assigneeID, err := uuid.Parse(value)
+		if err != nil {
+			return query, fmt.Errorf("invalid assignee: %s", value)
+		}
+		query.AssigneeID = &assigneeID
+	}
+	if value := c.Query("include_archived"); value != "" {
+		withArchived, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, fmt.Errorf("invalid include_archived value: %s", value)
+		}
+		query.WithArchived = withArchived
+	}
+
+	now, parser, err := userClock(userID)
+	if err != nil {
+		return query, err
+	}
+	dateParams := map[string]**time.Time{
+		"due_before":     &query.DueBefore,
+		"due_after":      &query.DueAfter,
+		"created_before": &query.CreatedBefore,
+		"created_after":  &query.CreatedAfter,
+	}
+	for name, target := range dateParams {
+		if value := c.Query(name); value != "" {
+			parsed, err := parser.Parse(value, now)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s: %s", name, value)
+			}
+			*target = &parsed
+		}
+	}
+
+	if value := c.Query("overdue"); value != "" {
+		overdue, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, fmt.Errorf("invalid overdue value: %s", value)
+		}
+		if overdue {
+			query.OverdueAt = &now
+		}
+	}
+
+	switch sortBy := c.DefaultQuery("sort", repositories.SortByCreatedAt); sortBy {
+	case repositories.SortByCreatedAt, repositories.SortByUpdatedAt, repositories.SortByDueDate, repositories.SortByPriority:
+		query.SortBy = sortBy
+	default:
+		return query, fmt.Errorf("invalid sort field: %s", sortBy)
+	}
+	switch order := c.DefaultQuery("order", "asc"); order {
+	case "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, fmt.Errorf("invalid order: %s", order)
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > repositories.MaxTaskLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", repositories.MaxTaskLimit)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
+// splitListParam collects the comma separated values of a query parameter that may be repeated,
+// skipping blanks
+func splitListParam(c *gin.Context, name string) []string {
+	var values []string
+	for _, value := range c.QueryArray(name) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
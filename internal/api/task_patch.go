@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"todo-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// PatchTask handles partial task updates with JSON Merge Patch (RFC 7396) semantics: members
+// left out of the body are unchanged, and an explicit null clears the field. An If-Match header
+// makes the update conditional on the task's ETag.
+func PatchTask(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if contentType := c.ContentType(); contentType != mergePatchContentType && contentType != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + mergePatchContentType})
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	patch, err := parseTaskPatch(userID, body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := taskService.PatchTask(taskID, userID, patch, ifMatch(c))
+	if err != nil {
+		respondTaskError(c, err)
+		return
+	}
+
+	respondTask(c, http.StatusOK, task)
+}
+
+// parseTaskPatch reads a merge patch document into a TaskPatch. Unknown and read-only members
+// are rejected, so a typo does not silently do nothing.
+func parseTaskPatch(userID uuid.UUID, body []byte) (models.TaskPatch, error) {
+	var patch models.TaskPatch
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil || members == nil {
+		return patch, errors.New("request body must be a JSON object")
+	}
+
+	for name, raw := range members {
+		null := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+		switch name {
+		case "title":
+			if null {
+				return patch, errors.New("title cannot be removed")
+			}
+			title, err := patchString(name, raw)
+			if err != nil {
+				return patch, err
+			}
+			if strings.TrimSpace(title) == "" {
+				return patch, errors.New("title cannot be empty")
+			}
+			patch.Title = &title
+		case "description", "raw_text":
+			value := ""
+			if !null {
+				var err error
+				if value, err = patchString(name, raw); err != nil {
+					return patch, err
+				}
+			}
+			if name == "description" {
+				patch.Description = &value
+			} else {
+				patch.RawText = &value
+			}
+		case "due_date":
+			if null {
+				patch.ClearDueDate = true
+				continue
+			}
+			value, err := patchString(name, raw)
+			if err != nil {
+				return patch, err
+			}
+			dueDate, err := parseDueDate(userID, value)
+			if err != nil {
+				return patch, errors.New("Invalid due_date format")
+			}
+			patch.DueDate = &dueDate
+		case "priority":
+			// Removing the priority falls back to the user's default
+			priority := ""
+			if !null {
+				var err error
+				if priority, err = patchString(name, raw); err != nil {
+					return patch, err
+				}
+				if priority != "low" && priority != "medium" && priority != "high" {
+					return patch, fmt.Errorf("invalid priority: %s", priority)
+				}
+			}
+			patch.Priority = &priority
+		case "completed":
+			var completed bool
+			if null || json.Unmarshal(raw, &completed) != nil {
+				return patch, errors.New("completed must be true or false")
+			}
+			patch.Completed = &completed
+		case "recurrence":
+			// Removing the rule makes the task a one-off
+			rule := ""
+			if !null {
+				var err error
+				if rule, err = patchString(name, raw); err != nil {
+					return patch, err
+				}
+			}
+			patch.Recurrence = &rule
+		case "project_id":
+			// Removing the project moves the task back to the inbox
+			if null {
+				patch.ClearProject = true
+				continue
+			}
+			value, err := patchString(name, raw)
+			if err != nil {
+				return patch, err
+			}
+			projectID, err := uuid.Parse(value)
+			if err != nil {
+				return patch, errors.New("Invalid project_id")
+			}
+			patch.ProjectID = &projectID
+		case "assignee_id":
+			// Removing the assignee leaves the task unassigned
+			if null {
+				patch.ClearAssignee = true
+				continue
+			}
+			value, err := patchString(name, raw)
+			if err != nil {
+				return patch, err
+			}
+			assigneeID, err := uuid.Parse(value)
+			if err != nil {
+				return patch, errors.New("Invalid assignee_id")
+			}
+			patch.AssigneeID = &assigneeID
+		default:
+			return patch, fmt.Errorf("unknown or read-only field: %s", name)
+		}
+	}
+	return patch, nil
+}
+
+func patchString(name string, raw json.RawMessage) (string, error) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("%s must be a string", name)
+	}
+	return value, nil
+}
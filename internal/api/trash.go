@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"todo-backend/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrash handles listing the authenticated user's deleted tasks, most recently deleted first
+func GetTrash(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	trash, err := taskService.GetTrash(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, trash)
+}
+
+// RestoreTask handles taking a task out of the trash, together with the subtasks deleted along
+// with it
+func RestoreTask(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	task, err := taskService.RestoreTask(taskID, userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrParentDeleted) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		respondTaskError(c, err)
+		return
+	}
+	respondTask(c, http.StatusOK, task)
+}
+
+// PurgeTask handles permanently deleting a task in the trash
+func PurgeTask(c *gin.Context) {
+	taskID, ok := taskIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := taskService.PurgeTask(taskID, userID); err != nil {
+		respondTaskError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// EmptyTrash handles permanently deleting everything in the authenticated user's trash
+func EmptyTrash(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	purged, err := taskService.EmptyTrash(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"purged": purged})
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -18,6 +20,45 @@ type Config struct {
 	DBSslMode  string
 	JWTSecret  string
 	OpenAPIKey string
+
+	LLMProvider     string // "openai", "anthropic", "ollama", "openai-compatible" or "rules"
+	LLMModel        string // empty for the provider's default model
+	LLMBaseURL      string // empty for the provider's default endpoint
+	LLMTemperature  float64
+	LLMTimeout      time.Duration
+	LLMAPIKey       string // key for "openai-compatible" endpoints that require one
+	AnthropicAPIKey string
+	LLMFallback     bool // fall back to rule-based extraction when the provider fails
+
+	STTProvider   string // "openai" or "whispercpp"
+	WhisperCppURL string
+
+	StorageBackend   string // "local" or "s3"
+	StorageLocalDir  string
+	StoragePublicURL string // base URL that local presigned links point at
+	S3Endpoint       string
+	S3Region         string
+	S3Bucket         string
+	S3AccessKey      string
+	S3SecretKey      string
+	S3UsePathStyle   bool
+
+	JobQueue       string // "postgres" or "memory"
+	JobWorkers     int
+	JobMaxAttempts int
+
+	TrashRetention     time.Duration // how long deleted tasks stay restorable; 0 keeps them forever
+	TrashSweepInterval time.Duration
+
+	Notifier         string        // "log", "webhook" or "smtp"
+	ReminderInterval time.Duration // how often due reminders are looked for
+	WebhookURL       string
+	WebhookSecret    string // signs webhook bodies; empty to send them unsigned
+	SMTPHost         string
+	SMTPPort         int
+	SMTPUsername     string
+	SMTPPassword     string
+	SMTPFrom         string
 }
 
 // Load loads the configuration from environment variables
@@ -37,6 +78,45 @@ func Load() *Config {
 		DBSslMode:  getEnv("DB_SSLMODE", "disable"),
 		JWTSecret:  getEnv("JWT_SECRET", "your-secret-key"),
 		OpenAPIKey: getEnv("OPENAI_API_KEY", ""),
+
+		LLMProvider:     getEnv("LLM_PROVIDER", "openai"),
+		LLMModel:        getEnv("LLM_MODEL", ""),
+		LLMBaseURL:      getEnv("LLM_BASE_URL", ""),
+		LLMTemperature:  getEnvFloat("LLM_TEMPERATURE", 0.2),
+		LLMTimeout:      getEnvDuration("LLM_TIMEOUT", 60*time.Second),
+		LLMAPIKey:       getEnv("LLM_API_KEY", ""),
+		AnthropicAPIKey: getEnv("ANTHROPIC_API_KEY", ""),
+		LLMFallback:     getEnvBool("LLM_FALLBACK", true),
+
+		STTProvider:   getEnv("STT_PROVIDER", "openai"),
+		WhisperCppURL: getEnv("WHISPER_CPP_URL", "http://localhost:8081"),
+
+		StorageBackend:   getEnv("STORAGE_BACKEND", "local"),
+		StorageLocalDir:  getEnv("STORAGE_LOCAL_DIR", "./data/blobs"),
+		StoragePublicURL: getEnv("STORAGE_PUBLIC_URL", "http://localhost:8080/blobs"),
+		S3Endpoint:       getEnv("S3_ENDPOINT", "http://localhost:9000"),
+		S3Region:         getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:         getEnv("S3_BUCKET", "todo-backend"),
+		S3AccessKey:      getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:      getEnv("S3_SECRET_KEY", ""),
+		S3UsePathStyle:   getEnvBool("S3_USE_PATH_STYLE", true),
+
+		JobQueue:       getEnv("JOB_QUEUE", "postgres"),
+		JobWorkers:     getEnvInt("JOB_WORKERS", 2),
+		JobMaxAttempts: getEnvInt("JOB_MAX_ATTEMPTS", 5),
+
+		TrashRetention:     getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+		TrashSweepInterval: getEnvDuration("TRASH_SWEEP_INTERVAL", time.Hour),
+
+		Notifier:         getEnv("NOTIFIER", "log"),
+		ReminderInterval: getEnvDuration("REMINDER_INTERVAL", 30*time.Second),
+		WebhookURL:       getEnv("NOTIFIER_WEBHOOK_URL", ""),
+		WebhookSecret:    getEnv("NOTIFIER_WEBHOOK_SECRET", ""),
+		SMTPHost:         getEnv("SMTP_HOST", ""),
+		SMTPPort:         getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:         getEnv("SMTP_FROM", ""),
 	}
 }
 
@@ -46,3 +126,47 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvBool(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err == nil {
+			return parsed
+		}
+		log.Printf("Invalid boolean for %s: %q, using default %v", key, value, fallback)
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok {
+		parsed, err := strconv.Atoi(value)
+		if err == nil {
+			return parsed
+		}
+		log.Printf("Invalid integer for %s: %q, using default %d", key, value, fallback)
+	}
+	return fallback
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	if value, ok := os.LookupEnv(key); ok {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return parsed
+		}
+		log.Printf("Invalid number for %s: %q, using default %v", key, value, fallback)
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, ok := os.LookupEnv(key); ok {
+		parsed, err := time.ParseDuration(value)
+		if err == nil {
+			return parsed
+		}
+		log.Printf("Invalid duration for %s: %q, using default %v", key, value, fallback)
+	}
+	return fallback
+}
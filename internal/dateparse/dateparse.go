@@ -0,0 +1,378 @@
+// Package dateparse resolves relative and absolute date expressions such as "tomorrow at 5pm",
+// "next Monday", "in 3 days" or "2025-12-24" against a supplied current time. Results are in
+// the location of that time, so callers pass now.In(userLocation) to get the user's "tomorrow".
+package dateparse
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var numberWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "weds": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var monthNames = map[string]time.Month{
+	"jan": time.January, "january": time.January, "feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March, "apr": time.April, "april": time.April, "may": time.May,
+	"jun": time.June, "june": time.June, "jul": time.July, "july": time.July,
+	"aug": time.August, "august": time.August, "sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October, "nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+const (
+	datePrep   = `(?:(?:on|by|before|until|till|due|for)\s+)?`
+	timePrep   = `(?:(?:at|by|around|before|@)\s*)?`
+	weekdayRe  = `(sunday|mon(?:day)?|tue(?:s|sday)?|wednesday|weds|thu(?:r|rs|rsday)?|fri(?:day)?|saturday)` // no "sat", "sun" or "wed"; they are ordinary words
+	monthRe    = `(jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sept?(?:ember)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)`
+	amountRe   = `(\d+|an?|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve)`
+	ordinalRe  = `(\d{1,2})(?:st|nd|rd|th)?`
+	yearSuffix = `(?:,?\s+(\d{4}))?`
+)
+
+// dayPattern recognises an expression naming a day. Resolvers report exact when the result is
+// a precise instant ("in 2 hours") that an explicit time of day must not override.
+type dayPattern struct {
+	re      *regexp.Regexp
+	resolve func(m []string, now time.Time, weekStart time.Weekday) (t time.Time, exact bool, ok bool)
+}
+
+var dayPatterns = []dayPattern{
+	{regexp.MustCompile(`(?i)\bin\s+` + amountRe + `\s+(minute|min|hour|hr|day|week|month|year)s?\b`), resolveRelative},
+	{regexp.MustCompile(`(?i)\b` + datePrep + `(\d{4})-(\d{2})-(\d{2})\b`), func(m []string, now time.Time, _ time.Weekday) (time.Time, bool, bool) {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		return validDate(year, time.Month(month), day, now.Location())
+	}},
+	{regexp.MustCompile(`(?i)\b` + datePrep + `(?:the\s+)?day\s+after\s+tomorrow\b`), func(m []string, now time.Time, _ time.Weekday) (time.Time, bool, bool) {
+		return startOfDay(now).AddDate(0, 0, 2), false, true
+	}},
+	{regexp.MustCompile(`(?i)\b` + datePrep + `(today|tonight|tomorrow|tmrw|tmr)\b`), func(m []string, now time.Time, _ time.Weekday) (time.Time, bool, bool) {
+		switch strings.ToLower(m[1]) {
+		case "today":
+			return startOfDay(now), false, true
+		case "tonight":
+			return startOfDay(now).Add(20 * time.Hour), false, true
+		}
+		return startOfDay(now).AddDate(0, 0, 1), false, true
+	}},
+	{regexp.MustCompile(`(?i)\b` + datePrep + `(?:the\s+)?end\s+of\s+(?:the\s+)?(day|week|month)\b|\b(eod|eow)\b`), func(m []string, now time.Time, weekStart time.Weekday) (time.Time, bool, bool) {
+		switch strings.ToLower(m[1] + m[2]) {
+		case "day", "eod":
+			return startOfDay(now).Add(17 * time.Hour), false, true
+		case "week", "eow":
+			return nextWeekday(startOfDay(now), time.Friday, true), false, true
+		}
+		firstOfNext := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+		return firstOfNext.AddDate(0, 0, -1), false, true
+	}},
+	{regexp.MustCompile(`(?i)\b` + datePrep + `(?:this\s+|the\s+)?weekend\b`), func(m []string, now time.Time, _ time.Weekday) (time.Time, bool, bool) {
+		today := startOfDay(now)
+		if today.Weekday() == time.Sunday {
+			return today, false, true
+		}
+		return nextWeekday(today, time.Saturday, true), false, true
+	}},
+	{regexp.MustCompile(`(?i)\b` + datePrep + `next\s+(week|month|year)\b`), func(m []string, now time.Time, weekStart time.Weekday) (time.Time, bool, bool) {
+		switch strings.ToLower(m[1]) {
+		case "week":
+			return startOfWeek(startOfDay(now), weekStart).AddDate(0, 0, 7), false, true
+		case "month":
+			return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location()), false, true
+		}
+		return time.Date(now.Year()+1, time.January, 1, 0, 0, 0, 0, now.Location()), false, true
+	}},
+	{regexp.MustCompile(`(?i)\b` + datePrep + `(?:(this|next|coming)\s+)?` + weekdayRe + `\b`), func(m []string, now time.Time, weekStart time.Weekday) (time.Time, bool, bool) {
+		weekday := weekdayNames[strings.ToLower(m[2])]
+		today := startOfDay(now)
+		switch strings.ToLower(m[1]) {
+		case "next":
+			// The named day in the following week
+			return startOfWeek(today, weekStart).AddDate(0, 0, 7+daysFrom(weekStart, weekday)), false, true
+		case "this":
+			return nextWeekday(today, weekday, true), false, true
+		}
+		return nextWeekday(today, weekday, false), false, true
+	}},
+	{regexp.MustCompile(`(?i)\b` + datePrep + monthRe + `\.?\s+` + ordinalRe + yearSuffix + `\b`), func(m []string, now time.Time, _ time.Weekday) (time.Time, bool, bool) {
+		day, _ := strconv.Atoi(m[2])
+		return resolveMonthDay(monthNames[strings.ToLower(m[1])], day, m[3], now)
+	}},
+	{regexp.MustCompile(`(?i)\b` + datePrep + `(?:the\s+)?` + ordinalRe + `\s+(?:of\s+)?` + monthRe + yearSuffix + `\b`), func(m []string, now time.Time, _ time.Weekday) (time.Time, bool, bool) {
+		day, _ := strconv.Atoi(m[1])
+		return resolveMonthDay(monthNames[strings.ToLower(m[2])], day, m[3], now)
+	}},
+	{regexp.MustCompile(`(?i)\b` + datePrep + `the\s+(\d{1,2})(?:st|nd|rd|th)\b`), func(m []string, now time.Time, _ time.Weekday) (time.Time, bool, bool) {
+		day, _ := strconv.Atoi(m[1])
+		date, _, ok := validDate(now.Year(), now.Month(), day, now.Location())
+		if ok && date.Before(startOfDay(now)) {
+			date, _, ok = validDate(now.Year(), now.Month()+1, day, now.Location())
+		}
+		return date, false, ok
+	}},
+}
+
+// timePattern recognises a time of day, returned as hour and minute
+type timePattern struct {
+	re      *regexp.Regexp
+	resolve func(m []string) (hour int, minute int, ok bool)
+}
+
+var timePatterns = []timePattern{
+	{regexp.MustCompile(`(?i)` + timePrep + `\b(\d{1,2})(?:[:.](\d{2}))?\s*(am|pm|a\.m\.|p\.m\.)`), func(m []string) (int, int, bool) {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour < 1 || hour > 12 || minute > 59 {
+			return 0, 0, false
+		}
+		if hour == 12 {
+			hour = 0
+		}
+		if strings.HasPrefix(strings.ToLower(m[3]), "p") {
+			hour += 12
+		}
+		return hour, minute, true
+	}},
+	{regexp.MustCompile(`(?i)` + timePrep + `\b([01]?\d|2[0-3]):([0-5]\d)\b`), func(m []string) (int, int, bool) {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		return hour, minute, true
+	}},
+	{regexp.MustCompile(`(?i)\b(?:at|by|around)\s+(\d{1,2})\b(?:\s*o'?clock)?`), func(m []string) (int, int, bool) {
+		hour, _ := strconv.Atoi(m[1])
+		if hour > 23 {
+			return 0, 0, false
+		}
+		// "at 5" almost always means the afternoon
+		if hour >= 1 && hour <= 7 {
+			hour += 12
+		}
+		return hour, 0, true
+	}},
+	{regexp.MustCompile(`(?i)` + timePrep + `\b(noon|midday|midnight)\b`), func(m []string) (int, int, bool) {
+		if strings.EqualFold(m[1], "midnight") {
+			return 23, 59, true
+		}
+		return 12, 0, true
+	}},
+	{regexp.MustCompile(`(?i)\b(?:in\s+the\s+|this\s+)?(morning|afternoon|evening)\b`), func(m []string) (int, int, bool) {
+		switch strings.ToLower(m[1]) {
+		case "morning":
+			return 9, 0, true
+		case "afternoon":
+			return 15, 0, true
+		}
+		return 18, 0, true
+	}},
+}
+
+// Parser resolves date expressions. Its zero value starts weeks on Sunday; use New for Monday.
+type Parser struct {
+	WeekStart time.Weekday // first day of the week, used by "next week" and "next Monday"
+}
+
+// New creates a Parser with weeks starting on Monday.
+func New() *Parser {
+	return &Parser{WeekStart: time.Monday}
+}
+
+var defaultParser = New()
+
+// Parse resolves expr with weeks starting on Monday. See Parser.Parse.
+func Parse(expr string, now time.Time) (time.Time, error) {
+	return defaultParser.Parse(expr, now)
+}
+
+// Extract finds a date in text with weeks starting on Monday. See Parser.Extract.
+func Extract(text string, now time.Time) (time.Time, string, bool) {
+	return defaultParser.Extract(text, now)
+}
+
+// Parse resolves expr, which must be nothing but a date: an RFC 3339 timestamp, a date or
+// date-time without an offset (taken in now's location) or a natural-language expression.
+func (p *Parser) Parse(expr string, now time.Time) (time.Time, error) {
+	expr = strings.TrimSpace(expr)
+	if parsed, err := time.Parse(time.RFC3339, expr); err == nil {
+		return parsed, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+		if parsed, err := time.ParseInLocation(layout, expr, now.Location()); err == nil {
+			return parsed, nil
+		}
+	}
+
+	parsed, rest, ok := p.Extract(expr, now)
+	if !ok || strings.Trim(rest, " ,.") != "" {
+		return time.Time{}, fmt.Errorf("unsupported date format: %s", expr)
+	}
+	return parsed, nil
+}
+
+// Extract looks for a date and time of day in free text and resolves it against now.
+// It returns the resolved time, the text with the matched expressions removed, and whether a date was found.
+func (p *Parser) Extract(text string, now time.Time) (time.Time, string, bool) {
+	weekStart := p.WeekStart
+	var spans [][2]int
+	var date time.Time
+	dayFound, exact := false, false
+
+	for _, pattern := range dayPatterns {
+		loc := pattern.re.FindStringSubmatchIndex(text)
+		if loc == nil {
+			continue
+		}
+		resolved, isExact, ok := pattern.resolve(submatches(text, loc), now, weekStart)
+		if !ok {
+			continue
+		}
+		date, dayFound, exact = resolved, true, isExact
+		spans = append(spans, [2]int{loc[0], loc[1]})
+		break
+	}
+
+	hour, minute, timeFound := 0, 0, false
+	if !exact {
+		for _, pattern := range timePatterns {
+			loc := firstMatchOutside(pattern.re, text, spans)
+			if loc == nil {
+				continue
+			}
+			h, m, ok := pattern.resolve(submatches(text, loc))
+			if !ok {
+				continue
+			}
+			hour, minute, timeFound = h, m, true
+			spans = append(spans, [2]int{loc[0], loc[1]})
+			break
+		}
+	}
+
+	switch {
+	case dayFound && timeFound:
+		date = time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, date.Location())
+	case timeFound:
+		date = time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+		if date.Before(now) {
+			date = date.AddDate(0, 0, 1)
+		}
+	case !dayFound:
+		return time.Time{}, text, false
+	}
+
+	return date, removeSpans(text, spans), true
+}
+
+func resolveRelative(m []string, now time.Time, _ time.Weekday) (time.Time, bool, bool) {
+	amount, ok := numberWords[strings.ToLower(m[1])]
+	if !ok {
+		amount, _ = strconv.Atoi(m[1])
+	}
+	switch strings.ToLower(m[2]) {
+	case "minute", "min":
+		return now.Add(time.Duration(amount) * time.Minute).Truncate(time.Minute), true, true
+	case "hour", "hr":
+		return now.Add(time.Duration(amount) * time.Hour).Truncate(time.Minute), true, true
+	case "day":
+		return startOfDay(now).AddDate(0, 0, amount), false, true
+	case "week":
+		return startOfDay(now).AddDate(0, 0, 7*amount), false, true
+	case "month":
+		return startOfDay(now).AddDate(0, amount, 0), false, true
+	}
+	return startOfDay(now).AddDate(amount, 0, 0), false, true
+}
+
+// resolveMonthDay resolves "March 3" style dates, picking next year when the date has already passed
+func resolveMonthDay(month time.Month, day int, year string, now time.Time) (time.Time, bool, bool) {
+	if year != "" {
+		y, _ := strconv.Atoi(year)
+		return validDate(y, month, day, now.Location())
+	}
+	date, _, ok := validDate(now.Year(), month, day, now.Location())
+	if ok && date.Before(startOfDay(now)) {
+		date, _, ok = validDate(now.Year()+1, month, day, now.Location())
+	}
+	return date, false, ok
+}
+
+func validDate(year int, month time.Month, day int, loc *time.Location) (time.Time, bool, bool) {
+	date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if day < 1 || date.Day() != day {
+		return time.Time{}, false, false
+	}
+	return date, false, true
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func startOfWeek(day time.Time, weekStart time.Weekday) time.Time {
+	return day.AddDate(0, 0, -daysFrom(weekStart, day.Weekday()))
+}
+
+// daysFrom counts the days from one weekday forward to another
+func daysFrom(from time.Weekday, to time.Weekday) int {
+	return (int(to) - int(from) + 7) % 7
+}
+
+// nextWeekday returns the next day falling on weekday, counting today only if includeToday is set
+func nextWeekday(today time.Time, weekday time.Weekday, includeToday bool) time.Time {
+	days := daysFrom(today.Weekday(), weekday)
+	if days == 0 && !includeToday {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+func submatches(text string, loc []int) []string {
+	matches := make([]string, len(loc)/2)
+	for i := range matches {
+		if loc[2*i] >= 0 {
+			matches[i] = text[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return matches
+}
+
+// firstMatchOutside returns the first match of re that does not overlap any of the spans
+func firstMatchOutside(re *regexp.Regexp, text string, spans [][2]int) []int {
+	for _, loc := range re.FindAllStringSubmatchIndex(text, -1) {
+		overlaps := false
+		for _, span := range spans {
+			if loc[0] < span[1] && span[0] < loc[1] {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			return loc
+		}
+	}
+	return nil
+}
+
+func removeSpans(text string, spans [][2]int) string {
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] > spans[j][0] })
+	for _, span := range spans {
+		text = text[:span[0]] + " " + text[span[1]:]
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
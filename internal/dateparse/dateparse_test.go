@@ -0,0 +1,115 @@
+package dateparse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Wednesday, 19 November 2025, 10:00
+var now = time.Date(2025, time.November, 19, 10, 0, 0, 0, time.UTC)
+
+func TestParser_Extract(t *testing.T) {
+	day := func(month time.Month, d int, hour int, minute int) time.Time {
+		return time.Date(2025, month, d, hour, minute, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		text      string
+		expected  time.Time
+		remaining string
+	}{
+		{"call mom tomorrow at 5pm", day(time.November, 20, 17, 0), "call mom"},
+		{"dentist next Monday", day(time.November, 24, 0, 0), "dentist"},
+		{"report due Friday", day(time.November, 21, 0, 0), "report"},
+		{"review next Friday", day(time.November, 28, 0, 0), "review"},
+		{"renew passport in 3 days", day(time.November, 22, 0, 0), "renew passport"},
+		{"check the oven in two hours", day(time.November, 19, 12, 0), "check the oven"},
+		{"standup at 9am", day(time.November, 20, 9, 0), "standup"},
+		{"lunch at noon", day(time.November, 19, 12, 0), "lunch"},
+		{"take out the trash tonight", day(time.November, 19, 20, 0), "take out the trash"},
+		{"movie tonight at 9:30 pm", day(time.November, 19, 21, 30), "movie"},
+		{"gym Monday morning", day(time.November, 24, 9, 0), "gym"},
+		{"party on December 3rd", day(time.December, 3, 0, 0), "party"},
+		{"dinner on the 5th of December at 19:30", day(time.December, 5, 19, 30), "dinner"},
+		{"file taxes by 2025-12-24", day(time.December, 24, 0, 0), "file taxes"},
+		{"invoices by the end of the month", day(time.November, 30, 0, 0), "invoices"},
+		{"clean the garage this weekend", day(time.November, 22, 0, 0), "clean the garage"},
+		{"plan sprint next week", day(time.November, 24, 0, 0), "plan sprint"},
+		{"pay rent on the 1st", day(time.December, 1, 0, 0), "pay rent"},
+		{"send report EOD", day(time.November, 19, 17, 0), "send report"},
+	}
+	for _, tc := range cases {
+		due, remaining, ok := New().Extract(tc.text, now)
+		assert.True(t, ok, tc.text)
+		assert.Equal(t, tc.expected, due, tc.text)
+		assert.Equal(t, tc.remaining, remaining, tc.text)
+	}
+
+	t.Run("should roll past month-day dates into next year", func(t *testing.T) {
+		due, _, ok := Extract("birthday March 3", now)
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2026, time.March, 3, 0, 0, 0, 0, time.UTC), due)
+	})
+
+	t.Run("should resolve next week from the configured week start", func(t *testing.T) {
+		due, _, ok := (&Parser{WeekStart: time.Sunday}).Extract("next week", now)
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2025, time.November, 23, 0, 0, 0, 0, time.UTC), due)
+	})
+
+	t.Run("should leave text without dates alone", func(t *testing.T) {
+		for _, text := range []string{"buy milk", "I sat down in the sun", "read chapter 5", "february 30"} {
+			_, remaining, ok := Extract(text, now)
+			assert.False(t, ok, text)
+			assert.Equal(t, text, remaining)
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if !assert.NoError(t, err) {
+		return
+	}
+	berlinNow := now.In(berlin)
+
+	t.Run("should accept RFC 3339 timestamps unchanged", func(t *testing.T) {
+		parsed, err := Parse("2025-11-23T10:00:00Z", berlinNow)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2025, time.November, 23, 10, 0, 0, 0, time.UTC), parsed)
+	})
+
+	t.Run("should read dates without an offset in now's location", func(t *testing.T) {
+		parsed, err := Parse("2025-11-23", berlinNow)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2025, time.November, 23, 0, 0, 0, 0, berlin), parsed)
+
+		parsed, err = Parse("2025-11-23T18:30", berlinNow)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2025, time.November, 23, 18, 30, 0, 0, berlin), parsed)
+	})
+
+	t.Run("should resolve natural-language dates in now's location", func(t *testing.T) {
+		// 11:30 UTC is already 00:30 on the 20th in Auckland, so "tomorrow" is the 21st there
+		auckland, err := time.LoadLocation("Pacific/Auckland")
+		if !assert.NoError(t, err) {
+			return
+		}
+		parsed, err := Parse("tomorrow at 5pm", time.Date(2025, time.November, 19, 11, 30, 0, 0, time.UTC).In(auckland))
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2025, time.November, 21, 17, 0, 0, 0, auckland), parsed)
+
+		parsed, err = Parse("  Next Monday. ", berlinNow)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2025, time.November, 24, 0, 0, 0, 0, berlin), parsed)
+	})
+
+	t.Run("should reject anything that is not only a date", func(t *testing.T) {
+		for _, expr := range []string{"", "soon", "tomorrow buy milk", "2025-13-45"} {
+			_, err := Parse(expr, berlinNow)
+			assert.EqualError(t, err, "unsupported date format: "+expr, expr)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MemoryQueue is a Queue kept in process memory, for tests and single-process development setups.
+// Jobs are lost when the process exits.
+type MemoryQueue struct {
+	mu           sync.Mutex
+	jobs         map[uuid.UUID]*models.Job
+	LeaseTimeout time.Duration
+	now          func() time.Time
+}
+
+// NewMemoryQueue creates a new, empty MemoryQueue
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{
+		jobs:         make(map[uuid.UUID]*models.Job),
+		LeaseTimeout: DefaultLeaseTimeout,
+		now:          time.Now,
+	}
+}
+
+// Enqueue stores a new job
+func (q *MemoryQueue) Enqueue(ctx context.Context, job *models.Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now().UTC()
+	prepareJob(job, now)
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	stored := *job
+	q.jobs[job.ID] = &stored
+	return nil
+}
+
+// Claim leases the oldest runnable job
+func (q *MemoryQueue) Claim(ctx context.Context, workerID string) (*models.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now().UTC()
+	var next *models.Job
+	for _, job := range q.jobs {
+		runnable := job.Status == models.JobStatusQueued && !job.RunAt.After(now)
+		expired := job.Status == models.JobStatusRunning && job.LockedAt != nil && job.LockedAt.Before(now.Add(-q.LeaseTimeout))
+		if !runnable && !expired {
+			continue
+		}
+		if next == nil || job.RunAt.Before(next.RunAt) || (job.RunAt.Equal(next.RunAt) && job.CreatedAt.Before(next.CreatedAt)) {
+			next = job
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	next.Status = models.JobStatusRunning
+	next.Attempts++
+	next.LockedAt = &now
+	next.LockedBy = workerID
+	next.UpdatedAt = now
+
+	claimed := *next
+	return &claimed, nil
+}
+
+// Complete marks a claimed job as succeeded
+func (q *MemoryQueue) Complete(ctx context.Context, job *models.Job, result json.RawMessage) error {
+	return q.release(job, func(stored *models.Job, now time.Time) {
+		stored.Status = models.JobStatusSucceeded
+		stored.Result = result
+		stored.LastError = ""
+		stored.CompletedAt = &now
+	})
+}
+
+// Retry releases a claimed job so that it runs again at runAt
+func (q *MemoryQueue) Retry(ctx context.Context, job *models.Job, cause error, runAt time.Time) error {
+	return q.release(job, func(stored *models.Job, now time.Time) {
+		stored.Status = models.JobStatusQueued
+		stored.LastError = cause.Error()
+		stored.RunAt = runAt.UTC()
+	})
+}
+
+// Bury moves a claimed job to the dead state
+func (q *MemoryQueue) Bury(ctx context.Context, job *models.Job, cause error) error {
+	return q.release(job, func(stored *models.Job, now time.Time) {
+		stored.Status = models.JobStatusDead
+		stored.LastError = cause.Error()
+		stored.CompletedAt = &now
+	})
+}
+
+// Get retrieves a job owned by the given user
+func (q *MemoryQueue) Get(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok || job.UserID != userID {
+		return nil, ErrJobNotFound
+	}
+	found := *job
+	return &found, nil
+}
+
+// release applies the outcome of a claimed job, provided the claiming worker still holds the lease
+func (q *MemoryQueue) release(job *models.Job, apply func(stored *models.Job, now time.Time)) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stored, ok := q.jobs[job.ID]
+	if !ok || stored.Status != models.JobStatusRunning || stored.LockedBy != job.LockedBy {
+		return ErrLeaseLost
+	}
+
+	now := q.now().UTC()
+	apply(stored, now)
+	stored.LockedAt = nil
+	stored.LockedBy = ""
+	stored.UpdatedAt = now
+	*job = *stored
+	return nil
+}
@@ -0,0 +1,197 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultJobTimeout is how long a handler may run by default, leaving a minute of the lease to
+// record the outcome
+const DefaultJobTimeout = DefaultLeaseTimeout - time.Minute
+
+// recordTimeout bounds recording the outcome of a job, which still happens after the pool is stopped
+const recordTimeout = 10 * time.Second
+
+// Handler runs a job of one type. The returned result is stored as JSON on the job.
+type Handler func(ctx context.Context, job *models.Job) (interface{}, error)
+
+// permanentError marks a handler failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that the pool moves the job straight to the dead state instead of retrying it
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// ExponentialBackoff waits 2^(attempt-1) seconds before the next attempt, capped at ten minutes
+func ExponentialBackoff(attempt int) time.Duration {
+	const maxBackoff = 10 * time.Minute
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > 10 {
+		return maxBackoff
+	}
+	delay := time.Duration(1<<(attempt-1)) * time.Second
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
+
+// Pool runs registered handlers for jobs claimed from a Queue
+type Pool struct {
+	queue    Queue
+	handlers map[string]Handler
+	workers  int
+	workerID string
+
+	// PollInterval is how long an idle worker waits before looking for new jobs
+	PollInterval time.Duration
+	// Backoff returns the delay before retrying a job that failed on the given attempt
+	Backoff func(attempt int) time.Duration
+	// Timeout bounds each run of a handler. It must be shorter than the queue's lease timeout, or
+	// another worker may claim the job while it is still running.
+	Timeout time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	now    func() time.Time
+}
+
+// NewPool creates a worker pool that runs the given number of workers once started
+func NewPool(queue Queue, workers int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	hostname, _ := os.Hostname()
+	return &Pool{
+		queue:        queue,
+		handlers:     make(map[string]Handler),
+		workers:      workers,
+		workerID:     fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8]),
+		PollInterval: time.Second,
+		Backoff:      ExponentialBackoff,
+		Timeout:      DefaultJobTimeout,
+		now:          time.Now,
+	}
+}
+
+// Register sets the handler for a job type. It must be called before Start.
+func (p *Pool) Register(jobType string, handler Handler) {
+	p.handlers[jobType] = handler
+}
+
+// Start launches the workers in the background until ctx is cancelled or Stop is called
+func (p *Pool) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work(ctx)
+	}
+	log.Info().Int("workers", p.workers).Str("worker_id", p.workerID).Msg("Job workers started")
+}
+
+// Stop asks the workers to exit and waits for the jobs they are running to finish
+func (p *Pool) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
+func (p *Pool) work(ctx context.Context) {
+	defer p.wg.Done()
+	for {
+		processed, err := p.ProcessNext(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Job worker error")
+		}
+		if processed {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.PollInterval):
+		}
+	}
+}
+
+// ProcessNext claims and runs a single job. It reports whether a job was claimed; the
+// returned error is about the queue itself, since handler failures are recorded on the job.
+func (p *Pool) ProcessNext(ctx context.Context) (bool, error) {
+	if ctx.Err() != nil {
+		return false, nil
+	}
+
+	job, err := p.queue.Claim(ctx, p.workerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim job: %w", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	// A job that was claimed must have its outcome recorded even when Stop cancels ctx meanwhile,
+	// or it stays leased and runs again once the lease expires
+	record, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	handler, ok := p.handlers[job.Type]
+	if !ok {
+		return true, p.queue.Bury(record, job, fmt.Errorf("no handler registered for job type %q", job.Type))
+	}
+	if job.Attempts > job.MaxAttempts {
+		// Only possible when a worker died holding the lease on the last attempt
+		return true, p.queue.Bury(record, job, errors.New("exceeded max attempts"))
+	}
+
+	runCtx, cancelRun := context.WithTimeout(ctx, p.Timeout)
+	defer cancelRun()
+	result, err := p.run(runCtx, handler, job)
+	if err == nil {
+		var encoded json.RawMessage
+		if result != nil {
+			if encoded, err = json.Marshal(result); err != nil {
+				return true, p.queue.Bury(record, job, fmt.Errorf("failed to encode job result: %w", err))
+			}
+		}
+		return true, p.queue.Complete(record, job, encoded)
+	}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts {
+		log.Warn().Err(err).Str("job_id", job.ID.String()).Str("type", job.Type).Int("attempts", job.Attempts).Msg("Job failed permanently")
+		return true, p.queue.Bury(record, job, err)
+	}
+	return true, p.queue.Retry(record, job, err, p.now().Add(p.Backoff(job.Attempts)))
+}
+
+// run calls the handler, turning a panic into an ordinary failure so that it does not kill the worker
+func (p *Pool) run(ctx context.Context, handler Handler, job *models.Job) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
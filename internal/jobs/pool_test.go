@@ -0,0 +1,216 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPool(queue Queue, clock *testClock) *Pool {
+	pool := NewPool(queue, 1)
+	pool.now = clock.Now
+	return pool
+}
+
+func TestPoolRunsHandlerAndStoresResult(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 11, 19, 9, 0, 0, 0, time.UTC)}
+	queue := newTestMemoryQueue(t, clock)
+	pool := newTestPool(queue, clock)
+	pool.Register("echo", func(ctx context.Context, job *models.Job) (interface{}, error) {
+		return map[string]int{"attempt": job.Attempts}, nil
+	})
+
+	ctx := context.Background()
+	job := &models.Job{UserID: uuid.New(), Type: "echo"}
+	assert.NoError(t, queue.Enqueue(ctx, job))
+
+	processed, err := pool.ProcessNext(ctx)
+	assert.NoError(t, err)
+	assert.True(t, processed)
+
+	stored, err := queue.Get(ctx, job.ID, job.UserID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobStatusSucceeded, stored.Status)
+	assert.JSONEq(t, `{"attempt":1}`, string(stored.Result))
+
+	processed, err = pool.ProcessNext(ctx)
+	assert.NoError(t, err)
+	assert.False(t, processed)
+}
+
+func TestPoolRetriesWithBackoffThenBuries(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 11, 19, 9, 0, 0, 0, time.UTC)}
+	queue := newTestMemoryQueue(t, clock)
+	pool := newTestPool(queue, clock)
+	var calls int32
+	pool.Register("flaky", func(ctx context.Context, job *models.Job) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, errors.New("upstream unavailable")
+	})
+
+	ctx := context.Background()
+	job := &models.Job{UserID: uuid.New(), Type: "flaky", MaxAttempts: 3}
+	assert.NoError(t, queue.Enqueue(ctx, job))
+
+	// First attempt fails and is scheduled one second later
+	processed, err := pool.ProcessNext(ctx)
+	assert.NoError(t, err)
+	assert.True(t, processed)
+	stored, _ := queue.Get(ctx, job.ID, job.UserID)
+	assert.Equal(t, models.JobStatusQueued, stored.Status)
+	assert.Equal(t, clock.now.Add(time.Second), stored.RunAt)
+
+	processed, err = pool.ProcessNext(ctx)
+	assert.NoError(t, err)
+	assert.False(t, processed, "retry must wait for its backoff")
+
+	// Second attempt backs off for two seconds
+	clock.now = clock.now.Add(time.Second)
+	_, err = pool.ProcessNext(ctx)
+	assert.NoError(t, err)
+	stored, _ = queue.Get(ctx, job.ID, job.UserID)
+	assert.Equal(t, models.JobStatusQueued, stored.Status)
+	assert.Equal(t, clock.now.Add(2*time.Second), stored.RunAt)
+
+	// Third attempt exhausts MaxAttempts and the job is dead-lettered
+	clock.now = clock.now.Add(2 * time.Second)
+	_, err = pool.ProcessNext(ctx)
+	assert.NoError(t, err)
+	stored, _ = queue.Get(ctx, job.ID, job.UserID)
+	assert.Equal(t, models.JobStatusDead, stored.Status)
+	assert.Equal(t, "upstream unavailable", stored.LastError)
+	assert.Equal(t, 3, stored.Attempts)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestPoolPermanentErrorSkipsRetries(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 11, 19, 9, 0, 0, 0, time.UTC)}
+	queue := newTestMemoryQueue(t, clock)
+	pool := newTestPool(queue, clock)
+	pool.Register("broken", func(ctx context.Context, job *models.Job) (interface{}, error) {
+		return nil, Permanent(errors.New("invalid payload"))
+	})
+	pool.Register("panics", func(ctx context.Context, job *models.Job) (interface{}, error) {
+		panic("boom")
+	})
+
+	ctx := context.Background()
+	broken := &models.Job{UserID: uuid.New(), Type: "broken"}
+	assert.NoError(t, queue.Enqueue(ctx, broken))
+	unknown := &models.Job{UserID: uuid.New(), Type: "unknown", RunAt: clock.now.Add(time.Millisecond)}
+	assert.NoError(t, queue.Enqueue(ctx, unknown))
+	panics := &models.Job{UserID: uuid.New(), Type: "panics", MaxAttempts: 1, RunAt: clock.now.Add(2 * time.Millisecond)}
+	assert.NoError(t, queue.Enqueue(ctx, panics))
+
+	clock.now = clock.now.Add(time.Second)
+	for i := 0; i < 3; i++ {
+		_, err := pool.ProcessNext(ctx)
+		assert.NoError(t, err)
+	}
+
+	stored, _ := queue.Get(ctx, broken.ID, broken.UserID)
+	assert.Equal(t, models.JobStatusDead, stored.Status)
+	assert.Equal(t, 1, stored.Attempts)
+	assert.Equal(t, "invalid payload", stored.LastError)
+
+	stored, _ = queue.Get(ctx, unknown.ID, unknown.UserID)
+	assert.Equal(t, models.JobStatusDead, stored.Status)
+	assert.Contains(t, stored.LastError, "no handler registered")
+
+	stored, _ = queue.Get(ctx, panics.ID, panics.UserID)
+	assert.Equal(t, models.JobStatusDead, stored.Status)
+	assert.Contains(t, stored.LastError, "panicked")
+}
+
+func TestPoolBoundsHandlerRuns(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 11, 19, 9, 0, 0, 0, time.UTC)}
+	queue := newTestMemoryQueue(t, clock)
+	pool := newTestPool(queue, clock)
+	pool.Timeout = 10 * time.Millisecond
+	pool.Register("stuck", func(ctx context.Context, job *models.Job) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	ctx := context.Background()
+	job := &models.Job{UserID: uuid.New(), Type: "stuck"}
+	assert.NoError(t, queue.Enqueue(ctx, job))
+
+	processed, err := pool.ProcessNext(ctx)
+	assert.NoError(t, err)
+	assert.True(t, processed)
+
+	// The run gives up before the lease runs out, and the job is retried
+	stored, _ := queue.Get(ctx, job.ID, job.UserID)
+	assert.Equal(t, models.JobStatusQueued, stored.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), stored.LastError)
+	assert.Less(t, DefaultJobTimeout, DefaultLeaseTimeout)
+}
+
+// contextQueue fails to record outcomes under a cancelled context, like a database queue would
+type contextQueue struct {
+	Queue
+}
+
+func (q *contextQueue) Complete(ctx context.Context, job *models.Job, result json.RawMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return q.Queue.Complete(ctx, job, result)
+}
+
+func TestPoolRecordsOutcomeAfterStop(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 11, 19, 9, 0, 0, 0, time.UTC)}
+	queue := newTestMemoryQueue(t, clock)
+	pool := newTestPool(&contextQueue{Queue: queue}, clock)
+	ctx, cancel := context.WithCancel(context.Background())
+	pool.Register("shutdown", func(ctx context.Context, job *models.Job) (interface{}, error) {
+		cancel() // the pool is stopped while the job runs
+		return "done", nil
+	})
+
+	job := &models.Job{UserID: uuid.New(), Type: "shutdown"}
+	assert.NoError(t, queue.Enqueue(context.Background(), job))
+
+	processed, err := pool.ProcessNext(ctx)
+	assert.NoError(t, err)
+	assert.True(t, processed)
+
+	stored, err := queue.Get(context.Background(), job.ID, job.UserID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobStatusSucceeded, stored.Status)
+}
+
+func TestPoolStartAndStop(t *testing.T) {
+	queue := NewMemoryQueue()
+	pool := NewPool(queue, 2)
+	pool.PollInterval = 10 * time.Millisecond
+	done := make(chan struct{})
+	pool.Register("signal", func(ctx context.Context, job *models.Job) (interface{}, error) {
+		close(done)
+		return nil, nil
+	})
+
+	pool.Start(context.Background())
+	assert.NoError(t, queue.Enqueue(context.Background(), &models.Job{UserID: uuid.New(), Type: "signal"}))
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("job was not processed by the started pool")
+	}
+	pool.Stop()
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, ExponentialBackoff(1))
+	assert.Equal(t, 4*time.Second, ExponentialBackoff(3))
+	assert.Equal(t, 10*time.Minute, ExponentialBackoff(50))
+}
@@ -0,0 +1,128 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultLeaseTimeout is how long a claimed job may run before another worker may reclaim it
+const DefaultLeaseTimeout = 10 * time.Minute
+
+// PostgresQueue is a Queue stored in the jobs table.
+// Workers claim jobs with SELECT ... FOR UPDATE SKIP LOCKED, so any number of
+// server processes can share the table without handing out the same job twice.
+type PostgresQueue struct {
+	db           *gorm.DB
+	LeaseTimeout time.Duration
+	now          func() time.Time
+}
+
+// NewPostgresQueue creates a new PostgresQueue
+func NewPostgresQueue(db *gorm.DB) *PostgresQueue {
+	return &PostgresQueue{db: db, LeaseTimeout: DefaultLeaseTimeout, now: time.Now}
+}
+
+// Enqueue stores a new job
+func (q *PostgresQueue) Enqueue(ctx context.Context, job *models.Job) error {
+	prepareJob(job, q.now().UTC())
+	return q.db.WithContext(ctx).Create(job).Error
+}
+
+// Claim leases the oldest runnable job. Jobs left running by a worker that died are
+// reclaimed once their lease has expired.
+func (q *PostgresQueue) Claim(ctx context.Context, workerID string) (*models.Job, error) {
+	now := q.now().UTC()
+
+	// SQLite has no row locks and serialises writers anyway; it is only used for tests and local runs
+	lockClause := " FOR UPDATE SKIP LOCKED"
+	if q.db.Dialector.Name() == "sqlite" {
+		lockClause = ""
+	}
+
+	var job models.Job
+	result := q.db.WithContext(ctx).Raw(`
+		UPDATE jobs
+		SET status = ?, attempts = attempts + 1, locked_at = ?, locked_by = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE (status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)
+			ORDER BY run_at
+			LIMIT 1`+lockClause+`
+		)
+		RETURNING *`,
+		models.JobStatusRunning, now, workerID, now,
+		models.JobStatusQueued, now, models.JobStatusRunning, now.Add(-q.LeaseTimeout),
+	).Scan(&job)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+// Complete marks a claimed job as succeeded
+func (q *PostgresQueue) Complete(ctx context.Context, job *models.Job, result json.RawMessage) error {
+	now := q.now().UTC()
+	job.Status = models.JobStatusSucceeded
+	job.Result = result
+	job.LastError = ""
+	job.CompletedAt = &now
+	return q.release(ctx, job)
+}
+
+// Retry releases a claimed job so that it runs again at runAt
+func (q *PostgresQueue) Retry(ctx context.Context, job *models.Job, cause error, runAt time.Time) error {
+	job.Status = models.JobStatusQueued
+	job.LastError = cause.Error()
+	job.RunAt = runAt.UTC()
+	return q.release(ctx, job)
+}
+
+// Bury moves a claimed job to the dead state
+func (q *PostgresQueue) Bury(ctx context.Context, job *models.Job, cause error) error {
+	now := q.now().UTC()
+	job.Status = models.JobStatusDead
+	job.LastError = cause.Error()
+	job.CompletedAt = &now
+	return q.release(ctx, job)
+}
+
+// Get retrieves a job owned by the given user
+func (q *PostgresQueue) Get(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Job, error) {
+	var job models.Job
+	err := q.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// release writes the outcome of a claimed job, provided the claiming worker still holds the lease
+func (q *PostgresQueue) release(ctx context.Context, job *models.Job) error {
+	workerID := job.LockedBy
+	job.LockedAt = nil
+	job.LockedBy = ""
+
+	result := q.db.WithContext(ctx).Model(&models.Job{}).
+		Where("id = ? AND status = ? AND locked_by = ?", job.ID, models.JobStatusRunning, workerID).
+		Select("status", "last_error", "run_at", "result", "completed_at", "locked_at", "locked_by", "updated_at").
+		Updates(job)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"todo-backend/internal/config"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultMaxAttempts is used for jobs enqueued without an explicit attempt limit
+const DefaultMaxAttempts = 5
+
+// ErrJobNotFound is returned when a job does not exist or belongs to another user
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrLeaseLost is returned when a worker reports on a job whose lease was reclaimed by another worker
+var ErrLeaseLost = errors.New("job lease lost")
+
+// Queue stores background jobs and hands them out to workers
+type Queue interface {
+	// Enqueue stores a new job so that it runs no earlier than its RunAt time
+	Enqueue(ctx context.Context, job *models.Job) error
+	// Claim leases the next runnable job to the given worker, or returns nil when there is none
+	Claim(ctx context.Context, workerID string) (*models.Job, error)
+	// Complete marks a claimed job as succeeded with the given result
+	Complete(ctx context.Context, job *models.Job, result json.RawMessage) error
+	// Retry releases a claimed job so that it runs again at runAt
+	Retry(ctx context.Context, job *models.Job, cause error, runAt time.Time) error
+	// Bury moves a claimed job to the dead state
+	Bury(ctx context.Context, job *models.Job, cause error) error
+	// Get retrieves a job owned by the given user
+	Get(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Job, error)
+}
+
+// NewQueue creates the queue selected by cfg.JobQueue
+func NewQueue(cfg *config.Config, db *gorm.DB) (Queue, error) {
+	switch cfg.JobQueue {
+	case "", "postgres":
+		return NewPostgresQueue(db), nil
+	case "memory":
+		return NewMemoryQueue(), nil
+	default:
+		return nil, fmt.Errorf("unknown job queue: %s", cfg.JobQueue)
+	}
+}
+
+// prepareJob fills in the defaults for a job that is about to be enqueued
+func prepareJob(job *models.Job, now time.Time) {
+	job.Status = models.JobStatusQueued
+	job.Attempts = 0
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = DefaultMaxAttempts
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	if job.Payload == nil {
+		job.Payload = json.RawMessage("{}")
+	}
+}
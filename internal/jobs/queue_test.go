@@ -0,0 +1,202 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// testClock is a controllable time source shared by a queue and a pool under test
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func newTestPostgresQueue(t *testing.T, clock *testClock) *PostgresQueue {
+	// A private in-memory database per test keeps queued jobs from leaking between tests
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.NewString())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Job{}); err != nil {
+		t.Fatalf("failed to migrate jobs: %v", err)
+	}
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+
+	queue := NewPostgresQueue(db)
+	queue.now = clock.Now
+	return queue
+}
+
+func newTestMemoryQueue(t *testing.T, clock *testClock) *MemoryQueue {
+	queue := NewMemoryQueue()
+	queue.now = clock.Now
+	return queue
+}
+
+// forEachQueue runs the same behavioural test against every Queue implementation
+func forEachQueue(t *testing.T, test func(t *testing.T, queue Queue, clock *testClock)) {
+	t.Run("postgres", func(t *testing.T) {
+		clock := &testClock{now: time.Date(2025, 11, 19, 9, 0, 0, 0, time.UTC)}
+		test(t, newTestPostgresQueue(t, clock), clock)
+	})
+	t.Run("memory", func(t *testing.T) {
+		clock := &testClock{now: time.Date(2025, 11, 19, 9, 0, 0, 0, time.UTC)}
+		test(t, newTestMemoryQueue(t, clock), clock)
+	})
+}
+
+func TestQueueClaimAndComplete(t *testing.T) {
+	forEachQueue(t, func(t *testing.T, queue Queue, clock *testClock) {
+		ctx := context.Background()
+		userID := uuid.New()
+
+		job := &models.Job{UserID: userID, Type: "extract", Payload: json.RawMessage(`{"text":"buy milk"}`)}
+		assert.NoError(t, queue.Enqueue(ctx, job))
+		assert.Equal(t, models.JobStatusQueued, job.Status)
+		assert.Equal(t, DefaultMaxAttempts, job.MaxAttempts)
+
+		claimed, err := queue.Claim(ctx, "worker-1")
+		assert.NoError(t, err)
+		if !assert.NotNil(t, claimed) {
+			return
+		}
+		assert.Equal(t, job.ID, claimed.ID)
+		assert.Equal(t, models.JobStatusRunning, claimed.Status)
+		assert.Equal(t, 1, claimed.Attempts)
+		assert.Equal(t, "worker-1", claimed.LockedBy)
+		assert.JSONEq(t, `{"text":"buy milk"}`, string(claimed.Payload))
+
+		// A running job is not handed out twice
+		again, err := queue.Claim(ctx, "worker-2")
+		assert.NoError(t, err)
+		assert.Nil(t, again)
+
+		assert.NoError(t, queue.Complete(ctx, claimed, json.RawMessage(`{"task_ids":[]}`)))
+
+		stored, err := queue.Get(ctx, job.ID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.JobStatusSucceeded, stored.Status)
+		assert.JSONEq(t, `{"task_ids":[]}`, string(stored.Result))
+		assert.NotNil(t, stored.CompletedAt)
+	})
+}
+
+func TestQueueClaimRespectsRunAt(t *testing.T) {
+	forEachQueue(t, func(t *testing.T, queue Queue, clock *testClock) {
+		ctx := context.Background()
+		later := &models.Job{UserID: uuid.New(), Type: "extract", RunAt: clock.now.Add(time.Minute)}
+		assert.NoError(t, queue.Enqueue(ctx, later))
+		due := &models.Job{UserID: uuid.New(), Type: "extract"}
+		assert.NoError(t, queue.Enqueue(ctx, due))
+
+		claimed, err := queue.Claim(ctx, "worker-1")
+		assert.NoError(t, err)
+		if !assert.NotNil(t, claimed) {
+			return
+		}
+		assert.Equal(t, due.ID, claimed.ID)
+
+		claimed, err = queue.Claim(ctx, "worker-1")
+		assert.NoError(t, err)
+		assert.Nil(t, claimed)
+
+		clock.now = clock.now.Add(2 * time.Minute)
+		claimed, err = queue.Claim(ctx, "worker-1")
+		assert.NoError(t, err)
+		if !assert.NotNil(t, claimed) {
+			return
+		}
+		assert.Equal(t, later.ID, claimed.ID)
+	})
+}
+
+func TestQueueRetryAndBury(t *testing.T) {
+	forEachQueue(t, func(t *testing.T, queue Queue, clock *testClock) {
+		ctx := context.Background()
+		userID := uuid.New()
+		job := &models.Job{UserID: userID, Type: "transcribe"}
+		assert.NoError(t, queue.Enqueue(ctx, job))
+
+		claimed, err := queue.Claim(ctx, "worker-1")
+		assert.NoError(t, err)
+		assert.NoError(t, queue.Retry(ctx, claimed, errors.New("timeout"), clock.now.Add(30*time.Second)))
+
+		stored, err := queue.Get(ctx, job.ID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.JobStatusQueued, stored.Status)
+		assert.Equal(t, "timeout", stored.LastError)
+
+		clock.now = clock.now.Add(time.Minute)
+		claimed, err = queue.Claim(ctx, "worker-1")
+		assert.NoError(t, err)
+		if !assert.NotNil(t, claimed) {
+			return
+		}
+		assert.Equal(t, 2, claimed.Attempts)
+
+		assert.NoError(t, queue.Bury(ctx, claimed, errors.New("bad audio")))
+		stored, err = queue.Get(ctx, job.ID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.JobStatusDead, stored.Status)
+		assert.Equal(t, "bad audio", stored.LastError)
+
+		claimed, err = queue.Claim(ctx, "worker-1")
+		assert.NoError(t, err)
+		assert.Nil(t, claimed)
+	})
+}
+
+func TestQueueReclaimsExpiredLease(t *testing.T) {
+	forEachQueue(t, func(t *testing.T, queue Queue, clock *testClock) {
+		ctx := context.Background()
+		job := &models.Job{UserID: uuid.New(), Type: "extract"}
+		assert.NoError(t, queue.Enqueue(ctx, job))
+
+		abandoned, err := queue.Claim(ctx, "worker-1")
+		assert.NoError(t, err)
+		if !assert.NotNil(t, abandoned) {
+			return
+		}
+
+		clock.now = clock.now.Add(DefaultLeaseTimeout + time.Second)
+		reclaimed, err := queue.Claim(ctx, "worker-2")
+		assert.NoError(t, err)
+		if !assert.NotNil(t, reclaimed) {
+			return
+		}
+		assert.Equal(t, job.ID, reclaimed.ID)
+		assert.Equal(t, 2, reclaimed.Attempts)
+
+		// The original worker no longer holds the lease and cannot overwrite the outcome
+		assert.ErrorIs(t, queue.Complete(ctx, abandoned, nil), ErrLeaseLost)
+		assert.NoError(t, queue.Complete(ctx, reclaimed, nil))
+	})
+}
+
+func TestQueueGetIsScopedToUser(t *testing.T) {
+	forEachQueue(t, func(t *testing.T, queue Queue, clock *testClock) {
+		ctx := context.Background()
+		job := &models.Job{UserID: uuid.New(), Type: "extract"}
+		assert.NoError(t, queue.Enqueue(ctx, job))
+
+		_, err := queue.Get(ctx, job.ID, uuid.New())
+		assert.ErrorIs(t, err, ErrJobNotFound)
+		_, err = queue.Get(ctx, uuid.New(), job.UserID)
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+}
@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultAnthropicBaseURL  = "https://api.anthropic.com"
+	defaultAnthropicModel    = "claude-3-5-haiku-latest"
+	anthropicAPIVersion      = "2023-06-01"
+	anthropicMaxOutputTokens = 4096
+)
+
+// AnthropicExtractor implements the TaskExtractor interface using Anthropic's Messages API.
+type AnthropicExtractor struct {
+	apiKey      string
+	apiBaseURL  string
+	model       string
+	temperature float64
+	httpClient  *http.Client
+}
+
+// NewAnthropicExtractor creates a new AnthropicExtractor.
+func NewAnthropicExtractor(opts Options, client *http.Client) *AnthropicExtractor {
+	return &AnthropicExtractor{
+		apiKey:      opts.APIKey,
+		apiBaseURL:  strings.TrimRight(withDefault(opts.BaseURL, defaultAnthropicBaseURL), "/"),
+		model:       withDefault(opts.Model, defaultAnthropicModel),
+		temperature: opts.Temperature,
+		httpClient:  client,
+	}
+}
+
+// ExtractTasks extracts tasks from text using a Claude model.
+func (e *AnthropicExtractor) ExtractTasks(ctx context.Context, text string, opts ExtractOptions) ([]Task, error) {
+	requestBody := map[string]interface{}{
+		"model":       e.model,
+		"max_tokens":  anthropicMaxOutputTokens,
+		"temperature": e.temperature,
+		"system":      buildPrompt(opts),
+		"messages": []map[string]string{
+			{"role": "user", "content": text},
+		},
+	}
+	headers := map[string]string{
+		"x-api-key":         e.apiKey,
+		"anthropic-version": anthropicAPIVersion,
+	}
+
+	var anthropicResponse struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := postJSON(ctx, e.httpClient, ProviderAnthropic, e.apiBaseURL+"/v1/messages", headers, requestBody, &anthropicResponse); err != nil {
+		return nil, err
+	}
+
+	var content strings.Builder
+	for _, block := range anthropicResponse.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+	if strings.TrimSpace(content.String()) == "" {
+		return []Task{}, nil
+	}
+	return parseTasks(content.String())
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnthropicExtractor_ExtractTasks(t *testing.T) {
+	// Mock Anthropic Messages API Server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "test-anthropic-key", r.Header.Get("x-api-key"))
+		assert.Equal(t, anthropicAPIVersion, r.Header.Get("anthropic-version"))
+
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err)
+		assert.Equal(t, "claude-test", reqBody["model"])
+		assert.Equal(t, 0.3, reqBody["temperature"])
+		assert.Contains(t, reqBody["system"], "task extraction")
+		assert.Contains(t, reqBody, "max_tokens")
+
+		userContent := reqBody["messages"].([]interface{})[0].(map[string]interface{})["content"].(string)
+		switch {
+		case strings.Contains(userContent, "buy milk"):
+			_, _ = w.Write([]byte(`{
+				"content": [
+					{"type": "text", "text": "[{\"title\": \"Buy milk\", \"description\": \"Buy milk tomorrow\", \"due_date\": \"2025-11-20T08:00:00Z\", \"priority\": \"high\", \"subtasks\": [\"Check fridge\"]}]"}
+				],
+				"stop_reason": "end_turn"
+			}`))
+		case strings.Contains(userContent, "fenced"):
+			_, _ = w.Write([]byte(`{"content": [{"type": "text", "text": "` + "```json\\n[{\\\"title\\\": \\\"Call mom\\\"}]\\n```" + `"}]}`))
+		case strings.Contains(userContent, "overloaded"):
+			w.WriteHeader(529)
+			_, _ = w.Write([]byte(`{"type": "error", "error": {"type": "overloaded_error"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"content": [{"type": "text", "text": "[]"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	extractor := NewAnthropicExtractor(Options{APIKey: "test-anthropic-key", BaseURL: server.URL, Model: "claude-test", Temperature: 0.3}, server.Client())
+
+	t.Run("should extract a single task", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(context.Background(), "Tomorrow buy milk, it's urgent", ExtractOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1)
+		assert.Equal(t, "Buy milk", tasks[0].Title)
+		assert.Equal(t, "high", tasks[0].Priority)
+		assert.Equal(t, []string{"Check fridge"}, tasks[0].Subtasks)
+		expectedDate, _ := time.Parse(time.RFC3339, "2025-11-20T08:00:00Z")
+		assert.Equal(t, expectedDate, tasks[0].DueDate)
+	})
+
+	t.Run("should accept JSON fenced in a code block", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(context.Background(), "fenced reply please", ExtractOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1)
+		assert.Equal(t, "Call mom", tasks[0].Title)
+	})
+
+	t.Run("should return empty array for no tasks", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(context.Background(), "Just some random text.", ExtractOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+
+	t.Run("should handle API errors", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(context.Background(), "overloaded", ExtractOptions{})
+		assert.Error(t, err)
+		assert.Empty(t, tasks)
+		assert.Contains(t, err.Error(), "anthropic api error: status 529")
+	})
+}
@@ -12,9 +12,45 @@ type Task struct {
 	DueDate     time.Time `json:"due_date"`
 	Priority    string    `json:"priority"` // low|medium|high
 	Subtasks    []string  `json:"subtasks"`
+	Recurrence  string    `json:"recurrence"` // RRULE for repeating tasks, e.g. "FREQ=WEEKLY;BYDAY=MO"; empty for one-offs
+	Tags        []string  `json:"tags"`       // names from ExtractOptions.Tags that fit the task
+	DependsOn   []int     `json:"depends_on"` // positions in the extracted list of earlier tasks to do first
+	Assignee    string    `json:"assignee"`   // the name from ExtractOptions.Members the task is handed to; empty if none
+}
+
+// ExtractOptions tells an extractor when and where the text was written, so relative
+// dates like "tomorrow" resolve to the user's tomorrow, and carries the user's preferences.
+type ExtractOptions struct {
+	Now             time.Time      // zero for the current time
+	Location        *time.Location // nil for UTC
+	WeekStart       time.Weekday   // first day of the user's week; the zero value is Sunday
+	Locale          string         // BCP 47 tag, e.g. "en-US"; empty if unknown
+	DefaultPriority string         // priority for tasks that do not state one; empty for "medium"
+	Tags            []string       // names of the user's tags, which extracted tasks may be given
+	Members         []string       // names of the people extracted tasks may be assigned to, such as the members of a shared list
+}
+
+// now returns the reference time in the reference location
+func (o ExtractOptions) now() time.Time {
+	now := o.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if o.Location != nil {
+		return now.In(o.Location)
+	}
+	return now.UTC()
+}
+
+// defaultPriority returns the priority for tasks that do not state one
+func (o ExtractOptions) defaultPriority() string {
+	if o.DefaultPriority == "" {
+		return "medium"
+	}
+	return o.DefaultPriority
 }
 
 // TaskExtractor defines the interface for LLM-based task extraction
 type TaskExtractor interface {
-	ExtractTasks(ctx context.Context, text string) ([]Task, error)
+	ExtractTasks(ctx context.Context, text string, opts ExtractOptions) ([]Task, error)
 }
@@ -0,0 +1,34 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FallbackExtractor tries a primary extractor and, when it fails, answers with a fallback
+// instead. It is used to fall back from a remote model to the rule-based extractor.
+type FallbackExtractor struct {
+	primary  TaskExtractor
+	fallback TaskExtractor
+}
+
+// NewFallbackExtractor creates a new FallbackExtractor.
+func NewFallbackExtractor(primary TaskExtractor, fallback TaskExtractor) *FallbackExtractor {
+	return &FallbackExtractor{primary: primary, fallback: fallback}
+}
+
+// ExtractTasks extracts tasks with the primary extractor, or the fallback if that errors.
+func (e *FallbackExtractor) ExtractTasks(ctx context.Context, text string, opts ExtractOptions) ([]Task, error) {
+	tasks, err := e.primary.ExtractTasks(ctx, text, opts)
+	if err == nil {
+		return tasks, nil
+	}
+	if ctx.Err() != nil {
+		// The caller gave up; there is nobody left to answer
+		return nil, err
+	}
+
+	log.Warn().Err(err).Msg("Task extraction failed, using fallback extractor")
+	return e.fallback.ExtractTasks(ctx, text, opts)
+}
@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultOllamaBaseURL = "http://localhost:11434"
+	defaultOllamaModel   = "llama3.1"
+)
+
+// OllamaExtractor implements the TaskExtractor interface using a local Ollama server's chat API.
+type OllamaExtractor struct {
+	apiBaseURL  string
+	model       string
+	temperature float64
+	httpClient  *http.Client
+}
+
+// NewOllamaExtractor creates a new OllamaExtractor.
+func NewOllamaExtractor(opts Options, client *http.Client) *OllamaExtractor {
+	return &OllamaExtractor{
+		apiBaseURL:  strings.TrimRight(withDefault(opts.BaseURL, defaultOllamaBaseURL), "/"),
+		model:       withDefault(opts.Model, defaultOllamaModel),
+		temperature: opts.Temperature,
+		httpClient:  client,
+	}
+}
+
+// ExtractTasks extracts tasks from text using a model served by Ollama.
+func (e *OllamaExtractor) ExtractTasks(ctx context.Context, text string, opts ExtractOptions) ([]Task, error) {
+	requestBody := map[string]interface{}{
+		"model": e.model,
+		"messages": []map[string]string{
+			{"role": "system", "content": buildPrompt(opts)},
+			{"role": "user", "content": text},
+		},
+		"stream": false,
+		"format": "json",
+		"options": map[string]interface{}{
+			"temperature": e.temperature,
+		},
+	}
+
+	var ollamaResponse struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	if err := postJSON(ctx, e.httpClient, ProviderOllama, e.apiBaseURL+"/api/chat", nil, requestBody, &ollamaResponse); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(ollamaResponse.Message.Content) == "" {
+		return []Task{}, nil
+	}
+	return parseTasks(ollamaResponse.Message.Content)
+}
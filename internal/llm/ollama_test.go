@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOllamaExtractor_ExtractTasks(t *testing.T) {
+	// Mock Ollama chat API Server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err)
+		assert.Equal(t, defaultOllamaModel, reqBody["model"])
+		assert.Equal(t, false, reqBody["stream"])
+		assert.Equal(t, "json", reqBody["format"])
+		assert.Equal(t, 0.1, reqBody["options"].(map[string]interface{})["temperature"])
+
+		userContent := reqBody["messages"].([]interface{})[1].(map[string]interface{})["content"].(string)
+		switch {
+		case strings.Contains(userContent, "buy milk"):
+			// JSON mode makes local models wrap the array in an object
+			_, _ = w.Write([]byte(`{"message": {"role": "assistant", "content": "{\"tasks\": [{\"title\": \"Buy milk\", \"priority\": \"low\"}]}"}, "done": true}`))
+		case strings.Contains(userContent, "model missing"):
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "model \"llama3.1\" not found, try pulling it first"}`))
+		default:
+			_, _ = w.Write([]byte(`{"message": {"role": "assistant", "content": "not json at all"}, "done": true}`))
+		}
+	}))
+	defer server.Close()
+
+	extractor := NewOllamaExtractor(Options{BaseURL: server.URL + "/", Temperature: 0.1}, server.Client())
+
+	t.Run("should extract tasks wrapped in an object", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(context.Background(), "buy milk sometime", ExtractOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1)
+		assert.Equal(t, "Buy milk", tasks[0].Title)
+		assert.Equal(t, "low", tasks[0].Priority)
+	})
+
+	t.Run("should handle invalid JSON from LLM gracefully", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(context.Background(), "something else", ExtractOptions{})
+		assert.Error(t, err)
+		assert.Empty(t, tasks)
+		assert.Contains(t, err.Error(), "failed to unmarshal tasks from LLM response")
+	})
+
+	t.Run("should handle API errors", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(context.Background(), "model missing", ExtractOptions{})
+		assert.Error(t, err)
+		assert.Empty(t, tasks)
+		assert.Contains(t, err.Error(), "ollama api error: status 404")
+	})
+}
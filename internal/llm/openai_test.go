@@ -74,7 +74,7 @@ func TestOpenAIExtractor_ExtractTasks(t *testing.T) {
 
 	t.Run("should extract a single task", func(t *testing.T) {
 		text := "Tomorrow buy milk and call Raj"
-		tasks, err := extractor.ExtractTasks(context.Background(), text)
+		tasks, err := extractor.ExtractTasks(context.Background(), text, ExtractOptions{})
 		assert.NoError(t, err)
 		assert.Len(t, tasks, 1)
 		assert.Equal(t, "Buy milk", tasks[0].Title)
@@ -85,14 +85,14 @@ func TestOpenAIExtractor_ExtractTasks(t *testing.T) {
 
 	t.Run("should return empty array for no tasks", func(t *testing.T) {
 		text := "Just some random text."
-		tasks, err := extractor.ExtractTasks(context.Background(), text)
+		tasks, err := extractor.ExtractTasks(context.Background(), text, ExtractOptions{})
 		assert.NoError(t, err)
 		assert.Empty(t, tasks)
 	})
 
 	t.Run("should handle invalid JSON from LLM gracefully", func(t *testing.T) {
 		text := "This is invalid json"
-		tasks, err := extractor.ExtractTasks(context.Background(), text)
+		tasks, err := extractor.ExtractTasks(context.Background(), text, ExtractOptions{})
 		assert.Error(t, err) // Should return an error indicating unmarshal failure
 		assert.Empty(t, tasks)
 		assert.Contains(t, err.Error(), "failed to unmarshal tasks from LLM response")
@@ -100,7 +100,7 @@ func TestOpenAIExtractor_ExtractTasks(t *testing.T) {
 
 	t.Run("should handle API errors", func(t *testing.T) {
 		text := "failed API call"
-		tasks, err := extractor.ExtractTasks(context.Background(), text)
+		tasks, err := extractor.ExtractTasks(context.Background(), text, ExtractOptions{})
 		assert.Error(t, err)
 		assert.Empty(t, tasks)
 		assert.Contains(t, err.Error(), "openai api error")
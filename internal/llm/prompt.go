@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const extractionPrompt = `
+You are a highly efficient task extraction AI. Your sole purpose is to parse user-provided text and extract structured tasks in a strict JSON array format.
+
+Current Date and Time: %s
+Time Zone: %s (UTC%s)
+Weeks Start On: %s
+User Locale: %s
+Existing Tags: %s
+List Members: %s
+
+Here are the rules:
+- ALWAYS respond with a JSON array of tasks. Do not include any other prose, explanations, or text outside the JSON array.
+- If no tasks can be extracted, return an empty JSON array: []
+- Each task object must adhere to the following strict JSON schema:
+  {
+    "title": "string",            // Required: A concise summary of the task.
+    "description": "string",      // Required: A detailed description of the task. If not explicitly provided, infer from the title.
+    "due_date": "string",         // Required: The due date of the task in ISO 8601 format with the time zone's offset (e.g., "2025-11-23T10:00:00%s"). If no specific time is given, default to 00:00:00 on the specified date. If no date is mentioned, use null.
+    "priority": "string",         // Required: The priority of the task. Must be one of: "low", "medium", "high". Default to "%s" if not specified.
+    "subtasks": ["string"],       // Required: An array of strings, where each string is a subtask. If no subtasks, return an empty array [].
+    "recurrence": "string",       // Required: For repeating tasks ("every Monday", "on the 1st of each month"), an iCalendar RRULE without the "RRULE:" prefix, using only FREQ=DAILY, WEEKLY or MONTHLY with INTERVAL, BYDAY (weekly only), BYMONTHDAY (monthly only), UNTIL and COUNT (e.g., "FREQ=WEEKLY;BYDAY=MO,TH"). Set due_date to the first occurrence. For one-off tasks, use null.
+    "tags": ["string"],           // Required: Names from the existing tags that fit the task, spelled exactly as listed. Never invent new tags. If none fit, return an empty array [].
+    "depends_on": [0],            // Required: When the text orders tasks ("after X, do Y", "once X is done", "X, then Y"), the 0-based positions in this array of the earlier tasks that must be done before this one. List tasks in the order they are to be done. If none, return an empty array [].
+    "assignee": "string"          // Required: When the text hands the task to someone ("ask Priya to...", "Sam should..."), that person's name from the list members, spelled exactly as listed; a first name is enough to match. Leave the person out of the title. If nobody listed is named, use null.
+  }
+- Handle natural date expressions (e.g., "tomorrow", "next week", "Monday morning", "in 3 days"). Convert them to the appropriate ISO 8601 timestamp relative to the current date and time.
+- Detect multiple tasks within a single input text.
+- Ensure all required fields are present. Infer if necessary.
+- On failure to extract or parse, return an empty array [].
+`
+
+// buildPrompt fills the extraction prompt with the current date and time zone from opts
+func buildPrompt(opts ExtractOptions) string {
+	now := opts.now()
+	offset := now.Format("-07:00")
+	locale := opts.Locale
+	if locale == "" {
+		locale = "unknown"
+	}
+	tags := "none"
+	if len(opts.Tags) > 0 {
+		quoted, _ := json.Marshal(opts.Tags)
+		tags = string(quoted)
+	}
+	members := "none"
+	if len(opts.Members) > 0 {
+		quoted, _ := json.Marshal(opts.Members)
+		members = string(quoted)
+	}
+	return fmt.Sprintf(extractionPrompt, now.Format("Monday, January 2, 2006 15:04"), now.Location(), offset,
+		opts.WeekStart, locale, tags, members, offset, opts.defaultPriority())
+}
+
+// parseTasks decodes the tasks from a model's reply. Besides the bare JSON array the prompt
+// asks for, it accepts the array wrapped in an object (as JSON mode forces on some providers)
+// and replies fenced in a markdown code block.
+func parseTasks(content string) ([]Task, error) {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "```") {
+		trimmed = strings.TrimPrefix(trimmed, "```json")
+		trimmed = strings.TrimPrefix(trimmed, "```")
+		trimmed = strings.TrimSuffix(strings.TrimSpace(trimmed), "```")
+		trimmed = strings.TrimSpace(trimmed)
+	}
+
+	var tasks []Task
+	err := json.Unmarshal([]byte(trimmed), &tasks)
+	if err == nil {
+		return tasks, nil
+	}
+
+	var wrapped map[string]json.RawMessage
+	if json.Unmarshal([]byte(trimmed), &wrapped) == nil {
+		for _, key := range []string{"tasks", "items", "data"} {
+			if raw, ok := wrapped[key]; ok {
+				if json.Unmarshal(raw, &tasks) == nil {
+					return tasks, nil
+				}
+			}
+		}
+		// A single task object on its own
+		var task Task
+		if _, ok := wrapped["title"]; ok && json.Unmarshal([]byte(trimmed), &task) == nil {
+			return []Task{task}, nil
+		}
+	}
+
+	// As per the prompt's contract, an unparseable reply yields no tasks
+	return []Task{}, fmt.Errorf("failed to unmarshal tasks from LLM response: %w. Response content: %s", err, content)
+}
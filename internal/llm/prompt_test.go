@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildPrompt(t *testing.T) {
+	t.Run("should state the current date in the user's time zone", func(t *testing.T) {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		if !assert.NoError(t, err) {
+			return
+		}
+		prompt := buildPrompt(ExtractOptions{
+			Now:             time.Date(2025, time.November, 19, 23, 30, 0, 0, time.UTC),
+			Location:        berlin,
+			WeekStart:       time.Monday,
+			Locale:          "de-DE",
+			DefaultPriority: "low",
+			Tags:            []string{"work", "errands"},
+			Members:         []string{"Priya Sharma", "Sam"},
+		})
+		assert.Contains(t, prompt, "Current Date and Time: Thursday, November 20, 2025 00:30")
+		assert.Contains(t, prompt, "Time Zone: Europe/Berlin (UTC+01:00)")
+		assert.Contains(t, prompt, `"2025-11-23T10:00:00+01:00"`)
+		assert.Contains(t, prompt, "Weeks Start On: Monday")
+		assert.Contains(t, prompt, "User Locale: de-DE")
+		assert.Contains(t, prompt, `Default to "low" if not specified`)
+		assert.Contains(t, prompt, `"recurrence": "string"`)
+		assert.Contains(t, prompt, `"depends_on": [0]`)
+		assert.Contains(t, prompt, `Existing Tags: ["work","errands"]`)
+		assert.Contains(t, prompt, `List Members: ["Priya Sharma","Sam"]`)
+		assert.Contains(t, prompt, `"assignee": "string"`)
+		assert.NotContains(t, prompt, "%!")
+	})
+
+	t.Run("should default to the current time in UTC", func(t *testing.T) {
+		prompt := buildPrompt(ExtractOptions{})
+		assert.Contains(t, prompt, "Current Date and Time: "+time.Now().UTC().Format("Monday, January 2, 2006"))
+		assert.Contains(t, prompt, "Time Zone: UTC (UTC+00:00)")
+		assert.Contains(t, prompt, `Default to "medium" if not specified`)
+		assert.Contains(t, prompt, "Existing Tags: none")
+		assert.Contains(t, prompt, "List Members: none")
+	})
+}
@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"todo-backend/internal/config"
+)
+
+// Supported values of config.Config.LLMProvider
+const (
+	ProviderOpenAI           = "openai"
+	ProviderAnthropic        = "anthropic"
+	ProviderOllama           = "ollama"
+	ProviderOpenAICompatible = "openai-compatible" // llama.cpp server, vLLM, LM Studio, ...
+	ProviderRules            = "rules"             // offline, rule-based extraction
+)
+
+// Options tunes a remote extractor. Zero values fall back to the provider's defaults.
+type Options struct {
+	APIKey      string
+	BaseURL     string
+	Model       string
+	Temperature float64
+	Timeout     time.Duration
+}
+
+// NewExtractor creates the TaskExtractor selected by cfg.LLMProvider. With cfg.LLMFallback set,
+// remote extractors fall back to rule-based extraction when the provider call fails.
+func NewExtractor(cfg *config.Config) (TaskExtractor, error) {
+	extractor, err := newRemoteExtractor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if extractor == nil {
+		return NewRuleBasedExtractor(), nil
+	}
+	if cfg.LLMFallback {
+		return NewFallbackExtractor(extractor, NewRuleBasedExtractor()), nil
+	}
+	return extractor, nil
+}
+
+// newRemoteExtractor creates the model-backed extractor for cfg.LLMProvider, or nil for ProviderRules
+func newRemoteExtractor(cfg *config.Config) (TaskExtractor, error) {
+	opts := Options{
+		BaseURL:     cfg.LLMBaseURL,
+		Model:       cfg.LLMModel,
+		Temperature: cfg.LLMTemperature,
+		Timeout:     cfg.LLMTimeout,
+	}
+	client := &http.Client{Timeout: cfg.LLMTimeout}
+
+	switch cfg.LLMProvider {
+	case "", ProviderOpenAI:
+		opts.APIKey = cfg.OpenAPIKey
+		return NewOpenAIExtractorWithOptions(opts, client), nil
+	case ProviderAnthropic:
+		opts.APIKey = cfg.AnthropicAPIKey
+		return NewAnthropicExtractor(opts, client), nil
+	case ProviderOllama:
+		return NewOllamaExtractor(opts, client), nil
+	case ProviderOpenAICompatible:
+		if opts.BaseURL == "" {
+			return nil, fmt.Errorf("LLM_BASE_URL is required for the %s provider", ProviderOpenAICompatible)
+		}
+		opts.APIKey = cfg.LLMAPIKey
+		extractor := NewOpenAIExtractorWithOptions(opts, client)
+		extractor.name = ProviderOpenAICompatible
+		return extractor, nil
+	case ProviderRules:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider: %s", cfg.LLMProvider)
+	}
+}
+
+// postJSON sends body as JSON to url and decodes a 200 response into out
+func postJSON(ctx context.Context, client *http.Client, provider string, url string, headers map[string]string, body interface{}, out interface{}) error {
+	requestBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s api error: status %d, body: %s", provider, resp.StatusCode, respBody)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", provider, err)
+	}
+	return nil
+}
+
+func withDefault(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-backend/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewExtractor(t *testing.T) {
+	t.Run("should select the configured provider", func(t *testing.T) {
+		cases := map[string]interface{}{
+			"":                       &OpenAIExtractor{},
+			ProviderOpenAI:           &OpenAIExtractor{},
+			ProviderAnthropic:        &AnthropicExtractor{},
+			ProviderOllama:           &OllamaExtractor{},
+			ProviderOpenAICompatible: &OpenAIExtractor{},
+			ProviderRules:            &RuleBasedExtractor{},
+		}
+		for provider, expected := range cases {
+			extractor, err := NewExtractor(&config.Config{LLMProvider: provider, LLMBaseURL: "http://localhost:8000/v1"})
+			assert.NoError(t, err, provider)
+			assert.IsType(t, expected, extractor, provider)
+		}
+	})
+
+	t.Run("should apply model, base URL and timeout from config", func(t *testing.T) {
+		extractor, err := NewExtractor(&config.Config{
+			LLMProvider:    ProviderOpenAI,
+			OpenAPIKey:     "sk-test",
+			LLMModel:       "gpt-4o-mini",
+			LLMBaseURL:     "https://example.com/v1/",
+			LLMTemperature: 0.5,
+			LLMTimeout:     5 * time.Second,
+		})
+		assert.NoError(t, err)
+		openai := extractor.(*OpenAIExtractor)
+		assert.Equal(t, "gpt-4o-mini", openai.model)
+		assert.Equal(t, "https://example.com/v1", openai.apiBaseURL)
+		assert.Equal(t, 0.5, openai.temperature)
+		assert.Equal(t, 5*time.Second, openai.httpClient.Timeout)
+	})
+
+	t.Run("should wrap remote extractors with the rule-based fallback", func(t *testing.T) {
+		extractor, err := NewExtractor(&config.Config{LLMProvider: ProviderAnthropic, LLMFallback: true})
+		assert.NoError(t, err)
+		if assert.IsType(t, &FallbackExtractor{}, extractor) {
+			assert.IsType(t, &AnthropicExtractor{}, extractor.(*FallbackExtractor).primary)
+			assert.IsType(t, &RuleBasedExtractor{}, extractor.(*FallbackExtractor).fallback)
+		}
+
+		extractor, err = NewExtractor(&config.Config{LLMProvider: ProviderRules, LLMFallback: true})
+		assert.NoError(t, err)
+		assert.IsType(t, &RuleBasedExtractor{}, extractor)
+	})
+
+	t.Run("should require a base URL for OpenAI-compatible endpoints", func(t *testing.T) {
+		_, err := NewExtractor(&config.Config{LLMProvider: ProviderOpenAICompatible})
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject unknown providers", func(t *testing.T) {
+		_, err := NewExtractor(&config.Config{LLMProvider: "palm"})
+		assert.EqualError(t, err, "unknown LLM provider: palm")
+	})
+}
+
+func TestOpenAICompatibleExtractor_ExtractTasks(t *testing.T) {
+	// Mock llama.cpp-style server: OpenAI's API shape, no authentication
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err)
+		assert.Equal(t, "qwen2.5-7b-instruct", reqBody["model"])
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error": "loading model"}`))
+	}))
+	defer server.Close()
+
+	extractor, err := NewExtractor(&config.Config{
+		LLMProvider: ProviderOpenAICompatible,
+		LLMBaseURL:  server.URL + "/v1",
+		LLMModel:    "qwen2.5-7b-instruct",
+	})
+	assert.NoError(t, err)
+
+	tasks, err := extractor.ExtractTasks(context.Background(), "Tomorrow buy milk", ExtractOptions{})
+	assert.Empty(t, tasks)
+	assert.ErrorContains(t, err, "openai-compatible api error: status 503")
+}
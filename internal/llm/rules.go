@@ -0,0 +1,400 @@
+package llm
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+	"todo-backend/internal/dateparse"
+	"todo-backend/internal/recurrence"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	bulletPrefix = regexp.MustCompile(`^(\s*)(?:[-*•+]|\d{1,3}[.)])\s+(?:\[[ xX]?\]\s*)?`)
+	clauseBreak  = regexp.MustCompile(`(?i)\s*;\s*|,?\s+and\s+then\s+|,\s+then\s+|,\s+and\s+also\s+|,\s+also\s+`)
+	serialAnd    = regexp.MustCompile(`(?i),\s+and\s+`)
+	thenBreak    = regexp.MustCompile(`(?i)\bthen\b`)
+	thenPrefix   = regexp.MustCompile(`(?i)^(?:and\s+)?(?:then|after\s+that|afterwards?),?\s+`)
+
+	lowPriority  = regexp.MustCompile(`(?i)\b(?:low[- ]priority|not\s+urgent|no\s+rush|whenever|some\s?day|eventually|if\s+(?:i|we)\s+(?:have|get)\s+(?:the\s+)?time)\b`)
+	highPriority = regexp.MustCompile(`(?i)\b(?:urgent(?:ly)?|asap|as\s+soon\s+as\s+possible|immediately|high[- ]priority|top\s+priority|critical|important)\b|!{2,}`)
+)
+
+// Lead-ins that carry no meaning in a task title, longest first
+var fillerPrefixes = []string{
+	"i need to remember to", "don't forget to", "do not forget to", "i've got to", "i'd like to",
+	"make sure to", "remember to", "i need to", "i have to", "i got to", "i want to", "we need to",
+	"we have to", "i gotta", "i must", "i should", "we should", "we must", "i will", "need to",
+	"have to", "i'll", "let's", "please", "also", "and", "then", "so", "um", "uh", "to",
+}
+
+// Words left dangling once a priority phrase like "it's urgent" is cut from a title
+var danglingWords = map[string]bool{
+	"it's": true, "its": true, "it": true, "is": true, "this": true, "that's": true,
+	"very": true, "really": true, "super": true, "and": true, "but": true, "so": true,
+}
+
+// Sentences that never describe a task on their own
+var chatter = map[string]bool{
+	"ok": true, "okay": true, "thanks": true, "thank you": true, "hi": true, "hello": true,
+	"bye": true, "yes": true, "no": true, "that's it": true, "that's all": true,
+}
+
+// Abbreviations whose trailing period does not end a sentence
+var abbreviations = map[string]bool{
+	"dr": true, "mr": true, "mrs": true, "ms": true, "st": true, "vs": true, "etc": true,
+	"e.g": true, "i.e": true, "a.m": true, "p.m": true, "jr": true, "sr": true,
+}
+
+// RuleBasedExtractor implements the TaskExtractor interface without a model. It splits text into
+// tasks on bullets, sentences and clauses, spots priority keywords and resolves natural-language
+// dates, so extraction keeps working offline and gives the same answer for the same input. A task
+// introduced by "then" or "after that" depends on the task before it, and one handed to a list
+// member ("ask Priya to ...") is assigned to them.
+type RuleBasedExtractor struct{}
+
+// NewRuleBasedExtractor creates a new RuleBasedExtractor.
+func NewRuleBasedExtractor() *RuleBasedExtractor {
+	return &RuleBasedExtractor{}
+}
+
+// segment is one bullet, heading or sentence of the input. Each clause becomes a task and
+// nested bullets become subtasks of the last one.
+type segment struct {
+	clauses  []string
+	subtasks []string
+	bullet   bool
+	indent   int
+	heading  bool // a "Groceries:" line still collecting the bullets below it
+}
+
+// ExtractTasks extracts tasks from text using fixed rules.
+func (e *RuleBasedExtractor) ExtractTasks(ctx context.Context, text string, opts ExtractOptions) ([]Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	now := opts.now()
+	dates := &dateparse.Parser{WeekStart: opts.WeekStart}
+	tags := tagMatchers(opts.Tags)
+	members := memberMatchers(opts.Members)
+	tasks := []Task{}
+	for _, seg := range segmentText(text) {
+		var shared *time.Time
+		for i, clause := range seg.clauses {
+			after := thenPrefix.MatchString(clause)
+			task, ok := parseClause(clause, now, dates, tags, members, opts.defaultPriority())
+			if !ok {
+				continue
+			}
+			if after && len(tasks) > 0 {
+				task.DependsOn = []int{len(tasks) - 1}
+			}
+			// "Tomorrow buy milk, call mom, and book the dentist" dates the whole list
+			if i == 0 && !task.DueDate.IsZero() {
+				shared = &task.DueDate
+			} else if task.DueDate.IsZero() && shared != nil {
+				task.DueDate = *shared
+			}
+			if i == len(seg.clauses)-1 {
+				for _, subtask := range seg.subtasks {
+					if title := cleanTitle(subtask); title != "" {
+						task.Subtasks = append(task.Subtasks, title)
+					}
+				}
+			}
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func parseClause(clause string, now time.Time, dates *dateparse.Parser, tags []tagMatcher, members []memberMatcher, priority string) (Task, bool) {
+	task := Task{Priority: priority, Subtasks: []string{}, Tags: []string{}, DependsOn: []int{}}
+	clause = thenPrefix.ReplaceAllString(clause, "")
+	rest := clause
+
+	for _, member := range members {
+		if remaining, ok := member.match(rest); ok {
+			task.Assignee = member.name
+			rest = remaining
+			break
+		}
+	}
+
+	for _, tag := range tags {
+		if remaining, ok := tag.match(rest); ok {
+			task.Tags = append(task.Tags, tag.name)
+			rest = remaining
+		}
+	}
+
+	if loc := lowPriority.FindStringIndex(rest); loc != nil {
+		task.Priority = "low"
+		rest = trimDangling(rest[:loc[0]]) + " " + rest[loc[1]:]
+	} else if loc := highPriority.FindStringIndex(rest); loc != nil {
+		task.Priority = "high"
+		rest = trimDangling(rest[:loc[0]]) + " " + rest[loc[1]:]
+	}
+
+	// "Every Monday" repeats rather than naming one Monday, so it is taken out before the dates
+	if rule, remaining, ok := recurrence.Extract(rest); ok {
+		task.Recurrence = rule.String()
+		rest = remaining
+	}
+	if due, remaining, ok := dates.Extract(rest, now); ok {
+		task.DueDate = due
+		rest = remaining
+	}
+
+	task.Title = cleanTitle(rest)
+	if task.Title == "" || isChatter(task.Title) {
+		return Task{}, false
+	}
+	task.Description = capitalize(strings.TrimSpace(clause))
+	if !strings.ContainsAny(task.Description[len(task.Description)-1:], ".!?") {
+		task.Description += "."
+	}
+	return task, true
+}
+
+// tagMatcher finds one of the user's tags in a clause
+type tagMatcher struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// tagMatchers builds a matcher for each tag name. A tag is mentioned by its name as whole words,
+// ignoring case, or as a hashtag.
+func tagMatchers(names []string) []tagMatcher {
+	var matchers []tagMatcher
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		pattern := regexp.MustCompile(`(?i)(?:^|[^\pL\pN_#])(#?)(` + regexp.QuoteMeta(strings.TrimSpace(name)) + `)(?:$|[^\pL\pN_])`)
+		matchers = append(matchers, tagMatcher{name: name, pattern: pattern})
+	}
+	return matchers
+}
+
+// match reports whether text mentions the tag. A hashtag is cut from the text, so "#errands" does
+// not end up in the title; a tag named in words stays part of it.
+func (m tagMatcher) match(text string) (string, bool) {
+	loc := m.pattern.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return text, false
+	}
+	if loc[3] > loc[2] {
+		text = text[:loc[2]] + text[loc[5]:]
+	}
+	return text, true
+}
+
+// memberMatcher finds a list member a clause hands its task to
+type memberMatcher struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// memberMatchers builds a matcher for each member name. A member is named by their full name or,
+// when no other member shares it, their first name, ignoring case: "ask Priya to ...", "Priya
+// should ..." or "@priya".
+func memberMatchers(names []string) []memberMatcher {
+	firstNames := make(map[string]int)
+	for _, name := range names {
+		if fields := strings.Fields(strings.ToLower(name)); len(fields) > 0 {
+			firstNames[fields[0]]++
+		}
+	}
+	var matchers []memberMatcher
+	for _, name := range names {
+		fields := strings.Fields(name)
+		if len(fields) == 0 {
+			continue
+		}
+		who := regexp.QuoteMeta(strings.Join(fields, " "))
+		if len(fields) > 1 && firstNames[strings.ToLower(fields[0])] == 1 {
+			who += "|" + regexp.QuoteMeta(fields[0])
+		}
+		who = `(?:` + who + `)`
+		pattern := regexp.MustCompile(`(?i)\b(?:ask|tell|get|have|remind)\s+` + who + `\s+to\s+` +
+			`|^\s*` + who + `,?\s+(?:should|will|needs\s+to|has\s+to|must|can)\s+` +
+			`|(?:^|\s)@` + who + `\b`)
+		matchers = append(matchers, memberMatcher{name: name, pattern: pattern})
+	}
+	return matchers
+}
+
+// match reports whether text hands the task to the member, and cuts the phrase naming them from
+// the text, so "Ask Priya to send the slides" leaves "send the slides"
+func (m memberMatcher) match(text string) (string, bool) {
+	loc := m.pattern.FindStringIndex(text)
+	if loc == nil {
+		return text, false
+	}
+	return text[:loc[0]] + " " + text[loc[1]:], true
+}
+
+// isChatter reports whether title is only pleasantries such as "Ok, thanks"
+func isChatter(title string) bool {
+	for _, part := range strings.Split(strings.ToLower(title), ",") {
+		if !chatter[strings.TrimSpace(part)] {
+			return false
+		}
+	}
+	return true
+}
+
+// segmentText splits text into bullets, headings with their bullets, and sentences
+func segmentText(text string) []segment {
+	var segments []segment
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			for _, sentence := range splitSentences(strings.Join(paragraph, " ")) {
+				segments = append(segments, segment{clauses: splitClauses(sentence)})
+			}
+			paragraph = nil
+		}
+	}
+	closeHeading := func() {
+		if n := len(segments); n > 0 {
+			segments[n-1].heading = false
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			flush()
+			closeHeading()
+			continue
+		}
+
+		if m := bulletPrefix.FindStringSubmatch(line); m != nil {
+			flush()
+			content := strings.TrimSpace(line[len(m[0]):])
+			indent := len(m[1])
+			if n := len(segments); n > 0 {
+				if last := &segments[n-1]; last.heading || (last.bullet && indent > last.indent) {
+					last.subtasks = append(last.subtasks, content)
+					continue
+				}
+			}
+			segments = append(segments, segment{clauses: []string{content}, bullet: true, indent: indent})
+			continue
+		}
+
+		if strings.HasSuffix(trimmed, ":") {
+			flush()
+			segments = append(segments, segment{clauses: []string{strings.TrimSuffix(trimmed, ":")}, heading: true})
+			continue
+		}
+
+		closeHeading()
+		paragraph = append(paragraph, trimmed)
+	}
+	flush()
+	return segments
+}
+
+// splitSentences splits prose on sentence-ending punctuation, ignoring common abbreviations
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if !strings.ContainsRune(".!?", rune(text[i])) {
+			continue
+		}
+		end := i + 1
+		for end < len(text) && strings.ContainsRune(".!?", rune(text[end])) {
+			end++
+		}
+		if end < len(text) && text[end] != ' ' {
+			i = end - 1
+			continue
+		}
+		if text[i] == '.' && end == i+1 {
+			words := strings.Fields(text[start:i])
+			if len(words) > 0 && abbreviations[strings.ToLower(words[len(words)-1])] {
+				continue
+			}
+		}
+		if sentence := strings.TrimSpace(text[start:end]); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = end
+		i = end - 1
+	}
+	if sentence := strings.TrimSpace(text[start:]); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+// splitClauses splits a sentence into the separate tasks it lists: "A; B", "A, then B" and
+// serial lists like "A, B, and C". A clause that followed "then" keeps it as a prefix, to mark
+// that it comes after the clause before it.
+func splitClauses(sentence string) []string {
+	var clauses []string
+	breaks := clauseBreak.FindAllStringIndex(sentence, -1)
+	for i, part := range clauseBreak.Split(sentence, -1) {
+		if i > 0 && thenBreak.MatchString(sentence[breaks[i-1][0]:breaks[i-1][1]]) && strings.TrimSpace(part) != "" {
+			part = "then " + part
+		}
+		items := serialAnd.Split(part, -1)
+		if len(items) > 1 {
+			// "A, B, and C": the items before the final "and" are comma separated
+			head := strings.Split(strings.Join(items[:len(items)-1], ", "), ", ")
+			items = append(head, items[len(items)-1])
+		}
+		for _, item := range items {
+			if item = strings.TrimSpace(item); item != "" {
+				clauses = append(clauses, item)
+			}
+		}
+	}
+	return clauses
+}
+
+// cleanTitle strips lead-in filler and stray punctuation and capitalises what is left
+func cleanTitle(text string) string {
+	title := strings.Trim(strings.Join(strings.Fields(text), " "), " ,.;:!?-–—")
+	for changed := true; changed; {
+		changed = false
+		lower := strings.ToLower(title)
+		for _, prefix := range fillerPrefixes {
+			if lower == prefix {
+				return ""
+			}
+			if strings.HasPrefix(lower, prefix+" ") {
+				title = strings.TrimLeft(title[len(prefix):], " ,")
+				changed = true
+				break
+			}
+		}
+	}
+	return capitalize(strings.Trim(title, " ,.;:!?-–—"))
+}
+
+// trimDangling drops trailing words such as "it's" that only introduced a removed phrase
+func trimDangling(text string) string {
+	words := strings.Fields(strings.TrimRight(text, " ,-–—"))
+	for len(words) > 0 && danglingWords[strings.ToLower(strings.Trim(words[len(words)-1], ","))] {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+func capitalize(text string) string {
+	r, size := utf8.DecodeRuneInString(text)
+	if r == utf8.RuneError {
+		return text
+	}
+	return string(unicode.ToUpper(r)) + text[size:]
+}
@@ -0,0 +1,205 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Wednesday, 19 November 2025, 10:00
+var rulesOptions = ExtractOptions{Now: time.Date(2025, time.November, 19, 10, 0, 0, 0, time.UTC)}
+
+func TestRuleBasedExtractor_ExtractTasks(t *testing.T) {
+	extractor := NewRuleBasedExtractor()
+	ctx := context.Background()
+
+	t.Run("should split sentences into tasks", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(ctx, "I need to buy milk. Call mom! Ok, thanks.", rulesOptions)
+		assert.NoError(t, err)
+		if assert.Len(t, tasks, 2) {
+			assert.Equal(t, "Buy milk", tasks[0].Title)
+			assert.Equal(t, "I need to buy milk.", tasks[0].Description)
+			assert.Equal(t, "medium", tasks[0].Priority)
+			assert.True(t, tasks[0].DueDate.IsZero())
+			assert.Equal(t, "Call mom", tasks[1].Title)
+		}
+	})
+
+	t.Run("should split a serial list and share its date", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(ctx, "Tomorrow I need to buy milk, call Dr. Smith, and book the car service on Friday", rulesOptions)
+		assert.NoError(t, err)
+		if assert.Len(t, tasks, 3) {
+			tomorrow := time.Date(2025, time.November, 20, 0, 0, 0, 0, time.UTC)
+			assert.Equal(t, "Buy milk", tasks[0].Title)
+			assert.Equal(t, tomorrow, tasks[0].DueDate)
+			assert.Equal(t, "Call Dr. Smith", tasks[1].Title)
+			assert.Equal(t, tomorrow, tasks[1].DueDate)
+			assert.Equal(t, "Book the car service", tasks[2].Title)
+			assert.Equal(t, time.Date(2025, time.November, 21, 0, 0, 0, 0, time.UTC), tasks[2].DueDate)
+		}
+	})
+
+	t.Run("should detect priority keywords", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(ctx, "Call the bank, it's urgent. Fix the login bug ASAP. Clean the garage, no rush. Water plants", rulesOptions)
+		assert.NoError(t, err)
+		if assert.Len(t, tasks, 4) {
+			assert.Equal(t, "Call the bank", tasks[0].Title)
+			assert.Equal(t, "high", tasks[0].Priority)
+			assert.Equal(t, "Fix the login bug", tasks[1].Title)
+			assert.Equal(t, "high", tasks[1].Priority)
+			assert.Equal(t, "Clean the garage", tasks[2].Title)
+			assert.Equal(t, "low", tasks[2].Priority)
+			assert.Equal(t, "medium", tasks[3].Priority)
+		}
+	})
+
+	t.Run("should turn bullets into tasks and nested bullets into subtasks", func(t *testing.T) {
+		text := "- Plan the party next Saturday\n  - book a venue\n  - send invites\n- [ ] Pay rent\n\nGroceries:\n* milk\n* eggs\n"
+		tasks, err := extractor.ExtractTasks(ctx, text, rulesOptions)
+		assert.NoError(t, err)
+		if assert.Len(t, tasks, 3) {
+			assert.Equal(t, "Plan the party", tasks[0].Title)
+			assert.Equal(t, time.Date(2025, time.November, 29, 0, 0, 0, 0, time.UTC), tasks[0].DueDate)
+			assert.Equal(t, []string{"Book a venue", "Send invites"}, tasks[0].Subtasks)
+			assert.Equal(t, "Pay rent", tasks[1].Title)
+			assert.Empty(t, tasks[1].Subtasks)
+			assert.Equal(t, "Groceries", tasks[2].Title)
+			assert.Equal(t, []string{"Milk", "Eggs"}, tasks[2].Subtasks)
+		}
+	})
+
+	t.Run("should use the user's default priority and week start", func(t *testing.T) {
+		opts := rulesOptions
+		opts.DefaultPriority = "low"
+		opts.WeekStart = time.Sunday
+		tasks, err := extractor.ExtractTasks(ctx, "Plan the offsite next week", opts)
+		assert.NoError(t, err)
+		if assert.Len(t, tasks, 1) {
+			assert.Equal(t, "low", tasks[0].Priority)
+			assert.Equal(t, time.Date(2025, time.November, 23, 0, 0, 0, 0, time.UTC), tasks[0].DueDate)
+		}
+	})
+
+	t.Run("should turn repetition phrases into recurrence rules", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(ctx, "Pay rent on the 1st of every month. Water the plants every other day. Dentist on Friday", rulesOptions)
+		assert.NoError(t, err)
+		if assert.Len(t, tasks, 3) {
+			assert.Equal(t, "Pay rent", tasks[0].Title)
+			assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=1", tasks[0].Recurrence)
+			assert.True(t, tasks[0].DueDate.IsZero())
+			assert.Equal(t, "Water the plants", tasks[1].Title)
+			assert.Equal(t, "FREQ=DAILY;INTERVAL=2", tasks[1].Recurrence)
+			assert.Equal(t, "Dentist", tasks[2].Title)
+			assert.Empty(t, tasks[2].Recurrence)
+			assert.Equal(t, time.Date(2025, time.November, 21, 0, 0, 0, 0, time.UTC), tasks[2].DueDate)
+		}
+	})
+
+	t.Run("should suggest the user's existing tags", func(t *testing.T) {
+		opts := rulesOptions
+		opts.Tags = []string{"Errands", "work", "home office"}
+		tasks, err := extractor.ExtractTasks(ctx, "Buy stamps #errands. Set up the home office desk. Call the plumber", opts)
+		assert.NoError(t, err)
+		if assert.Len(t, tasks, 3) {
+			assert.Equal(t, "Buy stamps", tasks[0].Title)
+			assert.Equal(t, []string{"Errands"}, tasks[0].Tags)
+			assert.Equal(t, "Set up the home office desk", tasks[1].Title)
+			assert.Equal(t, []string{"home office"}, tasks[1].Tags)
+			assert.Empty(t, tasks[2].Tags)
+		}
+	})
+
+	t.Run("should assign tasks handed to list members", func(t *testing.T) {
+		opts := rulesOptions
+		opts.Members = []string{"Priya Sharma", "Sam Lee", "Sam Park"}
+		tasks, err := extractor.ExtractTasks(ctx, "I need to ask Priya to send the slides by Friday. Sam Park should book the room. @priya review the budget. Ask Sam to call the venue. Ask Jo to bring snacks", opts)
+		assert.NoError(t, err)
+		if assert.Len(t, tasks, 5) {
+			assert.Equal(t, "Send the slides", tasks[0].Title)
+			assert.Equal(t, "Priya Sharma", tasks[0].Assignee)
+			assert.Equal(t, time.Date(2025, time.November, 21, 0, 0, 0, 0, time.UTC), tasks[0].DueDate)
+			assert.Equal(t, "I need to ask Priya to send the slides by Friday.", tasks[0].Description)
+			assert.Equal(t, "Book the room", tasks[1].Title)
+			assert.Equal(t, "Sam Park", tasks[1].Assignee)
+			assert.Equal(t, "Review the budget", tasks[2].Title)
+			assert.Equal(t, "Priya Sharma", tasks[2].Assignee)
+			// Two members are called Sam, and nobody is called Jo
+			assert.Equal(t, "Ask Sam to call the venue", tasks[3].Title)
+			assert.Empty(t, tasks[3].Assignee)
+			assert.Empty(t, tasks[4].Assignee)
+		}
+	})
+
+	t.Run("should make tasks introduced by then depend on the task before", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(ctx, "Buy paint, then paint the fence. Call mom. After that, water the plants; mow the lawn", rulesOptions)
+		assert.NoError(t, err)
+		if assert.Len(t, tasks, 5) {
+			assert.Empty(t, tasks[0].DependsOn)
+			assert.Equal(t, "Paint the fence", tasks[1].Title)
+			assert.Equal(t, "Paint the fence.", tasks[1].Description)
+			assert.Equal(t, []int{0}, tasks[1].DependsOn)
+			assert.Empty(t, tasks[2].DependsOn)
+			assert.Equal(t, "Water the plants", tasks[3].Title)
+			assert.Equal(t, []int{2}, tasks[3].DependsOn)
+			assert.Empty(t, tasks[4].DependsOn)
+		}
+	})
+
+	t.Run("should return an empty list for empty text", func(t *testing.T) {
+		tasks, err := extractor.ExtractTasks(ctx, "  \n ", rulesOptions)
+		assert.NoError(t, err)
+		assert.NotNil(t, tasks)
+		assert.Empty(t, tasks)
+	})
+
+	t.Run("should give the same answer for the same input", func(t *testing.T) {
+		text := "Submit the report by Friday at 3pm. Call the plumber, it's urgent"
+		first, _ := extractor.ExtractTasks(ctx, text, rulesOptions)
+		second, _ := extractor.ExtractTasks(ctx, text, rulesOptions)
+		assert.Equal(t, first, second)
+	})
+}
+
+type stubExtractor struct {
+	tasks []Task
+	err   error
+	calls int
+}
+
+func (s *stubExtractor) ExtractTasks(ctx context.Context, text string, opts ExtractOptions) ([]Task, error) {
+	s.calls++
+	return s.tasks, s.err
+}
+
+func TestFallbackExtractor_ExtractTasks(t *testing.T) {
+	t.Run("should use the primary extractor when it succeeds", func(t *testing.T) {
+		primary := &stubExtractor{tasks: []Task{{Title: "From model"}}}
+		fallback := &stubExtractor{}
+		tasks, err := NewFallbackExtractor(primary, fallback).ExtractTasks(context.Background(), "text", ExtractOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "From model", tasks[0].Title)
+		assert.Equal(t, 0, fallback.calls)
+	})
+
+	t.Run("should fall back when the primary extractor errors", func(t *testing.T) {
+		primary := &stubExtractor{err: errors.New("openai api error: status 503")}
+		tasks, err := NewFallbackExtractor(primary, NewRuleBasedExtractor()).ExtractTasks(context.Background(), "Buy milk tomorrow", rulesOptions)
+		assert.NoError(t, err)
+		if assert.Len(t, tasks, 1) {
+			assert.Equal(t, "Buy milk", tasks[0].Title)
+		}
+	})
+
+	t.Run("should not fall back once the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		primary := &stubExtractor{err: context.Canceled}
+		fallback := &stubExtractor{}
+		_, err := NewFallbackExtractor(primary, fallback).ExtractTasks(ctx, "text", ExtractOptions{})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, fallback.calls)
+	})
+}
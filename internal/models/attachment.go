@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attachment is a file attached to a task, such as a photo or document. The content lives in blob
+// storage under StorageKey; the record holds what clients need to list and download it.
+type Attachment struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	TaskID     uuid.UUID `json:"task_id" gorm:"type:uuid;not null;index"`
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;not null"` // who uploaded it
+	StorageKey string    `json:"-" gorm:"not null"`
+	Filename   string    `json:"filename" gorm:"not null"`
+	MimeType   string    `json:"mime_type" gorm:"not null"` // sniffed from the content, not taken from the client
+	SizeBytes  int64     `json:"size_bytes" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BeforeCreate assigns a new UUID when the caller has not set one
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
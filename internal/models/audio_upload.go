@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AudioUploadStatus tracks an audio upload through the transcription pipeline
+type AudioUploadStatus string
+
+const (
+	AudioStatusUploaded     AudioUploadStatus = "uploaded"
+	AudioStatusTranscribing AudioUploadStatus = "transcribing"
+	AudioStatusExtracting   AudioUploadStatus = "extracting"
+	AudioStatusDone         AudioUploadStatus = "done"
+	AudioStatusFailed       AudioUploadStatus = "failed"
+)
+
+type AudioUpload struct {
+	ID              uuid.UUID         `json:"id" gorm:"type:uuid;primary_key"`
+	UserID          uuid.UUID         `json:"user_id" gorm:"type:uuid;not null;index"`
+	StorageKey      string            `json:"storage_key"`
+	Filename        string            `json:"filename"`
+	MimeType        string            `json:"mime_type"`
+	SizeBytes       int64             `json:"size_bytes"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	Language        string            `json:"language"`
+	Transcript      string            `json:"transcript"`
+	Status          AudioUploadStatus `json:"status" gorm:"not null;default:'uploaded'"`
+	ErrorMessage    string            `json:"error_message,omitempty"`
+	TaskIDs         []uuid.UUID       `json:"task_ids" gorm:"serializer:json"`
+	Tasks           []Task            `json:"tasks" gorm:"-"`
+	AudioURL        string            `json:"audio_url,omitempty" gorm:"-"`
+	CreatedAt       time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate assigns a new UUID when the caller has not set one
+func (a *AudioUpload) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Comment is a message in the discussion thread of a task. Bodies are markdown, stored as written
+// and rendered by clients.
+type Comment struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	TaskID      uuid.UUID  `json:"task_id" gorm:"type:uuid;not null;index"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"` // the author
+	AuthorEmail string     `json:"author_email" gorm:"-"`             // filled in when reading comments
+	Body        string     `json:"body" gorm:"not null"`
+	EditedAt    *time.Time `json:"edited_at"` // when the body was last changed; nil if it never was
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate assigns a new UUID when the caller has not set one
+func (c *Comment) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
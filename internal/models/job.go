@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobStatus tracks a background job through the queue
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusDead      JobStatus = "dead" // gave up after exhausting its attempts or a permanent error
+)
+
+// Job is a unit of background work such as transcribing an upload or extracting tasks from text
+type Job struct {
+	ID          uuid.UUID       `json:"id" gorm:"type:uuid;primary_key"`
+	UserID      uuid.UUID       `json:"user_id" gorm:"type:uuid;not null;index"`
+	Type        string          `json:"type" gorm:"not null"`
+	Payload     json.RawMessage `json:"-" gorm:"serializer:json"`
+	Status      JobStatus       `json:"status" gorm:"not null;default:'queued';index"`
+	Attempts    int             `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int             `json:"max_attempts" gorm:"not null"`
+	RunAt       time.Time       `json:"run_at" gorm:"not null"`
+	LockedAt    *time.Time      `json:"-"`
+	LockedBy    string          `json:"-"`
+	LastError   string          `json:"last_error,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty" gorm:"serializer:json"`
+	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+// BeforeCreate assigns a new UUID when the caller has not set one
+func (j *Job) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	return nil
+}
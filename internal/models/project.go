@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Project groups a user's tasks into a list such as "Home" or "Work". Tasks without a project are
+// in the user's inbox. A project can be shared with other users, who become its members; its
+// tasks stay owned by the user who created it.
+type Project struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"` // the user who created the project
+	Name      string    `json:"name" gorm:"not null"`
+	Color     string    `json:"color,omitempty"`                        // "#rrggbb"; empty for the client's default
+	Archived  bool      `json:"archived" gorm:"not null;default:false"` // hides the project's tasks from default views
+	Position  int       `json:"position" gorm:"not null;default:0"`     // order among the user's projects
+	LeftSeq   int64     `json:"-" gorm:"not null;default:0"`            // the owner's change sequence number when a task last left the project; older sync tokens of members miss that
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Counts of the project's top-level tasks, filled in by the project service
+	OpenCount    int `json:"open_count" gorm:"-"`
+	OverdueCount int `json:"overdue_count" gorm:"-"`
+
+	Role string `json:"role,omitempty" gorm:"-"` // the requesting user's role in the project
+}
+
+// BeforeCreate assigns a new UUID when the caller has not set one
+func (p *Project) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// ProjectTaskCounts are the numbers of open and overdue top-level tasks in a project
+type ProjectTaskCounts struct {
+	ProjectID    uuid.UUID
+	OpenCount    int
+	OverdueCount int
+}
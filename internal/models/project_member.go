@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Roles a user can have in a project. Viewers see the project's tasks, editors can also change
+// them, and owners can also change the project itself and who it is shared with.
+const (
+	ProjectRoleViewer = "viewer"
+	ProjectRoleEditor = "editor"
+	ProjectRoleOwner  = "owner"
+)
+
+// ValidProjectRole reports whether role is one of the project roles
+func ValidProjectRole(role string) bool {
+	switch role {
+	case ProjectRoleViewer, ProjectRoleEditor, ProjectRoleOwner:
+		return true
+	}
+	return false
+}
+
+// ProjectMember gives a user a role in a project. The user who created a project is always one
+// of its owners.
+type ProjectMember struct {
+	ProjectID uuid.UUID `json:"project_id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	Email     string    `json:"email" gorm:"-"` // the member's account email, filled in when listing members
+	Role      string    `json:"role" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Statuses of a project invitation
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+// ProjectInvitation asks a registered user to join a project with a role. The user becomes a
+// member once they accept it.
+type ProjectInvitation struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	ProjectID uuid.UUID `json:"project_id" gorm:"type:uuid;not null;index"`
+	InviterID uuid.UUID `json:"inviter_id" gorm:"type:uuid;not null"`
+	InviteeID uuid.UUID `json:"invitee_id" gorm:"type:uuid;not null;index"`
+	Email     string    `json:"email" gorm:"not null"` // the invitee's account email
+	Role      string    `json:"role" gorm:"not null"`
+	Status    string    `json:"status" gorm:"not null;default:'pending'"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	ProjectName string `json:"project_name,omitempty" gorm:"-"` // filled in when listing the invitee's invitations
+}
+
+// BeforeCreate assigns a new UUID when the caller has not set one
+func (i *ProjectInvitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
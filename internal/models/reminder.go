@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReminderStatus tracks a reminder from creation to delivery
+type ReminderStatus string
+
+const (
+	ReminderPending   ReminderStatus = "pending"
+	ReminderSending   ReminderStatus = "sending" // claimed by the scheduler, being delivered
+	ReminderSent      ReminderStatus = "sent"
+	ReminderDismissed ReminderStatus = "dismissed"
+	ReminderFailed    ReminderStatus = "failed" // gave up after exhausting its delivery attempts
+)
+
+// Reminder is a notification about a task, due either at a fixed time (RemindAt) or a number of
+// minutes before the task's due date (MinutesBefore)
+type Reminder struct {
+	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primary_key"`
+	TaskID        uuid.UUID      `json:"task_id" gorm:"type:uuid;not null;index"`
+	UserID        uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	RemindAt      *time.Time     `json:"remind_at,omitempty"`
+	MinutesBefore *int           `json:"minutes_before,omitempty"`
+	SnoozedUntil  *time.Time     `json:"snoozed_until,omitempty"`
+	FireAt        *time.Time     `json:"fire_at"` // when the reminder goes off; nil while a relative reminder's task has no due date
+	Status        ReminderStatus `json:"status" gorm:"not null;default:'pending'"`
+	Attempts      int            `json:"attempts" gorm:"not null;default:0"`
+	LastError     string         `json:"last_error,omitempty"`
+	LockedAt      *time.Time     `json:"-"`
+	LockedBy      string         `json:"-"`
+	SentAt        *time.Time     `json:"sent_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate assigns a new UUID when the caller has not set one
+func (r *Reminder) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Schedule sets FireAt from the snooze, the fixed time or the task's due date, in that order
+func (r *Reminder) Schedule(dueDate *time.Time) {
+	r.FireAt = r.ScheduledAt(dueDate)
+}
+
+// ScheduledAt is the time the reminder is set to go off for a task due at dueDate, before any
+// retries of a failed delivery; nil while a relative reminder's task has no due date
+func (r *Reminder) ScheduledAt(dueDate *time.Time) *time.Time {
+	var at time.Time
+	switch {
+	case r.SnoozedUntil != nil:
+		at = *r.SnoozedUntil
+	case r.RemindAt != nil:
+		at = *r.RemindAt
+	case r.MinutesBefore != nil && dueDate != nil:
+		at = dueDate.Add(-time.Duration(*r.MinutesBefore) * time.Minute)
+	default:
+		return nil
+	}
+	at = at.UTC()
+	return &at
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskChangeCounter is the last change sequence number issued for a user's tasks. Writers bump
+// it inside their transaction, so sequence numbers are handed out in commit order per user.
+type TaskChangeCounter struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primary_key"`
+	Seq       int64     `gorm:"not null"`
+	PurgedSeq int64     `gorm:"not null;default:0"` // highest sequence number among purged tasks; older sync tokens miss their deletion
+}
+
+// TaskTombstone reports a task deleted since the client's last sync
+type TaskTombstone struct {
+	ID        uuid.UUID  `json:"id"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+	DeletedAt time.Time  `json:"deleted_at"`
+}
+
+// SyncChanges is one page of the changes to a user's tasks after a sync token. Tasks are flat,
+// with subtasks linked through parent_id.
+type SyncChanges struct {
+	Created []Task          `json:"created"`
+	Updated []Task          `json:"updated"`
+	Deleted []TaskTombstone `json:"deleted"`
+	Token   string          `json:"token"`    // pass as since to fetch the changes after this page
+	HasMore bool            `json:"has_more"` // more changes follow; fetch again with Token
+}
+
+// Sync mutation operations
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+)
+
+// Sync mutation outcomes
+const (
+	SyncApplied  = "applied"
+	SyncConflict = "conflict"  // the task changed on the server since BaseVersion; Task holds the server copy
+	SyncNotFound = "not_found" // the task does not exist, or was deleted on the server
+	SyncInvalid  = "invalid"
+	SyncFailed   = "error"
+)
+
+// SyncMutationRequest is one change made on a client while offline
+type SyncMutationRequest struct {
+	Op          string          `json:"op" binding:"required,oneof=create update delete"`
+	ID          uuid.UUID       `json:"id" binding:"required"` // clients generate the ID of tasks they create
+	BaseVersion int             `json:"base_version"`          // task version the change was made on; 0 to apply unconditionally
+	ParentID    *uuid.UUID      `json:"parent_id"`             // creates the task as the last subtask of this one
+	Fields      json.RawMessage `json:"fields"`                // merge patch of the task fields, for create and update
+}
+
+// SyncPushRequest is a batch of offline changes, applied in order
+type SyncPushRequest struct {
+	Mutations []SyncMutationRequest `json:"mutations" binding:"required,dive"`
+}
+
+// TaskMutation is a validated sync mutation
+type TaskMutation struct {
+	Op          string
+	ID          uuid.UUID
+	BaseVersion int
+	ParentID    *uuid.UUID
+	Patch       TaskPatch
+}
+
+// SyncResult is the outcome of one pushed mutation
+type SyncResult struct {
+	ID     uuid.UUID `json:"id"`
+	Op     string    `json:"op"`
+	Status string    `json:"status"`
+	Error  string    `json:"error,omitempty"`
+	Task   *Task     `json:"task,omitempty"` // the task after the change, or the server copy on a conflict
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tag is a label a user puts on tasks, such as "work" or "errands". Names are unique per user,
+// ignoring case.
+type Tag struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name      string    `json:"name" gorm:"not null"`
+	Color     string    `json:"color,omitempty"` // "#rrggbb"; empty for the client's default
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate assigns a new UUID when the caller has not set one
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// TaskTag puts a tag on a task
+type TaskTag struct {
+	TaskID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TagID     uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
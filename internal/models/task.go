@@ -4,10 +4,11 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Task struct {
-	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
 	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
 	Title       string     `json:"title" gorm:"not null"`
 	Description string     `json:"description"`
@@ -15,8 +16,82 @@ type Task struct {
 	Priority    string     `json:"priority" gorm:"default:'medium'"`
 	RawText     string     `json:"raw_text"`
 	Completed   bool       `json:"completed" gorm:"default:false"`
+	CompletedAt *time.Time `json:"completed_at"` // when the task was last completed; nil while open
+	ParentID    *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid;index"`
+	ProjectID   *uuid.UUID `json:"project_id" gorm:"type:uuid;index"` // nil for the inbox; subtasks are in their parent's project
+	AssigneeID  *uuid.UUID `json:"assignee_id" gorm:"type:uuid;index"` // a member of the task's project, or the owner for inbox tasks; nil if unassigned
+	Position    int        `json:"position" gorm:"not null;default:0"` // order among the parent's subtasks
+	Version     int        `json:"version" gorm:"not null;default:1"` // bumped on every change; the first part of the ETag
+	Subtasks    []Task     `json:"subtasks,omitempty" gorm:"-"`
+	Tags        []Tag      `json:"tags,omitempty" gorm:"-"` // loaded with the task, sorted by name
+	BlockedBy   []uuid.UUID `json:"blocked_by,omitempty" gorm:"-"` // tasks that have to be done first, loaded with the task
+	Blocked     bool       `json:"blocked" gorm:"-"` // whether any of BlockedBy is still open
+	Attachments []Attachment `json:"attachments,omitempty" gorm:"-"` // files attached to the task, loaded with it, oldest first
 	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Sync bookkeeping: deleted tasks stay behind as tombstones, and ChangeSeq and CreatedSeq are
+	// the positions in the owner's change sequence of the last change and of the creation
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+	ChangeSeq  int64          `json:"-" gorm:"not null;default:0"`
+	CreatedSeq int64          `json:"-" gorm:"not null;default:0"`
+
+	// Repeating tasks: Recurrence is an RRULE such as "FREQ=WEEKLY;BYDAY=MO". Completing an
+	// occurrence creates the next one, which shares the SeriesID of the first occurrence (nil on
+	// the first occurrence itself) and has the next RecurrenceIndex, counting from 0.
+	Recurrence      string     `json:"recurrence,omitempty"`
+	SeriesID        *uuid.UUID `json:"series_id,omitempty" gorm:"type:uuid;uniqueIndex:idx_tasks_series_occurrence"`
+	RecurrenceIndex int        `json:"recurrence_index" gorm:"not null;default:0;uniqueIndex:idx_tasks_series_occurrence"`
+
+	// SourceID is the job or audio upload whose extraction created the task, so that running it
+	// again returns the tasks it made instead of creating them twice; nil for other tasks
+	SourceID *uuid.UUID `json:"-" gorm:"type:uuid;index"`
+}
+
+// TaskSearchResult is a task matched by a full-text search
+type TaskSearchResult struct {
+	Task
+	Rank    float64 `json:"rank"`    // relevance; higher is better
+	Snippet string  `json:"snippet"` // HTML excerpt of the matched text: escaped, with hits wrapped in <mark> tags
+}
+
+// TrashedTask is a task in the trash
+type TrashedTask struct {
+	Task
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// BeforeCreate assigns a new UUID when the caller has not set one
+func (t *Task) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if t.Version == 0 {
+		t.Version = 1
+	}
+	return nil
+}
+
+// BeforeSave stores due dates in UTC; due_date has no time zone, and filters compare it in SQL
+func (t *Task) BeforeSave(tx *gorm.DB) error {
+	if t.DueDate != nil {
+		utc := t.DueDate.UTC()
+		t.DueDate = &utc
+	}
+	return nil
+}
+
+// PriorityRank orders priorities from low (1) to high (3); unknown priorities rank 0
+func PriorityRank(priority string) int {
+	switch priority {
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	}
+	return 0
 }
 
 type CreateTaskRequest struct {
@@ -34,6 +109,57 @@ type UpdateTaskRequest struct {
 	Completed   *bool      `json:"completed"`
 }
 
+// TaskPatch is a partial task update; nil fields are left unchanged. An empty Priority resets the
+// task to the user's default priority, and ClearDueDate removes the due date.
+type TaskPatch struct {
+	Title         *string
+	Description   *string
+	DueDate       *time.Time
+	ClearDueDate  bool
+	Priority      *string
+	RawText       *string
+	Completed     *bool   // applied by PatchTask, which logs the change, rather than Apply
+	Recurrence    *string // an empty rule makes the task a one-off
+	ProjectID     *uuid.UUID
+	ClearProject  bool // moves the task back to the inbox
+	AssigneeID    *uuid.UUID
+	ClearAssignee bool // leaves the task unassigned
+}
+
+// Apply copies the patched fields onto task
+func (p TaskPatch) Apply(task *Task) {
+	if p.Title != nil {
+		task.Title = *p.Title
+	}
+	if p.Description != nil {
+		task.Description = *p.Description
+	}
+	if p.ClearDueDate {
+		task.DueDate = nil
+	} else if p.DueDate != nil {
+		task.DueDate = p.DueDate
+	}
+	if p.Priority != nil {
+		task.Priority = *p.Priority
+	}
+	if p.RawText != nil {
+		task.RawText = *p.RawText
+	}
+	if p.Recurrence != nil {
+		task.Recurrence = *p.Recurrence
+	}
+	if p.ClearProject {
+		task.ProjectID = nil
+	} else if p.ProjectID != nil {
+		task.ProjectID = p.ProjectID
+	}
+	if p.ClearAssignee {
+		task.AssigneeID = nil
+	} else if p.AssigneeID != nil {
+		task.AssigneeID = p.AssigneeID
+	}
+}
+
 type ExtractTasksRequest struct {
 	Text string `json:"text" binding:"required"`
 }
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskDependency records that a task cannot be done before its blocker: "after X, do Y" makes X
+// a blocker of Y
+type TaskDependency struct {
+	TaskID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	BlockerID uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
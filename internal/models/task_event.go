@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Task event types
+const (
+	TaskEventCreated        = "created"
+	TaskEventTitleChanged   = "title_changed"
+	TaskEventDueDateChanged = "due_date_changed"
+	TaskEventCompleted      = "completed"
+	TaskEventReopened       = "reopened"
+	TaskEventAssigned       = "assigned" // also recorded when a task is unassigned, with no AssigneeID
+)
+
+// TaskEvent records something that happened to a task: its creation, a change to its title or due
+// date, being completed or reopened, or being reassigned. The events of a task are its activity
+// stream; those about completion and assignment are also its history.
+type TaskEvent struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	TaskID     uuid.UUID  `json:"task_id" gorm:"type:uuid;not null;index"`
+	UserID     uuid.UUID  `json:"-" gorm:"type:uuid;not null"`         // the task's owner
+	ActorID    *uuid.UUID `json:"actor_id,omitempty" gorm:"type:uuid"` // who did it; nil for what the server did on its own, such as creating the next occurrence of a repeating task
+	ActorEmail string     `json:"actor_email,omitempty" gorm:"-"`      // filled in when reading the events
+	Type       string     `json:"type" gorm:"not null"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+
+	// Assigned events: who the task was assigned to before and after
+	AssigneeID         *uuid.UUID `json:"assignee_id,omitempty" gorm:"type:uuid"`
+	PreviousAssigneeID *uuid.UUID `json:"previous_assignee_id,omitempty" gorm:"type:uuid"`
+
+	// Title and due date changes: the previous and new title, or due date in RFC 3339; empty
+	// when there was none
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// BeforeCreate assigns a new UUID when the caller has not set one
+func (e *TaskEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// TaskHistory is a task's completion and assignment log, oldest event first
+type TaskHistory struct {
+	TaskID          uuid.UUID   `json:"task_id"`
+	CompletionCount int         `json:"completion_count"`
+	LastCompletedAt *time.Time  `json:"last_completed_at"`
+	Events          []TaskEvent `json:"events"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Defaults for users who have not changed their settings
+const (
+	DefaultTimezone  = "UTC"
+	DefaultLocale    = "en-US"
+	DefaultWeekStart = "monday"
+	DefaultPriority  = "medium"
+)
+
+// UserSettings holds a user's regional preferences and task defaults
+type UserSettings struct {
+	UserID          uuid.UUID `json:"-" gorm:"type:uuid;primary_key"`
+	Timezone        string    `json:"timezone" gorm:"not null;default:'UTC'"`            // IANA name, e.g. "Europe/Berlin"
+	Locale          string    `json:"locale" gorm:"not null;default:'en-US'"`            // BCP 47 tag
+	WeekStart       string    `json:"week_start" gorm:"not null;default:'monday'"`       // lowercase weekday name
+	DefaultPriority string    `json:"default_priority" gorm:"not null;default:'medium'"` // low|medium|high
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// UpdateUserSettingsRequest is the body of PATCH /auth/me/settings; omitted fields are left unchanged
+type UpdateUserSettingsRequest struct {
+	Timezone        *string `json:"timezone"`
+	Locale          *string `json:"locale"`
+	WeekStart       *string `json:"week_start"`
+	DefaultPriority *string `json:"default_priority" binding:"omitempty,oneof=low medium high"`
+}
+
+// NewUserSettings returns the default settings for a user
+func NewUserSettings(userID uuid.UUID) *UserSettings {
+	return &UserSettings{
+		UserID:          userID,
+		Timezone:        DefaultTimezone,
+		Locale:          DefaultLocale,
+		WeekStart:       DefaultWeekStart,
+		DefaultPriority: DefaultPriority,
+	}
+}
+
+// Location returns the user's time zone, or UTC if it cannot be loaded
+func (s *UserSettings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Now returns the current time in the user's time zone
+func (s *UserSettings) Now() time.Time {
+	return time.Now().In(s.Location())
+}
+
+// WeekStartDay returns the weekday the user's weeks start on
+func (s *UserSettings) WeekStartDay() time.Weekday {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), s.WeekStart) {
+			return day
+		}
+	}
+	return time.Monday
+}
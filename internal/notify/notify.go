@@ -0,0 +1,95 @@
+// Package notify delivers reminders to users through webhooks, email or the log.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"todo-backend/internal/config"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Notification is a reminder about a task, ready to be delivered
+type Notification struct {
+	ReminderID  uuid.UUID  `json:"reminder_id"`
+	TaskID      uuid.UUID  `json:"task_id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Email       string     `json:"email"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	RemindAt    time.Time  `json:"remind_at"`
+	// IdempotencyKey is the same for every attempt to deliver one firing of a reminder, so
+	// receivers can drop the duplicates that at-least-once delivery may send
+	IdempotencyKey string         `json:"idempotency_key"`
+	Location       *time.Location `json:"-"` // the user's time zone, for formatting dates; nil for UTC
+}
+
+// Notifier delivers notifications. An error means the notification was not delivered and may be
+// retried. A notification may also be delivered more than once, when a scheduler stops before
+// recording that it was sent.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// New returns the Notifier selected by cfg.Notifier
+func New(cfg *config.Config) (Notifier, error) {
+	switch cfg.Notifier {
+	case "", "log":
+		return NewLogNotifier(), nil
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("NOTIFIER_WEBHOOK_URL is required for the webhook notifier")
+		}
+		return NewWebhookNotifier(cfg.WebhookURL, cfg.WebhookSecret, nil), nil
+	case "smtp":
+		if cfg.SMTPHost == "" || cfg.SMTPFrom == "" {
+			return nil, fmt.Errorf("SMTP_HOST and SMTP_FROM are required for the smtp notifier")
+		}
+		return NewSMTPNotifier(SMTPOptions{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier: %s", cfg.Notifier)
+	}
+}
+
+// LogNotifier writes notifications to the log instead of delivering them, for development and
+// for deployments without a delivery channel
+type LogNotifier struct{}
+
+// NewLogNotifier creates a new LogNotifier
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Notify logs the notification
+func (n *LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	log.Info().
+		Str("reminder_id", notification.ReminderID.String()).
+		Str("idempotency_key", notification.IdempotencyKey).
+		Str("task_id", notification.TaskID.String()).
+		Str("user_id", notification.UserID.String()).
+		Str("title", notification.Title).
+		Msg("Reminder")
+	return nil
+}
+
+// IdempotencyKey returns the key of the firing of reminderID scheduled for at
+func IdempotencyKey(reminderID uuid.UUID, at time.Time) string {
+	return fmt.Sprintf("%s-%d", reminderID, at.Unix())
+}
+
+// formatTime formats t in loc for people to read
+func formatTime(t time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format("Monday, January 2, 2006 15:04 MST")
+}
@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPOptions configures an SMTPNotifier
+type SMTPOptions struct {
+	Host     string
+	Port     int    // 587 when zero
+	Username string // empty to send without authenticating
+	Password string
+	From     string
+}
+
+// SMTPNotifier delivers notifications by email to the user's address. STARTTLS is used whenever
+// the server offers it.
+type SMTPNotifier struct {
+	opts SMTPOptions
+	now  func() time.Time
+}
+
+// NewSMTPNotifier creates a new SMTPNotifier
+func NewSMTPNotifier(opts SMTPOptions) *SMTPNotifier {
+	if opts.Port == 0 {
+		opts.Port = 587
+	}
+	return &SMTPNotifier{opts: opts, now: time.Now}
+}
+
+// Notify sends the notification as a plain-text email
+func (n *SMTPNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Email == "" {
+		return fmt.Errorf("no email address for user %s", notification.UserID)
+	}
+
+	addr := net.JoinHostPort(n.opts.Host, strconv.Itoa(n.opts.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(time.Minute)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, n.opts.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.opts.Host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if n.opts.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.opts.Username, n.opts.Password, n.opts.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.opts.From); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM failed: %w", err)
+	}
+	if err := client.Rcpt(notification.Email); err != nil {
+		return fmt.Errorf("SMTP RCPT TO failed: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := w.Write(n.message(notification)); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected the email: %w", err)
+	}
+	return client.Quit()
+}
+
+// message builds the email, headers included, with CRLF line endings
+func (n *SMTPNotifier) message(notification Notification) []byte {
+	var body strings.Builder
+	body.WriteString("Reminder: " + oneLine(notification.Title) + "\n")
+	if notification.DueDate != nil {
+		body.WriteString("Due: " + formatTime(*notification.DueDate, notification.Location) + "\n")
+	}
+	if notification.Description != "" && notification.Description != notification.Title {
+		body.WriteString("\n" + notification.Description + "\n")
+	}
+
+	var msg bytes.Buffer
+	header := func(name, value string) {
+		msg.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", n.opts.From)
+	header("To", notification.Email)
+	header("Subject", mime.QEncoding.Encode("utf-8", "Reminder: "+oneLine(notification.Title)))
+	header("Date", n.now().Format(time.RFC1123Z))
+	// Redeliveries share the Message-ID, which lets mail systems drop them as duplicates
+	messageID := notification.IdempotencyKey
+	if messageID == "" {
+		messageID = notification.ReminderID.String()
+	}
+	header("Message-ID", fmt.Sprintf("<%s@%s>", messageID, n.opts.Host))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "8bit")
+	msg.WriteString("\r\n")
+	text := strings.ReplaceAll(body.String(), "\r\n", "\n")
+	msg.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+	return msg.Bytes()
+}
+
+// oneLine keeps user text from breaking out of a header
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
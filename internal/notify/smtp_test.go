@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSMTPServer accepts mail on a local port and records it. It speaks just enough SMTP for
+// net/smtp, and rejects recipients at the rejected domain.
+type fakeSMTPServer struct {
+	listener net.Listener
+	messages chan fakeMessage
+}
+
+type fakeMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener, messages: make(chan fakeMessage, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	var msg fakeMessage
+	reply("220 localhost fake ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			msg = fakeMessage{from: mailbox(line[10:])}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			to := mailbox(line[8:])
+			if strings.HasSuffix(to, "@rejected.example") {
+				reply("550 No such user")
+				continue
+			}
+			msg.to = append(msg.to, to)
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			msg.data = data.String()
+			s.messages <- msg
+			reply("250 OK: queued")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// mailbox reads the address out of a MAIL FROM or RCPT TO argument such as "<a@b> BODY=8BITMIME"
+func mailbox(arg string) string {
+	arg = strings.TrimSpace(arg)
+	if end := strings.Index(arg, ">"); end >= 0 {
+		arg = arg[:end]
+	}
+	return strings.TrimPrefix(arg, "<")
+}
+
+func TestSMTPNotifier_Notify(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := NewSMTPNotifier(SMTPOptions{Host: "127.0.0.1", Port: server.port(), From: "reminders@todo.example"})
+	notifier.now = func() time.Time { return time.Date(2025, time.November, 20, 8, 0, 0, 0, time.UTC) }
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if !assert.NoError(t, err) {
+		return
+	}
+	due := time.Date(2025, time.November, 20, 9, 0, 0, 0, time.UTC)
+	notification := Notification{
+		ReminderID:  uuid.New(),
+		UserID:      uuid.New(),
+		Email:       "user@example.com",
+		Title:       "Submit the report\r\nBcc: everyone@example.com",
+		Description: "The quarterly one",
+		DueDate:     &due,
+		Location:    berlin,
+	}
+	notification.IdempotencyKey = IdempotencyKey(notification.ReminderID, due.Add(-time.Hour))
+
+	t.Run("should send the reminder to the user", func(t *testing.T) {
+		err := notifier.Notify(context.Background(), notification)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		select {
+		case msg := <-server.messages:
+			assert.Equal(t, "reminders@todo.example", msg.from)
+			assert.Equal(t, []string{"user@example.com"}, msg.to)
+			assert.Contains(t, msg.data, "To: user@example.com\r\n")
+			assert.Contains(t, msg.data, "Subject: Reminder: Submit the report Bcc: everyone@example.com\r\n")
+			assert.NotContains(t, msg.data, "\r\nBcc:")
+			assert.Contains(t, msg.data, "Due: Thursday, November 20, 2025 10:00 CET\r\n")
+			assert.Contains(t, msg.data, "The quarterly one")
+			assert.Contains(t, msg.data, "Message-ID: <"+notification.IdempotencyKey+"@127.0.0.1>\r\n")
+		case <-time.After(5 * time.Second):
+			t.Fatal("no message received")
+		}
+	})
+
+	t.Run("should fail when the server rejects the recipient", func(t *testing.T) {
+		rejected := notification
+		rejected.Email = "nobody@rejected.example"
+		err := notifier.Notify(context.Background(), rejected)
+		assert.ErrorContains(t, err, "RCPT TO")
+	})
+
+	t.Run("should fail when the server is unreachable", func(t *testing.T) {
+		closed := newFakeSMTPServer(t)
+		port := closed.port()
+		closed.listener.Close()
+
+		unreachable := NewSMTPNotifier(SMTPOptions{Host: "127.0.0.1", Port: port, From: "reminders@todo.example"})
+		err := unreachable.Notify(context.Background(), notification)
+		assert.ErrorContains(t, err, "failed to connect")
+	})
+
+	t.Run("should fail without an email address", func(t *testing.T) {
+		anonymous := notification
+		anonymous.Email = ""
+		assert.Error(t, notifier.Notify(context.Background(), anonymous))
+	})
+}
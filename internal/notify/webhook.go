@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of a webhook body, keyed with the webhook secret, as
+// "sha256=<hex>"
+const SignatureHeader = "X-Todo-Signature"
+
+// IdempotencyKeyHeader carries the notification's IdempotencyKey, so receivers can drop
+// redeliveries without parsing the body
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WebhookEvent is the body of a webhook request
+type WebhookEvent struct {
+	Event        string       `json:"event"` // always "reminder"
+	Notification Notification `json:"reminder"`
+}
+
+// WebhookNotifier delivers notifications as JSON POST requests to a URL
+type WebhookNotifier struct {
+	url        string
+	secret     []byte
+	httpClient *http.Client
+}
+
+// NewWebhookNotifier creates a notifier that posts to url. Bodies are signed when secret is not
+// empty; a nil client uses one with a 10 second timeout.
+func NewWebhookNotifier(url string, secret string, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookNotifier{url: url, secret: []byte(secret), httpClient: client}
+}
+
+// Notify posts the notification. Any response other than 2xx is an error.
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(WebhookEvent{Event: "reminder", Notification: notification})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if notification.IdempotencyKey != "" {
+		req.Header.Set(IdempotencyKeyHeader, notification.IdempotencyKey)
+	}
+	if len(n.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook error: status %d, body: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// Sign returns the SignatureHeader value for body
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	due := time.Date(2025, time.November, 20, 9, 0, 0, 0, time.UTC)
+	notification := Notification{
+		ReminderID: uuid.New(),
+		TaskID:     uuid.New(),
+		UserID:     uuid.New(),
+		Email:      "user@example.com",
+		Title:      "Submit the report",
+		DueDate:    &due,
+		RemindAt:   due.Add(-time.Hour),
+	}
+	notification.IdempotencyKey = IdempotencyKey(notification.ReminderID, notification.RemindAt)
+
+	t.Run("should post the signed notification", func(t *testing.T) {
+		var body []byte
+		var signature string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, notification.IdempotencyKey, r.Header.Get(IdempotencyKeyHeader))
+			signature = r.Header.Get(SignatureHeader)
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		err := NewWebhookNotifier(server.URL, "s3cret", nil).Notify(context.Background(), notification)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, Sign([]byte("s3cret"), body), signature)
+
+		var event WebhookEvent
+		if assert.NoError(t, json.Unmarshal(body, &event)) {
+			assert.Equal(t, "reminder", event.Event)
+			assert.Equal(t, notification.ReminderID, event.Notification.ReminderID)
+			assert.Equal(t, notification.IdempotencyKey, event.Notification.IdempotencyKey)
+			assert.Equal(t, "Submit the report", event.Notification.Title)
+			assert.Equal(t, due, event.Notification.DueDate.UTC())
+		}
+	})
+
+	t.Run("should leave the body unsigned without a secret", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Empty(t, r.Header.Get(SignatureHeader))
+		}))
+		defer server.Close()
+
+		assert.NoError(t, NewWebhookNotifier(server.URL, "", nil).Notify(context.Background(), notification))
+	})
+
+	t.Run("should fail on an error response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := NewWebhookNotifier(server.URL, "", nil).Notify(context.Background(), notification)
+		assert.ErrorContains(t, err, "status 503")
+	})
+}
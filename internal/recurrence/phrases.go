@@ -0,0 +1,136 @@
+package recurrence
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var phraseWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+var ordinalWords = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "sixth": 6, "seventh": 7,
+	"eighth": 8, "ninth": 9, "tenth": 10, "fifteenth": 15, "twentieth": 20, "last": -1,
+}
+
+var intervalWords = map[string]int{"other": 2, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6}
+
+var adverbFrequencies = map[string]Frequency{"daily": Daily, "weekly": Weekly, "monthly": Monthly}
+
+const (
+	phraseWeekdayRe = `(sunday|monday|tuesday|wednesday|thursday|friday|saturday)`
+	phraseOrdinalRe = `(\d{1,2}(?:st|nd|rd|th)|first|second|third|fourth|fifth|sixth|seventh|eighth|ninth|tenth|fifteenth|twentieth|last)`
+	phraseEvery     = `(?:every|each)\s+`
+	phraseAnd       = `(?:\s*,\s*(?:and\s+)?|\s+and\s+)`
+)
+
+var phraseWeekday = regexp.MustCompile(`(?i)` + phraseWeekdayRe)
+
+// phrasePattern recognises one way of saying how often something repeats
+type phrasePattern struct {
+	re    *regexp.Regexp
+	build func(m []string) (*Rule, bool)
+}
+
+var phrasePatterns = []phrasePattern{
+	// "every first of the month", "each 15th day of the month"
+	{regexp.MustCompile(`(?i)\b` + phraseEvery + phraseOrdinalRe + `\s+(?:day\s+)?of\s+(?:the|each|every)\s+month\b`), monthDayRule},
+	// "on the 15th of each month", "the last day of every month"; "by the 1st of the month" is a single day
+	{regexp.MustCompile(`(?i)\b(?:on\s+)?the\s+` + phraseOrdinalRe + `\s+(?:day\s+)?of\s+(?:each|every)\s+month\b`), monthDayRule},
+	// "every month on the 1st", "monthly on the 15th"
+	{regexp.MustCompile(`(?i)\b(?:` + phraseEvery + `month|monthly)\s+on\s+the\s+` + phraseOrdinalRe + `(?:\s+day)?\b`), monthDayRule},
+	// "every weekday"
+	{regexp.MustCompile(`(?i)\b` + phraseEvery + `weekday\b`), func([]string) (*Rule, bool) {
+		return &Rule{Freq: Weekly, Interval: 1, ByDay: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}}, true
+	}},
+	// "every Monday and Thursday", "every other Friday"
+	{regexp.MustCompile(`(?i)\b` + phraseEvery + `(?:(other)\s+)?` + phraseWeekdayRe + `(?:` + phraseAnd + phraseWeekdayRe + `)*\b`), weekdayRule},
+	// "on Tuesdays and Thursdays"; "on Tuesday" is a single day
+	{regexp.MustCompile(`(?i)\b()on\s+` + phraseWeekdayRe + `s(?:` + phraseAnd + phraseWeekdayRe + `s)*\b`), weekdayRule},
+	// "every 3 days", "every other week"
+	{regexp.MustCompile(`(?i)\b` + phraseEvery + `(\d{1,3}|other|two|three|four|five|six)\s+(day|week|month)s?\b`), func(m []string) (*Rule, bool) {
+		interval, ok := intervalWords[strings.ToLower(m[1])]
+		if !ok {
+			interval, _ = strconv.Atoi(m[1])
+		}
+		if interval < 1 {
+			return nil, false
+		}
+		return &Rule{Freq: unitFrequency(m[2]), Interval: interval}, true
+	}},
+	// "every day", "each week"
+	{regexp.MustCompile(`(?i)\b` + phraseEvery + `(?:single\s+)?(day|week|month)\b`), func(m []string) (*Rule, bool) {
+		return &Rule{Freq: unitFrequency(m[1]), Interval: 1}, true
+	}},
+	// A trailing "daily", so that "the weekly report" stays a one-off
+	{regexp.MustCompile(`(?i)\b(daily|weekly|monthly)\s*[.!]?$`), func(m []string) (*Rule, bool) {
+		return &Rule{Freq: adverbFrequencies[strings.ToLower(m[1])], Interval: 1}, true
+	}},
+}
+
+// Extract finds a phrase such as "every Monday" or "on the 1st of every month" in text and
+// returns the rule it describes, along with the text without the phrase
+func Extract(text string) (*Rule, string, bool) {
+	for _, pattern := range phrasePatterns {
+		loc := pattern.re.FindStringSubmatchIndex(text)
+		if loc == nil {
+			continue
+		}
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		rule, ok := pattern.build(m)
+		if !ok {
+			continue
+		}
+		remaining := strings.Join(strings.Fields(text[:loc[0]]+" "+text[loc[1]:]), " ")
+		return rule, remaining, true
+	}
+	return nil, text, false
+}
+
+func monthDayRule(m []string) (*Rule, bool) {
+	ordinal := strings.ToLower(m[1])
+	day, ok := ordinalWords[ordinal]
+	if !ok {
+		day, _ = strconv.Atoi(strings.TrimRight(ordinal, "stndrh"))
+	}
+	if day == 0 || day > 31 {
+		return nil, false
+	}
+	return &Rule{Freq: Monthly, Interval: 1, ByMonthDay: []int{day}}, true
+}
+
+func weekdayRule(m []string) (*Rule, bool) {
+	rule := &Rule{Freq: Weekly, Interval: 1}
+	if m[1] != "" {
+		rule.Interval = 2
+	}
+	// A repeated group only keeps its last match, so collect every weekday in the phrase
+	for _, word := range phraseWeekday.FindAllStringSubmatch(m[0], -1) {
+		day := phraseWeekdays[strings.ToLower(word[1])]
+		if !containsWeekday(rule.ByDay, day) {
+			rule.ByDay = append(rule.ByDay, day)
+		}
+	}
+	sort.Slice(rule.ByDay, func(i, j int) bool { return mondayOffset(rule.ByDay[i]) < mondayOffset(rule.ByDay[j]) })
+	return rule, true
+}
+
+func unitFrequency(unit string) Frequency {
+	switch strings.ToLower(unit) {
+	case "week":
+		return Weekly
+	case "month":
+		return Monthly
+	}
+	return Daily
+}
@@ -0,0 +1,313 @@
+// Package recurrence implements the subset of RFC 5545 recurrence rules used for repeating
+// tasks: FREQ=DAILY, WEEKLY (optionally BYDAY) and MONTHLY (optionally BYMONTHDAY), with
+// INTERVAL, UNTIL and COUNT. Occurrences are computed on the wall clock of the time they are
+// given, so callers pass times in the user's location to keep "every day at 9:00" at 9:00
+// across daylight saving changes.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ of a rule
+type Frequency string
+
+// Supported frequencies
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// ErrInvalidRule is wrapped by the errors of Parse
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+const (
+	untilDateLayout     = "20060102"
+	untilDateTimeLayout = "20060102T150405Z"
+)
+
+// Rule is a parsed recurrence rule
+type Rule struct {
+	Freq       Frequency
+	Interval   int            // every Interval days, weeks or months; at least 1
+	ByDay      []time.Weekday // weekly rules only; empty for the weekday of the first occurrence
+	ByMonthDay []int          // monthly rules only; negative days count from the end of the month
+	Until      time.Time      // last possible occurrence; zero for none
+	UntilDate  bool           // Until is a date, compared with the occurrence's date on its own wall clock
+	Count      int            // number of occurrences; zero for no limit
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH". An "RRULE:" prefix is allowed.
+func Parse(text string) (*Rule, error) {
+	text = strings.TrimSpace(text)
+	if len(text) >= 6 && strings.EqualFold(text[:6], "RRULE:") {
+		text = text[6:]
+	}
+	if text == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(text, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s given twice", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(value)
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly {
+				err = fmt.Errorf("unsupported FREQ %s", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = positiveInt(name, value, 1000)
+		case "COUNT":
+			rule.Count, err = positiveInt(name, value, 1000)
+		case "UNTIL":
+			if rule.Until, err = time.Parse(untilDateTimeLayout, value); err == nil {
+				break
+			}
+			rule.UntilDate = true
+			if rule.Until, err = time.Parse(untilDateLayout, value); err != nil {
+				err = fmt.Errorf("UNTIL must be a date (YYYYMMDD) or a UTC time (YYYYMMDDTHHMMSSZ)")
+			}
+		case "BYDAY":
+			rule.ByDay, err = parseWeekdays(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseMonthDays(value)
+		case "WKST":
+			if value != "MO" {
+				err = fmt.Errorf("only WKST=MO is supported")
+			}
+		default:
+			err = fmt.Errorf("unsupported part %s", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+	}
+
+	switch {
+	case rule.Freq == "":
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	case len(rule.ByDay) > 0 && rule.Freq != Weekly:
+		return nil, fmt.Errorf("%w: BYDAY is only supported with FREQ=WEEKLY", ErrInvalidRule)
+	case len(rule.ByMonthDay) > 0 && rule.Freq != Monthly:
+		return nil, fmt.Errorf("%w: BYMONTHDAY is only supported with FREQ=MONTHLY", ErrInvalidRule)
+	case rule.Count > 0 && !rule.Until.IsZero():
+		return nil, fmt.Errorf("%w: UNTIL and COUNT cannot be combined", ErrInvalidRule)
+	}
+	return rule, nil
+}
+
+// String formats the rule in canonical form, without the "RRULE:" prefix
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = weekdayCodes[day]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if !r.Until.IsZero() {
+		if r.UntilDate {
+			parts = append(parts, "UNTIL="+r.Until.Format(untilDateLayout))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilDateTimeLayout))
+		}
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// First returns the first occurrence at or after start, the series' start
+func (r *Rule) First(start time.Time) time.Time {
+	if r.matches(start) {
+		return start
+	}
+	return r.after(start)
+}
+
+// Next returns the occurrence following prev, which is occurrence number index of the series,
+// counting from 0. It reports false when the series ends before another occurrence.
+func (r *Rule) Next(prev time.Time, index int) (time.Time, bool) {
+	if r.Count > 0 && index+1 >= r.Count {
+		return time.Time{}, false
+	}
+	next := r.after(prev)
+	if !r.Until.IsZero() {
+		if r.UntilDate {
+			if civilDay(next) > civilDay(r.Until) {
+				return time.Time{}, false
+			}
+		} else if next.After(r.Until) {
+			return time.Time{}, false
+		}
+	}
+	return next, true
+}
+
+// matches reports whether t falls on one of the days the rule selects
+func (r *Rule) matches(t time.Time) bool {
+	switch r.Freq {
+	case Weekly:
+		return len(r.ByDay) == 0 || containsWeekday(r.ByDay, t.Weekday())
+	case Monthly:
+		if len(r.ByMonthDay) == 0 {
+			return true
+		}
+		for _, day := range r.monthDays(t.Year(), t.Month(), t.Day()) {
+			if day == t.Day() {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// after returns the first occurrence after prev in a series in which prev's day, week or month
+// is one of the active ones, at prev's time of day
+func (r *Rule) after(prev time.Time) time.Time {
+	interval := max(r.Interval, 1)
+	switch r.Freq {
+	case Weekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{prev.Weekday()}
+		}
+		prevWeek := civilDay(prev) - mondayOffset(prev.Weekday())
+		for offset := 1; ; offset++ {
+			next := prev.AddDate(0, 0, offset)
+			week := (civilDay(next) - mondayOffset(next.Weekday()) - prevWeek) / 7
+			if week%interval == 0 && containsWeekday(days, next.Weekday()) {
+				return next
+			}
+		}
+	case Monthly:
+		// A rule for the 31st skips shorter months, so look far enough ahead to find one
+		for months := 0; months <= 12*interval; months += interval {
+			first := time.Date(prev.Year(), prev.Month()+time.Month(months), 1, prev.Hour(), prev.Minute(), prev.Second(), prev.Nanosecond(), prev.Location())
+			for _, day := range r.monthDays(first.Year(), first.Month(), prev.Day()) {
+				next := first.AddDate(0, 0, day-1)
+				if next.After(prev) {
+					return next
+				}
+			}
+		}
+		return prev.AddDate(0, 12*interval, 0)
+	}
+	return prev.AddDate(0, 0, interval)
+}
+
+// monthDays resolves the rule's days of the month in the given month, in order. Days the month
+// does not have are skipped; without BYMONTHDAY the series' own day of the month is used.
+func (r *Rule) monthDays(year int, month time.Month, seriesDay int) []int {
+	length := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	wanted := r.ByMonthDay
+	if len(wanted) == 0 {
+		wanted = []int{seriesDay}
+	}
+	var days []int
+	for _, day := range wanted {
+		if day < 0 {
+			day = length + day + 1
+		}
+		if day >= 1 && day <= length {
+			days = append(days, day)
+		}
+	}
+	sort.Ints(days)
+	return days
+}
+
+func positiveInt(name string, value string, limit int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > limit {
+		return 0, fmt.Errorf("%s must be between 1 and %d", name, limit)
+	}
+	return n, nil
+}
+
+func parseWeekdays(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, code := range strings.Split(value, ",") {
+		day := -1
+		for i, known := range weekdayCodes {
+			if code == known {
+				day = i
+			}
+		}
+		if day < 0 {
+			return nil, fmt.Errorf("unsupported BYDAY value %q", code)
+		}
+		if !containsWeekday(days, time.Weekday(day)) {
+			days = append(days, time.Weekday(day))
+		}
+	}
+	// Monday first, as in WKST=MO
+	sort.Slice(days, func(i, j int) bool { return mondayOffset(days[i]) < mondayOffset(days[j]) })
+	return days, nil
+}
+
+func parseMonthDays(value string) ([]int, error) {
+	var days []int
+	for _, item := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(item)
+		if err != nil || day == 0 || day < -31 || day > 31 {
+			return nil, fmt.Errorf("BYMONTHDAY values must be between 1 and 31 or -31 and -1")
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// mondayOffset is the number of days from the Monday starting the week to day
+func mondayOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// civilDay numbers the calendar day of t on its own wall clock, ignoring the time of day and
+// daylight saving shifts
+func civilDay(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
@@ -0,0 +1,178 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should accept the supported subset and format it canonically", func(t *testing.T) {
+		cases := map[string]string{
+			"FREQ=DAILY":                            "FREQ=DAILY",
+			"RRULE:freq=weekly;byday=th,mo":         "FREQ=WEEKLY;BYDAY=MO,TH",
+			"FREQ=WEEKLY;INTERVAL=2;WKST=MO":        "FREQ=WEEKLY;INTERVAL=2",
+			"FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=12": "FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=12",
+			"FREQ=DAILY;INTERVAL=1;UNTIL=20251231":  "FREQ=DAILY;UNTIL=20251231",
+			"FREQ=DAILY;UNTIL=20251231T090000Z":     "FREQ=DAILY;UNTIL=20251231T090000Z",
+		}
+		for text, expected := range cases {
+			rule, err := Parse(text)
+			if assert.NoError(t, err, text) {
+				assert.Equal(t, expected, rule.String(), text)
+			}
+		}
+	})
+
+	t.Run("should reject rules outside the subset", func(t *testing.T) {
+		for _, text := range []string{
+			"",
+			"INTERVAL=2",
+			"FREQ=YEARLY",
+			"FREQ=HOURLY",
+			"FREQ=DAILY;INTERVAL=0",
+			"FREQ=DAILY;BYDAY=MO",
+			"FREQ=WEEKLY;BYDAY=1MO",
+			"FREQ=WEEKLY;BYMONTHDAY=1",
+			"FREQ=MONTHLY;BYMONTHDAY=32",
+			"FREQ=DAILY;COUNT=3;UNTIL=20251231",
+			"FREQ=DAILY;UNTIL=tomorrow",
+			"FREQ=DAILY;FREQ=WEEKLY",
+			"FREQ=DAILY;BYHOUR=9",
+			"FREQ=DAILY;WKST=SU",
+		} {
+			_, err := Parse(text)
+			assert.ErrorIs(t, err, ErrInvalidRule, text)
+		}
+	})
+}
+
+func TestRule_Next(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if !assert.NoError(t, err) {
+		return
+	}
+	at := func(year int, month time.Month, day int, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, berlin)
+	}
+
+	cases := []struct {
+		rule     string
+		prev     time.Time
+		expected []time.Time
+	}{
+		{"FREQ=DAILY", at(2025, time.March, 29, 9), []time.Time{at(2025, time.March, 30, 9), at(2025, time.March, 31, 9)}},
+		{"FREQ=DAILY;INTERVAL=3", at(2025, time.January, 30, 0), []time.Time{at(2025, time.February, 2, 0)}},
+		{"FREQ=WEEKLY", at(2025, time.November, 19, 8), []time.Time{at(2025, time.November, 26, 8)}},
+		{"FREQ=WEEKLY;BYDAY=MO,TH", at(2025, time.November, 17, 8), []time.Time{at(2025, time.November, 20, 8), at(2025, time.November, 24, 8)}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", at(2025, time.November, 19, 8), []time.Time{at(2025, time.December, 1, 8), at(2025, time.December, 3, 8), at(2025, time.December, 15, 8)}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=SU", at(2025, time.November, 23, 8), []time.Time{at(2025, time.December, 7, 8)}},
+		{"FREQ=MONTHLY", at(2025, time.January, 15, 0), []time.Time{at(2025, time.February, 15, 0), at(2025, time.March, 15, 0)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=1", at(2025, time.November, 1, 0), []time.Time{at(2025, time.December, 1, 0), at(2026, time.January, 1, 0)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,15", at(2025, time.November, 1, 0), []time.Time{at(2025, time.November, 15, 0), at(2025, time.December, 1, 0)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", at(2025, time.January, 31, 0), []time.Time{at(2025, time.February, 28, 0), at(2025, time.March, 31, 0)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=31", at(2025, time.January, 31, 0), []time.Time{at(2025, time.March, 31, 0), at(2025, time.May, 31, 0)}},
+		{"FREQ=MONTHLY;INTERVAL=3", at(2025, time.November, 30, 0), []time.Time{at(2026, time.May, 30, 0)}},
+	}
+	for _, tc := range cases {
+		rule, err := Parse(tc.rule)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		prev := tc.prev
+		for i, expected := range tc.expected {
+			next, ok := rule.Next(prev, i)
+			if assert.True(t, ok, tc.rule) {
+				assert.Equal(t, expected, next, "%s after %s", tc.rule, prev)
+			}
+			prev = next
+		}
+	}
+
+	t.Run("should keep the wall clock time across daylight saving changes", func(t *testing.T) {
+		rule, _ := Parse("FREQ=DAILY")
+		next, _ := rule.Next(at(2025, time.March, 29, 9), 0)
+		assert.Equal(t, 9, next.Hour())
+		assert.Equal(t, 23*time.Hour, next.Sub(at(2025, time.March, 29, 9)))
+	})
+
+	t.Run("should end the series after COUNT occurrences", func(t *testing.T) {
+		rule, _ := Parse("FREQ=DAILY;COUNT=2")
+		_, ok := rule.Next(at(2025, time.March, 1, 9), 0)
+		assert.True(t, ok)
+		_, ok = rule.Next(at(2025, time.March, 2, 9), 1)
+		assert.False(t, ok)
+	})
+
+	t.Run("should end the series at UNTIL", func(t *testing.T) {
+		rule, _ := Parse("FREQ=WEEKLY;UNTIL=20251126")
+		_, ok := rule.Next(at(2025, time.November, 19, 23), 0)
+		assert.True(t, ok, "a date UNTIL includes the whole day")
+		_, ok = rule.Next(at(2025, time.November, 26, 23), 1)
+		assert.False(t, ok)
+
+		rule, _ = Parse("FREQ=DAILY;UNTIL=20251120T070000Z")
+		_, ok = rule.Next(at(2025, time.November, 19, 8), 0)
+		assert.True(t, ok)
+		_, ok = rule.Next(at(2025, time.November, 19, 9), 0)
+		assert.False(t, ok)
+	})
+}
+
+func TestRule_First(t *testing.T) {
+	start := time.Date(2025, time.November, 19, 0, 0, 0, 0, time.UTC) // a Wednesday
+	cases := map[string]time.Time{
+		"FREQ=DAILY":                 start,
+		"FREQ=WEEKLY":                start,
+		"FREQ=WEEKLY;BYDAY=WE,FR":    start,
+		"FREQ=WEEKLY;BYDAY=MO":       time.Date(2025, time.November, 24, 0, 0, 0, 0, time.UTC),
+		"FREQ=MONTHLY;BYMONTHDAY=1":  time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC),
+		"FREQ=MONTHLY;BYMONTHDAY=-1": time.Date(2025, time.November, 30, 0, 0, 0, 0, time.UTC),
+	}
+	for text, expected := range cases {
+		rule, err := Parse(text)
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, rule.First(start), text)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	cases := []struct {
+		text      string
+		rule      string
+		remaining string
+	}{
+		{"pay rent every first of the month", "FREQ=MONTHLY;BYMONTHDAY=1", "pay rent"},
+		{"pay rent on the 1st of every month", "FREQ=MONTHLY;BYMONTHDAY=1", "pay rent"},
+		{"send invoices the last day of each month", "FREQ=MONTHLY;BYMONTHDAY=-1", "send invoices"},
+		{"every month on the 15th water the orchid", "FREQ=MONTHLY;BYMONTHDAY=15", "water the orchid"},
+		{"standup every weekday at 9am", "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", "standup at 9am"},
+		{"gym every Monday, Wednesday and Friday", "FREQ=WEEKLY;BYDAY=MO,WE,FR", "gym"},
+		{"piano lessons on Tuesdays and Thursdays", "FREQ=WEEKLY;BYDAY=TU,TH", "piano lessons"},
+		{"mow the lawn every other Saturday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=SA", "mow the lawn"},
+		{"change the filter every 3 months", "FREQ=MONTHLY;INTERVAL=3", "change the filter"},
+		{"water plants every other day", "FREQ=DAILY;INTERVAL=2", "water plants"},
+		{"take vitamins every day", "FREQ=DAILY", "take vitamins"},
+		{"back up the laptop weekly", "FREQ=WEEKLY", "back up the laptop"},
+	}
+	for _, tc := range cases {
+		rule, remaining, ok := Extract(tc.text)
+		if assert.True(t, ok, tc.text) {
+			assert.Equal(t, tc.rule, rule.String(), tc.text)
+			assert.Equal(t, tc.remaining, remaining, tc.text)
+		}
+	}
+
+	for _, text := range []string{
+		"dentist on Tuesday",
+		"pay rent by the first of the month",
+		"write the weekly report",
+		"buy milk",
+	} {
+		_, remaining, ok := Extract(text)
+		assert.False(t, ok, text)
+		assert.Equal(t, text, remaining)
+	}
+}
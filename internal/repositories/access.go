@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// visibleTasks restricts a query on tasks to those the user can see: the tasks in their own inbox
+// and the tasks of every project they are a member of, whoever owns them. Reads that serve a
+// user's request go through it; writes are scoped to the owner of the tasks, after the services
+// have checked the user's role.
+func visibleTasks(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return db.Where("((tasks.project_id IS NULL AND tasks.user_id = ?) OR tasks.project_id IN (?))", userID, memberProjectIDs(db, userID))
+}
+
+// memberProjectIDs is a subquery for the IDs of the projects the user is a member of
+func memberProjectIDs(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&models.ProjectMember{}).
+		Select("project_members.project_id").Where("project_members.user_id = ?", userID)
+}
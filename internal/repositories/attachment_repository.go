@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"errors"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTooManyAttachments is returned when a task already has as many attachments as it can have
+var ErrTooManyAttachments = errors.New("too many attachments")
+
+// AttachmentRepositoryInterface defines the methods for interacting with task attachment data
+type AttachmentRepositoryInterface interface {
+	CreateAttachment(attachment *models.Attachment, limit int) error
+	GetAttachmentByID(id uuid.UUID) (*models.Attachment, error)
+	GetAttachmentsByTaskID(taskID uuid.UUID) ([]models.Attachment, error)
+	DeleteAttachment(id uuid.UUID) error
+	GetOrphanedAttachments(limit int) ([]models.Attachment, error)
+}
+
+// AttachmentRepository handles database operations for task attachments. Callers check that the
+// user can see the task first, so nothing here is scoped to a user.
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAttachmentRepository creates a new AttachmentRepository
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+// CreateAttachment creates a new attachment in the database and records a change to its task,
+// whose JSON lists its attachments. The task row is locked while its attachments are counted, so
+// uploads running at the same time cannot take it past limit.
+func (r *AttachmentRepository) CreateAttachment(attachment *models.Attachment, limit int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var task models.Task
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "user_id").
+			Where("id = ?", attachment.TaskID).First(&task).Error
+		if err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.Attachment{}).Where("task_id = ?", task.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(limit) {
+			return ErrTooManyAttachments
+		}
+		if err := tx.Create(attachment).Error; err != nil {
+			return err
+		}
+		return touchTasks(tx, []uuid.UUID{task.ID}, task.UserID)
+	})
+}
+
+// GetAttachmentByID retrieves an attachment by its ID
+func (r *AttachmentRepository) GetAttachmentByID(id uuid.UUID) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.db.Where("id = ?", id).First(&attachment).Error
+	return &attachment, err
+}
+
+// GetAttachmentsByTaskID retrieves the attachments of a task, oldest first
+func (r *AttachmentRepository) GetAttachmentsByTaskID(taskID uuid.UUID) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.Where("task_id = ?", taskID).Order("created_at, id").Find(&attachments).Error
+	return attachments, err
+}
+
+// DeleteAttachment deletes an attachment record and records a change to its task, unless the task
+// was purged already; the attachment's blob is the caller's to remove
+func (r *AttachmentRepository) DeleteAttachment(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var attachment models.Attachment
+		if err := tx.Select("id", "task_id").Where("id = ?", id).First(&attachment).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).Delete(&models.Attachment{}).Error; err != nil {
+			return err
+		}
+		var owners []uuid.UUID
+		if err := tx.Model(&models.Task{}).Where("id = ?", attachment.TaskID).Pluck("user_id", &owners).Error; err != nil {
+			return err
+		}
+		if len(owners) == 0 {
+			return nil
+		}
+		return touchTasks(tx, []uuid.UUID{attachment.TaskID}, owners[0])
+	})
+}
+
+// GetOrphanedAttachments retrieves up to limit attachments whose task was purged. Purging a task
+// leaves its attachment records behind so their blobs can be removed outside the transaction.
+func (r *AttachmentRepository) GetOrphanedAttachments(limit int) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.Model(&models.Attachment{}).Select("attachments.*").
+		Joins("LEFT JOIN tasks ON tasks.id = attachments.task_id").
+		Where("tasks.id IS NULL").
+		Order("attachments.created_at, attachments.id").
+		Limit(limit).
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// attachAttachments loads the attachments of each of the given tasks, oldest first
+func attachAttachments(db *gorm.DB, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var rows []models.Attachment
+	err := db.Where("task_id IN ?", ids).Order("created_at, id").Find(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	attachments := make(map[uuid.UUID][]models.Attachment)
+	for _, row := range rows {
+		attachments[row.TaskID] = append(attachments[row.TaskID], row)
+	}
+	for i := range tasks {
+		tasks[i].Attachments = attachments[tasks[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AudioUploadRepositoryInterface defines the methods for interacting with audio upload data
+type AudioUploadRepositoryInterface interface {
+	CreateAudioUpload(upload *models.AudioUpload) error
+	GetAudioUploadByID(id uuid.UUID, userID uuid.UUID) (*models.AudioUpload, error)
+	GetAudioUploadsByUserID(userID uuid.UUID) ([]models.AudioUpload, error)
+	UpdateAudioUpload(upload *models.AudioUpload) error
+}
+
+// AudioUploadRepository handles database operations for audio uploads
+type AudioUploadRepository struct {
+	db *gorm.DB
+}
+
+// NewAudioUploadRepository creates a new AudioUploadRepository
+func NewAudioUploadRepository(db *gorm.DB) *AudioUploadRepository {
+	return &AudioUploadRepository{db: db}
+}
+
+// CreateAudioUpload creates a new audio upload in the database
+func (r *AudioUploadRepository) CreateAudioUpload(upload *models.AudioUpload) error {
+	return r.db.Create(upload).Error
+}
+
+// GetAudioUploadByID retrieves an audio upload by its ID
+func (r *AudioUploadRepository) GetAudioUploadByID(id uuid.UUID, userID uuid.UUID) (*models.AudioUpload, error) {
+	var upload models.AudioUpload
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&upload).Error
+	return &upload, err
+}
+
+// GetAudioUploadsByUserID retrieves all audio uploads for a given user ID, newest first
+func (r *AudioUploadRepository) GetAudioUploadsByUserID(userID uuid.UUID) ([]models.AudioUpload, error) {
+	var uploads []models.AudioUpload
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&uploads).Error
+	return uploads, err
+}
+
+// UpdateAudioUpload updates an existing audio upload in the database
+func (r *AudioUploadRepository) UpdateAudioUpload(upload *models.AudioUpload) error {
+	return r.db.Save(upload).Error
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CommentRepositoryInterface defines the methods for interacting with task comments
+type CommentRepositoryInterface interface {
+	CreateComment(comment *models.Comment) error
+	GetCommentByID(id uuid.UUID) (*models.Comment, error)
+	GetCommentsByTaskID(taskID uuid.UUID) ([]models.Comment, error)
+	UpdateComment(comment *models.Comment) error
+	DeleteComment(id uuid.UUID) error
+}
+
+// CommentRepository handles database operations for task comments. Callers check
+// that the user can see the task first, so nothing here is scoped to a user.
+type CommentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository creates a new CommentRepository
+func NewCommentRepository(db *gorm.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+// commentRow is a comment with the email of its author
+type commentRow struct {
+	models.Comment
+	UserEmail string
+}
+
+// CreateComment creates a new comment in the database
+func (r *CommentRepository) CreateComment(comment *models.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+// GetCommentByID retrieves a comment by its ID, with the email of its author
+func (r *CommentRepository) GetCommentByID(id uuid.UUID) (*models.Comment, error) {
+	var row commentRow
+	err := r.db.Model(&models.Comment{}).Select("comments.*, users.email AS user_email").
+		Joins("LEFT JOIN users ON users.id = comments.user_id").
+		Where("comments.id = ?", id).
+		Take(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	row.Comment.AuthorEmail = row.UserEmail
+	return &row.Comment, nil
+}
+
+// GetCommentsByTaskID retrieves the comments on a task with the emails of their authors, oldest first
+func (r *CommentRepository) GetCommentsByTaskID(taskID uuid.UUID) ([]models.Comment, error) {
+	var rows []commentRow
+	err := r.db.Model(&models.Comment{}).Select("comments.*, users.email AS user_email").
+		Joins("LEFT JOIN users ON users.id = comments.user_id").
+		Where("comments.task_id = ?", taskID).
+		Order("comments.created_at, comments.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	comments := make([]models.Comment, len(rows))
+	for i, row := range rows {
+		comments[i] = row.Comment
+		comments[i].AuthorEmail = row.UserEmail
+	}
+	return comments, nil
+}
+
+// UpdateComment writes the body of a comment and when it was edited
+func (r *CommentRepository) UpdateComment(comment *models.Comment) error {
+	result := r.db.Model(comment).Where("id = ?", comment.ID).Select("body", "edited_at", "updated_at").Updates(comment)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// DeleteComment deletes a comment
+func (r *CommentRepository) DeleteComment(id uuid.UUID) error {
+	result := r.db.Where("id = ?", id).Delete(&models.Comment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProjectRepositoryInterface defines the methods for interacting with project data
+type ProjectRepositoryInterface interface {
+	CreateProject(project *models.Project) error
+	GetProjectByID(id uuid.UUID, userID uuid.UUID) (*models.Project, error)
+	GetProjectsByUserID(userID uuid.UUID, includeArchived bool) ([]models.Project, error)
+	GetProjectTaskCounts(userID uuid.UUID, now time.Time) ([]models.ProjectTaskCounts, error)
+	UpdateProject(project *models.Project) error
+	DeleteProject(id uuid.UUID, userID uuid.UUID) error
+	MoveTasks(ids []uuid.UUID, projectID *uuid.UUID, userID uuid.UUID) error
+	GetProjectMembers(projectID uuid.UUID) ([]models.ProjectMember, error)
+	UpdateProjectMember(member *models.ProjectMember) error
+	RemoveProjectMember(projectID uuid.UUID, userID uuid.UUID) error
+	CreateInvitation(invitation *models.ProjectInvitation) error
+	GetInvitationByID(id uuid.UUID) (*models.ProjectInvitation, error)
+	GetPendingInvitations(projectID uuid.UUID) ([]models.ProjectInvitation, error)
+	GetPendingInvitationsByInvitee(inviteeID uuid.UUID) ([]models.ProjectInvitation, error)
+	UpdateInvitation(invitation *models.ProjectInvitation) error
+	DeleteInvitation(id uuid.UUID) error
+	AcceptInvitation(invitation *models.ProjectInvitation) error
+}
+
+// ProjectRepository handles database operations for projects
+type ProjectRepository struct {
+	db *gorm.DB
+}
+
+// NewProjectRepository creates a new ProjectRepository
+func NewProjectRepository(db *gorm.DB) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+// CreateProject creates a new project in the database, with its creator as its owner
+func (r *ProjectRepository) CreateProject(project *models.Project) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		project.Role = models.ProjectRoleOwner
+		return tx.Create(&models.ProjectMember{ProjectID: project.ID, UserID: project.UserID, Role: project.Role}).Error
+	})
+}
+
+// projectRow is a project with the role of the user it was loaded for
+type projectRow struct {
+	models.Project
+	MemberRole string
+}
+
+// memberProjects selects the projects the user is a member of, with their role in each
+func (r *ProjectRepository) memberProjects(userID uuid.UUID) *gorm.DB {
+	return r.db.Model(&models.Project{}).Select("projects.*, project_members.role AS member_role").
+		Joins("JOIN project_members ON project_members.project_id = projects.id AND project_members.user_id = ?", userID)
+}
+
+// GetProjectByID retrieves a project the user is a member of, with their role in it
+func (r *ProjectRepository) GetProjectByID(id uuid.UUID, userID uuid.UUID) (*models.Project, error) {
+	var row projectRow
+	err := r.memberProjects(userID).Where("projects.id = ?", id).Take(&row).Error
+	row.Project.Role = row.MemberRole
+	return &row.Project, err
+}
+
+// GetProjectsByUserID retrieves the projects the user is a member of in their sort order, with
+// the user's role in each, leaving out archived ones unless includeArchived is set
+func (r *ProjectRepository) GetProjectsByUserID(userID uuid.UUID, includeArchived bool) ([]models.Project, error) {
+	db := r.memberProjects(userID)
+	if !includeArchived {
+		db = db.Where("projects.archived = ?", false)
+	}
+	var rows []projectRow
+	if err := db.Order("projects.position, projects.created_at, projects.id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	projects := make([]models.Project, len(rows))
+	for i, row := range rows {
+		projects[i] = row.Project
+		projects[i].Role = row.MemberRole
+	}
+	return projects, nil
+}
+
+// GetProjectTaskCounts counts the open top-level tasks in each of the projects the user is a
+// member of, and those of them that were due before now. Projects without open tasks are left out.
+func (r *ProjectRepository) GetProjectTaskCounts(userID uuid.UUID, now time.Time) ([]models.ProjectTaskCounts, error) {
+	var counts []models.ProjectTaskCounts
+	err := r.db.Model(&models.Task{}).
+		Select("project_id, COUNT(*) AS open_count, SUM(CASE WHEN due_date < ? THEN 1 ELSE 0 END) AS overdue_count", now.UTC()).
+		Where("parent_id IS NULL AND project_id IN (?) AND completed = ?", memberProjectIDs(r.db, userID), false).
+		Group("project_id").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// UpdateProject writes the name, colour, position and archived flag of a project
+func (r *ProjectRepository) UpdateProject(project *models.Project) error {
+	result := r.db.Model(project).Where("user_id = ?", project.UserID).
+		Select("name", "color", "archived", "position", "updated_at").Updates(project)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// DeleteProject deletes a project with its members and invitations, and moves its tasks, trashed
+// ones included, back to the inbox of the user who owns them
+func (r *ProjectRepository) DeleteProject(id uuid.UUID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var taskIDs []uuid.UUID
+		err := tx.Unscoped().Model(&models.Task{}).Where("project_id = ? AND user_id = ?", id, userID).Pluck("id", &taskIDs).Error
+		if err != nil {
+			return err
+		}
+		if err := moveTasks(tx.Unscoped(), taskIDs, nil, userID); err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", id).Delete(&models.ProjectInvitation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", id).Delete(&models.ProjectMember{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Project{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// MoveTasks puts the user's tasks and their subtasks in a project, or back in the inbox when
+// projectID is nil. Tasks already there are left alone.
+func (r *ProjectRepository) MoveTasks(ids []uuid.UUID, projectID *uuid.UUID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		descendants, err := descendantIDs(tx.Where("user_id = ?", userID), ids)
+		if err != nil {
+			return err
+		}
+		tree := append(append([]uuid.UUID{}, ids...), descendants...)
+		var moving []uuid.UUID
+		db := tx.Model(&models.Task{}).Where("id IN ? AND user_id = ?", tree, userID)
+		if projectID != nil {
+			db = db.Where("project_id IS NULL OR project_id <> ?", *projectID)
+		} else {
+			db = db.Where("project_id IS NOT NULL")
+		}
+		if err := db.Pluck("id", &moving).Error; err != nil {
+			return err
+		}
+		return moveTasks(tx, moving, projectID, userID)
+	})
+}
+
+// moveTasks sets the project of tasks, bumping their version and change sequence number. Tasks
+// whose assignee is not a member of the project they move to are left unassigned; in the inbox,
+// only the owner can be assigned.
+func moveTasks(tx *gorm.DB, ids []uuid.UUID, projectID *uuid.UUID, userID uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	seq, err := nextChangeSeq(tx, userID)
+	if err != nil {
+		return err
+	}
+	var left []uuid.UUID
+	query := tx.Session(&gorm.Session{}).Model(&models.Task{}).Where("id IN ? AND user_id = ? AND project_id IS NOT NULL", ids, userID)
+	if projectID != nil {
+		query = query.Where("project_id <> ?", *projectID)
+	}
+	if err := query.Distinct("project_id").Pluck("project_id", &left).Error; err != nil {
+		return err
+	}
+	if err := leaveProjects(tx, left, seq); err != nil {
+		return err
+	}
+	err = tx.Session(&gorm.Session{}).Model(&models.Task{}).Where("id IN ? AND user_id = ?", ids, userID).
+		Updates(map[string]interface{}{"project_id": projectID, "version": gorm.Expr("version + 1"), "change_seq": seq}).Error
+	if err != nil {
+		return err
+	}
+	if projectID == nil {
+		return unassignTasks(tx, userID, "id IN ? AND assignee_id <> ?", ids, userID)
+	}
+	members := tx.Session(&gorm.Session{NewDB: true}).Model(&models.ProjectMember{}).
+		Select("project_members.user_id").Where("project_members.project_id = ?", *projectID)
+	return unassignTasks(tx, userID, "id IN ? AND assignee_id NOT IN (?)", ids, members)
+}
+
+// leaveProjects records that tasks left the given projects with change sequence number seq. The
+// tasks drop out of the projects' sync streams without a tombstone, so members whose sync tokens
+// are older have to sync again from scratch.
+func leaveProjects(tx *gorm.DB, projectIDs []uuid.UUID, seq int64) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+	return tx.Session(&gorm.Session{NewDB: true}).Model(&models.Project{}).Where("id IN ?", projectIDs).
+		UpdateColumn("left_seq", seq).Error
+}
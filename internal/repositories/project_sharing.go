@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// memberRow is a project member with the email of their account
+type memberRow struct {
+	models.ProjectMember
+	MemberEmail string
+}
+
+// GetProjectMembers retrieves the members of a project with their emails, in the order they joined
+func (r *ProjectRepository) GetProjectMembers(projectID uuid.UUID) ([]models.ProjectMember, error) {
+	var rows []memberRow
+	err := r.db.Model(&models.ProjectMember{}).Select("project_members.*, users.email AS member_email").
+		Joins("JOIN users ON users.id = project_members.user_id").
+		Where("project_members.project_id = ?", projectID).
+		Order("project_members.created_at, project_members.user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	members := make([]models.ProjectMember, len(rows))
+	for i, row := range rows {
+		members[i] = row.ProjectMember
+		members[i].Email = row.MemberEmail
+	}
+	return members, nil
+}
+
+// UpdateProjectMember writes the role of a project member
+func (r *ProjectRepository) UpdateProjectMember(member *models.ProjectMember) error {
+	result := r.db.Model(member).Where("project_id = ? AND user_id = ?", member.ProjectID, member.UserID).
+		Select("role", "updated_at").Updates(member)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// RemoveProjectMember takes a user out of a project. The project's tasks assigned to them, trashed
+// ones included, are left unassigned.
+func (r *ProjectRepository) RemoveProjectMember(projectID uuid.UUID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("project_id = ? AND user_id = ?", projectID, userID).Delete(&models.ProjectMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		var project models.Project
+		if err := tx.Select("user_id").Where("id = ?", projectID).Take(&project).Error; err != nil {
+			return err
+		}
+		return unassignTasks(tx.Unscoped(), project.UserID, "project_id = ? AND assignee_id = ?", projectID, userID)
+	})
+}
+
+// CreateInvitation creates a new project invitation in the database
+func (r *ProjectRepository) CreateInvitation(invitation *models.ProjectInvitation) error {
+	return r.db.Create(invitation).Error
+}
+
+// GetInvitationByID retrieves a project invitation by its ID
+func (r *ProjectRepository) GetInvitationByID(id uuid.UUID) (*models.ProjectInvitation, error) {
+	var invitation models.ProjectInvitation
+	err := r.db.Where("id = ?", id).First(&invitation).Error
+	return &invitation, err
+}
+
+// GetPendingInvitations retrieves the invitations to a project that are not answered yet, oldest first
+func (r *ProjectRepository) GetPendingInvitations(projectID uuid.UUID) ([]models.ProjectInvitation, error) {
+	var invitations []models.ProjectInvitation
+	err := r.db.Where("project_id = ? AND status = ?", projectID, models.InvitationPending).
+		Order("created_at, id").Find(&invitations).Error
+	return invitations, err
+}
+
+// invitationRow is a project invitation with the name of its project
+type invitationRow struct {
+	models.ProjectInvitation
+	ProjectName string
+}
+
+// GetPendingInvitationsByInvitee retrieves the invitations a user has not answered yet, oldest
+// first, with the names of their projects
+func (r *ProjectRepository) GetPendingInvitationsByInvitee(inviteeID uuid.UUID) ([]models.ProjectInvitation, error) {
+	var rows []invitationRow
+	err := r.db.Model(&models.ProjectInvitation{}).Select("project_invitations.*, projects.name AS project_name").
+		Joins("JOIN projects ON projects.id = project_invitations.project_id").
+		Where("project_invitations.invitee_id = ? AND project_invitations.status = ?", inviteeID, models.InvitationPending).
+		Order("project_invitations.created_at, project_invitations.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	invitations := make([]models.ProjectInvitation, len(rows))
+	for i, row := range rows {
+		invitations[i] = row.ProjectInvitation
+		invitations[i].ProjectName = row.ProjectName
+	}
+	return invitations, nil
+}
+
+// UpdateInvitation writes the role and status of a project invitation
+func (r *ProjectRepository) UpdateInvitation(invitation *models.ProjectInvitation) error {
+	result := r.db.Model(invitation).Select("role", "status", "updated_at").Updates(invitation)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// DeleteInvitation deletes a project invitation
+func (r *ProjectRepository) DeleteInvitation(id uuid.UUID) error {
+	result := r.db.Where("id = ?", id).Delete(&models.ProjectInvitation{})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// AcceptInvitation marks a pending invitation accepted and makes the invitee a member of the
+// project with the invitation's role, replacing any role they had. gorm.ErrRecordNotFound means
+// the invitation was answered or withdrawn in the meantime.
+func (r *ProjectRepository) AcceptInvitation(invitation *models.ProjectInvitation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(invitation).Where("status = ?", models.InvitationPending).
+			Update("status", models.InvitationAccepted)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		member := models.ProjectMember{ProjectID: invitation.ProjectID, UserID: invitation.InviteeID, Role: invitation.Role}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+		}).Create(&member).Error
+	})
+}
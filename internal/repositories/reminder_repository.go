@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrReminderLeaseLost is returned when a claimed reminder is released by a scheduler that no
+// longer holds its lease
+var ErrReminderLeaseLost = errors.New("reminder lease lost")
+
+// ReminderRepositoryInterface defines the methods for interacting with reminder data
+type ReminderRepositoryInterface interface {
+	CreateReminder(reminder *models.Reminder) error
+	GetReminderByID(id uuid.UUID, userID uuid.UUID) (*models.Reminder, error)
+	GetRemindersByTaskID(taskID uuid.UUID, userID uuid.UUID) ([]models.Reminder, error)
+	GetAllRemindersByTaskID(taskID uuid.UUID) ([]models.Reminder, error)
+	GetUpcomingReminders(userID uuid.UUID) ([]models.Reminder, error)
+	UpdateReminder(reminder *models.Reminder) error
+	DeleteReminder(id uuid.UUID, userID uuid.UUID) error
+	ClaimDueReminders(now time.Time, leaseExpiredBefore time.Time, limit int, lockedBy string) ([]models.Reminder, error)
+	ReleaseReminder(reminder *models.Reminder) error
+}
+
+// ReminderRepository handles database operations for reminders
+type ReminderRepository struct {
+	db *gorm.DB
+}
+
+// NewReminderRepository creates a new ReminderRepository
+func NewReminderRepository(db *gorm.DB) *ReminderRepository {
+	return &ReminderRepository{db: db}
+}
+
+// CreateReminder creates a new reminder in the database
+func (r *ReminderRepository) CreateReminder(reminder *models.Reminder) error {
+	return r.db.Create(reminder).Error
+}
+
+// GetReminderByID retrieves a reminder by its ID
+func (r *ReminderRepository) GetReminderByID(id uuid.UUID, userID uuid.UUID) (*models.Reminder, error) {
+	var reminder models.Reminder
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&reminder).Error
+	return &reminder, err
+}
+
+// GetRemindersByTaskID retrieves the reminders the user set on a task, oldest first
+func (r *ReminderRepository) GetRemindersByTaskID(taskID uuid.UUID, userID uuid.UUID) ([]models.Reminder, error) {
+	var reminders []models.Reminder
+	err := r.db.Where("task_id = ? AND user_id = ?", taskID, userID).Order("created_at, id").Find(&reminders).Error
+	return reminders, err
+}
+
+// GetAllRemindersByTaskID retrieves the reminders every member of its project set on a task,
+// oldest first
+func (r *ReminderRepository) GetAllRemindersByTaskID(taskID uuid.UUID) ([]models.Reminder, error) {
+	var reminders []models.Reminder
+	err := r.db.Where("task_id = ?", taskID).Order("created_at, id").Find(&reminders).Error
+	return reminders, err
+}
+
+// GetUpcomingReminders retrieves the user's scheduled reminders on open tasks, soonest first
+func (r *ReminderRepository) GetUpcomingReminders(userID uuid.UUID) ([]models.Reminder, error) {
+	var reminders []models.Reminder
+	err := r.db.Joins("JOIN tasks ON tasks.id = reminders.task_id").
+		Where("reminders.user_id = ? AND reminders.status = ? AND reminders.fire_at IS NOT NULL", userID, models.ReminderPending).
+		Where("tasks.deleted_at IS NULL AND tasks.completed = ?", false).
+		Order("reminders.fire_at, reminders.id").
+		Find(&reminders).Error
+	return reminders, err
+}
+
+// UpdateReminder updates an existing reminder in the database
+func (r *ReminderRepository) UpdateReminder(reminder *models.Reminder) error {
+	return r.db.Save(reminder).Error
+}
+
+// DeleteReminder deletes a reminder
+func (r *ReminderRepository) DeleteReminder(id uuid.UUID, userID uuid.UUID) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Reminder{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ClaimDueReminders leases up to limit reminders that are due, marking them as being sent by
+// lockedBy. Reminders left claimed by a scheduler that died are reclaimed once their lease has
+// expired, that is when they were locked before leaseExpiredBefore. Reminders on completed or
+// deleted tasks are left alone.
+func (r *ReminderRepository) ClaimDueReminders(now time.Time, leaseExpiredBefore time.Time, limit int, lockedBy string) ([]models.Reminder, error) {
+	now = now.UTC()
+
+	// SQLite has no row locks and serialises writers anyway; it is only used for tests and local runs
+	lockClause := " FOR UPDATE OF reminders SKIP LOCKED"
+	if r.db.Dialector.Name() == "sqlite" {
+		lockClause = ""
+	}
+
+	var reminders []models.Reminder
+	err := r.db.Raw(`
+		UPDATE reminders
+		SET status = ?, attempts = attempts + 1, locked_at = ?, locked_by = ?, updated_at = ?
+		WHERE id IN (
+			SELECT reminders.id FROM reminders
+			JOIN tasks ON tasks.id = reminders.task_id
+			WHERE ((reminders.status = ? AND reminders.fire_at <= ?) OR (reminders.status = ? AND reminders.locked_at < ?))
+			AND tasks.deleted_at IS NULL AND tasks.completed = ?
+			ORDER BY reminders.fire_at
+			LIMIT ?`+lockClause+`
+		)
+		RETURNING *`,
+		models.ReminderSending, now, lockedBy, now,
+		models.ReminderPending, now, models.ReminderSending, leaseExpiredBefore.UTC(), false,
+		limit,
+	).Scan(&reminders).Error
+	return reminders, err
+}
+
+// ReleaseReminder writes the outcome of a claimed reminder, provided the claiming scheduler
+// still holds the lease
+func (r *ReminderRepository) ReleaseReminder(reminder *models.Reminder) error {
+	lockedBy := reminder.LockedBy
+	reminder.LockedAt = nil
+	reminder.LockedBy = ""
+
+	result := r.db.Model(&models.Reminder{}).
+		Where("id = ? AND status = ? AND locked_by = ?", reminder.ID, models.ReminderSending, lockedBy).
+		Select("status", "fire_at", "last_error", "sent_at", "locked_at", "locked_by", "updated_at").
+		Updates(reminder)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrReminderLeaseLost
+	}
+	return nil
+}
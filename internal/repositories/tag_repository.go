@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"strings"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TagRepositoryInterface defines the methods for interacting with tag data
+type TagRepositoryInterface interface {
+	CreateTag(tag *models.Tag) error
+	GetTagByID(id uuid.UUID, userID uuid.UUID) (*models.Tag, error)
+	GetTagsByNames(names []string, userID uuid.UUID) ([]models.Tag, error)
+	GetTagsByUserID(userID uuid.UUID) ([]models.Tag, error)
+	UpdateTag(tag *models.Tag) error
+	DeleteTag(id uuid.UUID, userID uuid.UUID) error
+	AddTaskTags(taskID uuid.UUID, tagIDs []uuid.UUID, userID uuid.UUID) error
+	RemoveTaskTag(taskID uuid.UUID, tagID uuid.UUID, userID uuid.UUID) error
+}
+
+// TagRepository handles database operations for tags and the tags on tasks
+type TagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository creates a new TagRepository
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// CreateTag creates a new tag in the database
+func (r *TagRepository) CreateTag(tag *models.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+// GetTagByID retrieves a tag by its ID
+func (r *TagRepository) GetTagByID(id uuid.UUID, userID uuid.UUID) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&tag).Error
+	return &tag, err
+}
+
+// GetTagsByNames retrieves the user's tags with the given names, ignoring case. Names without
+// a tag are skipped.
+func (r *TagRepository) GetTagsByNames(names []string, userID uuid.UUID) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	var tags []models.Tag
+	err := r.db.Where("user_id = ? AND LOWER(name) IN ?", userID, lowerNames(names)).Order("name, id").Find(&tags).Error
+	return tags, err
+}
+
+// GetTagsByUserID retrieves all of the user's tags, sorted by name
+func (r *TagRepository) GetTagsByUserID(userID uuid.UUID) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.db.Where("user_id = ?", userID).Order("LOWER(name), id").Find(&tags).Error
+	return tags, err
+}
+
+// UpdateTag renames or recolours a tag. The tasks it is on change with it, for syncing clients.
+func (r *TagRepository) UpdateTag(tag *models.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(tag).Where("user_id = ?", tag.UserID).Select("name", "color", "updated_at").Updates(tag)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return touchTaggedTasks(tx, tag.ID, tag.UserID)
+	})
+}
+
+// DeleteTag deletes a tag and takes it off every task
+func (r *TagRepository) DeleteTag(id uuid.UUID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := touchTaggedTasks(tx, id, userID); err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", id).Delete(&models.TaskTag{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Tag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// AddTaskTags puts tags on one of the user's tasks. Tags already on the task are left alone.
+func (r *TagRepository) AddTaskTags(taskID uuid.UUID, tagIDs []uuid.UUID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		added, err := insertTaskTags(tx, taskID, tagIDs)
+		if err != nil || added == 0 {
+			return err
+		}
+		return touchTasks(tx, []uuid.UUID{taskID}, userID)
+	})
+}
+
+// RemoveTaskTag takes a tag off one of the user's tasks
+func (r *TagRepository) RemoveTaskTag(taskID uuid.UUID, tagID uuid.UUID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("task_id = ? AND tag_id = ?", taskID, tagID).Delete(&models.TaskTag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return touchTasks(tx, []uuid.UUID{taskID}, userID)
+	})
+}
+
+// insertTaskTags puts tags on a task, skipping those already on it, and returns how many were added
+func insertTaskTags(tx *gorm.DB, taskID uuid.UUID, tagIDs []uuid.UUID) (int64, error) {
+	if len(tagIDs) == 0 {
+		return 0, nil
+	}
+	rows := make([]models.TaskTag, len(tagIDs))
+	for i, tagID := range tagIDs {
+		rows[i] = models.TaskTag{TaskID: taskID, TagID: tagID}
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows)
+	return result.RowsAffected, result.Error
+}
+
+// touchTaggedTasks records a change to every task the tag is on
+func touchTaggedTasks(tx *gorm.DB, tagID uuid.UUID, userID uuid.UUID) error {
+	var taskIDs []uuid.UUID
+	if err := tx.Model(&models.TaskTag{}).Where("tag_id = ?", tagID).Pluck("task_id", &taskIDs).Error; err != nil {
+		return err
+	}
+	return touchTasks(tx, taskIDs, userID)
+}
+
+// touchTasks bumps the version and change sequence number of the user's tasks, whose tags changed
+func touchTasks(tx *gorm.DB, ids []uuid.UUID, userID uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	seq, err := nextChangeSeq(tx, userID)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.Task{}).Where("id IN ? AND user_id = ?", ids, userID).
+		Updates(map[string]interface{}{"version": gorm.Expr("version + 1"), "change_seq": seq}).Error
+}
+
+// taskTagRow is a tag on the task with TaskID
+type taskTagRow struct {
+	TaskID uuid.UUID
+	models.Tag
+}
+
+// attachTags loads the tags of each of the given tasks
+func attachTags(db *gorm.DB, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var rows []taskTagRow
+	err := db.Table("task_tags").
+		Select("task_tags.task_id, tags.id, tags.user_id, tags.name, tags.color, tags.created_at, tags.updated_at").
+		Joins("JOIN tags ON tags.id = task_tags.tag_id").
+		Where("task_tags.task_id IN ?", ids).
+		Order("LOWER(tags.name), tags.id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	tags := make(map[uuid.UUID][]models.Tag)
+	for _, row := range rows {
+		tags[row.TaskID] = append(tags[row.TaskID], row.Tag)
+	}
+	for i := range tasks {
+		tasks[i].Tags = tags[tasks[i].ID]
+	}
+	return nil
+}
+
+// lowerNames lowercases tag names for comparison with LOWER(name)
+func lowerNames(names []string) []string {
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+	return lowered
+}
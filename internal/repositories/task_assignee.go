@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// assignmentEvent is the assigned event for a task whose assignee changed from previous
+func assignmentEvent(task *models.Task, previous *uuid.UUID, actorID *uuid.UUID) models.TaskEvent {
+	return models.TaskEvent{
+		TaskID:             task.ID,
+		UserID:             task.UserID,
+		ActorID:            actorID,
+		Type:               models.TaskEventAssigned,
+		AssigneeID:         task.AssigneeID,
+		PreviousAssigneeID: previous,
+	}
+}
+
+// sameAssignee reports whether two optional assignees are both unset or the same user
+func sameAssignee(a *uuid.UUID, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// unassignTasks leaves the owner's assigned tasks matching the conditions unassigned, bumping
+// their version and change sequence number and recording an assigned event for each. Nobody
+// asked for these, so the events have no actor.
+func unassignTasks(tx *gorm.DB, ownerID uuid.UUID, query interface{}, args ...interface{}) error {
+	var tasks []models.Task
+	err := tx.Session(&gorm.Session{}).Model(&models.Task{}).Select("id", "user_id", "assignee_id").
+		Where("user_id = ? AND assignee_id IS NOT NULL", ownerID).Where(query, args...).Find(&tasks).Error
+	if err != nil || len(tasks) == 0 {
+		return err
+	}
+
+	seq, err := nextChangeSeq(tx, ownerID)
+	if err != nil {
+		return err
+	}
+	ids := make([]uuid.UUID, len(tasks))
+	events := make([]models.TaskEvent, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		previous := tasks[i].AssigneeID
+		tasks[i].AssigneeID = nil
+		events[i] = assignmentEvent(&tasks[i], previous, nil)
+	}
+	err = tx.Session(&gorm.Session{}).Model(&models.Task{}).Where("id IN ? AND user_id = ?", ids, ownerID).
+		Updates(map[string]interface{}{"assignee_id": nil, "version": gorm.Expr("version + 1"), "change_seq": seq}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Session(&gorm.Session{}).Create(&events).Error
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"errors"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrDependencyCycle is returned when a new blocker already waits, directly or through other
+// tasks, on the task it would block
+var ErrDependencyCycle = errors.New("dependency would create a cycle")
+
+// AddTaskBlockers makes one of the user's tasks wait on blockers. Blockers it already waits on
+// are left alone. Nothing is added when any of them would close a cycle.
+func (r *TaskRepository) AddTaskBlockers(taskID uuid.UUID, blockerIDs []uuid.UUID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Issuing the change sequence number first locks the user's counter row until the
+		// transaction ends, so two additions cannot both pass the cycle check and then close a
+		// cycle between them. Dependencies only link tasks of the same user.
+		seq, err := nextChangeSeq(tx, userID)
+		if err != nil {
+			return err
+		}
+		cycle, err := dependsOn(tx, blockerIDs, taskID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrDependencyCycle
+		}
+		added, err := insertTaskBlockers(tx, taskID, blockerIDs)
+		if err != nil || added == 0 {
+			return err
+		}
+		return tx.Model(&models.Task{}).Where("id = ? AND user_id = ?", taskID, userID).
+			Updates(map[string]interface{}{"version": gorm.Expr("version + 1"), "change_seq": seq}).Error
+	})
+}
+
+// RemoveTaskBlocker stops one of the user's tasks from waiting on a blocker
+func (r *TaskRepository) RemoveTaskBlocker(taskID uuid.UUID, blockerID uuid.UUID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("task_id = ? AND blocker_id = ?", taskID, blockerID).Delete(&models.TaskDependency{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return touchTasks(tx, []uuid.UUID{taskID}, userID)
+	})
+}
+
+// dependsOn reports whether any of the tasks waits on target, directly or through other tasks.
+// Dependencies on tasks in the trash count too, as those tasks may be restored.
+func dependsOn(tx *gorm.DB, ids []uuid.UUID, target uuid.UUID) (bool, error) {
+	seen := make(map[uuid.UUID]bool)
+	for len(ids) > 0 {
+		for _, id := range ids {
+			if id == target {
+				return true, nil
+			}
+			seen[id] = true
+		}
+		var blockers []uuid.UUID
+		if err := tx.Model(&models.TaskDependency{}).Where("task_id IN ?", ids).Distinct().Pluck("blocker_id", &blockers).Error; err != nil {
+			return false, err
+		}
+		var next []uuid.UUID
+		for _, id := range blockers {
+			if !seen[id] {
+				next = append(next, id)
+			}
+		}
+		ids = next
+	}
+	return false, nil
+}
+
+// insertTaskBlockers makes a task wait on blockers, skipping those it already waits on, and
+// returns how many were added
+func insertTaskBlockers(tx *gorm.DB, taskID uuid.UUID, blockerIDs []uuid.UUID) (int64, error) {
+	if len(blockerIDs) == 0 {
+		return 0, nil
+	}
+	rows := make([]models.TaskDependency, len(blockerIDs))
+	for i, blockerID := range blockerIDs {
+		rows[i] = models.TaskDependency{TaskID: taskID, BlockerID: blockerID}
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows)
+	return result.RowsAffected, result.Error
+}
+
+// touchDependents records a change, under the change sequence number seq, to the user's tasks
+// waiting on any of the given tasks, whose blocked flag may have changed with them
+func touchDependents(tx *gorm.DB, ids []uuid.UUID, userID uuid.UUID, seq int64) error {
+	var dependents []uuid.UUID
+	err := tx.Model(&models.TaskDependency{}).Where("blocker_id IN ?", ids).Distinct().Pluck("task_id", &dependents).Error
+	if err != nil || len(dependents) == 0 {
+		return err
+	}
+	return tx.Model(&models.Task{}).Where("id IN ? AND user_id = ?", dependents, userID).
+		Updates(map[string]interface{}{"version": gorm.Expr("version + 1"), "change_seq": seq}).Error
+}
+
+// taskBlockerRow is a blocker of the task with TaskID
+type taskBlockerRow struct {
+	TaskID    uuid.UUID
+	BlockerID uuid.UUID
+	Completed bool
+}
+
+// attachBlockers loads the blockers of each of the given tasks and derives their blocked flag.
+// Blockers in the trash are left out.
+func attachBlockers(db *gorm.DB, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var rows []taskBlockerRow
+	err := db.Table("task_dependencies").
+		Select("task_dependencies.task_id, task_dependencies.blocker_id, tasks.completed").
+		Joins("JOIN tasks ON tasks.id = task_dependencies.blocker_id AND tasks.deleted_at IS NULL").
+		Where("task_dependencies.task_id IN ?", ids).
+		Order("tasks.created_at, tasks.id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	blockers := make(map[uuid.UUID][]uuid.UUID)
+	blocked := make(map[uuid.UUID]bool)
+	for _, row := range rows {
+		blockers[row.TaskID] = append(blockers[row.TaskID], row.BlockerID)
+		blocked[row.TaskID] = blocked[row.TaskID] || !row.Completed
+	}
+	for i := range tasks {
+		tasks[i].BlockedBy = blockers[tasks[i].ID]
+		tasks[i].Blocked = blocked[tasks[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// Sortable task fields
+const (
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+	SortByDueDate   = "due_date"
+	SortByPriority  = "priority"
+)
+
+// Page size limits for ListTasks
+const (
+	DefaultTaskLimit = 50
+	MaxTaskLimit     = 200
+)
+
+// ErrInvalidCursor is returned for cursors that are malformed or belong to a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Undated tasks sort after dated ones in both directions
+var (
+	noDueDateAsc  = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+	noDueDateDesc = time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+const priorityRank = "CASE priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END"
+
+// TaskQuery selects, orders and pages the top-level tasks a user can see. Zero-valued filters are
+// not applied.
+type TaskQuery struct {
+	UserID        uuid.UUID
+	Completed     *bool
+	Priorities    []string
+	DueBefore     *time.Time
+	DueAfter      *time.Time
+	OverdueAt     *time.Time // only tasks due before this instant that are not completed
+	CreatedBefore *time.Time
+	CreatedAfter  *time.Time
+	TagsAny       []string // tag names, ignoring case; tasks with at least one of them
+	TagsAll       []string // tag names, ignoring case; tasks with every one of them
+	ProjectID     *uuid.UUID
+	Inbox         bool       // only tasks without a project
+	WithArchived  bool       // also tasks in archived projects, which are otherwise left out unless asked for by ProjectID
+	AssigneeID    *uuid.UUID // only tasks assigned to this user
+	Unassigned    bool       // only tasks without an assignee
+	SortBy        string     // one of the SortBy constants; empty for created_at
+	Descending    bool
+	Limit         int    // page size; zero for DefaultTaskLimit
+	Cursor        string // opaque cursor from a previous page's next cursor
+}
+
+// taskCursor is the position after the last task of a page. Sort and Desc tie it to one ordering.
+type taskCursor struct {
+	Sort string          `json:"s"`
+	Desc bool            `json:"d"`
+	Key  json.RawMessage `json:"k"`
+	ID   uuid.UUID       `json:"id"`
+}
+
+// ListTasks retrieves one page of the tasks matched by query, with the cursor of the next page,
+// or an empty cursor on the last page
+func (r *TaskRepository) ListTasks(query TaskQuery) ([]models.Task, string, error) {
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = SortByCreatedAt
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultTaskLimit
+	}
+	if limit > MaxTaskLimit {
+		limit = MaxTaskLimit
+	}
+
+	db := visibleTasks(r.db.Model(&models.Task{}), query.UserID).Where("parent_id IS NULL")
+	if query.Completed != nil {
+		db = db.Where("completed = ?", *query.Completed)
+	}
+	if len(query.Priorities) > 0 {
+		db = db.Where("priority IN ?", query.Priorities)
+	}
+	if query.DueBefore != nil {
+		db = db.Where("due_date < ?", query.DueBefore.UTC())
+	}
+	if query.DueAfter != nil {
+		db = db.Where("due_date >= ?", query.DueAfter.UTC())
+	}
+	if query.OverdueAt != nil {
+		db = db.Where("due_date < ? AND completed = ?", query.OverdueAt.UTC(), false)
+	}
+	if query.CreatedBefore != nil {
+		db = db.Where("created_at < ?", query.CreatedBefore)
+	}
+	if query.CreatedAfter != nil {
+		db = db.Where("created_at >= ?", query.CreatedAfter)
+	}
+	// Tags belong to the task's owner, who is not the user for the tasks of shared projects
+	if len(query.TagsAny) > 0 {
+		db = db.Where("id IN (?)", r.db.Table("task_tags").Select("task_tags.task_id").
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Where("LOWER(tags.name) IN ?", lowerNames(query.TagsAny)))
+	}
+	if len(query.TagsAll) > 0 {
+		names := distinctNames(lowerNames(query.TagsAll))
+		db = db.Where("id IN (?)", r.db.Table("task_tags").Select("task_tags.task_id").
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Where("LOWER(tags.name) IN ?", names).
+			Group("task_tags.task_id").
+			Having("COUNT(DISTINCT LOWER(tags.name)) = ?", len(names)))
+	}
+	if query.AssigneeID != nil {
+		db = db.Where("assignee_id = ?", *query.AssigneeID)
+	} else if query.Unassigned {
+		db = db.Where("assignee_id IS NULL")
+	}
+	switch {
+	case query.ProjectID != nil:
+		db = db.Where("project_id = ?", *query.ProjectID)
+	case query.Inbox:
+		db = db.Where("project_id IS NULL")
+	case !query.WithArchived:
+		db = db.Where("project_id IS NULL OR project_id NOT IN (?)", r.db.Model(&models.Project{}).Select("id").
+			Where("archived = ?", true).Where("id IN (?)", memberProjectIDs(r.db, query.UserID)))
+	}
+
+	keyExpr, keyArgs, err := sortKey(sortBy, query.Descending)
+	if err != nil {
+		return nil, "", err
+	}
+	op, direction := ">", "ASC"
+	if query.Descending {
+		op, direction = "<", "DESC"
+	}
+
+	if query.Cursor != "" {
+		cursor, key, err := decodeCursor(query.Cursor, sortBy, query.Descending)
+		if err != nil {
+			return nil, "", err
+		}
+		// Keyset condition: (key, id) strictly after the cursor in the sort direction
+		args := append([]interface{}{}, keyArgs...)
+		args = append(args, key)
+		args = append(args, keyArgs...)
+		args = append(args, key, cursor.ID)
+		db = db.Where(fmt.Sprintf("(%[1]s %[2]s ?) OR (%[1]s = ? AND id %[2]s ?)", keyExpr, op), args...)
+	}
+
+	var tasks []models.Task
+	err = db.Clauses(clause.OrderBy{Expression: clause.Expr{
+		SQL:                keyExpr + " " + direction + ", id " + direction,
+		Vars:               keyArgs,
+		WithoutParentheses: true,
+	}}).Limit(limit + 1).Find(&tasks).Error
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+		next, err = encodeCursor(tasks[limit-1], sortBy, query.Descending)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if err := attachDetails(r.db, tasks); err != nil {
+		return nil, "", err
+	}
+	return tasks, next, nil
+}
+
+// sortKey returns the SQL expression tasks are ordered by, with its arguments
+func sortKey(sortBy string, descending bool) (string, []interface{}, error) {
+	switch sortBy {
+	case SortByCreatedAt, SortByUpdatedAt:
+		return sortBy, nil, nil
+	case SortByDueDate:
+		if descending {
+			return "COALESCE(due_date, ?)", []interface{}{noDueDateDesc}, nil
+		}
+		return "COALESCE(due_date, ?)", []interface{}{noDueDateAsc}, nil
+	case SortByPriority:
+		return priorityRank, nil, nil
+	}
+	return "", nil, fmt.Errorf("unsupported sort field: %s", sortBy)
+}
+
+func encodeCursor(last models.Task, sortBy string, descending bool) (string, error) {
+	var key interface{}
+	switch sortBy {
+	case SortByCreatedAt:
+		key = last.CreatedAt
+	case SortByUpdatedAt:
+		key = last.UpdatedAt
+	case SortByDueDate:
+		switch {
+		case last.DueDate != nil:
+			key = last.DueDate.UTC()
+		case descending:
+			key = noDueDateDesc
+		default:
+			key = noDueDateAsc
+		}
+	case SortByPriority:
+		key = models.PriorityRank(last.Priority)
+	}
+
+	rawKey, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	raw, err := json.Marshal(taskCursor{Sort: sortBy, Desc: descending, Key: rawKey, ID: last.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(encoded string, sortBy string, descending bool) (*taskCursor, interface{}, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, ErrInvalidCursor
+	}
+	var cursor taskCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Sort != sortBy || cursor.Desc != descending {
+		return nil, nil, ErrInvalidCursor
+	}
+
+	if sortBy == SortByPriority {
+		var rank int
+		if err := json.Unmarshal(cursor.Key, &rank); err != nil {
+			return nil, nil, ErrInvalidCursor
+		}
+		return &cursor, rank, nil
+	}
+	var key time.Time
+	if err := json.Unmarshal(cursor.Key, &key); err != nil {
+		return nil, nil, ErrInvalidCursor
+	}
+	return &cursor, key, nil
+}
+
+// distinctNames removes repeated names
+func distinctNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	distinct := names[:0:0]
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			distinct = append(distinct, name)
+		}
+	}
+	return distinct
+}
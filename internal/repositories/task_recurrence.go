@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"todo-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CreateNextOccurrence creates the next occurrence of a repeating task, together with its
+// Subtasks tree, unless the series already has an occurrence at or beyond next.RecurrenceIndex.
+// It reports whether the occurrence was created; completing, reopening and completing a task
+// again therefore generates its successor only once.
+func (r *TaskRepository) CreateNextOccurrence(next *models.Task) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		err := tx.Unscoped().Model(&models.Task{}).
+			Where("user_id = ? AND series_id = ? AND recurrence_index >= ?", next.UserID, next.SeriesID, next.RecurrenceIndex).
+			Count(&existing).Error
+		if err != nil || existing > 0 {
+			return err
+		}
+
+		seq, err := nextChangeSeq(tx, next.UserID)
+		if err != nil {
+			return err
+		}
+		// Nobody created the occurrence but the server
+		if err := createTaskTree(tx, next, seq, nil); err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
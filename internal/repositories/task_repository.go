@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"errors"
+	"time"
 	"todo-backend/internal/models"
 
 	"github.com/google/uuid"
@@ -9,11 +11,43 @@ import (
 
 // TaskRepositoryInterface defines the methods for interacting with task data
 type TaskRepositoryInterface interface {
-	CreateTask(task *models.Task) error
+	CreateTask(task *models.Task, actorID uuid.UUID) error
 	GetTaskByID(id uuid.UUID, userID uuid.UUID) (*models.Task, error)
 	GetTasksByUserID(userID uuid.UUID) ([]models.Task, error)
-	UpdateTask(task *models.Task) error
-	DeleteTask(id uuid.UUID, userID uuid.UUID) error
+	ListTasks(query TaskQuery) ([]models.Task, string, error)
+	SearchTasks(search TaskSearch) ([]models.TaskSearchResult, error)
+	GetTasksByIDs(ids []uuid.UUID, userID uuid.UUID) ([]models.Task, error)
+	GetTasksBySource(sourceID uuid.UUID, userID uuid.UUID) ([]models.Task, error)
+	GetSubtasksByParentIDs(parentIDs []uuid.UUID, userID uuid.UUID) ([]models.Task, error)
+	GetDescendantIDs(id uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error)
+	UpdateTask(task *models.Task, actorID uuid.UUID, cond TaskPrecondition) error
+	UpdateTaskPositions(positions map[uuid.UUID]int, userID uuid.UUID) error
+	SetTasksCompleted(ids []uuid.UUID, userID uuid.UUID, completed bool, actorID uuid.UUID) error
+	GetTaskEvents(taskID uuid.UUID, userID uuid.UUID) ([]models.TaskEvent, error)
+	DeleteTask(id uuid.UUID, userID uuid.UUID, cond TaskPrecondition) error
+	GetTaskChanges(userID uuid.UUID, since int64, limit int) ([]models.Task, error)
+	GetSharedTaskChanges(projectID uuid.UUID, userID uuid.UUID, since int64, limit int) ([]models.Task, error)
+	GetSharedProjects(userID uuid.UUID) ([]models.Project, error)
+	GetChangeCounter(userID uuid.UUID) (*models.TaskChangeCounter, error)
+	GetDeletedTasks(userID uuid.UUID) ([]models.Task, error)
+	GetDeletedTaskByID(id uuid.UUID, userID uuid.UUID) (*models.Task, error)
+	RestoreTask(id uuid.UUID, userID uuid.UUID) error
+	PurgeTask(id uuid.UUID, userID uuid.UUID) error
+	PurgeDeletedTasks(userID uuid.UUID) (int64, error)
+	PurgeTasksDeletedBefore(before time.Time) (int64, error)
+	CreateNextOccurrence(next *models.Task) (bool, error)
+	AddTaskBlockers(taskID uuid.UUID, blockerIDs []uuid.UUID, userID uuid.UUID) error
+	RemoveTaskBlocker(taskID uuid.UUID, blockerID uuid.UUID, userID uuid.UUID) error
+}
+
+// ErrVersionConflict is returned when a task was changed after the version the caller expected
+var ErrVersionConflict = errors.New("task has been modified")
+
+// TaskPrecondition is what a task must look like for a write to it to go ahead; the write fails
+// with ErrVersionConflict otherwise. The zero value always holds.
+type TaskPrecondition struct {
+	Version int                          // the task's version, or 0 for any
+	Match   func(task *models.Task) bool // a test on the task as served, with its details and subtasks, or nil
 }
 
 // TaskRepository handles database operations for tasks
@@ -26,35 +60,492 @@ func NewTaskRepository(db *gorm.DB) *TaskRepository {
 	return &TaskRepository{db: db}
 }
 
-// CreateTask creates a new task in the database
-func (r *TaskRepository) CreateTask(task *models.Task) error {
-	return r.db.Create(task).Error
+// CreateTask creates a new task in the database, together with its Subtasks tree, in a single
+// transaction, and records that actorID created each of them
+func (r *TaskRepository) CreateTask(task *models.Task, actorID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		seq, err := nextChangeSeq(tx, task.UserID)
+		if err != nil {
+			return err
+		}
+		return createTaskTree(tx, task, seq, &actorID)
+	})
+}
+
+func createTaskTree(tx *gorm.DB, task *models.Task, seq int64, actorID *uuid.UUID) error {
+	task.ChangeSeq = seq
+	task.CreatedSeq = seq
+	if err := tx.Create(task).Error; err != nil {
+		return err
+	}
+	events := []models.TaskEvent{{TaskID: task.ID, UserID: task.UserID, ActorID: actorID, Type: models.TaskEventCreated}}
+	if task.AssigneeID != nil {
+		events = append(events, assignmentEvent(task, nil, actorID))
+	}
+	if err := tx.Create(&events).Error; err != nil {
+		return err
+	}
+	tagIDs := make([]uuid.UUID, len(task.Tags))
+	for i, tag := range task.Tags {
+		tagIDs[i] = tag.ID
+	}
+	if _, err := insertTaskTags(tx, task.ID, tagIDs); err != nil {
+		return err
+	}
+	if _, err := insertTaskBlockers(tx, task.ID, task.BlockedBy); err != nil {
+		return err
+	}
+	for i := range task.Subtasks {
+		subtask := &task.Subtasks[i]
+		subtask.ParentID = &task.ID
+		subtask.UserID = task.UserID
+		subtask.ProjectID = task.ProjectID
+		if err := createTaskTree(tx, subtask, seq, actorID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// GetTaskByID retrieves a task by its ID
+// GetTaskByID retrieves a task by its ID, if the user can see it
 func (r *TaskRepository) GetTaskByID(id uuid.UUID, userID uuid.UUID) (*models.Task, error) {
 	var task models.Task
-	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&task).Error
-	return &task, err
+	err := visibleTasks(r.db, userID).Where("id = ?", id).First(&task).Error
+	if err != nil {
+		return &task, err
+	}
+	tasks := []models.Task{task}
+	err = attachDetails(r.db, tasks)
+	return &tasks[0], err
 }
 
-// GetTasksByUserID retrieves all tasks for a given user ID
+// GetTasksByUserID retrieves all top-level tasks the user can see; subtasks are loaded through their parents
 func (r *TaskRepository) GetTasksByUserID(userID uuid.UUID) ([]models.Task, error) {
 	var tasks []models.Task
-	err := r.db.Where("user_id = ?", userID).Find(&tasks).Error
-	return tasks, err
+	if err := visibleTasks(r.db, userID).Where("parent_id IS NULL").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, attachDetails(r.db, tasks)
+}
+
+// GetTasksByIDs retrieves the tasks with the given IDs that the user can see
+func (r *TaskRepository) GetTasksByIDs(ids []uuid.UUID, userID uuid.UUID) ([]models.Task, error) {
+	var tasks []models.Task
+	if len(ids) == 0 {
+		return tasks, nil
+	}
+	if err := visibleTasks(r.db, userID).Where("id IN ?", ids).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, attachDetails(r.db, tasks)
+}
+
+// GetTasksBySource retrieves the top-level tasks extracted from a job or audio upload that the
+// user can see, in the order they were created
+func (r *TaskRepository) GetTasksBySource(sourceID uuid.UUID, userID uuid.UUID) ([]models.Task, error) {
+	var tasks []models.Task
+	if err := visibleTasks(r.db, userID).Where("source_id = ? AND parent_id IS NULL", sourceID).Order("created_seq, created_at").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, attachDetails(r.db, tasks)
+}
+
+// GetSubtasksByParentIDs retrieves the direct subtasks of the given tasks, ordered by position
+func (r *TaskRepository) GetSubtasksByParentIDs(parentIDs []uuid.UUID, userID uuid.UUID) ([]models.Task, error) {
+	var tasks []models.Task
+	if len(parentIDs) == 0 {
+		return tasks, nil
+	}
+	if err := visibleTasks(r.db, userID).Where("parent_id IN ?", parentIDs).Order("position, created_at").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, attachDetails(r.db, tasks)
+}
+
+// GetDescendantIDs retrieves the IDs of all subtasks below a task, at any depth
+func (r *TaskRepository) GetDescendantIDs(id uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error) {
+	return descendantIDs(r.db.Where("user_id = ?", userID), []uuid.UUID{id})
+}
+
+// descendantIDs walks the subtask tree one level at a time below the given parents
+func descendantIDs(scope *gorm.DB, parentIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var all []uuid.UUID
+	for len(parentIDs) > 0 {
+		var children []uuid.UUID
+		if err := scope.Session(&gorm.Session{}).Model(&models.Task{}).Where("parent_id IN ?", parentIDs).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+		all = append(all, children...)
+		parentIDs = children
+	}
+	return all, nil
+}
+
+// UpdateTask writes all fields of an existing task, provided its stored version still matches
+// task.Version and it holds to cond, and bumps the version. ErrVersionConflict means someone else
+// changed it first. Changes to the title, due date, completion and assignee are recorded as events
+// by actorID; a change of completion sets or clears CompletedAt, and the tasks waiting on the task
+// change too.
+func (r *TaskRepository) UpdateTask(task *models.Task, actorID uuid.UUID, cond TaskPrecondition) error {
+	expected, changeSeq, completedAt := task.Version, task.ChangeSeq, task.CompletedAt
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		seq, err := nextChangeSeq(tx, task.UserID)
+		if err != nil {
+			return err
+		}
+		if err := checkPrecondition(tx, task.ID, task.UserID, cond); err != nil {
+			return err
+		}
+		var stored models.Task
+		if err := tx.Select("title", "due_date", "project_id", "assignee_id", "completed").Where("id = ? AND user_id = ?", task.ID, task.UserID).Limit(1).Find(&stored).Error; err != nil {
+			return err
+		}
+		if task.Completed != stored.Completed {
+			task.CompletedAt = nil
+			if task.Completed {
+				now := time.Now().UTC()
+				task.CompletedAt = &now
+			}
+		}
+		task.Version = expected + 1
+		task.ChangeSeq = seq
+		result := tx.Model(task).Where("user_id = ? AND version = ?", task.UserID, expected).
+			Select("*").Omit("id", "user_id", "created_at", "created_seq", "deleted_at").Updates(task)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if task.Completed != stored.Completed {
+			if err := touchDependents(tx, []uuid.UUID{task.ID}, task.UserID, seq); err != nil {
+				return err
+			}
+		}
+		if stored.ProjectID != nil && (task.ProjectID == nil || *task.ProjectID != *stored.ProjectID) {
+			if err := leaveProjects(tx, []uuid.UUID{*stored.ProjectID}, seq); err != nil {
+				return err
+			}
+		}
+		if events := changeEvents(&stored, task, &actorID); len(events) > 0 {
+			return tx.Create(&events).Error
+		}
+		return nil
+	})
+	if err != nil {
+		task.Version, task.ChangeSeq, task.CompletedAt = expected, changeSeq, completedAt
+	}
+	return err
+}
+
+// checkPrecondition fails with ErrVersionConflict unless one of the user's tasks holds to cond as
+// it is stored now. The caller takes the user's change sequence in tx first: every change to
+// what a task looks like as served takes it too, so the task stays as checked until tx ends.
+func checkPrecondition(tx *gorm.DB, id uuid.UUID, userID uuid.UUID, cond TaskPrecondition) error {
+	if cond.Version == 0 && cond.Match == nil {
+		return nil
+	}
+	var tasks []models.Task
+	if err := tx.Where("id = ? AND user_id = ?", id, userID).Limit(1).Find(&tasks).Error; err != nil {
+		return err
+	}
+	if len(tasks) == 0 || (cond.Version != 0 && tasks[0].Version != cond.Version) {
+		return ErrVersionConflict
+	}
+	if cond.Match == nil {
+		return nil
+	}
+	if err := attachDetails(tx, tasks); err != nil {
+		return err
+	}
+	if err := attachSubtaskTrees(tx, tasks, userID); err != nil {
+		return err
+	}
+	if !cond.Match(&tasks[0]) {
+		return ErrVersionConflict
+	}
+	return nil
 }
 
-// UpdateTask updates an existing task in the database
-func (r *TaskRepository) UpdateTask(task *models.Task) error {
-	return r.db.Save(task).Error
+// attachSubtaskTrees loads the subtask trees below the given tasks of the user, as the task
+// service serves them, one query per level of depth
+func attachSubtaskTrees(tx *gorm.DB, tasks []models.Task, userID uuid.UUID) error {
+	parentIDs := make([]uuid.UUID, len(tasks))
+	for i, task := range tasks {
+		parentIDs[i] = task.ID
+	}
+	var children []models.Task
+	if err := tx.Where("parent_id IN ? AND user_id = ?", parentIDs, userID).Order("position, created_at").Find(&children).Error; err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		return nil
+	}
+	if err := attachDetails(tx, children); err != nil {
+		return err
+	}
+	if err := attachSubtaskTrees(tx, children, userID); err != nil {
+		return err
+	}
+
+	byParent := make(map[uuid.UUID][]models.Task)
+	for _, child := range children {
+		byParent[*child.ParentID] = append(byParent[*child.ParentID], child)
+	}
+	for i := range tasks {
+		tasks[i].Subtasks = byParent[tasks[i].ID]
+	}
+	return nil
 }
 
-// DeleteTask deletes a task from the database
-func (r *TaskRepository) DeleteTask(id uuid.UUID, userID uuid.UUID) error {
-	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Task{})
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+// changeEvents returns the events for the changes to a task's title, due date, completion and
+// assignee from before to after
+func changeEvents(before *models.Task, after *models.Task, actorID *uuid.UUID) []models.TaskEvent {
+	change := func(kind string, from string, to string) models.TaskEvent {
+		return models.TaskEvent{TaskID: after.ID, UserID: after.UserID, ActorID: actorID, Type: kind, From: from, To: to}
 	}
-	return result.Error
+	var events []models.TaskEvent
+	if before.Title != after.Title {
+		events = append(events, change(models.TaskEventTitleChanged, before.Title, after.Title))
+	}
+	if from, to := eventTime(before.DueDate), eventTime(after.DueDate); from != to {
+		events = append(events, change(models.TaskEventDueDateChanged, from, to))
+	}
+	if before.Completed != after.Completed {
+		kind := models.TaskEventReopened
+		if after.Completed {
+			kind = models.TaskEventCompleted
+		}
+		events = append(events, change(kind, "", ""))
+	}
+	if !sameAssignee(before.AssigneeID, after.AssigneeID) {
+		events = append(events, assignmentEvent(after, before.AssigneeID, actorID))
+	}
+	return events
+}
+
+// eventTime formats an optional time for an event, as RFC 3339 in UTC
+func eventTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// UpdateTaskPositions sets the position of each of the user's tasks in a single transaction
+func (r *TaskRepository) UpdateTaskPositions(positions map[uuid.UUID]int, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		seq, err := nextChangeSeq(tx, userID)
+		if err != nil {
+			return err
+		}
+		for id, position := range positions {
+			err := tx.Model(&models.Task{}).Where("id = ? AND user_id = ?", id, userID).
+				Updates(map[string]interface{}{"position": position, "version": gorm.Expr("version + 1"), "change_seq": seq}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetTasksCompleted marks the user's tasks with the given IDs as completed or not completed.
+// Tasks that change state get their CompletedAt set or cleared and a completed or reopened event
+// by actorID, and the tasks waiting on them change too.
+func (r *TaskRepository) SetTasksCompleted(ids []uuid.UUID, userID uuid.UUID, completed bool, actorID uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var changed []uuid.UUID
+		err := tx.Model(&models.Task{}).Where("id IN ? AND user_id = ? AND completed <> ?", ids, userID, completed).Pluck("id", &changed).Error
+		if err != nil || len(changed) == 0 {
+			return err
+		}
+
+		seq, err := nextChangeSeq(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		updates := map[string]interface{}{"completed": completed, "completed_at": nil, "version": gorm.Expr("version + 1"), "change_seq": seq}
+		eventType := models.TaskEventReopened
+		if completed {
+			updates["completed_at"] = now
+			eventType = models.TaskEventCompleted
+		}
+		if err := tx.Model(&models.Task{}).Where("id IN ? AND user_id = ?", changed, userID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if err := touchDependents(tx, changed, userID, seq); err != nil {
+			return err
+		}
+
+		events := make([]models.TaskEvent, len(changed))
+		for i, id := range changed {
+			events[i] = models.TaskEvent{TaskID: id, UserID: userID, ActorID: &actorID, Type: eventType, CreatedAt: now}
+		}
+		return tx.Create(&events).Error
+	})
+}
+
+// taskEventRow is a task event with the email of the user who did it
+type taskEventRow struct {
+	models.TaskEvent
+	UserEmail string
+}
+
+// GetTaskEvents retrieves the events recorded for one of the user's tasks with the emails of the
+// users who did them, oldest first
+func (r *TaskRepository) GetTaskEvents(taskID uuid.UUID, userID uuid.UUID) ([]models.TaskEvent, error) {
+	var rows []taskEventRow
+	err := r.db.Model(&models.TaskEvent{}).Select("task_events.*, users.email AS user_email").
+		Joins("LEFT JOIN users ON users.id = task_events.actor_id").
+		Where("task_events.task_id = ? AND task_events.user_id = ?", taskID, userID).
+		Order("task_events.created_at, task_events.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	events := make([]models.TaskEvent, len(rows))
+	for i, row := range rows {
+		events[i] = row.TaskEvent
+		events[i].ActorEmail = row.UserEmail
+	}
+	return events, nil
+}
+
+// DeleteTask moves a task and all of its subtasks to the trash, provided the task holds to cond.
+// The rows stay behind, marked with the same DeletedAt, until they are restored or purged; every
+// other query skips them. Tasks waiting on them change too, as they are no longer blocked by them.
+func (r *TaskRepository) DeleteTask(id uuid.UUID, userID uuid.UUID, cond TaskPrecondition) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		seq, err := nextChangeSeq(tx, userID)
+		if err != nil {
+			return err
+		}
+		if err := checkPrecondition(tx, id, userID, cond); err != nil {
+			return err
+		}
+		children, err := descendantIDs(tx.Where("user_id = ?", userID), []uuid.UUID{id})
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"deleted_at": time.Now().UTC(), "change_seq": seq}
+		result := tx.Model(&models.Task{}).Where("id = ? AND user_id = ?", id, userID).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if len(children) > 0 {
+			if err := tx.Model(&models.Task{}).Where("id IN ? AND user_id = ?", children, userID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return touchDependents(tx, append(children, id), userID, seq)
+	})
+}
+
+// Page sizes for GetTaskChanges
+const (
+	DefaultSyncLimit = 500
+	MaxSyncLimit     = 1000
+)
+
+// GetTaskChanges retrieves the tasks the user owns that changed after the change sequence
+// number since, deleted ones included, in the order they changed. At least limit tasks (zero for
+// DefaultSyncLimit) are returned when there are that many; tasks sharing the last one's sequence
+// number are all included, so a page never ends inside a change. Tasks already deleted are left
+// out when since is 0.
+func (r *TaskRepository) GetTaskChanges(userID uuid.UUID, since int64, limit int) ([]models.Task, error) {
+	return r.getTaskChanges(r.db.Unscoped().Where("tasks.user_id = ?", userID), since, limit)
+}
+
+// GetSharedTaskChanges is GetTaskChanges for the tasks of a project someone else owns and the
+// user is a member of. Their sequence numbers are the project owner's.
+func (r *TaskRepository) GetSharedTaskChanges(projectID uuid.UUID, userID uuid.UUID, since int64, limit int) ([]models.Task, error) {
+	return r.getTaskChanges(visibleTasks(r.db.Unscoped(), userID).Where("tasks.project_id = ?", projectID), since, limit)
+}
+
+// GetSharedProjects retrieves the projects the user is a member of but does not own, whose tasks
+// they sync separately from their own
+func (r *TaskRepository) GetSharedProjects(userID uuid.UUID) ([]models.Project, error) {
+	var projects []models.Project
+	err := r.db.Where("projects.user_id <> ? AND projects.id IN (?)", userID, memberProjectIDs(r.db, userID)).
+		Order("projects.id").Find(&projects).Error
+	return projects, err
+}
+
+// getTaskChanges pages through the tasks selected by base, which must be unscoped so that
+// deleted tasks are included
+func (r *TaskRepository) getTaskChanges(base *gorm.DB, since int64, limit int) ([]models.Task, error) {
+	if limit <= 0 {
+		limit = DefaultSyncLimit
+	}
+	if limit > MaxSyncLimit {
+		limit = MaxSyncLimit
+	}
+
+	scope := func() *gorm.DB {
+		query := base.Session(&gorm.Session{}).Where("tasks.change_seq > ?", since)
+		if since == 0 {
+			query = query.Where("tasks.deleted_at IS NULL")
+		}
+		return query
+	}
+
+	var tasks []models.Task
+	if err := scope().Order("tasks.change_seq, tasks.id").Limit(limit).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	if len(tasks) >= limit {
+		last := tasks[len(tasks)-1]
+		var rest []models.Task
+		if err := scope().Where("tasks.change_seq = ? AND tasks.id > ?", last.ChangeSeq, last.ID).Order("tasks.id").Find(&rest).Error; err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, rest...)
+	}
+	return tasks, attachDetails(r.db, tasks)
+}
+
+// GetChangeCounter retrieves the user's change counter, which is all zeros for users who have
+// never changed a task
+func (r *TaskRepository) GetChangeCounter(userID uuid.UUID) (*models.TaskChangeCounter, error) {
+	counter := models.TaskChangeCounter{UserID: userID}
+	var counters []models.TaskChangeCounter
+	if err := r.db.Where("user_id = ?", userID).Limit(1).Find(&counters).Error; err != nil {
+		return nil, err
+	}
+	if len(counters) > 0 {
+		counter = counters[0]
+	}
+	return &counter, nil
+}
+
+// attachDetails loads the tags, blockers and attachments of each of the given tasks
+func attachDetails(db *gorm.DB, tasks []models.Task) error {
+	if err := attachTags(db, tasks); err != nil {
+		return err
+	}
+	if err := attachBlockers(db, tasks); err != nil {
+		return err
+	}
+	return attachAttachments(db, tasks)
+}
+
+// nextChangeSeq issues the next number in the user's change sequence. The counter row stays
+// locked until tx ends, so the numbers of a user's changes follow the order they commit in.
+func nextChangeSeq(tx *gorm.DB, userID uuid.UUID) (int64, error) {
+	var seq int64
+	err := tx.Raw(`INSERT INTO task_change_counters (user_id, seq) VALUES (?, 1)
+		ON CONFLICT (user_id) DO UPDATE SET seq = task_change_counters.seq + 1
+		RETURNING seq`, userID).Scan(&seq).Error
+	return seq, err
 }
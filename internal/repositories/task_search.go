@@ -0,0 +1,259 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Result limits for SearchTasks
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// Text search configuration of the Postgres search_vector column; see migration 000008
+const searchConfig = "english"
+
+// Markers the database wraps hits in. They are control characters rather than the <mark> tags
+// clients get, so that the task text around them can be escaped first; see highlightSnippet.
+const (
+	highlightStart = "\x02"
+	highlightEnd   = "\x03"
+)
+
+// Weights of title, description and raw_text hits, mirroring the A, B and C weights of search_vector
+var searchColumnWeights = []float64{3, 2, 1}
+
+// TaskSearch selects the tasks the user can see, top-level or not, whose text matches Text
+type TaskSearch struct {
+	UserID uuid.UUID
+	Text   string
+	Limit  int // zero for DefaultSearchLimit
+}
+
+// taskSearchRow is a task with the rank and snippet columns selected alongside it
+type taskSearchRow struct {
+	models.Task
+	Rank    float64
+	Snippet string
+	Offsets string // FTS4 only
+}
+
+// SetupTaskSearch creates the full-text index on SQLite databases: an FTS5 table when the driver
+// is built with the sqlite_fts5 tag, FTS4 otherwise, kept in sync with tasks by triggers.
+// Postgres gets its index from migrations, so this does nothing there.
+func SetupTaskSearch(db *gorm.DB) error {
+	if db.Dialector.Name() != "sqlite" {
+		return nil
+	}
+
+	statements := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(title, description, raw_text, content='tasks', content_rowid='rowid')`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+			INSERT INTO tasks_fts(rowid, title, description, raw_text) VALUES (new.rowid, new.title, new.description, new.raw_text);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasks BEGIN
+			INSERT INTO tasks_fts(tasks_fts, rowid, title, description, raw_text) VALUES ('delete', old.rowid, old.title, old.description, old.raw_text);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE ON tasks BEGIN
+			INSERT INTO tasks_fts(tasks_fts, rowid, title, description, raw_text) VALUES ('delete', old.rowid, old.title, old.description, old.raw_text);
+			INSERT INTO tasks_fts(rowid, title, description, raw_text) VALUES (new.rowid, new.title, new.description, new.raw_text);
+		END`,
+	}
+	if err := db.Exec(statements[0]).Error; err != nil {
+		if !strings.Contains(err.Error(), "no such module") {
+			return err
+		}
+		// FTS4 reads the old values from tasks when deleting, so those triggers run before the change
+		statements = []string{
+			`CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts4(content="tasks", title, description, raw_text)`,
+			`CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+				INSERT INTO tasks_fts(docid, title, description, raw_text) VALUES (new.rowid, new.title, new.description, new.raw_text);
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS tasks_fts_delete BEFORE DELETE ON tasks BEGIN
+				DELETE FROM tasks_fts WHERE docid = old.rowid;
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS tasks_fts_update_before BEFORE UPDATE ON tasks BEGIN
+				DELETE FROM tasks_fts WHERE docid = old.rowid;
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS tasks_fts_update_after AFTER UPDATE ON tasks BEGIN
+				INSERT INTO tasks_fts(docid, title, description, raw_text) VALUES (new.rowid, new.title, new.description, new.raw_text);
+			END`,
+		}
+	}
+	statements = append(statements, `INSERT INTO tasks_fts(tasks_fts) VALUES ('rebuild')`)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SearchTasks retrieves the tasks matching search, best match first, each with an HTML snippet of
+// the matched text where the hits are wrapped in <mark> tags
+func (r *TaskRepository) SearchTasks(search TaskSearch) ([]models.TaskSearchResult, error) {
+	limit := search.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	var rows []taskSearchRow
+	var err error
+	switch r.db.Dialector.Name() {
+	case "postgres":
+		rows, err = r.searchPostgres(search, limit)
+	case "sqlite":
+		rows, err = r.searchSQLite(search, limit)
+	default:
+		err = fmt.Errorf("full-text search is not supported on %s", r.db.Dialector.Name())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]models.Task, len(rows))
+	for i, row := range rows {
+		tasks[i] = row.Task
+	}
+	if err := attachDetails(r.db, tasks); err != nil {
+		return nil, err
+	}
+	results := make([]models.TaskSearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = models.TaskSearchResult{Task: tasks[i], Rank: row.Rank, Snippet: highlightSnippet(row.Snippet)}
+	}
+	return results, nil
+}
+
+func (r *TaskRepository) searchPostgres(search TaskSearch, limit int) ([]taskSearchRow, error) {
+	headline := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=20, MinWords=5, MaxFragments=2", highlightStart, highlightEnd)
+
+	var rows []taskSearchRow
+	err := visibleTasks(r.db.Model(&models.Task{}), search.UserID).
+		Select("tasks.*, ts_rank_cd(tasks.search_vector, query) AS rank, ts_headline(?::regconfig, concat_ws(' ', tasks.title, tasks.description, tasks.raw_text), query, ?) AS snippet", searchConfig, headline).
+		Joins("CROSS JOIN websearch_to_tsquery(?::regconfig, ?) AS query", searchConfig, search.Text).
+		Where("tasks.search_vector @@ query").
+		Order("rank DESC, tasks.created_at DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *TaskRepository) searchSQLite(search TaskSearch, limit int) ([]taskSearchRow, error) {
+	match := ftsQuery(search.Text)
+	if match == "" {
+		return nil, nil
+	}
+
+	var module string
+	if err := r.db.Raw("SELECT sql FROM sqlite_master WHERE name = 'tasks_fts'").Scan(&module).Error; err != nil {
+		return nil, err
+	}
+	if module == "" {
+		return nil, errors.New("full-text index missing; call SetupTaskSearch")
+	}
+
+	query := visibleTasks(r.db.Model(&models.Task{}), search.UserID).
+		Joins("JOIN tasks_fts ON tasks_fts.rowid = tasks.rowid").
+		Where("tasks_fts MATCH ?", match)
+
+	var rows []taskSearchRow
+	if strings.Contains(strings.ToLower(module), "fts5") {
+		// bm25 scores better matches lower; negate it so higher ranks are better, as on Postgres
+		err := query.
+			Select("tasks.*, -bm25(tasks_fts, ?, ?, ?) AS rank, snippet(tasks_fts, -1, ?, ?, '…', 12) AS snippet",
+				searchColumnWeights[0], searchColumnWeights[1], searchColumnWeights[2], highlightStart, highlightEnd).
+			Order("rank DESC, tasks.created_at DESC").
+			Limit(limit).
+			Scan(&rows).Error
+		return rows, err
+	}
+
+	// FTS4 has no ranking function: score the weighted hits reported by offsets() instead
+	err := query.
+		Select("tasks.*, offsets(tasks_fts) AS offsets, snippet(tasks_fts, ?, ?, '…', -1, 12) AS snippet", highlightStart, highlightEnd).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Rank = offsetsScore(rows[i].Offsets)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Rank != rows[j].Rank {
+			return rows[i].Rank > rows[j].Rank
+		}
+		return rows[i].CreatedAt.After(rows[j].CreatedAt)
+	})
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+// highlightSnippet turns a snippet with hits between highlightStart and highlightEnd into HTML:
+// the task text is escaped, and only the <mark> tags around the hits are left as markup. Markers
+// that made it into the task text itself cannot unbalance the tags.
+func highlightSnippet(snippet string) string {
+	var b strings.Builder
+	open := false
+	for _, part := range strings.SplitAfter(snippet, highlightEnd) {
+		text, hit, found := strings.Cut(strings.TrimSuffix(part, highlightEnd), highlightStart)
+		b.WriteString(html.EscapeString(strings.ReplaceAll(text, highlightStart, "")))
+		if found && !open {
+			b.WriteString("<mark>")
+			open = true
+		}
+		b.WriteString(html.EscapeString(strings.ReplaceAll(hit, highlightStart, "")))
+		if open && strings.HasSuffix(part, highlightEnd) {
+			b.WriteString("</mark>")
+			open = false
+		}
+	}
+	if open {
+		b.WriteString("</mark>")
+	}
+	return b.String()
+}
+
+// ftsQuery turns free text into an SQLite MATCH expression that requires every word, as a prefix.
+// Punctuation is dropped, so user input cannot form FTS operators or syntax errors.
+func ftsQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + "*"
+	}
+	return strings.Join(words, " ")
+}
+
+// offsetsScore sums the column weights of the hits in an FTS4 offsets() result, which lists
+// four integers per hit: column, term, byte offset and size
+func offsetsScore(offsets string) float64 {
+	fields := strings.Fields(offsets)
+	score := 0.0
+	for i := 0; i+3 < len(fields); i += 4 {
+		column, err := strconv.Atoi(fields[i])
+		if err == nil && column >= 0 && column < len(searchColumnWeights) {
+			score += searchColumnWeights[column]
+		}
+	}
+	return score
+}
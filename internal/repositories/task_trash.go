@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrParentDeleted is returned when restoring a subtask whose parent is still in the trash
+var ErrParentDeleted = errors.New("parent task is in the trash")
+
+// GetDeletedTasks retrieves the user's trash: each deleted task they can see, whoever owns it,
+// most recently deleted first. Subtasks deleted along with their parent are not listed separately.
+func (r *TaskRepository) GetDeletedTasks(userID uuid.UUID) ([]models.Task, error) {
+	var tasks []models.Task
+	err := visibleTasks(r.db.Unscoped(), userID).
+		Where("tasks.deleted_at IS NOT NULL").
+		Where("NOT EXISTS (SELECT 1 FROM tasks parent WHERE parent.id = tasks.parent_id AND parent.deleted_at = tasks.deleted_at)").
+		Order("tasks.deleted_at DESC, tasks.id").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, attachDetails(r.db, tasks)
+}
+
+// GetDeletedTaskByID retrieves a task in the trash that the user can see
+func (r *TaskRepository) GetDeletedTaskByID(id uuid.UUID, userID uuid.UUID) (*models.Task, error) {
+	var task models.Task
+	err := visibleTasks(r.db.Unscoped(), userID).Where("tasks.id = ? AND tasks.deleted_at IS NOT NULL", id).First(&task).Error
+	return &task, err
+}
+
+// RestoreTask takes one of the user's deleted tasks out of the trash, together with the subtasks deleted along
+// with it. Restored tasks are new to syncing clients, which were told they were deleted. Tasks
+// waiting on them change too, as they may be blocked again.
+func (r *TaskRepository) RestoreTask(id uuid.UUID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var task models.Task
+		if err := tx.Unscoped().Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).First(&task).Error; err != nil {
+			return err
+		}
+		if task.ParentID != nil {
+			var deletedParents int64
+			err := tx.Unscoped().Model(&models.Task{}).Where("id = ? AND deleted_at IS NOT NULL", *task.ParentID).Count(&deletedParents).Error
+			if err != nil {
+				return err
+			}
+			if deletedParents > 0 {
+				return ErrParentDeleted
+			}
+		}
+
+		children, err := descendantIDs(tx.Unscoped().Where("user_id = ? AND deleted_at = ?", userID, task.DeletedAt), []uuid.UUID{id})
+		if err != nil {
+			return err
+		}
+		seq, err := nextChangeSeq(tx, userID)
+		if err != nil {
+			return err
+		}
+		restored := append(children, id)
+		err = tx.Unscoped().Model(&models.Task{}).Where("id IN ? AND user_id = ?", restored, userID).
+			Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1"), "change_seq": seq, "created_seq": seq}).Error
+		if err != nil {
+			return err
+		}
+		return touchDependents(tx, restored, userID, seq)
+	})
+}
+
+// PurgeTask permanently deletes a task in the trash and everything below it
+func (r *TaskRepository) PurgeTask(id uuid.UUID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		purged, err := purgeDeletedTasks(tx, "id = ? AND user_id = ?", id, userID)
+		if err == nil && purged == 0 {
+			err = gorm.ErrRecordNotFound
+		}
+		return err
+	})
+}
+
+// PurgeDeletedTasks permanently deletes everything in the user's trash and returns the number
+// of tasks removed
+func (r *TaskRepository) PurgeDeletedTasks(userID uuid.UUID) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		purged, err = purgeDeletedTasks(tx, "user_id = ?", userID)
+		return err
+	})
+	return purged, err
+}
+
+// PurgeTasksDeletedBefore permanently deletes the tasks of all users that were moved to the
+// trash before the given time, and returns the number of tasks removed
+func (r *TaskRepository) PurgeTasksDeletedBefore(before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		purged, err = purgeDeletedTasks(tx, "deleted_at < ?", before.UTC())
+		return err
+	})
+	return purged, err
+}
+
+// purgeDeletedTasks hard-deletes the deleted tasks matching the condition, with all their
+// subtasks, events, reminders, tags, dependencies, comments and activity, and records the purge in each owner's
+// change counter
+func purgeDeletedTasks(tx *gorm.DB, query string, args ...interface{}) (int64, error) {
+	var roots []models.Task
+	err := tx.Unscoped().Select("id", "user_id", "change_seq").Where("deleted_at IS NOT NULL").Where(query, args...).Find(&roots).Error
+	if err != nil || len(roots) == 0 {
+		return 0, err
+	}
+
+	ids := make([]uuid.UUID, len(roots))
+	purgedSeq := make(map[uuid.UUID]int64)
+	for i, root := range roots {
+		ids[i] = root.ID
+		purgedSeq[root.UserID] = max(purgedSeq[root.UserID], root.ChangeSeq)
+	}
+	children, err := descendantIDs(tx.Unscoped(), ids)
+	if err != nil {
+		return 0, err
+	}
+	ids = append(ids, children...)
+
+	if err := tx.Where("task_id IN ?", ids).Delete(&models.TaskEvent{}).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&models.Reminder{}).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&models.TaskTag{}).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Where("task_id IN ? OR blocker_id IN ?", ids, ids).Delete(&models.TaskDependency{}).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&models.Comment{}).Error; err != nil {
+		return 0, err
+	}
+	result := tx.Unscoped().Where("id IN ?", ids).Delete(&models.Task{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	for userID, seq := range purgedSeq {
+		err := tx.Model(&models.TaskChangeCounter{}).Where("user_id = ? AND purged_seq < ?", userID, seq).Update("purged_seq", seq).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserSettingsRepositoryInterface defines the methods for interacting with user settings
+type UserSettingsRepositoryInterface interface {
+	GetUserSettings(userID uuid.UUID) (*models.UserSettings, error)
+	SaveUserSettings(settings *models.UserSettings) error
+}
+
+// UserSettingsRepository handles database operations for user settings
+type UserSettingsRepository struct {
+	db *gorm.DB
+}
+
+// NewUserSettingsRepository creates a new UserSettingsRepository
+func NewUserSettingsRepository(db *gorm.DB) *UserSettingsRepository {
+	return &UserSettingsRepository{db: db}
+}
+
+// GetUserSettings retrieves the stored settings of a user
+func (r *UserSettingsRepository) GetUserSettings(userID uuid.UUID) (*models.UserSettings, error) {
+	var settings models.UserSettings
+	err := r.db.Where("user_id = ?", userID).First(&settings).Error
+	return &settings, err
+}
+
+// SaveUserSettings inserts or replaces a user's settings
+func (r *UserSettingsRepository) SaveUserSettings(settings *models.UserSettings) error {
+	return r.db.Save(settings).Error
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrPermissionDenied is returned when a user can see a project or task but their role does not
+// let them do what they asked
+var ErrPermissionDenied = errors.New("permission denied")
+
+// access is what a user wants to do with a project or its tasks
+type access int
+
+const (
+	accessView   access = iota // see the project and its tasks
+	accessEdit                 // also create, change, complete and delete its tasks
+	accessManage               // also change the project itself and who it is shared with
+)
+
+// roleGrants reports whether a project role allows the access
+func roleGrants(role string, level access) bool {
+	switch role {
+	case models.ProjectRoleOwner:
+		return true
+	case models.ProjectRoleEditor:
+		return level <= accessEdit
+	case models.ProjectRoleViewer:
+		return level == accessView
+	}
+	return false
+}
+
+// authorize retrieves a project the user is a member of, failing with ErrProjectNotFound when
+// they are not one and with ErrPermissionDenied when their role does not allow the access
+func (s *ProjectService) authorize(id uuid.UUID, userID uuid.UUID, level access) (*models.Project, error) {
+	project, err := s.getProject(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !roleGrants(project.Role, level) {
+		return nil, ErrPermissionDenied
+	}
+	return project, nil
+}
+
+// authorizeTask checks that the user's role in the task's project allows the access. Only their
+// owner can see inbox tasks, so those need no check once they are found.
+func (s *TaskService) authorizeTask(task *models.Task, userID uuid.UUID, level access) error {
+	if task.ProjectID == nil || s.projectService == nil {
+		return nil
+	}
+	_, err := s.projectService.authorize(*task.ProjectID, userID, level)
+	return err
+}
+
+// editableTask retrieves a task without its subtasks, provided the user may change it
+func (s *TaskService) editableTask(id uuid.UUID, userID uuid.UUID) (*models.Task, error) {
+	task, err := s.getTask(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeTask(task, userID, accessEdit); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+	"todo-backend/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// MaxTaskAttachments is how many files can be attached to one task
+const MaxTaskAttachments = 20
+
+// orphanBatchSize is how many attachments of purged tasks are removed per query
+const orphanBatchSize = 100
+
+var (
+	// ErrAttachmentNotFound is returned for attachments that do not exist or are not on the given task
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrInvalidAttachment is wrapped by the validation errors of attachments
+	ErrInvalidAttachment = errors.New("invalid attachment")
+)
+
+// AttachmentService handles the files attached to tasks. Everyone who can see a task can list and
+// download its attachments; adding and removing them takes the right to change the task.
+type AttachmentService struct {
+	attachmentRepo repositories.AttachmentRepositoryInterface
+	blobStore      storage.BlobStore
+	taskService    *TaskService
+}
+
+// NewAttachmentService creates a new AttachmentService
+func NewAttachmentService(attachmentRepo repositories.AttachmentRepositoryInterface, blobStore storage.BlobStore, taskService *TaskService) *AttachmentService {
+	return &AttachmentService{
+		attachmentRepo: attachmentRepo,
+		blobStore:      blobStore,
+		taskService:    taskService,
+	}
+}
+
+// CreateAttachment stores the content of a file in blob storage and attaches it to a task. The
+// caller sets the attachment's filename, MIME type and size. The per-task limit is enforced when
+// the attachment is recorded, after the upload, so a rejected file's blob is removed again.
+func (s *AttachmentService) CreateAttachment(ctx context.Context, taskID uuid.UUID, content io.Reader, attachment *models.Attachment, userID uuid.UUID) error {
+	task, err := s.taskService.editableTask(taskID, userID)
+	if err != nil {
+		return err
+	}
+	attachment.ID = uuid.Nil
+	attachment.TaskID = taskID
+	attachment.UserID = userID
+	attachment.StorageKey = storage.NewKey("attachments", task.UserID, filepath.Ext(attachment.Filename))
+	if err := s.blobStore.Put(ctx, attachment.StorageKey, content, attachment.SizeBytes, attachment.MimeType); err != nil {
+		return fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if err := s.attachmentRepo.CreateAttachment(attachment, MaxTaskAttachments); err != nil {
+		if deleteErr := s.blobStore.Delete(ctx, attachment.StorageKey); deleteErr != nil {
+			log.Warn().Err(deleteErr).Str("key", attachment.StorageKey).Msg("Failed to remove the blob of an unrecorded attachment")
+		}
+		switch {
+		case errors.Is(err, repositories.ErrTooManyAttachments):
+			return fmt.Errorf("%w: a task can have at most %d attachments", ErrInvalidAttachment, MaxTaskAttachments)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return errors.New("task not found or unauthorized")
+		}
+		return fmt.Errorf("failed to record attachment: %w", err)
+	}
+	return nil
+}
+
+// GetAttachments retrieves the attachments of a task, oldest first
+func (s *AttachmentService) GetAttachments(taskID uuid.UUID, userID uuid.UUID) ([]models.Attachment, error) {
+	if _, err := s.taskService.getTask(taskID, userID); err != nil {
+		return nil, err
+	}
+	return s.attachmentRepo.GetAttachmentsByTaskID(taskID)
+}
+
+// OpenAttachment retrieves an attachment of a task with a reader of its content, which the caller
+// has to close
+func (s *AttachmentService) OpenAttachment(ctx context.Context, taskID uuid.UUID, id uuid.UUID, userID uuid.UUID) (*models.Attachment, io.ReadCloser, error) {
+	if _, err := s.taskService.getTask(taskID, userID); err != nil {
+		return nil, nil, err
+	}
+	attachment, err := s.getAttachment(taskID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := s.blobStore.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load attachment: %w", err)
+	}
+	return attachment, content, nil
+}
+
+// DeleteAttachment removes an attachment from a task along with its stored content. The blob goes
+// first, so a failure leaves a record that can be deleted again rather than a blob nothing points to.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, taskID uuid.UUID, id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.taskService.editableTask(taskID, userID); err != nil {
+		return err
+	}
+	attachment, err := s.getAttachment(taskID, id)
+	if err != nil {
+		return err
+	}
+	return s.remove(ctx, attachment)
+}
+
+// PurgeOrphans removes the attachments of tasks that were purged from the trash, content included,
+// and returns how many were removed
+func (s *AttachmentService) PurgeOrphans(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		orphans, err := s.attachmentRepo.GetOrphanedAttachments(orphanBatchSize)
+		if err != nil {
+			return removed, err
+		}
+		for i := range orphans {
+			if err := s.remove(ctx, &orphans[i]); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		if len(orphans) < orphanBatchSize {
+			return removed, nil
+		}
+	}
+}
+
+// remove deletes the blob of an attachment and then its record
+func (s *AttachmentService) remove(ctx context.Context, attachment *models.Attachment) error {
+	if err := s.blobStore.Delete(ctx, attachment.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("failed to delete attachment content: %w", err)
+	}
+	err := s.attachmentRepo.DeleteAttachment(attachment.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAttachmentNotFound
+	}
+	return err
+}
+
+// getAttachment retrieves an attachment, making sure it is on the given task
+func (s *AttachmentService) getAttachment(taskID uuid.UUID, id uuid.UUID) (*models.Attachment, error) {
+	attachment, err := s.attachmentRepo.GetAttachmentByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	if attachment.TaskID != taskID {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+// tasksPurged lets the attachment service remove the files of the tasks just purged. Failures are
+// only logged: the purge itself went through, and the files are picked up by the next purge.
+func (s *TaskService) tasksPurged() {
+	if s.attachmentService == nil {
+		return
+	}
+	if _, err := s.attachmentService.PurgeOrphans(context.Background()); err != nil {
+		log.Error().Err(err).Msg("Failed to remove the attachments of purged tasks")
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+	"todo-backend/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAttachmentRepository is a mock implementation of AttachmentRepositoryInterface
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) CreateAttachment(attachment *models.Attachment, limit int) error {
+	args := m.Called(attachment, limit)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) GetAttachmentByID(id uuid.UUID) (*models.Attachment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) GetAttachmentsByTaskID(taskID uuid.UUID) ([]models.Attachment, error) {
+	args := m.Called(taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) DeleteAttachment(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) GetOrphanedAttachments(limit int) ([]models.Attachment, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Attachment), args.Error(1)
+}
+
+func TestAttachmentService(t *testing.T) {
+	userID := uuid.New()
+	task := &models.Task{ID: uuid.New(), UserID: userID, Title: "Fix the bike", Priority: "low"}
+
+	setup := func() (*AttachmentService, *MockAttachmentRepository, *MockBlobStore, *MockTaskRepository) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockAttachmentRepo := new(MockAttachmentRepository)
+		mockBlobStore := new(MockBlobStore)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		attachmentService := NewAttachmentService(mockAttachmentRepo, mockBlobStore, taskService)
+		taskService.SetAttachmentService(attachmentService)
+		mockTaskRepo.On("GetTaskByID", task.ID, userID).Return(task, nil)
+		return attachmentService, mockAttachmentRepo, mockBlobStore, mockTaskRepo
+	}
+
+	t.Run("stores files under the task owner and removes them when recording fails", func(t *testing.T) {
+		attachmentService, mockAttachmentRepo, mockBlobStore, _ := setup()
+		var key string
+		mockBlobStore.On("Put", mock.Anything, mock.MatchedBy(func(k string) bool {
+			key = k
+			return strings.HasPrefix(k, "attachments/"+userID.String()+"/") && strings.HasSuffix(k, ".png")
+		}), mock.Anything, int64(4), "image/png").Return(nil).Once()
+		mockAttachmentRepo.On("CreateAttachment", mock.AnythingOfType("*models.Attachment"), MaxTaskAttachments).Return(errors.New("db down")).Once()
+		mockBlobStore.On("Delete", mock.Anything, mock.MatchedBy(func(k string) bool { return k == key })).Return(nil).Once()
+
+		attachment := &models.Attachment{Filename: "Tyre.PNG", MimeType: "image/png", SizeBytes: 4}
+		err := attachmentService.CreateAttachment(context.Background(), task.ID, strings.NewReader("tyre"), attachment, userID)
+		assert.Error(t, err)
+		mockBlobStore.AssertExpectations(t)
+		mockAttachmentRepo.AssertExpectations(t)
+	})
+
+	t.Run("limits the number of files on a task", func(t *testing.T) {
+		attachmentService, mockAttachmentRepo, mockBlobStore, _ := setup()
+		mockBlobStore.On("Put", mock.Anything, mock.Anything, mock.Anything, int64(4), "image/png").Return(nil).Once()
+		mockAttachmentRepo.On("CreateAttachment", mock.AnythingOfType("*models.Attachment"), MaxTaskAttachments).Return(repositories.ErrTooManyAttachments).Once()
+		mockBlobStore.On("Delete", mock.Anything, mock.Anything).Return(nil).Once()
+
+		attachment := &models.Attachment{Filename: "one-more.png", MimeType: "image/png", SizeBytes: 4}
+		err := attachmentService.CreateAttachment(context.Background(), task.ID, strings.NewReader("more"), attachment, userID)
+		assert.ErrorIs(t, err, ErrInvalidAttachment)
+		mockBlobStore.AssertExpectations(t)
+	})
+
+	t.Run("removes the files of purged tasks", func(t *testing.T) {
+		attachmentService, mockAttachmentRepo, mockBlobStore, mockTaskRepo := setup()
+		orphans := []models.Attachment{
+			{ID: uuid.New(), TaskID: task.ID, StorageKey: "attachments/a.png"},
+			{ID: uuid.New(), TaskID: task.ID, StorageKey: "attachments/b.pdf"},
+		}
+		mockTaskRepo.On("PurgeDeletedTasks", userID).Return(int64(1), nil)
+		mockAttachmentRepo.On("GetOrphanedAttachments", orphanBatchSize).Return(orphans, nil).Once()
+		// A blob that is already gone does not stop its record from being removed
+		mockBlobStore.On("Delete", mock.Anything, "attachments/a.png").Return(storage.ErrNotFound).Once()
+		mockBlobStore.On("Delete", mock.Anything, "attachments/b.pdf").Return(nil).Once()
+		mockAttachmentRepo.On("DeleteAttachment", orphans[0].ID).Return(nil).Once()
+		mockAttachmentRepo.On("DeleteAttachment", orphans[1].ID).Return(nil).Once()
+
+		purged, err := attachmentService.taskService.EmptyTrash(userID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+		mockBlobStore.AssertExpectations(t)
+		mockAttachmentRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+	"todo-backend/internal/storage"
+	"todo-backend/internal/stt"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// audioURLExpiry is how long the audio_url returned with an upload stays valid
+const audioURLExpiry = 15 * time.Minute
+
+// AudioService handles turning uploaded voice notes into tasks
+type AudioService struct {
+	audioRepo   repositories.AudioUploadRepositoryInterface
+	blobStore   storage.BlobStore
+	transcriber stt.Transcriber
+	taskService *TaskService
+}
+
+// NewAudioService creates a new AudioService
+func NewAudioService(audioRepo repositories.AudioUploadRepositoryInterface, blobStore storage.BlobStore, transcriber stt.Transcriber, taskService *TaskService) *AudioService {
+	return &AudioService{
+		audioRepo:   audioRepo,
+		blobStore:   blobStore,
+		transcriber: transcriber,
+		taskService: taskService,
+	}
+}
+
+// ProcessAudio stores and records an upload, transcribes it and creates tasks from the transcript.
+// The upload's status is persisted at every step, so a failed upload can still be inspected afterwards.
+func (s *AudioService) ProcessAudio(ctx context.Context, audio io.ReadSeeker, upload *models.AudioUpload) (*models.AudioUpload, error) {
+	if err := s.StoreAudio(ctx, audio, upload); err != nil {
+		return nil, err
+	}
+	if _, err := audio.Seek(0, io.SeekStart); err != nil {
+		return upload, s.fail(upload, fmt.Errorf("failed to rewind audio: %w", err))
+	}
+	return upload, s.transcribeAndExtract(ctx, audio, upload)
+}
+
+// StoreAudio saves the audio to blob storage and records the upload with status "uploaded". The
+// blob is removed again when the upload cannot be recorded.
+func (s *AudioService) StoreAudio(ctx context.Context, audio io.Reader, upload *models.AudioUpload) error {
+	upload.StorageKey = storage.NewKey("audio", upload.UserID, filepath.Ext(upload.Filename))
+	if err := s.blobStore.Put(ctx, upload.StorageKey, audio, upload.SizeBytes, upload.MimeType); err != nil {
+		return fmt.Errorf("failed to store audio: %w", err)
+	}
+
+	upload.Status = models.AudioStatusUploaded
+	if err := s.audioRepo.CreateAudioUpload(upload); err != nil {
+		// Nothing refers to the blob without the upload record
+		if deleteErr := s.blobStore.Delete(ctx, upload.StorageKey); deleteErr != nil {
+			log.Warn().Err(deleteErr).Str("key", upload.StorageKey).Msg("Failed to remove the blob of an unrecorded audio upload")
+		}
+		return fmt.Errorf("failed to record audio upload: %w", err)
+	}
+	return nil
+}
+
+// TranscribeStoredAudio runs the transcription pipeline for an upload previously saved with
+// StoreAudio. An upload that is done already is returned as it is.
+func (s *AudioService) TranscribeStoredAudio(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.AudioUpload, error) {
+	upload, err := s.audioRepo.GetAudioUploadByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("audio upload not found or unauthorized")
+		}
+		return nil, err
+	}
+	if upload.Status == models.AudioStatusDone {
+		// A job that runs again after it finished has nothing left to do
+		return upload, nil
+	}
+
+	audio, err := s.blobStore.Get(ctx, upload.StorageKey)
+	if err != nil {
+		return upload, s.fail(upload, fmt.Errorf("failed to load audio: %w", err))
+	}
+	defer audio.Close()
+
+	return upload, s.transcribeAndExtract(ctx, audio, upload)
+}
+
+// transcribeAndExtract moves a recorded upload through transcription and task extraction
+func (s *AudioService) transcribeAndExtract(ctx context.Context, audio io.Reader, upload *models.AudioUpload) error {
+	upload.ErrorMessage = ""
+	if err := s.setStatus(upload, models.AudioStatusTranscribing); err != nil {
+		return err
+	}
+	transcript, err := s.transcriber.Transcribe(ctx, audio, upload.Filename)
+	if err != nil {
+		return s.fail(upload, fmt.Errorf("failed to transcribe audio: %w", err))
+	}
+	upload.Transcript = strings.TrimSpace(transcript.Text)
+	upload.Language = transcript.Language
+	upload.DurationSeconds = transcript.Duration
+
+	if err := s.setStatus(upload, models.AudioStatusExtracting); err != nil {
+		return err
+	}
+	upload.Tasks = []models.Task{}
+	if upload.Transcript != "" {
+		// The tasks are created before the upload is marked done, so a rerun may find them already
+		tasks, err := s.taskService.ExtractAndCreateTasksOnce(ctx, upload.ID, upload.Transcript, upload.UserID, nil)
+		if err != nil {
+			return s.fail(upload, err)
+		}
+		if tasks != nil {
+			upload.Tasks = tasks
+		}
+	}
+
+	upload.TaskIDs = make([]uuid.UUID, 0, len(upload.Tasks))
+	for _, task := range upload.Tasks {
+		upload.TaskIDs = append(upload.TaskIDs, task.ID)
+	}
+	return s.setStatus(upload, models.AudioStatusDone)
+}
+
+// GetAudioUploadByID retrieves an audio upload together with the tasks it produced
+func (s *AudioService) GetAudioUploadByID(id uuid.UUID, userID uuid.UUID) (*models.AudioUpload, error) {
+	upload, err := s.audioRepo.GetAudioUploadByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("audio upload not found or unauthorized")
+		}
+		return nil, err
+	}
+
+	tasks, err := s.taskService.GetTasksByIDs(upload.TaskIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	upload.Tasks = tasks
+
+	if upload.StorageKey != "" {
+		audioURL, err := s.blobStore.PresignedURL(context.Background(), upload.StorageKey, audioURLExpiry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign audio URL: %w", err)
+		}
+		upload.AudioURL = audioURL
+	}
+	return upload, nil
+}
+
+// GetAudioUploadsByUserID retrieves all audio uploads for a user together with the tasks they produced
+func (s *AudioService) GetAudioUploadsByUserID(userID uuid.UUID) ([]models.AudioUpload, error) {
+	uploads, err := s.audioRepo.GetAudioUploadsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the linked tasks for every upload in a single query
+	var taskIDs []uuid.UUID
+	for _, upload := range uploads {
+		taskIDs = append(taskIDs, upload.TaskIDs...)
+	}
+	tasks, err := s.taskService.GetTasksByIDs(taskIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	tasksByID := make(map[uuid.UUID]models.Task, len(tasks))
+	for _, task := range tasks {
+		tasksByID[task.ID] = task
+	}
+
+	for i := range uploads {
+		uploads[i].Tasks = []models.Task{}
+		for _, id := range uploads[i].TaskIDs {
+			if task, ok := tasksByID[id]; ok {
+				uploads[i].Tasks = append(uploads[i].Tasks, task)
+			}
+		}
+	}
+	return uploads, nil
+}
+
+// setStatus moves an upload to the given status and persists it
+func (s *AudioService) setStatus(upload *models.AudioUpload, status models.AudioUploadStatus) error {
+	upload.Status = status
+	if err := s.audioRepo.UpdateAudioUpload(upload); err != nil {
+		return fmt.Errorf("failed to update audio upload status: %w", err)
+	}
+	return nil
+}
+
+// fail marks an upload as failed with the given cause and returns the cause
+func (s *AudioService) fail(upload *models.AudioUpload, cause error) error {
+	upload.Status = models.AudioStatusFailed
+	upload.ErrorMessage = cause.Error()
+	// The original cause is more useful to the caller than a failure to record it
+	_ = s.audioRepo.UpdateAudioUpload(upload)
+	return cause
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"todo-backend/internal/llm"
+	"todo-backend/internal/models"
+	"todo-backend/internal/storage"
+	"todo-backend/internal/stt"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockTranscriber is a mock implementation of stt.Transcriber
+type MockTranscriber struct {
+	mock.Mock
+}
+
+func (m *MockTranscriber) Transcribe(ctx context.Context, audio io.Reader, filename string) (*stt.Transcript, error) {
+	args := m.Called(ctx, audio, filename)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stt.Transcript), args.Error(1)
+}
+
+// MockBlobStore is a mock implementation of storage.BlobStore
+type MockBlobStore struct {
+	mock.Mock
+}
+
+func (m *MockBlobStore) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	args := m.Called(ctx, key, content, size, contentType)
+	return args.Error(0)
+}
+
+func (m *MockBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockBlobStore) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockBlobStore) PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	args := m.Called(ctx, key, expiry)
+	return args.String(0), args.Error(1)
+}
+
+// MockAudioUploadRepository is a mock implementation of AudioUploadRepositoryInterface
+type MockAudioUploadRepository struct {
+	mock.Mock
+	statuses []models.AudioUploadStatus // every status that was persisted, in order
+}
+
+func (m *MockAudioUploadRepository) CreateAudioUpload(upload *models.AudioUpload) error {
+	m.statuses = append(m.statuses, upload.Status)
+	args := m.Called(upload)
+	return args.Error(0)
+}
+
+func (m *MockAudioUploadRepository) GetAudioUploadByID(id uuid.UUID, userID uuid.UUID) (*models.AudioUpload, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AudioUpload), args.Error(1)
+}
+
+func (m *MockAudioUploadRepository) GetAudioUploadsByUserID(userID uuid.UUID) ([]models.AudioUpload, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AudioUpload), args.Error(1)
+}
+
+func (m *MockAudioUploadRepository) UpdateAudioUpload(upload *models.AudioUpload) error {
+	m.statuses = append(m.statuses, upload.Status)
+	args := m.Called(upload)
+	return args.Error(0)
+}
+
+func TestAudioService_ProcessAudio(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("transcribes audio and creates tasks from the transcript", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockLLMExtractor := new(MockLLMExtractor)
+		mockTranscriber := new(MockTranscriber)
+		mockAudioRepo := new(MockAudioUploadRepository)
+		mockBlobStore := new(MockBlobStore)
+		audioService := NewAudioService(mockAudioRepo, mockBlobStore, mockTranscriber, NewTaskService(mockTaskRepo, mockLLMExtractor))
+
+		audio := strings.NewReader("audio bytes")
+		mockBlobStore.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "audio/"+userID.String()+"/") && strings.HasSuffix(key, ".m4a")
+		}), audio, int64(11), "audio/mp4").Return(nil).Once()
+		mockAudioRepo.On("CreateAudioUpload", mock.AnythingOfType("*models.AudioUpload")).Return(nil).Once()
+		mockAudioRepo.On("UpdateAudioUpload", mock.AnythingOfType("*models.AudioUpload")).Return(nil)
+		mockTranscriber.On("Transcribe", mock.Anything, audio, "note.m4a").Return(&stt.Transcript{Text: " Buy milk tomorrow\n", Duration: 4}, nil).Once()
+		mockLLMExtractor.On("ExtractTasks", mock.Anything, "Buy milk tomorrow", mock.AnythingOfType("llm.ExtractOptions")).Return([]llm.Task{{Title: "Buy milk"}}, nil).Once()
+		mockTaskRepo.On("GetTasksBySource", mock.Anything, userID).Return([]models.Task{}, nil).Once()
+		mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task"), mock.Anything).Return(nil).Once()
+
+		upload, err := audioService.ProcessAudio(context.Background(), audio, &models.AudioUpload{UserID: userID, Filename: "note.m4a", MimeType: "audio/mp4", SizeBytes: 11})
+		assert.NoError(t, err)
+		assert.Equal(t, models.AudioStatusDone, upload.Status)
+		assert.NotEmpty(t, upload.StorageKey)
+		assert.Equal(t, "Buy milk tomorrow", upload.Transcript)
+		assert.Equal(t, 4.0, upload.DurationSeconds)
+		assert.Len(t, upload.Tasks, 1)
+		assert.Equal(t, "Buy milk", upload.Tasks[0].Title)
+		assert.Equal(t, []uuid.UUID{upload.Tasks[0].ID}, upload.TaskIDs)
+		assert.Equal(t, []models.AudioUploadStatus{
+			models.AudioStatusUploaded,
+			models.AudioStatusTranscribing,
+			models.AudioStatusExtracting,
+			models.AudioStatusDone,
+		}, mockAudioRepo.statuses)
+		mockBlobStore.AssertExpectations(t)
+		mockTranscriber.AssertExpectations(t)
+		mockLLMExtractor.AssertExpectations(t)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("skips extraction for an empty transcript", func(t *testing.T) {
+		mockLLMExtractor := new(MockLLMExtractor)
+		mockTranscriber := new(MockTranscriber)
+		mockAudioRepo := new(MockAudioUploadRepository)
+		mockBlobStore := new(MockBlobStore)
+		mockBlobStore.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		audioService := NewAudioService(mockAudioRepo, mockBlobStore, mockTranscriber, NewTaskService(new(MockTaskRepository), mockLLMExtractor))
+
+		audio := strings.NewReader("silence")
+		mockAudioRepo.On("CreateAudioUpload", mock.AnythingOfType("*models.AudioUpload")).Return(nil).Once()
+		mockAudioRepo.On("UpdateAudioUpload", mock.AnythingOfType("*models.AudioUpload")).Return(nil)
+		mockTranscriber.On("Transcribe", mock.Anything, audio, "silence.wav").Return(&stt.Transcript{Text: "   "}, nil).Once()
+
+		upload, err := audioService.ProcessAudio(context.Background(), audio, &models.AudioUpload{UserID: userID, Filename: "silence.wav"})
+		assert.NoError(t, err)
+		assert.Equal(t, models.AudioStatusDone, upload.Status)
+		assert.Empty(t, upload.Transcript)
+		assert.Empty(t, upload.Tasks)
+		mockLLMExtractor.AssertNotCalled(t, "ExtractTasks")
+	})
+
+	t.Run("marks the upload as failed if transcription fails", func(t *testing.T) {
+		mockTranscriber := new(MockTranscriber)
+		mockAudioRepo := new(MockAudioUploadRepository)
+		mockBlobStore := new(MockBlobStore)
+		mockBlobStore.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		audioService := NewAudioService(mockAudioRepo, mockBlobStore, mockTranscriber, NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+
+		audio := strings.NewReader("audio bytes")
+		mockAudioRepo.On("CreateAudioUpload", mock.AnythingOfType("*models.AudioUpload")).Return(nil).Once()
+		mockAudioRepo.On("UpdateAudioUpload", mock.AnythingOfType("*models.AudioUpload")).Return(nil)
+		mockTranscriber.On("Transcribe", mock.Anything, audio, "note.m4a").Return(nil, errors.New("stt error")).Once()
+
+		upload, err := audioService.ProcessAudio(context.Background(), audio, &models.AudioUpload{UserID: userID, Filename: "note.m4a"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to transcribe audio")
+		assert.Equal(t, models.AudioStatusFailed, upload.Status)
+		assert.Contains(t, upload.ErrorMessage, "stt error")
+		assert.Equal(t, models.AudioStatusFailed, mockAudioRepo.statuses[len(mockAudioRepo.statuses)-1])
+	})
+
+	t.Run("does not record the upload if storing the audio fails", func(t *testing.T) {
+		mockAudioRepo := new(MockAudioUploadRepository)
+		mockBlobStore := new(MockBlobStore)
+		mockBlobStore.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("disk full")).Once()
+		audioService := NewAudioService(mockAudioRepo, mockBlobStore, new(MockTranscriber), NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+
+		upload, err := audioService.ProcessAudio(context.Background(), strings.NewReader("audio bytes"), &models.AudioUpload{UserID: userID, Filename: "note.m4a"})
+		assert.Nil(t, upload)
+		assert.Contains(t, err.Error(), "failed to store audio")
+		mockAudioRepo.AssertNotCalled(t, "CreateAudioUpload", mock.Anything)
+	})
+
+	t.Run("removes the stored audio if the upload cannot be recorded", func(t *testing.T) {
+		mockAudioRepo := new(MockAudioUploadRepository)
+		mockBlobStore := new(MockBlobStore)
+		var key string
+		mockBlobStore.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			key = args.String(1)
+		}).Return(nil).Once()
+		mockAudioRepo.On("CreateAudioUpload", mock.Anything).Return(errors.New("connection reset")).Once()
+		mockBlobStore.On("Delete", mock.Anything, mock.MatchedBy(func(k string) bool { return k == key })).Return(nil).Once()
+		audioService := NewAudioService(mockAudioRepo, mockBlobStore, new(MockTranscriber), NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+
+		upload, err := audioService.ProcessAudio(context.Background(), strings.NewReader("audio bytes"), &models.AudioUpload{UserID: userID, Filename: "note.m4a"})
+		assert.Nil(t, upload)
+		assert.Contains(t, err.Error(), "failed to record audio upload")
+		mockBlobStore.AssertExpectations(t)
+	})
+}
+
+func TestAudioService_TranscribeStoredAudio(t *testing.T) {
+	userID := uuid.New()
+	uploadID := uuid.New()
+
+	t.Run("transcribes a stored upload loaded from blob storage", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockLLMExtractor := new(MockLLMExtractor)
+		mockTranscriber := new(MockTranscriber)
+		mockAudioRepo := new(MockAudioUploadRepository)
+		mockBlobStore := new(MockBlobStore)
+		audioService := NewAudioService(mockAudioRepo, mockBlobStore, mockTranscriber, NewTaskService(mockTaskRepo, mockLLMExtractor))
+
+		stored := &models.AudioUpload{ID: uploadID, UserID: userID, StorageKey: "audio/key.m4a", Filename: "note.m4a", Status: models.AudioStatusFailed, ErrorMessage: "stt error"}
+		audio := io.NopCloser(strings.NewReader("audio bytes"))
+		mockAudioRepo.On("GetAudioUploadByID", uploadID, userID).Return(stored, nil).Once()
+		mockAudioRepo.On("UpdateAudioUpload", stored).Return(nil)
+		mockBlobStore.On("Get", mock.Anything, "audio/key.m4a").Return(audio, nil).Once()
+		mockTranscriber.On("Transcribe", mock.Anything, audio, "note.m4a").Return(&stt.Transcript{Text: "Buy milk"}, nil).Once()
+		mockLLMExtractor.On("ExtractTasks", mock.Anything, "Buy milk", mock.AnythingOfType("llm.ExtractOptions")).Return([]llm.Task{{Title: "Buy milk"}}, nil).Once()
+		mockTaskRepo.On("GetTasksBySource", uploadID, userID).Return([]models.Task{}, nil).Once()
+		mockTaskRepo.On("CreateTask", mock.MatchedBy(func(task *models.Task) bool {
+			return task.SourceID != nil && *task.SourceID == uploadID
+		}), mock.Anything).Return(nil).Once()
+
+		upload, err := audioService.TranscribeStoredAudio(context.Background(), uploadID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AudioStatusDone, upload.Status)
+		assert.Empty(t, upload.ErrorMessage)
+		assert.Len(t, upload.TaskIDs, 1)
+		assert.Equal(t, []models.AudioUploadStatus{
+			models.AudioStatusTranscribing,
+			models.AudioStatusExtracting,
+			models.AudioStatusDone,
+		}, mockAudioRepo.statuses)
+		mockBlobStore.AssertExpectations(t)
+		mockTranscriber.AssertExpectations(t)
+	})
+
+	t.Run("leaves an upload that is done already alone", func(t *testing.T) {
+		mockAudioRepo := new(MockAudioUploadRepository)
+		mockBlobStore := new(MockBlobStore)
+		audioService := NewAudioService(mockAudioRepo, mockBlobStore, new(MockTranscriber), NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+
+		taskIDs := []uuid.UUID{uuid.New()}
+		stored := &models.AudioUpload{ID: uploadID, UserID: userID, StorageKey: "audio/key.m4a", Status: models.AudioStatusDone, TaskIDs: taskIDs}
+		mockAudioRepo.On("GetAudioUploadByID", uploadID, userID).Return(stored, nil).Once()
+
+		upload, err := audioService.TranscribeStoredAudio(context.Background(), uploadID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, taskIDs, upload.TaskIDs)
+		mockBlobStore.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+		mockAudioRepo.AssertNotCalled(t, "UpdateAudioUpload", mock.Anything)
+	})
+
+	t.Run("returns the tasks of an earlier run that stopped before the upload was done", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockLLMExtractor := new(MockLLMExtractor)
+		mockTranscriber := new(MockTranscriber)
+		mockAudioRepo := new(MockAudioUploadRepository)
+		mockBlobStore := new(MockBlobStore)
+		audioService := NewAudioService(mockAudioRepo, mockBlobStore, mockTranscriber, NewTaskService(mockTaskRepo, mockLLMExtractor))
+
+		stored := &models.AudioUpload{ID: uploadID, UserID: userID, StorageKey: "audio/key.m4a", Filename: "note.m4a", Status: models.AudioStatusExtracting}
+		audio := io.NopCloser(strings.NewReader("audio bytes"))
+		earlier := models.Task{ID: uuid.New(), UserID: userID, Title: "Buy milk", SourceID: &uploadID}
+		mockAudioRepo.On("GetAudioUploadByID", uploadID, userID).Return(stored, nil).Once()
+		mockAudioRepo.On("UpdateAudioUpload", stored).Return(nil)
+		mockBlobStore.On("Get", mock.Anything, "audio/key.m4a").Return(audio, nil).Once()
+		mockTranscriber.On("Transcribe", mock.Anything, audio, "note.m4a").Return(&stt.Transcript{Text: "Buy milk"}, nil).Once()
+		mockTaskRepo.On("GetTasksBySource", uploadID, userID).Return([]models.Task{earlier}, nil).Once()
+
+		upload, err := audioService.TranscribeStoredAudio(context.Background(), uploadID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AudioStatusDone, upload.Status)
+		assert.Equal(t, []uuid.UUID{earlier.ID}, upload.TaskIDs)
+		mockLLMExtractor.AssertNotCalled(t, "ExtractTasks", mock.Anything, mock.Anything, mock.Anything)
+		mockTaskRepo.AssertNotCalled(t, "CreateTask", mock.Anything, mock.Anything)
+	})
+
+	t.Run("marks the upload as failed if the audio cannot be loaded", func(t *testing.T) {
+		mockAudioRepo := new(MockAudioUploadRepository)
+		mockBlobStore := new(MockBlobStore)
+		audioService := NewAudioService(mockAudioRepo, mockBlobStore, new(MockTranscriber), NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+
+		stored := &models.AudioUpload{ID: uploadID, UserID: userID, StorageKey: "audio/key.m4a", Status: models.AudioStatusUploaded}
+		mockAudioRepo.On("GetAudioUploadByID", uploadID, userID).Return(stored, nil).Once()
+		mockAudioRepo.On("UpdateAudioUpload", stored).Return(nil)
+		mockBlobStore.On("Get", mock.Anything, "audio/key.m4a").Return(nil, storage.ErrNotFound).Once()
+
+		upload, err := audioService.TranscribeStoredAudio(context.Background(), uploadID, userID)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+		assert.Equal(t, models.AudioStatusFailed, upload.Status)
+	})
+
+	t.Run("returns error if upload not found", func(t *testing.T) {
+		mockAudioRepo := new(MockAudioUploadRepository)
+		audioService := NewAudioService(mockAudioRepo, new(MockBlobStore), new(MockTranscriber), NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+		mockAudioRepo.On("GetAudioUploadByID", uploadID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		upload, err := audioService.TranscribeStoredAudio(context.Background(), uploadID, userID)
+		assert.Nil(t, upload)
+		assert.EqualError(t, err, "audio upload not found or unauthorized")
+	})
+}
+
+func TestAudioService_GetAudioUploadByID(t *testing.T) {
+	mockTaskRepo := new(MockTaskRepository)
+	mockAudioRepo := new(MockAudioUploadRepository)
+	mockBlobStore := new(MockBlobStore)
+	audioService := NewAudioService(mockAudioRepo, mockBlobStore, new(MockTranscriber), NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+
+	userID := uuid.New()
+	uploadID := uuid.New()
+	taskID := uuid.New()
+
+	t.Run("returns the upload with its tasks and a signed audio URL", func(t *testing.T) {
+		upload := &models.AudioUpload{ID: uploadID, UserID: userID, Status: models.AudioStatusDone, StorageKey: "audio/key.m4a", TaskIDs: []uuid.UUID{taskID}}
+		mockAudioRepo.On("GetAudioUploadByID", uploadID, userID).Return(upload, nil).Once()
+		mockBlobStore.On("PresignedURL", mock.Anything, "audio/key.m4a", audioURLExpiry).Return("https://blobs/audio/key.m4a?sig", nil).Once()
+		mockTaskRepo.On("GetTasksByIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{{ID: taskID, Title: "Buy milk"}}, nil).Once()
+
+		result, err := audioService.GetAudioUploadByID(uploadID, userID)
+		assert.NoError(t, err)
+		assert.Len(t, result.Tasks, 1)
+		assert.Equal(t, "Buy milk", result.Tasks[0].Title)
+		assert.Equal(t, "https://blobs/audio/key.m4a?sig", result.AudioURL)
+		mockAudioRepo.AssertExpectations(t)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error if upload not found", func(t *testing.T) {
+		mockAudioRepo.On("GetAudioUploadByID", uploadID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result, err := audioService.GetAudioUploadByID(uploadID, userID)
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "audio upload not found or unauthorized")
+		mockAudioRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxCommentLength is the longest comment body accepted, in characters
+const MaxCommentLength = 10000
+
+var (
+	// ErrCommentNotFound is returned for comments that do not exist or are not on the given task
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrInvalidComment is wrapped by the validation errors of comments
+	ErrInvalidComment = errors.New("invalid comment")
+)
+
+// CommentService handles the comment threads and activity streams of tasks. Everyone who can see
+// a task can read and add to its thread, viewers of a shared project included.
+type CommentService struct {
+	commentRepo repositories.CommentRepositoryInterface
+	taskService *TaskService
+	now         func() time.Time
+}
+
+// NewCommentService creates a new CommentService
+func NewCommentService(commentRepo repositories.CommentRepositoryInterface, taskService *TaskService) *CommentService {
+	return &CommentService{
+		commentRepo: commentRepo,
+		taskService: taskService,
+		now:         time.Now,
+	}
+}
+
+// GetComments retrieves the comments on a task, oldest first
+func (s *CommentService) GetComments(taskID uuid.UUID, userID uuid.UUID) ([]models.Comment, error) {
+	if _, err := s.taskService.getTask(taskID, userID); err != nil {
+		return nil, err
+	}
+	return s.commentRepo.GetCommentsByTaskID(taskID)
+}
+
+// CreateComment adds a comment by the user to a task
+func (s *CommentService) CreateComment(taskID uuid.UUID, body string, userID uuid.UUID) (*models.Comment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.taskService.getTask(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{TaskID: taskID, UserID: userID, Body: body}
+	if err := s.commentRepo.CreateComment(comment); err != nil {
+		return nil, err
+	}
+	return s.getComment(taskID, comment.ID)
+}
+
+// UpdateComment changes the body of a comment. Only its author can.
+func (s *CommentService) UpdateComment(taskID uuid.UUID, id uuid.UUID, body string, userID uuid.UUID) (*models.Comment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.taskService.getTask(taskID, userID); err != nil {
+		return nil, err
+	}
+	comment, err := s.getComment(taskID, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != userID {
+		return nil, ErrPermissionDenied
+	}
+	if comment.Body == body {
+		return comment, nil
+	}
+
+	editedAt := s.now().UTC()
+	comment.Body = body
+	comment.EditedAt = &editedAt
+	if err := s.commentRepo.UpdateComment(comment); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+// DeleteComment deletes a comment. Its author can, and so can whoever manages the task's project,
+// or owns the task when it is in their inbox.
+func (s *CommentService) DeleteComment(taskID uuid.UUID, id uuid.UUID, userID uuid.UUID) error {
+	task, err := s.taskService.getTask(taskID, userID)
+	if err != nil {
+		return err
+	}
+	comment, err := s.getComment(taskID, id)
+	if err != nil {
+		return err
+	}
+	if comment.UserID != userID {
+		if err := s.taskService.authorizeTask(task, userID, accessManage); err != nil {
+			return err
+		}
+	}
+
+	err = s.commentRepo.DeleteComment(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCommentNotFound
+	}
+	return err
+}
+
+// getComment retrieves a comment, making sure it is on the given task
+func (s *CommentService) getComment(taskID uuid.UUID, id uuid.UUID) (*models.Comment, error) {
+	comment, err := s.commentRepo.GetCommentByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	if comment.TaskID != taskID {
+		return nil, ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+// validateCommentBody trims a comment body and checks it is neither empty nor too long
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", fmt.Errorf("%w: body is required", ErrInvalidComment)
+	}
+	if utf8.RuneCountInString(body) > MaxCommentLength {
+		return "", fmt.Errorf("%w: body must be at most %d characters", ErrInvalidComment, MaxCommentLength)
+	}
+	return body, nil
+}
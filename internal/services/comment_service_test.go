@@ -0,0 +1,110 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCommentRepository is a mock implementation of CommentRepositoryInterface
+type MockCommentRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommentRepository) CreateComment(comment *models.Comment) error {
+	args := m.Called(comment)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) GetCommentByID(id uuid.UUID) (*models.Comment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetCommentsByTaskID(taskID uuid.UUID) ([]models.Comment, error) {
+	args := m.Called(taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) UpdateComment(comment *models.Comment) error {
+	args := m.Called(comment)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) DeleteComment(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestCommentService(t *testing.T) {
+	ownerID := uuid.New()
+	authorID := uuid.New()
+	task := &models.Task{ID: uuid.New(), UserID: ownerID, Title: "Water the plants", Priority: "low", Version: 1}
+	otherTask := &models.Task{ID: uuid.New(), UserID: ownerID, Title: "Repot the fern", Priority: "low", Version: 1}
+
+	setup := func() (*CommentService, *MockCommentRepository, *MockTaskRepository) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockCommentRepo := new(MockCommentRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		commentService := NewCommentService(mockCommentRepo, taskService)
+		stored := *task
+		mockTaskRepo.On("GetTaskByID", task.ID, mock.Anything).Return(&stored, nil)
+		mockTaskRepo.On("GetTaskByID", otherTask.ID, mock.Anything).Return(otherTask, nil)
+		return commentService, mockCommentRepo, mockTaskRepo
+	}
+
+	t.Run("rejects empty and overlong comments", func(t *testing.T) {
+		commentService, mockCommentRepo, _ := setup()
+
+		_, err := commentService.CreateComment(task.ID, " \n ", ownerID)
+		assert.ErrorIs(t, err, ErrInvalidComment)
+		long := make([]rune, MaxCommentLength+1)
+		for i := range long {
+			long[i] = 'é'
+		}
+		_, err = commentService.CreateComment(task.ID, string(long), ownerID)
+		assert.ErrorIs(t, err, ErrInvalidComment)
+		mockCommentRepo.AssertNotCalled(t, "CreateComment", mock.Anything)
+	})
+
+	t.Run("only the author edits a comment", func(t *testing.T) {
+		commentService, mockCommentRepo, _ := setup()
+		editedAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+		commentService.now = func() time.Time { return editedAt }
+		comment := &models.Comment{ID: uuid.New(), TaskID: task.ID, UserID: authorID, Body: "Twice a week"}
+		mockCommentRepo.On("GetCommentByID", comment.ID).Return(comment, nil)
+		mockCommentRepo.On("UpdateComment", comment).Return(nil).Once()
+
+		_, err := commentService.UpdateComment(task.ID, comment.ID, "Daily", ownerID)
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+		updated, err := commentService.UpdateComment(task.ID, comment.ID, "Daily", authorID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Daily", updated.Body)
+		assert.Equal(t, &editedAt, updated.EditedAt)
+
+		// A comment is only found on its own task
+		_, err = commentService.UpdateComment(otherTask.ID, comment.ID, "Daily", authorID)
+		assert.ErrorIs(t, err, ErrCommentNotFound)
+		mockCommentRepo.AssertExpectations(t)
+	})
+
+	t.Run("the owner of an inbox task deletes any comment on it", func(t *testing.T) {
+		commentService, mockCommentRepo, _ := setup()
+		comment := &models.Comment{ID: uuid.New(), TaskID: task.ID, UserID: authorID, Body: "Done"}
+		mockCommentRepo.On("GetCommentByID", comment.ID).Return(comment, nil)
+		mockCommentRepo.On("DeleteComment", comment.ID).Return(nil).Once()
+
+		assert.NoError(t, commentService.DeleteComment(task.ID, comment.ID, ownerID))
+		mockCommentRepo.AssertExpectations(t)
+	})
+}
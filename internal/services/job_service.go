@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"todo-backend/internal/jobs"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Job types handled by JobService
+const (
+	JobTypeExtract    = "extract"
+	JobTypeTranscribe = "transcribe"
+)
+
+// extractJobPayload is the payload of an "extract" job
+type extractJobPayload struct {
+	Text      string     `json:"text"`
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+}
+
+// transcribeJobPayload is the payload of a "transcribe" job
+type transcribeJobPayload struct {
+	AudioID uuid.UUID `json:"audio_id"`
+}
+
+// JobResult is stored on a finished "extract" or "transcribe" job
+type JobResult struct {
+	AudioID *uuid.UUID  `json:"audio_id,omitempty"`
+	TaskIDs []uuid.UUID `json:"task_ids"`
+}
+
+// JobService enqueues background work and runs it on a jobs.Pool
+type JobService struct {
+	queue        jobs.Queue
+	taskService  *TaskService
+	audioService *AudioService
+	maxAttempts  int
+}
+
+// NewJobService creates a new JobService
+func NewJobService(queue jobs.Queue, taskService *TaskService, audioService *AudioService, maxAttempts int) *JobService {
+	return &JobService{
+		queue:        queue,
+		taskService:  taskService,
+		audioService: audioService,
+		maxAttempts:  maxAttempts,
+	}
+}
+
+// EnqueueExtract queues extracting and creating tasks from text for a user, in a project or in
+// their inbox when projectID is nil
+func (s *JobService) EnqueueExtract(ctx context.Context, text string, projectID *uuid.UUID, userID uuid.UUID) (*models.Job, error) {
+	return s.enqueue(ctx, JobTypeExtract, userID, extractJobPayload{Text: text, ProjectID: projectID})
+}
+
+// EnqueueTranscribe queues the transcription pipeline for an upload stored with AudioService.StoreAudio
+func (s *JobService) EnqueueTranscribe(ctx context.Context, audioID uuid.UUID, userID uuid.UUID) (*models.Job, error) {
+	return s.enqueue(ctx, JobTypeTranscribe, userID, transcribeJobPayload{AudioID: audioID})
+}
+
+// GetJob retrieves a job owned by the user
+func (s *JobService) GetJob(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Job, error) {
+	job, err := s.queue.Get(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, jobs.ErrJobNotFound) {
+			return nil, errors.New("job not found or unauthorized")
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// RegisterHandlers registers the handlers for the job types enqueued by this service
+func (s *JobService) RegisterHandlers(pool *jobs.Pool) {
+	pool.Register(JobTypeExtract, s.runExtract)
+	pool.Register(JobTypeTranscribe, s.runTranscribe)
+}
+
+func (s *JobService) enqueue(ctx context.Context, jobType string, userID uuid.UUID, payload interface{}) (*models.Job, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	job := &models.Job{
+		UserID:      userID,
+		Type:        jobType,
+		Payload:     encoded,
+		MaxAttempts: s.maxAttempts,
+	}
+	if err := s.queue.Enqueue(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to enqueue %s job: %w", jobType, err)
+	}
+	return job, nil
+}
+
+func (s *JobService) runExtract(ctx context.Context, job *models.Job) (interface{}, error) {
+	var payload extractJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("invalid extract job payload: %w", err))
+	}
+
+	// The job may run again after it created its tasks, when recording the result failed
+	tasks, err := s.taskService.ExtractAndCreateTasksOnce(ctx, job.ID, payload.Text, job.UserID, payload.ProjectID)
+	if err != nil {
+		// Retrying does not help when the project is gone or the user's role no longer allows it
+		if errors.Is(err, ErrProjectNotFound) || errors.Is(err, ErrPermissionDenied) {
+			return nil, jobs.Permanent(err)
+		}
+		return nil, err
+	}
+
+	result := JobResult{TaskIDs: make([]uuid.UUID, 0, len(tasks))}
+	for _, task := range tasks {
+		result.TaskIDs = append(result.TaskIDs, task.ID)
+	}
+	return result, nil
+}
+
+func (s *JobService) runTranscribe(ctx context.Context, job *models.Job) (interface{}, error) {
+	var payload transcribeJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("invalid transcribe job payload: %w", err))
+	}
+
+	upload, err := s.audioService.TranscribeStoredAudio(ctx, payload.AudioID, job.UserID)
+	if err != nil {
+		if upload == nil && err.Error() == "audio upload not found or unauthorized" {
+			// The upload was deleted, so there is nothing to retry
+			return nil, jobs.Permanent(err)
+		}
+		return nil, err
+	}
+	return JobResult{AudioID: &upload.ID, TaskIDs: upload.TaskIDs}, nil
+}
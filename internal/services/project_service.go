@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxProjectNameLength is the longest project name, in characters
+const maxProjectNameLength = 100
+
+var (
+	// ErrProjectNotFound is returned for projects that do not exist or the user is not a member of
+	ErrProjectNotFound = errors.New("project not found or unauthorized")
+	// ErrInvalidProject is wrapped by the validation errors of projects and of moving tasks
+	ErrInvalidProject = errors.New("invalid project")
+)
+
+// ProjectService handles the projects users group their tasks into, and who they share them with
+type ProjectService struct {
+	projectRepo repositories.ProjectRepositoryInterface
+	userRepo    repositories.UserRepositoryInterface
+	taskService *TaskService
+	now         func() time.Time
+}
+
+// NewProjectService creates a new ProjectService
+func NewProjectService(projectRepo repositories.ProjectRepositoryInterface, userRepo repositories.UserRepositoryInterface, taskService *TaskService) *ProjectService {
+	return &ProjectService{projectRepo: projectRepo, userRepo: userRepo, taskService: taskService, now: time.Now}
+}
+
+// GetProjects retrieves the projects the user is a member of in their sort order, with their task counts.
+// Archived projects are left out unless includeArchived is set.
+func (s *ProjectService) GetProjects(userID uuid.UUID, includeArchived bool) ([]models.Project, error) {
+	projects, err := s.projectRepo.GetProjectsByUserID(userID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.countTasks(projects, userID); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// GetProject retrieves a project the user is a member of with its task counts
+func (s *ProjectService) GetProject(id uuid.UUID, userID uuid.UUID) (*models.Project, error) {
+	project, err := s.getProject(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	projects := []models.Project{*project}
+	if err := s.countTasks(projects, userID); err != nil {
+		return nil, err
+	}
+	return &projects[0], nil
+}
+
+// CreateProject creates a project owned by project.UserID, after the user's other projects
+func (s *ProjectService) CreateProject(project *models.Project) error {
+	name, err := normalizeProjectName(project.Name)
+	if err != nil {
+		return err
+	}
+	if err := validateProjectColor(project.Color); err != nil {
+		return err
+	}
+
+	existing, err := s.projectRepo.GetProjectsByUserID(project.UserID, true)
+	if err != nil {
+		return err
+	}
+	project.ID = uuid.Nil
+	project.Name = name
+	project.Archived = false
+	project.Position = 0
+	for _, other := range existing {
+		project.Position = max(project.Position, other.Position+1)
+	}
+	return s.projectRepo.CreateProject(project)
+}
+
+// UpdateProject renames, recolours or reorders a project; nil fields are left unchanged. Only the
+// project's owners can.
+func (s *ProjectService) UpdateProject(id uuid.UUID, userID uuid.UUID, name *string, color *string, position *int) (*models.Project, error) {
+	project, err := s.authorize(id, userID, accessManage)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		if project.Name, err = normalizeProjectName(*name); err != nil {
+			return nil, err
+		}
+	}
+	if color != nil {
+		if err := validateProjectColor(*color); err != nil {
+			return nil, err
+		}
+		project.Color = *color
+	}
+	if position != nil {
+		if *position < 0 {
+			return nil, fmt.Errorf("%w: position must not be negative", ErrInvalidProject)
+		}
+		project.Position = *position
+	}
+	return s.saveProject(project, userID)
+}
+
+// SetArchived archives or unarchives a project for all of its members. The tasks of archived
+// projects are left out of task lists unless asked for. Only the project's owners can.
+func (s *ProjectService) SetArchived(id uuid.UUID, userID uuid.UUID, archived bool) (*models.Project, error) {
+	project, err := s.authorize(id, userID, accessManage)
+	if err != nil {
+		return nil, err
+	}
+	project.Archived = archived
+	return s.saveProject(project, userID)
+}
+
+// DeleteProject deletes a project, moving its tasks back to the inbox of the user who created it.
+// Only the project's owners can.
+func (s *ProjectService) DeleteProject(id uuid.UUID, userID uuid.UUID) error {
+	project, err := s.authorize(id, userID, accessManage)
+	if err != nil {
+		return err
+	}
+	err = s.projectRepo.DeleteProject(id, project.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrProjectNotFound
+	}
+	return err
+}
+
+// MoveTasks puts top-level tasks, with their subtasks, in a project, or back in the user's inbox
+// when projectID is nil. The user must be allowed to change the tasks where they are and where
+// they go, and tasks only move between the projects and inbox of the user who owns them.
+func (s *ProjectService) MoveTasks(projectID *uuid.UUID, taskIDs []uuid.UUID, userID uuid.UUID) error {
+	if len(taskIDs) == 0 {
+		return fmt.Errorf("%w: no tasks given", ErrInvalidProject)
+	}
+	ownerID := userID
+	if projectID != nil {
+		project, err := s.authorize(*projectID, userID, accessEdit)
+		if err != nil {
+			return err
+		}
+		ownerID = project.UserID
+	}
+
+	tasks, err := s.taskService.GetTasksByIDs(taskIDs, userID)
+	if err != nil {
+		return err
+	}
+	found := make(map[uuid.UUID]bool, len(tasks))
+	for _, task := range tasks {
+		if task.ParentID != nil {
+			return fmt.Errorf("%w: subtasks are in their parent's project", ErrInvalidProject)
+		}
+		if task.UserID != ownerID {
+			return fmt.Errorf("%w: tasks can only move between projects of the user who owns them", ErrInvalidProject)
+		}
+		if err := s.taskService.authorizeTask(&task, userID, accessEdit); err != nil {
+			return err
+		}
+		found[task.ID] = true
+	}
+	for _, id := range taskIDs {
+		if !found[id] {
+			return errors.New("task not found or unauthorized")
+		}
+	}
+	return s.projectRepo.MoveTasks(taskIDs, projectID, ownerID)
+}
+
+// getProject retrieves a project the user is a member of without its counts, mapping a missing
+// row to ErrProjectNotFound
+func (s *ProjectService) getProject(id uuid.UUID, userID uuid.UUID) (*models.Project, error) {
+	project, err := s.projectRepo.GetProjectByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+// saveProject writes a project and returns it as the user sees it, with its task counts
+func (s *ProjectService) saveProject(project *models.Project, userID uuid.UUID) (*models.Project, error) {
+	if err := s.projectRepo.UpdateProject(project); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	return s.GetProject(project.ID, userID)
+}
+
+// countTasks fills in the open and overdue task counts of the projects the user is a member of
+func (s *ProjectService) countTasks(projects []models.Project, userID uuid.UUID) error {
+	if len(projects) == 0 {
+		return nil
+	}
+	counts, err := s.projectRepo.GetProjectTaskCounts(userID, s.now())
+	if err != nil {
+		return err
+	}
+	byProject := make(map[uuid.UUID]models.ProjectTaskCounts, len(counts))
+	for _, count := range counts {
+		byProject[count.ProjectID] = count
+	}
+	for i := range projects {
+		projects[i].OpenCount = byProject[projects[i].ID].OpenCount
+		projects[i].OverdueCount = byProject[projects[i].ID].OverdueCount
+	}
+	return nil
+}
+
+// normalizeProjectName trims a project name and collapses runs of spaces
+func normalizeProjectName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	switch {
+	case name == "":
+		return "", fmt.Errorf("%w: name is required", ErrInvalidProject)
+	case utf8.RuneCountInString(name) > maxProjectNameLength:
+		return "", fmt.Errorf("%w: name is longer than %d characters", ErrInvalidProject, maxProjectNameLength)
+	}
+	return name, nil
+}
+
+// validateProjectColor accepts "#rrggbb" colours and the empty string
+func validateProjectColor(color string) error {
+	if color != "" && !colorPattern.MatchString(color) {
+		return fmt.Errorf("%w: color must look like #1e90ff", ErrInvalidProject)
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockProjectRepository is a mock implementation of ProjectRepositoryInterface
+type MockProjectRepository struct {
+	mock.Mock
+}
+
+func (m *MockProjectRepository) CreateProject(project *models.Project) error {
+	args := m.Called(project)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) GetProjectByID(id uuid.UUID, userID uuid.UUID) (*models.Project, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) GetProjectsByUserID(userID uuid.UUID, includeArchived bool) ([]models.Project, error) {
+	args := m.Called(userID, includeArchived)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) GetProjectTaskCounts(userID uuid.UUID, now time.Time) ([]models.ProjectTaskCounts, error) {
+	args := m.Called(userID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ProjectTaskCounts), args.Error(1)
+}
+
+func (m *MockProjectRepository) UpdateProject(project *models.Project) error {
+	args := m.Called(project)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) DeleteProject(id uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) MoveTasks(ids []uuid.UUID, projectID *uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(ids, projectID, userID)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) GetProjectMembers(projectID uuid.UUID) ([]models.ProjectMember, error) {
+	args := m.Called(projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ProjectMember), args.Error(1)
+}
+
+func (m *MockProjectRepository) UpdateProjectMember(member *models.ProjectMember) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) RemoveProjectMember(projectID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(projectID, userID)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) CreateInvitation(invitation *models.ProjectInvitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) GetInvitationByID(id uuid.UUID) (*models.ProjectInvitation, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProjectInvitation), args.Error(1)
+}
+
+func (m *MockProjectRepository) GetPendingInvitations(projectID uuid.UUID) ([]models.ProjectInvitation, error) {
+	args := m.Called(projectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ProjectInvitation), args.Error(1)
+}
+
+func (m *MockProjectRepository) GetPendingInvitationsByInvitee(inviteeID uuid.UUID) ([]models.ProjectInvitation, error) {
+	args := m.Called(inviteeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ProjectInvitation), args.Error(1)
+}
+
+func (m *MockProjectRepository) UpdateInvitation(invitation *models.ProjectInvitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) DeleteInvitation(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) AcceptInvitation(invitation *models.ProjectInvitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func TestProjectService_CreateProject(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("puts the project after the user's others", func(t *testing.T) {
+		mockProjectRepo := new(MockProjectRepository)
+		projectService := NewProjectService(mockProjectRepo, new(MockUserRepository), NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+		mockProjectRepo.On("GetProjectsByUserID", userID, true).Return([]models.Project{{Position: 0}, {Position: 3, Archived: true}}, nil).Once()
+		mockProjectRepo.On("CreateProject", mock.Anything).Return(nil).Once()
+
+		project := &models.Project{UserID: userID, Name: "  Home   renovation ", Archived: true}
+		assert.NoError(t, projectService.CreateProject(project))
+		assert.Equal(t, "Home renovation", project.Name)
+		assert.Equal(t, 4, project.Position)
+		assert.False(t, project.Archived)
+		mockProjectRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid names and colours", func(t *testing.T) {
+		mockProjectRepo := new(MockProjectRepository)
+		projectService := NewProjectService(mockProjectRepo, new(MockUserRepository), NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+
+		for _, project := range []models.Project{
+			{UserID: userID, Name: "  "},
+			{UserID: userID, Name: "Home", Color: "blue"},
+		} {
+			assert.ErrorIs(t, projectService.CreateProject(&project), ErrInvalidProject, project.Name)
+		}
+		mockProjectRepo.AssertNotCalled(t, "CreateProject", mock.Anything)
+	})
+}
+
+func TestProjectService_GetProjects(t *testing.T) {
+	userID := uuid.New()
+	home := models.Project{ID: uuid.New(), UserID: userID, Name: "Home"}
+	work := models.Project{ID: uuid.New(), UserID: userID, Name: "Work"}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mockProjectRepo := new(MockProjectRepository)
+	projectService := NewProjectService(mockProjectRepo, new(MockUserRepository), NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+	projectService.now = func() time.Time { return now }
+	mockProjectRepo.On("GetProjectsByUserID", userID, false).Return([]models.Project{home, work}, nil).Once()
+	mockProjectRepo.On("GetProjectTaskCounts", userID, now).Return([]models.ProjectTaskCounts{
+		{ProjectID: work.ID, OpenCount: 3, OverdueCount: 1},
+	}, nil).Once()
+
+	projects, err := projectService.GetProjects(userID, false)
+	if !assert.NoError(t, err) || !assert.Len(t, projects, 2) {
+		return
+	}
+	// Projects without open tasks are counted as empty
+	assert.Zero(t, projects[0].OpenCount)
+	assert.Equal(t, 3, projects[1].OpenCount)
+	assert.Equal(t, 1, projects[1].OverdueCount)
+	mockProjectRepo.AssertExpectations(t)
+}
+
+func TestProjectService_MoveTasks(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID, Name: "Home", Role: models.ProjectRoleOwner}
+	parentID := uuid.New()
+	task := models.Task{ID: uuid.New(), UserID: userID}
+	subtask := models.Task{ID: uuid.New(), UserID: userID, ParentID: &parentID}
+
+	t.Run("moves top-level tasks", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		projectService := NewProjectService(mockProjectRepo, new(MockUserRepository), NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+		mockProjectRepo.On("GetProjectByID", project.ID, userID).Return(project, nil).Once()
+		mockTaskRepo.On("GetTasksByIDs", []uuid.UUID{task.ID}, userID).Return([]models.Task{task}, nil).Once()
+		mockProjectRepo.On("MoveTasks", []uuid.UUID{task.ID}, &project.ID, userID).Return(nil).Once()
+
+		assert.NoError(t, projectService.MoveTasks(&project.ID, []uuid.UUID{task.ID}, userID))
+		mockProjectRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects subtasks and unknown tasks", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		projectService := NewProjectService(mockProjectRepo, new(MockUserRepository), NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+		mockProjectRepo.On("GetProjectByID", project.ID, userID).Return(project, nil)
+		mockTaskRepo.On("GetTasksByIDs", []uuid.UUID{subtask.ID}, userID).Return([]models.Task{subtask}, nil).Once()
+		mockTaskRepo.On("GetTasksByIDs", []uuid.UUID{task.ID, uuid.Nil}, userID).Return([]models.Task{task}, nil).Once()
+
+		err := projectService.MoveTasks(&project.ID, []uuid.UUID{subtask.ID}, userID)
+		assert.ErrorIs(t, err, ErrInvalidProject)
+		err = projectService.MoveTasks(&project.ID, []uuid.UUID{task.ID, uuid.Nil}, userID)
+		assert.EqualError(t, err, "task not found or unauthorized")
+		mockProjectRepo.AssertNotCalled(t, "MoveTasks", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestProjectService_Invite(t *testing.T) {
+	ownerID := uuid.New()
+	invitee := &models.User{ID: uuid.New(), Email: "sam@example.com"}
+	project := &models.Project{ID: uuid.New(), UserID: ownerID, Name: "Household", Role: models.ProjectRoleOwner}
+
+	t.Run("invites a registered user", func(t *testing.T) {
+		mockProjectRepo := new(MockProjectRepository)
+		mockUserRepo := new(MockUserRepository)
+		projectService := NewProjectService(mockProjectRepo, mockUserRepo, NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+		mockProjectRepo.On("GetProjectByID", project.ID, ownerID).Return(project, nil).Once()
+		mockUserRepo.On("GetUserByEmail", "sam@example.com").Return(invitee, nil).Once()
+		mockProjectRepo.On("GetProjectMembers", project.ID).Return([]models.ProjectMember{{ProjectID: project.ID, UserID: ownerID, Role: models.ProjectRoleOwner}}, nil).Once()
+		mockProjectRepo.On("GetPendingInvitations", project.ID).Return([]models.ProjectInvitation{}, nil).Once()
+		mockProjectRepo.On("CreateInvitation", mock.Anything).Return(nil).Once()
+
+		invitation, err := projectService.Invite(project.ID, " sam@example.com ", models.ProjectRoleEditor, ownerID)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, invitee.ID, invitation.InviteeID)
+		assert.Equal(t, ownerID, invitation.InviterID)
+		assert.Equal(t, models.InvitationPending, invitation.Status)
+		mockProjectRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown emails, invalid roles and non-owners", func(t *testing.T) {
+		mockProjectRepo := new(MockProjectRepository)
+		mockUserRepo := new(MockUserRepository)
+		projectService := NewProjectService(mockProjectRepo, mockUserRepo, NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+		editorID := uuid.New()
+		mockProjectRepo.On("GetProjectByID", project.ID, ownerID).Return(project, nil)
+		mockProjectRepo.On("GetProjectByID", project.ID, editorID).Return(&models.Project{ID: project.ID, UserID: ownerID, Role: models.ProjectRoleEditor}, nil)
+		mockUserRepo.On("GetUserByEmail", "nobody@example.com").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := projectService.Invite(project.ID, "nobody@example.com", models.ProjectRoleViewer, ownerID)
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+		_, err = projectService.Invite(project.ID, "sam@example.com", "admin", ownerID)
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+		_, err = projectService.Invite(project.ID, "sam@example.com", models.ProjectRoleViewer, editorID)
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+		mockProjectRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything)
+	})
+}
+
+func TestProjectService_Members(t *testing.T) {
+	ownerID := uuid.New()
+	viewerID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: ownerID, Name: "Household", Role: models.ProjectRoleOwner}
+	members := []models.ProjectMember{
+		{ProjectID: project.ID, UserID: ownerID, Email: "alex@example.com", Role: models.ProjectRoleOwner},
+		{ProjectID: project.ID, UserID: viewerID, Email: "sam@example.com", Role: models.ProjectRoleViewer},
+	}
+
+	t.Run("keeps the project's creator an owner", func(t *testing.T) {
+		mockProjectRepo := new(MockProjectRepository)
+		projectService := NewProjectService(mockProjectRepo, new(MockUserRepository), NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+		mockProjectRepo.On("GetProjectByID", project.ID, ownerID).Return(project, nil)
+
+		_, err := projectService.UpdateMember(project.ID, ownerID, models.ProjectRoleEditor, ownerID)
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+		assert.ErrorIs(t, projectService.RemoveMember(project.ID, ownerID, ownerID), ErrInvalidInvitation)
+		mockProjectRepo.AssertNotCalled(t, "UpdateProjectMember", mock.Anything)
+		mockProjectRepo.AssertNotCalled(t, "RemoveProjectMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("lets viewers leave but not remove others", func(t *testing.T) {
+		mockProjectRepo := new(MockProjectRepository)
+		projectService := NewProjectService(mockProjectRepo, new(MockUserRepository), NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+		mockProjectRepo.On("GetProjectByID", project.ID, viewerID).Return(&models.Project{ID: project.ID, UserID: ownerID, Role: models.ProjectRoleViewer}, nil)
+		mockProjectRepo.On("RemoveProjectMember", project.ID, viewerID).Return(nil).Once()
+
+		assert.ErrorIs(t, projectService.RemoveMember(project.ID, uuid.New(), viewerID), ErrPermissionDenied)
+		assert.NoError(t, projectService.RemoveMember(project.ID, viewerID, viewerID))
+		mockProjectRepo.AssertExpectations(t)
+	})
+
+	t.Run("changes the role of a member", func(t *testing.T) {
+		mockProjectRepo := new(MockProjectRepository)
+		projectService := NewProjectService(mockProjectRepo, new(MockUserRepository), NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+		mockProjectRepo.On("GetProjectByID", project.ID, ownerID).Return(project, nil).Once()
+		mockProjectRepo.On("GetProjectMembers", project.ID).Return(members, nil).Once()
+		mockProjectRepo.On("UpdateProjectMember", mock.Anything).Return(nil).Once()
+
+		member, err := projectService.UpdateMember(project.ID, viewerID, models.ProjectRoleEditor, ownerID)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, models.ProjectRoleEditor, member.Role)
+		assert.Equal(t, "sam@example.com", member.Email)
+		mockProjectRepo.AssertExpectations(t)
+	})
+}
+
+func TestTaskService_SharedProjectTasks(t *testing.T) {
+	ownerID := uuid.New()
+	memberID := uuid.New()
+	projectID := uuid.New()
+	task := &models.Task{ID: uuid.New(), UserID: ownerID, ProjectID: &projectID, Title: "Clean the gutters", Version: 1}
+
+	setup := func(role string) (*TaskService, *MockTaskRepository) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		taskService.SetProjectService(NewProjectService(mockProjectRepo, new(MockUserRepository), taskService))
+		mockProjectRepo.On("GetProjectByID", projectID, memberID).Return(&models.Project{ID: projectID, UserID: ownerID, Role: role}, nil)
+		mockTaskRepo.On("GetTaskByID", task.ID, memberID).Return(task, nil)
+		return taskService, mockTaskRepo
+	}
+
+	t.Run("viewers cannot change tasks", func(t *testing.T) {
+		taskService, mockTaskRepo := setup(models.ProjectRoleViewer)
+		title := "Skip the gutters"
+
+		_, err := taskService.PatchTask(task.ID, memberID, models.TaskPatch{Title: &title}, repositories.TaskPrecondition{})
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+		assert.ErrorIs(t, taskService.DeleteTask(task.ID, memberID, repositories.TaskPrecondition{}), ErrPermissionDenied)
+		mockTaskRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything)
+		mockTaskRepo.AssertNotCalled(t, "DeleteTask", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("editors change tasks in the owner's name", func(t *testing.T) {
+		taskService, mockTaskRepo := setup(models.ProjectRoleEditor)
+		mockTaskRepo.On("DeleteTask", task.ID, ownerID, mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, taskService.DeleteTask(task.ID, memberID, repositories.TaskPrecondition{}))
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("tasks created in the project belong to its owner", func(t *testing.T) {
+		taskService, mockTaskRepo := setup(models.ProjectRoleEditor)
+		mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task"), mock.Anything).Return(nil).Once()
+
+		created := &models.Task{UserID: memberID, ProjectID: &projectID, Title: "Buy bin bags", Priority: "low"}
+		assert.NoError(t, taskService.CreateTask(created))
+		assert.Equal(t, ownerID, created.UserID)
+	})
+}
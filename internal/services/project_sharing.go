@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrMemberNotFound is returned for users who are not members of the project
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrInvitationNotFound is returned for invitations that do not exist, were answered already or
+	// are not the user's to answer
+	ErrInvitationNotFound = errors.New("invitation not found")
+	// ErrInvalidInvitation is wrapped by the validation errors of invitations and member roles
+	ErrInvalidInvitation = errors.New("invalid invitation")
+)
+
+// GetMembers retrieves the members of a project the user is a member of, with their roles
+func (s *ProjectService) GetMembers(projectID uuid.UUID, userID uuid.UUID) ([]models.ProjectMember, error) {
+	if _, err := s.authorize(projectID, userID, accessView); err != nil {
+		return nil, err
+	}
+	return s.projectRepo.GetProjectMembers(projectID)
+}
+
+// UpdateMember gives a project member another role. Only the project's owners can, and the user
+// who created the project always stays an owner.
+func (s *ProjectService) UpdateMember(projectID uuid.UUID, memberID uuid.UUID, role string, userID uuid.UUID) (*models.ProjectMember, error) {
+	if err := validateRole(role); err != nil {
+		return nil, err
+	}
+	project, err := s.authorize(projectID, userID, accessManage)
+	if err != nil {
+		return nil, err
+	}
+	if memberID == project.UserID && role != models.ProjectRoleOwner {
+		return nil, fmt.Errorf("%w: the project's creator is always an owner", ErrInvalidInvitation)
+	}
+
+	member, err := s.getMember(projectID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	member.Role = role
+	if err := s.projectRepo.UpdateProjectMember(member); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember takes a user out of a project. Owners can remove other members, and every member
+// can leave, except for the user who created the project.
+func (s *ProjectService) RemoveMember(projectID uuid.UUID, memberID uuid.UUID, userID uuid.UUID) error {
+	level := accessManage
+	if memberID == userID {
+		level = accessView
+	}
+	project, err := s.authorize(projectID, userID, level)
+	if err != nil {
+		return err
+	}
+	if memberID == project.UserID {
+		return fmt.Errorf("%w: the project's creator cannot be removed from it", ErrInvalidInvitation)
+	}
+
+	err = s.projectRepo.RemoveProjectMember(projectID, memberID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMemberNotFound
+	}
+	return err
+}
+
+// Invite asks the registered user with the email to join a project with a role. Only the
+// project's owners can invite. Inviting a user again while they have not answered changes the
+// role of the pending invitation.
+func (s *ProjectService) Invite(projectID uuid.UUID, email string, role string, userID uuid.UUID) (*models.ProjectInvitation, error) {
+	if err := validateRole(role); err != nil {
+		return nil, err
+	}
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, fmt.Errorf("%w: email is required", ErrInvalidInvitation)
+	}
+	if _, err := s.authorize(projectID, userID, accessManage); err != nil {
+		return nil, err
+	}
+
+	invitee, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: no user is registered with that email", ErrInvalidInvitation)
+		}
+		return nil, err
+	}
+	if _, err := s.getMember(projectID, invitee.ID); err == nil {
+		return nil, fmt.Errorf("%w: the user is already a member", ErrInvalidInvitation)
+	} else if !errors.Is(err, ErrMemberNotFound) {
+		return nil, err
+	}
+
+	pending, err := s.projectRepo.GetPendingInvitations(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pending {
+		if pending[i].InviteeID == invitee.ID {
+			pending[i].Role = role
+			if err := s.projectRepo.UpdateInvitation(&pending[i]); err != nil {
+				return nil, err
+			}
+			return &pending[i], nil
+		}
+	}
+
+	invitation := &models.ProjectInvitation{
+		ProjectID: projectID,
+		InviterID: userID,
+		InviteeID: invitee.ID,
+		Email:     invitee.Email,
+		Role:      role,
+		Status:    models.InvitationPending,
+	}
+	if err := s.projectRepo.CreateInvitation(invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// GetInvitations retrieves the pending invitations to a project. Only the project's owners can.
+func (s *ProjectService) GetInvitations(projectID uuid.UUID, userID uuid.UUID) ([]models.ProjectInvitation, error) {
+	if _, err := s.authorize(projectID, userID, accessManage); err != nil {
+		return nil, err
+	}
+	return s.projectRepo.GetPendingInvitations(projectID)
+}
+
+// RevokeInvitation withdraws an invitation to a project. Only the project's owners can.
+func (s *ProjectService) RevokeInvitation(projectID uuid.UUID, invitationID uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.authorize(projectID, userID, accessManage); err != nil {
+		return err
+	}
+	invitation, err := s.projectRepo.GetInvitationByID(invitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvitationNotFound
+		}
+		return err
+	}
+	if invitation.ProjectID != projectID || invitation.Status != models.InvitationPending {
+		return ErrInvitationNotFound
+	}
+
+	err = s.projectRepo.DeleteInvitation(invitationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvitationNotFound
+	}
+	return err
+}
+
+// GetUserInvitations retrieves the invitations the user has not answered yet, with the names of
+// their projects
+func (s *ProjectService) GetUserInvitations(userID uuid.UUID) ([]models.ProjectInvitation, error) {
+	return s.projectRepo.GetPendingInvitationsByInvitee(userID)
+}
+
+// AcceptInvitation makes the user a member of the project they were invited to, with the
+// invitation's role, and returns the project
+func (s *ProjectService) AcceptInvitation(id uuid.UUID, userID uuid.UUID) (*models.Project, error) {
+	invitation, err := s.pendingInvitation(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.projectRepo.AcceptInvitation(invitation); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	return s.GetProject(invitation.ProjectID, userID)
+}
+
+// DeclineInvitation turns down an invitation the user has not answered yet
+func (s *ProjectService) DeclineInvitation(id uuid.UUID, userID uuid.UUID) error {
+	invitation, err := s.pendingInvitation(id, userID)
+	if err != nil {
+		return err
+	}
+	invitation.Status = models.InvitationDeclined
+	err = s.projectRepo.UpdateInvitation(invitation)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvitationNotFound
+	}
+	return err
+}
+
+// pendingInvitation retrieves an invitation to the user that they have not answered yet
+func (s *ProjectService) pendingInvitation(id uuid.UUID, userID uuid.UUID) (*models.ProjectInvitation, error) {
+	invitation, err := s.projectRepo.GetInvitationByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	if invitation.InviteeID != userID || invitation.Status != models.InvitationPending {
+		return nil, ErrInvitationNotFound
+	}
+	return invitation, nil
+}
+
+// getMember retrieves a member of a project with their email
+func (s *ProjectService) getMember(projectID uuid.UUID, userID uuid.UUID) (*models.ProjectMember, error) {
+	members, err := s.projectRepo.GetProjectMembers(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range members {
+		if members[i].UserID == userID {
+			return &members[i], nil
+		}
+	}
+	return nil, ErrMemberNotFound
+}
+
+// validateRole accepts the viewer, editor and owner roles
+func validateRole(role string) error {
+	if !models.ValidProjectRole(role) {
+		return fmt.Errorf("%w: role must be viewer, editor or owner", ErrInvalidInvitation)
+	}
+	return nil
+}
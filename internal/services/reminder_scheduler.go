@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/notify"
+	"todo-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// ReminderScheduler delivers reminders when they are due.
+// Due reminders are claimed with a lease before they are delivered, so schedulers on several
+// server instances do not send the same reminder at once. Delivery is at-least-once: a scheduler
+// that dies while delivering leaves its reminders claimed until the lease expires, and they are
+// then delivered by the next scheduler to look, even those that went out before it died. The
+// same happens to a delivery that outlasts the lease. Notifiers pass on the notification's
+// IdempotencyKey, the reminder ID and the time it was set for, so receivers can drop the repeats.
+type ReminderScheduler struct {
+	reminderService *ReminderService
+	notifier        notify.Notifier
+	schedulerID     string
+
+	// Interval is how long the scheduler waits between looking for due reminders
+	Interval time.Duration
+	// Lease is how long a claimed reminder may take to deliver before another scheduler may claim it
+	Lease time.Duration
+	// BatchSize is the most reminders claimed at once
+	BatchSize int
+	// Timeout bounds each delivery; BatchSize deliveries must fit in the lease
+	Timeout time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewReminderScheduler creates a scheduler that delivers reminders through notifier
+func NewReminderScheduler(reminderService *ReminderService, notifier notify.Notifier) *ReminderScheduler {
+	hostname, _ := os.Hostname()
+	return &ReminderScheduler{
+		reminderService: reminderService,
+		notifier:        notifier,
+		schedulerID:     fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8]),
+		Interval:        30 * time.Second,
+		Lease:           10 * time.Minute,
+		BatchSize:       10,
+		Timeout:         30 * time.Second,
+	}
+}
+
+// Start delivers due reminders right away and then every Interval in the background, until ctx
+// is cancelled or Stop is called
+func (s *ReminderScheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			if _, err := s.RunOnce(ctx); err != nil {
+				log.Error().Err(err).Msg("Reminder delivery failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.Interval):
+			}
+		}
+	}()
+	log.Info().Dur("interval", s.Interval).Str("scheduler_id", s.schedulerID).Msg("Reminder scheduler started")
+}
+
+// Stop asks the scheduler to exit and waits for the deliveries in progress to finish
+func (s *ReminderScheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// RunOnce delivers the reminders that are due, a batch at a time until none are left, and
+// returns how many were sent. The returned error is about claiming and recording reminders,
+// since delivery failures are recorded on the reminder.
+func (s *ReminderScheduler) RunOnce(ctx context.Context) (int, error) {
+	sent := 0
+	for ctx.Err() == nil {
+		reminders, err := s.reminderService.claimDue(s.Lease, s.BatchSize, s.schedulerID)
+		if err != nil {
+			return sent, err
+		}
+		for i := range reminders {
+			reminder := &reminders[i]
+			deliveryErr := s.deliver(ctx, reminder)
+			if deliveryErr != nil {
+				log.Warn().Err(deliveryErr).Str("reminder_id", reminder.ID.String()).Int("attempt", reminder.Attempts).Msg("Reminder not delivered")
+			}
+			err := s.reminderService.finishDelivery(reminder, deliveryErr)
+			if errors.Is(err, repositories.ErrReminderLeaseLost) {
+				// Snoozed, dismissed or deleted while it was being delivered
+				continue
+			}
+			if err != nil {
+				return sent, err
+			}
+			if deliveryErr == nil {
+				sent++
+			}
+		}
+		if len(reminders) < s.BatchSize {
+			break
+		}
+	}
+	return sent, nil
+}
+
+func (s *ReminderScheduler) deliver(ctx context.Context, reminder *models.Reminder) error {
+	notification, err := s.reminderService.notification(reminder)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+	return s.notifier.Notify(ctx, notification)
+}
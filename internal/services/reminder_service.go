@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/notify"
+	"todo-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxReminderAttempts is how many times a reminder is tried before it is marked as failed
+const MaxReminderAttempts = 5
+
+var (
+	// ErrReminderNotFound is returned for reminders that do not exist or belong to another user
+	ErrReminderNotFound = errors.New("reminder not found or unauthorized")
+	// ErrInvalidReminder is wrapped by the validation errors of reminders
+	ErrInvalidReminder = errors.New("invalid reminder")
+)
+
+// ReminderService handles reminders about tasks
+type ReminderService struct {
+	reminderRepo repositories.ReminderRepositoryInterface
+	taskService  *TaskService
+	userRepo     repositories.UserRepositoryInterface
+	now          func() time.Time
+}
+
+// NewReminderService creates a new ReminderService
+func NewReminderService(reminderRepo repositories.ReminderRepositoryInterface, taskService *TaskService, userRepo repositories.UserRepositoryInterface) *ReminderService {
+	return &ReminderService{
+		reminderRepo: reminderRepo,
+		taskService:  taskService,
+		userRepo:     userRepo,
+		now:          time.Now,
+	}
+}
+
+// CreateReminder adds a reminder to a task. Exactly one of RemindAt and MinutesBefore must be
+// set; a reminder relative to the due date waits until the task has one.
+func (s *ReminderService) CreateReminder(reminder *models.Reminder) error {
+	if (reminder.RemindAt == nil) == (reminder.MinutesBefore == nil) {
+		return fmt.Errorf("%w: set either remind_at or minutes_before", ErrInvalidReminder)
+	}
+	if reminder.MinutesBefore != nil && *reminder.MinutesBefore < 0 {
+		return fmt.Errorf("%w: minutes_before must not be negative", ErrInvalidReminder)
+	}
+	task, err := s.taskService.getTask(reminder.TaskID, reminder.UserID)
+	if err != nil {
+		return err
+	}
+
+	reminder.ID = uuid.Nil
+	reminder.SnoozedUntil = nil
+	reminder.Status = models.ReminderPending
+	reminder.Schedule(task.DueDate)
+	return s.reminderRepo.CreateReminder(reminder)
+}
+
+// GetTaskReminders retrieves the reminders of a task
+func (s *ReminderService) GetTaskReminders(taskID uuid.UUID, userID uuid.UUID) ([]models.Reminder, error) {
+	if _, err := s.taskService.getTask(taskID, userID); err != nil {
+		return nil, err
+	}
+	return s.reminderRepo.GetRemindersByTaskID(taskID, userID)
+}
+
+// GetUpcomingReminders retrieves the user's reminders that have yet to go off, soonest first
+func (s *ReminderService) GetUpcomingReminders(userID uuid.UUID) ([]models.Reminder, error) {
+	return s.reminderRepo.GetUpcomingReminders(userID)
+}
+
+// DeleteReminder deletes a reminder of a task
+func (s *ReminderService) DeleteReminder(taskID uuid.UUID, id uuid.UUID, userID uuid.UUID) error {
+	reminder, err := s.getReminder(id, userID)
+	if err != nil {
+		return err
+	}
+	if reminder.TaskID != taskID {
+		return ErrReminderNotFound
+	}
+	err = s.reminderRepo.DeleteReminder(id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrReminderNotFound
+	}
+	return err
+}
+
+// SnoozeReminder makes a reminder go off again at until, whether or not it has gone off already
+func (s *ReminderService) SnoozeReminder(id uuid.UUID, userID uuid.UUID, until time.Time) (*models.Reminder, error) {
+	if !until.After(s.now()) {
+		return nil, fmt.Errorf("%w: a reminder can only be snoozed until a future time", ErrInvalidReminder)
+	}
+	reminder, err := s.getReminder(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	until = until.UTC()
+	reminder.SnoozedUntil = &until
+	reminder.Schedule(nil) // the snooze takes precedence over the due date
+	s.rearm(reminder)
+	if err := s.reminderRepo.UpdateReminder(reminder); err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+// DismissReminder stops a reminder from going off
+func (s *ReminderService) DismissReminder(id uuid.UUID, userID uuid.UUID) (*models.Reminder, error) {
+	reminder, err := s.getReminder(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	reminder.Status = models.ReminderDismissed
+	reminder.LockedAt = nil
+	reminder.LockedBy = ""
+	if err := s.reminderRepo.UpdateReminder(reminder); err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+// TaskDueDateChanged moves the reminders relative to a task's due date along with it, whoever set
+// them. Those that already went off are scheduled again if their new time is still ahead.
+func (s *ReminderService) TaskDueDateChanged(task *models.Task) error {
+	reminders, err := s.reminderRepo.GetAllRemindersByTaskID(task.ID)
+	if err != nil {
+		return err
+	}
+	for i := range reminders {
+		reminder := &reminders[i]
+		if reminder.MinutesBefore == nil || reminder.SnoozedUntil != nil {
+			continue
+		}
+		switch reminder.Status {
+		case models.ReminderPending:
+			reminder.Schedule(task.DueDate)
+		case models.ReminderSent, models.ReminderFailed:
+			reminder.Schedule(task.DueDate)
+			if reminder.FireAt == nil || !reminder.FireAt.After(s.now()) {
+				continue
+			}
+			s.rearm(reminder)
+		default:
+			continue
+		}
+		if err := s.reminderRepo.UpdateReminder(reminder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OccurrenceCreated gives the next occurrence of a repeating task the reminders relative to the
+// due date of the one before it, each for the user who set it
+func (s *ReminderService) OccurrenceCreated(previous *models.Task, occurrence *models.Task) error {
+	reminders, err := s.reminderRepo.GetAllRemindersByTaskID(previous.ID)
+	if err != nil {
+		return err
+	}
+	for _, reminder := range reminders {
+		if reminder.MinutesBefore == nil {
+			continue
+		}
+		minutes := *reminder.MinutesBefore
+		next := models.Reminder{
+			TaskID:        occurrence.ID,
+			UserID:        reminder.UserID,
+			MinutesBefore: &minutes,
+			Status:        models.ReminderPending,
+		}
+		next.Schedule(occurrence.DueDate)
+		if err := s.reminderRepo.CreateReminder(&next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// claimDue leases due reminders for delivery by lockedBy
+func (s *ReminderService) claimDue(lease time.Duration, limit int, lockedBy string) ([]models.Reminder, error) {
+	now := s.now()
+	return s.reminderRepo.ClaimDueReminders(now, now.Add(-lease), limit, lockedBy)
+}
+
+// notification gathers what a notifier needs to deliver a claimed reminder
+func (s *ReminderService) notification(reminder *models.Reminder) (notify.Notification, error) {
+	task, err := s.taskService.getTask(reminder.TaskID, reminder.UserID)
+	if err != nil {
+		return notify.Notification{}, err
+	}
+	user, err := s.userRepo.GetUserByID(reminder.UserID)
+	if err != nil {
+		return notify.Notification{}, err
+	}
+	settings, err := s.taskService.userSettings(reminder.UserID)
+	if err != nil {
+		return notify.Notification{}, err
+	}
+
+	n := notify.Notification{
+		ReminderID:  reminder.ID,
+		TaskID:      task.ID,
+		UserID:      reminder.UserID,
+		Email:       user.Email,
+		Title:       task.Title,
+		Description: task.Description,
+		DueDate:     task.DueDate,
+		Location:    settings.Location(),
+	}
+	if reminder.FireAt != nil {
+		n.RemindAt = *reminder.FireAt
+	}
+	// Retries move FireAt, so the key comes from the time the reminder was set to go off
+	if scheduledAt := reminder.ScheduledAt(task.DueDate); scheduledAt != nil {
+		n.IdempotencyKey = notify.IdempotencyKey(reminder.ID, *scheduledAt)
+	} else {
+		n.IdempotencyKey = notify.IdempotencyKey(reminder.ID, n.RemindAt)
+	}
+	return n, nil
+}
+
+// finishDelivery records the outcome of delivering a claimed reminder. Failed deliveries are
+// retried after a growing delay until MaxReminderAttempts is reached.
+func (s *ReminderService) finishDelivery(reminder *models.Reminder, deliveryErr error) error {
+	now := s.now().UTC()
+	switch {
+	case deliveryErr == nil:
+		reminder.Status = models.ReminderSent
+		reminder.SentAt = &now
+		reminder.LastError = ""
+	case reminder.Attempts >= MaxReminderAttempts:
+		reminder.Status = models.ReminderFailed
+		reminder.LastError = deliveryErr.Error()
+	default:
+		retryAt := now.Add(time.Duration(reminder.Attempts*reminder.Attempts) * time.Minute)
+		reminder.Status = models.ReminderPending
+		reminder.FireAt = &retryAt
+		reminder.LastError = deliveryErr.Error()
+	}
+	return s.reminderRepo.ReleaseReminder(reminder)
+}
+
+// rearm makes a reminder pending again with a fresh set of attempts
+func (s *ReminderService) rearm(reminder *models.Reminder) {
+	reminder.Status = models.ReminderPending
+	reminder.Attempts = 0
+	reminder.LastError = ""
+	reminder.SentAt = nil
+	reminder.LockedAt = nil
+	reminder.LockedBy = ""
+}
+
+// getReminder retrieves a reminder, mapping a missing row to ErrReminderNotFound
+func (s *ReminderService) getReminder(id uuid.UUID, userID uuid.UUID) (*models.Reminder, error) {
+	reminder, err := s.reminderRepo.GetReminderByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReminderNotFound
+		}
+		return nil, err
+	}
+	return reminder, nil
+}
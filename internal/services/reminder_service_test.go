@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/notify"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReminderRepository is a mock implementation of ReminderRepositoryInterface
+type MockReminderRepository struct {
+	mock.Mock
+}
+
+func (m *MockReminderRepository) CreateReminder(reminder *models.Reminder) error {
+	args := m.Called(reminder)
+	return args.Error(0)
+}
+
+func (m *MockReminderRepository) GetReminderByID(id uuid.UUID, userID uuid.UUID) (*models.Reminder, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reminder), args.Error(1)
+}
+
+func (m *MockReminderRepository) GetRemindersByTaskID(taskID uuid.UUID, userID uuid.UUID) ([]models.Reminder, error) {
+	args := m.Called(taskID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Reminder), args.Error(1)
+}
+
+func (m *MockReminderRepository) GetAllRemindersByTaskID(taskID uuid.UUID) ([]models.Reminder, error) {
+	args := m.Called(taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Reminder), args.Error(1)
+}
+
+func (m *MockReminderRepository) GetUpcomingReminders(userID uuid.UUID) ([]models.Reminder, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Reminder), args.Error(1)
+}
+
+func (m *MockReminderRepository) UpdateReminder(reminder *models.Reminder) error {
+	args := m.Called(reminder)
+	return args.Error(0)
+}
+
+func (m *MockReminderRepository) DeleteReminder(id uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockReminderRepository) ClaimDueReminders(now time.Time, leaseExpiredBefore time.Time, limit int, lockedBy string) ([]models.Reminder, error) {
+	args := m.Called(now, leaseExpiredBefore, limit, lockedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Reminder), args.Error(1)
+}
+
+func (m *MockReminderRepository) ReleaseReminder(reminder *models.Reminder) error {
+	args := m.Called(reminder)
+	return args.Error(0)
+}
+
+// recordingNotifier records the notifications it is asked to deliver and fails with err
+type recordingNotifier struct {
+	notifications []notify.Notification
+	err           error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return n.err
+}
+
+func TestReminderService_CreateReminder(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+	due := time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC)
+	minutes := 30
+
+	t.Run("schedules a relative reminder before the due date", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockReminderRepo := new(MockReminderRepository)
+		reminderService := NewReminderService(mockReminderRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)), new(MockUserRepository))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID, DueDate: &due}, nil).Once()
+		mockReminderRepo.On("CreateReminder", mock.Anything).Return(nil).Once()
+
+		reminder := &models.Reminder{TaskID: taskID, UserID: userID, MinutesBefore: &minutes}
+		err := reminderService.CreateReminder(reminder)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, models.ReminderPending, reminder.Status)
+		if assert.NotNil(t, reminder.FireAt) {
+			assert.Equal(t, due.Add(-30*time.Minute), *reminder.FireAt)
+		}
+	})
+
+	t.Run("leaves a relative reminder unscheduled while the task has no due date", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockReminderRepo := new(MockReminderRepository)
+		reminderService := NewReminderService(mockReminderRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)), new(MockUserRepository))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID}, nil).Once()
+		mockReminderRepo.On("CreateReminder", mock.Anything).Return(nil).Once()
+
+		reminder := &models.Reminder{TaskID: taskID, UserID: userID, MinutesBefore: &minutes}
+		assert.NoError(t, reminderService.CreateReminder(reminder))
+		assert.Nil(t, reminder.FireAt)
+	})
+
+	t.Run("requires exactly one of remind_at and minutes_before", func(t *testing.T) {
+		mockReminderRepo := new(MockReminderRepository)
+		reminderService := NewReminderService(mockReminderRepo, NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)), new(MockUserRepository))
+
+		err := reminderService.CreateReminder(&models.Reminder{TaskID: taskID, UserID: userID})
+		assert.ErrorIs(t, err, ErrInvalidReminder)
+		err = reminderService.CreateReminder(&models.Reminder{TaskID: taskID, UserID: userID, RemindAt: &due, MinutesBefore: &minutes})
+		assert.ErrorIs(t, err, ErrInvalidReminder)
+		mockReminderRepo.AssertNotCalled(t, "CreateReminder", mock.Anything)
+	})
+}
+
+func TestReminderService_TaskDueDateChanged(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+	now := time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC)
+	oldFireAt := time.Date(2030, 3, 1, 8, 30, 0, 0, time.UTC)
+	due := time.Date(2030, 3, 2, 9, 0, 0, 0, time.UTC)
+	minutes := 30
+	sentAt := oldFireAt
+
+	mockReminderRepo := new(MockReminderRepository)
+	reminderService := NewReminderService(mockReminderRepo, NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)), new(MockUserRepository))
+	reminderService.now = func() time.Time { return now }
+
+	// A project member's reminder moves along with the owner's
+	pending := models.Reminder{ID: uuid.New(), TaskID: taskID, UserID: uuid.New(), MinutesBefore: &minutes, FireAt: &oldFireAt, Status: models.ReminderPending}
+	sent := models.Reminder{ID: uuid.New(), TaskID: taskID, UserID: userID, MinutesBefore: &minutes, FireAt: &oldFireAt, Status: models.ReminderSent, Attempts: 1, SentAt: &sentAt}
+	dismissed := models.Reminder{ID: uuid.New(), TaskID: taskID, UserID: userID, MinutesBefore: &minutes, FireAt: &oldFireAt, Status: models.ReminderDismissed}
+	absolute := models.Reminder{ID: uuid.New(), TaskID: taskID, UserID: userID, RemindAt: &oldFireAt, FireAt: &oldFireAt, Status: models.ReminderPending}
+	mockReminderRepo.On("GetAllRemindersByTaskID", taskID).Return([]models.Reminder{pending, sent, dismissed, absolute}, nil).Once()
+
+	var updated []models.Reminder
+	mockReminderRepo.On("UpdateReminder", mock.Anything).Run(func(args mock.Arguments) {
+		updated = append(updated, *args.Get(0).(*models.Reminder))
+	}).Return(nil)
+
+	err := reminderService.TaskDueDateChanged(&models.Task{ID: taskID, UserID: userID, DueDate: &due})
+	if !assert.NoError(t, err) || !assert.Len(t, updated, 2) {
+		return
+	}
+
+	newFireAt := due.Add(-30 * time.Minute)
+	assert.Equal(t, pending.ID, updated[0].ID)
+	assert.Equal(t, newFireAt, *updated[0].FireAt)
+
+	// The reminder that already went off is armed again for the new due date
+	assert.Equal(t, sent.ID, updated[1].ID)
+	assert.Equal(t, newFireAt, *updated[1].FireAt)
+	assert.Equal(t, models.ReminderPending, updated[1].Status)
+	assert.Zero(t, updated[1].Attempts)
+	assert.Nil(t, updated[1].SentAt)
+}
+
+func TestReminderService_OccurrenceCreated(t *testing.T) {
+	ownerID := uuid.New()
+	memberID := uuid.New()
+	minutes := 15
+	due := time.Date(2030, 3, 9, 9, 0, 0, 0, time.UTC)
+	previous := &models.Task{ID: uuid.New(), UserID: ownerID}
+	occurrence := &models.Task{ID: uuid.New(), UserID: ownerID, DueDate: &due}
+
+	mockReminderRepo := new(MockReminderRepository)
+	reminderService := NewReminderService(mockReminderRepo, NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)), new(MockUserRepository))
+	mockReminderRepo.On("GetAllRemindersByTaskID", previous.ID).Return([]models.Reminder{
+		{ID: uuid.New(), TaskID: previous.ID, UserID: memberID, MinutesBefore: &minutes, Status: models.ReminderSent},
+		{ID: uuid.New(), TaskID: previous.ID, UserID: ownerID, RemindAt: &due, Status: models.ReminderSent},
+	}, nil).Once()
+	var created []models.Reminder
+	mockReminderRepo.On("CreateReminder", mock.Anything).Run(func(args mock.Arguments) {
+		created = append(created, *args.Get(0).(*models.Reminder))
+	}).Return(nil)
+
+	err := reminderService.OccurrenceCreated(previous, occurrence)
+	if !assert.NoError(t, err) || !assert.Len(t, created, 1, "only reminders relative to the due date carry over") {
+		return
+	}
+	assert.Equal(t, occurrence.ID, created[0].TaskID)
+	assert.Equal(t, memberID, created[0].UserID, "the reminder stays with the member who set it")
+	assert.Equal(t, due.Add(-15*time.Minute), *created[0].FireAt)
+	assert.Equal(t, models.ReminderPending, created[0].Status)
+}
+
+func TestReminderScheduler_RunOnce(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+	now := time.Date(2030, 3, 1, 8, 30, 0, 0, time.UTC)
+	due := time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	// setup returns a scheduler whose single claim yields the given reminder
+	setup := func(notifier notify.Notifier, reminder models.Reminder) (*ReminderScheduler, *MockReminderRepository) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockReminderRepo := new(MockReminderRepository)
+		mockUserRepo := new(MockUserRepository)
+		reminderService := NewReminderService(mockReminderRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)), mockUserRepo)
+		reminderService.now = func() time.Time { return now }
+		scheduler := NewReminderScheduler(reminderService, notifier)
+
+		mockReminderRepo.On("ClaimDueReminders", now, now.Add(-scheduler.Lease), scheduler.BatchSize, scheduler.schedulerID).Return([]models.Reminder{reminder}, nil).Once()
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID, Title: "Call the dentist", DueDate: &due}, nil)
+		mockUserRepo.On("GetUserByID", userID).Return(&models.User{ID: userID, Email: "user@example.com"}, nil)
+		return scheduler, mockReminderRepo
+	}
+	claimed := func(attempts int) models.Reminder {
+		fireAt := now
+		return models.Reminder{ID: uuid.New(), TaskID: taskID, UserID: userID, FireAt: &fireAt, Status: models.ReminderSending, Attempts: attempts, LockedBy: "scheduler"}
+	}
+
+	t.Run("delivers due reminders and marks them as sent", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		reminder := claimed(1)
+		scheduler, mockReminderRepo := setup(notifier, reminder)
+		mockReminderRepo.On("ReleaseReminder", mock.MatchedBy(func(r *models.Reminder) bool {
+			return r.ID == reminder.ID && r.Status == models.ReminderSent && r.SentAt != nil
+		})).Return(nil).Once()
+
+		sent, err := scheduler.RunOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		if assert.Len(t, notifier.notifications, 1) {
+			assert.Equal(t, reminder.ID, notifier.notifications[0].ReminderID)
+			assert.Equal(t, "Call the dentist", notifier.notifications[0].Title)
+			assert.Equal(t, "user@example.com", notifier.notifications[0].Email)
+			assert.Equal(t, now, notifier.notifications[0].RemindAt)
+			assert.Equal(t, notify.IdempotencyKey(reminder.ID, now), notifier.notifications[0].IdempotencyKey)
+		}
+		mockReminderRepo.AssertExpectations(t)
+	})
+
+	t.Run("keys a retried delivery by the time the reminder was set for", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		reminder := claimed(2)
+		minutes := 60 // set for 8:00, retried at 8:30
+		reminder.MinutesBefore = &minutes
+		scheduler, mockReminderRepo := setup(notifier, reminder)
+		mockReminderRepo.On("ReleaseReminder", mock.Anything).Return(nil).Once()
+
+		_, err := scheduler.RunOnce(context.Background())
+		assert.NoError(t, err)
+		if assert.Len(t, notifier.notifications, 1) {
+			assert.Equal(t, notify.IdempotencyKey(reminder.ID, due.Add(-time.Hour)), notifier.notifications[0].IdempotencyKey)
+		}
+	})
+
+	t.Run("retries a failed delivery later", func(t *testing.T) {
+		reminder := claimed(2)
+		scheduler, mockReminderRepo := setup(&recordingNotifier{err: errors.New("connection refused")}, reminder)
+		mockReminderRepo.On("ReleaseReminder", mock.MatchedBy(func(r *models.Reminder) bool {
+			return r.Status == models.ReminderPending && r.FireAt.Equal(now.Add(4*time.Minute)) && r.LastError == "connection refused"
+		})).Return(nil).Once()
+
+		sent, err := scheduler.RunOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Zero(t, sent)
+		mockReminderRepo.AssertExpectations(t)
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		reminder := claimed(MaxReminderAttempts)
+		scheduler, mockReminderRepo := setup(&recordingNotifier{err: errors.New("connection refused")}, reminder)
+		mockReminderRepo.On("ReleaseReminder", mock.MatchedBy(func(r *models.Reminder) bool {
+			return r.Status == models.ReminderFailed
+		})).Return(nil).Once()
+
+		_, err := scheduler.RunOnce(context.Background())
+		assert.NoError(t, err)
+		mockReminderRepo.AssertExpectations(t)
+	})
+}
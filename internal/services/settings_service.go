@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"todo-backend/internal/dateparse"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidSettings is wrapped by the validation errors of UpdateSettings
+var ErrInvalidSettings = errors.New("invalid settings")
+
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// SettingsService handles user preferences
+type SettingsService struct {
+	settingsRepo repositories.UserSettingsRepositoryInterface
+}
+
+// NewSettingsService creates a new SettingsService
+func NewSettingsService(settingsRepo repositories.UserSettingsRepositoryInterface) *SettingsService {
+	return &SettingsService{settingsRepo: settingsRepo}
+}
+
+// GetSettings retrieves a user's settings, falling back to the defaults for users who have never saved any
+func (s *SettingsService) GetSettings(userID uuid.UUID) (*models.UserSettings, error) {
+	settings, err := s.settingsRepo.GetUserSettings(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.NewUserSettings(userID), nil
+		}
+		return nil, err
+	}
+	return settings, nil
+}
+
+// UpdateSettings validates and applies the fields set in req
+func (s *SettingsService) UpdateSettings(userID uuid.UUID, req *models.UpdateUserSettingsRequest) (*models.UserSettings, error) {
+	settings, err := s.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Timezone != nil {
+		// "Local" would silently mean the server's zone
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" || *req.Timezone == "Local" {
+			return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidSettings, *req.Timezone)
+		}
+		settings.Timezone = *req.Timezone
+	}
+	if req.Locale != nil {
+		if !localePattern.MatchString(*req.Locale) {
+			return nil, fmt.Errorf("%w: malformed locale %q", ErrInvalidSettings, *req.Locale)
+		}
+		settings.Locale = *req.Locale
+	}
+	if req.WeekStart != nil {
+		weekStart := strings.ToLower(*req.WeekStart)
+		if weekStart != "saturday" && weekStart != "sunday" && weekStart != "monday" {
+			return nil, fmt.Errorf("%w: week_start must be saturday, sunday or monday", ErrInvalidSettings)
+		}
+		settings.WeekStart = weekStart
+	}
+	if req.DefaultPriority != nil {
+		settings.DefaultPriority = *req.DefaultPriority
+	}
+
+	if err := s.settingsRepo.SaveUserSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Clock returns the current time in the user's time zone and a date parser using their week start
+func (s *SettingsService) Clock(userID uuid.UUID) (time.Time, *dateparse.Parser, error) {
+	settings, err := s.GetSettings(userID)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return settings.Now(), &dateparse.Parser{WeekStart: settings.WeekStartDay()}, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-backend/internal/llm"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockUserSettingsRepository is a mock implementation of UserSettingsRepositoryInterface
+type MockUserSettingsRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserSettingsRepository) GetUserSettings(userID uuid.UUID) (*models.UserSettings, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserSettings), args.Error(1)
+}
+
+func (m *MockUserSettingsRepository) SaveUserSettings(settings *models.UserSettings) error {
+	args := m.Called(settings)
+	return args.Error(0)
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestSettingsService_GetSettings(t *testing.T) {
+	mockSettingsRepo := new(MockUserSettingsRepository)
+	settingsService := NewSettingsService(mockSettingsRepo)
+	userID := uuid.New()
+
+	t.Run("returns the defaults when nothing is stored", func(t *testing.T) {
+		mockSettingsRepo.On("GetUserSettings", userID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		settings, err := settingsService.GetSettings(userID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.NewUserSettings(userID), settings)
+		mockSettingsRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns database errors", func(t *testing.T) {
+		mockSettingsRepo.On("GetUserSettings", userID).Return(nil, errors.New("db error")).Once()
+
+		settings, err := settingsService.GetSettings(userID)
+		assert.EqualError(t, err, "db error")
+		assert.Nil(t, settings)
+		mockSettingsRepo.AssertExpectations(t)
+	})
+}
+
+func TestSettingsService_UpdateSettings(t *testing.T) {
+	mockSettingsRepo := new(MockUserSettingsRepository)
+	settingsService := NewSettingsService(mockSettingsRepo)
+	userID := uuid.New()
+
+	t.Run("applies and saves the given fields", func(t *testing.T) {
+		stored := models.NewUserSettings(userID)
+		stored.Locale = "de-DE"
+		mockSettingsRepo.On("GetUserSettings", userID).Return(stored, nil).Once()
+		mockSettingsRepo.On("SaveUserSettings", stored).Return(nil).Once()
+
+		settings, err := settingsService.UpdateSettings(userID, &models.UpdateUserSettingsRequest{
+			Timezone:  stringPtr("Europe/Berlin"),
+			WeekStart: stringPtr("Sunday"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "Europe/Berlin", settings.Timezone)
+		assert.Equal(t, "sunday", settings.WeekStart)
+		assert.Equal(t, "de-DE", settings.Locale)
+		assert.Equal(t, time.Sunday, settings.WeekStartDay())
+		mockSettingsRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid values without saving", func(t *testing.T) {
+		mockSettingsRepo := new(MockUserSettingsRepository)
+		settingsService := NewSettingsService(mockSettingsRepo)
+		requests := []*models.UpdateUserSettingsRequest{
+			{Timezone: stringPtr("Nowhere/Special")},
+			{Timezone: stringPtr("Local")},
+			{Locale: stringPtr("english please")},
+			{WeekStart: stringPtr("thursday")},
+		}
+		for _, req := range requests {
+			mockSettingsRepo.On("GetUserSettings", userID).Return(models.NewUserSettings(userID), nil).Once()
+
+			settings, err := settingsService.UpdateSettings(userID, req)
+			assert.ErrorIs(t, err, ErrInvalidSettings)
+			assert.Nil(t, settings)
+		}
+		mockSettingsRepo.AssertNotCalled(t, "SaveUserSettings", mock.Anything)
+	})
+}
+
+func TestTaskService_UsesUserSettings(t *testing.T) {
+	mockTaskRepo := new(MockTaskRepository)
+	mockLLMExtractor := new(MockLLMExtractor)
+	mockSettingsRepo := new(MockUserSettingsRepository)
+	taskService := NewTaskService(mockTaskRepo, mockLLMExtractor)
+	taskService.SetSettingsService(NewSettingsService(mockSettingsRepo))
+
+	userID := uuid.New()
+	settings := models.NewUserSettings(userID)
+	settings.Timezone = "America/New_York"
+	settings.WeekStart = "sunday"
+	settings.Locale = "en-US"
+	settings.DefaultPriority = "low"
+	mockSettingsRepo.On("GetUserSettings", userID).Return(settings, nil)
+
+	t.Run("passes the user's time zone and preferences to the extractor", func(t *testing.T) {
+		mockLLMExtractor.On("ExtractTasks", mock.AnythingOfType("context.backgroundCtx"), "Call mom", mock.MatchedBy(func(opts llm.ExtractOptions) bool {
+			return opts.Location.String() == "America/New_York" && opts.WeekStart == time.Sunday &&
+				opts.Locale == "en-US" && opts.DefaultPriority == "low"
+		})).Return([]llm.Task{{Title: "Call mom"}}, nil).Once()
+		mockTaskRepo.On("CreateTask", mock.MatchedBy(func(task *models.Task) bool {
+			return task.Priority == "low"
+		}), mock.Anything).Return(nil).Once()
+
+		createdTasks, err := taskService.ExtractAndCreateTasks(context.Background(), "Call mom", userID)
+		assert.NoError(t, err)
+		assert.Len(t, createdTasks, 1)
+		mockLLMExtractor.AssertExpectations(t)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("gives new tasks the user's default priority", func(t *testing.T) {
+		task := &models.Task{UserID: userID, Title: "Water plants"}
+		mockTaskRepo.On("CreateTask", task, mock.Anything).Return(nil).Once()
+
+		err := taskService.CreateTask(task)
+		assert.NoError(t, err)
+		assert.Equal(t, "low", task.Priority)
+		mockTaskRepo.AssertExpectations(t)
+	})
+}
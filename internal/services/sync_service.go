@@ -0,0 +1,306 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSyncToken is returned for sync tokens not issued by Changes
+var ErrInvalidSyncToken = errors.New("invalid sync token")
+
+// ErrSyncTokenExpired is returned for tokens older than the tombstones of purged tasks. The
+// client may have missed deletions and has to sync from scratch.
+var ErrSyncTokenExpired = errors.New("sync token expired; sync again without a token")
+
+// SyncService lets offline-first clients pull the changes to their tasks and push the changes
+// they made while offline
+type SyncService struct {
+	taskRepo    repositories.TaskRepositoryInterface
+	taskService *TaskService
+}
+
+// NewSyncService creates a new SyncService; pushed changes go through taskService
+func NewSyncService(taskRepo repositories.TaskRepositoryInterface, taskService *TaskService) *SyncService {
+	return &SyncService{taskRepo: taskRepo, taskService: taskService}
+}
+
+// Changes retrieves one page of the changes to the user's tasks after token, which is empty on
+// the first sync. A first sync lists every task as created and reports no deletions.
+//
+// Change sequence numbers are issued per owner, so the user's own tasks and the tasks of each
+// project shared with them are separate streams, each with its position in the token. A project
+// the user joins later starts from scratch. Tasks that leave the user's view without being
+// deleted, because they left a project or the user left the project, have no tombstones, so
+// tokens from before that expire and the client syncs again from scratch.
+func (s *SyncService) Changes(userID uuid.UUID, token string, limit int) (*models.SyncChanges, error) {
+	since, err := parseSyncToken(token)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := s.taskRepo.GetSharedProjects(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &models.SyncChanges{
+		Created: []models.Task{},
+		Updated: []models.Task{},
+		Deleted: []models.TaskTombstone{},
+	}
+	next := syncToken{projects: make(map[uuid.UUID]int64)}
+	remaining := effectiveSyncLimit(limit)
+
+	next.own, remaining, err = s.pull(changes, userID, nil, userID, since.own, remaining)
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range projects {
+		position := since.projects[project.ID]
+		if remaining > 0 {
+			position, remaining, err = s.pull(changes, project.UserID, &project.ID, userID, position, remaining)
+			if err != nil {
+				return nil, err
+			}
+		}
+		next.projects[project.ID] = position
+	}
+	// A stream that filled the page may have more; so may the streams it left no room for
+	changes.HasMore = remaining <= 0
+	changes.Token = next.String()
+
+	// Read the projects again now that their counters are read, so that no task left them
+	// between since and the new token unnoticed
+	if projects, err = s.taskRepo.GetSharedProjects(userID); err != nil {
+		return nil, err
+	}
+	if err := checkProjectsLeft(since, projects); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// checkProjectsLeft fails with ErrSyncTokenExpired when the client synced one of its projects
+// before a task left it, or the project is no longer shared with the user
+func checkProjectsLeft(since syncToken, projects []models.Project) error {
+	shared := make(map[uuid.UUID]bool, len(projects))
+	for _, project := range projects {
+		shared[project.ID] = true
+		if position := since.projects[project.ID]; position > 0 && position < project.LeftSeq {
+			return ErrSyncTokenExpired
+		}
+	}
+	for id, position := range since.projects {
+		if position > 0 && !shared[id] {
+			return ErrSyncTokenExpired
+		}
+	}
+	return nil
+}
+
+// pull adds the changes of one stream after since to changes: the tasks of the user's own when
+// projectID is nil, those of a project shared with them otherwise. It returns the stream's new
+// position and how much room is left on the page, which is zero or less once the page is full.
+func (s *SyncService) pull(changes *models.SyncChanges, ownerID uuid.UUID, projectID *uuid.UUID, userID uuid.UUID, since int64, limit int) (int64, int, error) {
+	// Read the counter first: every change numbered up to it has committed, so the tasks read
+	// next include them all
+	counter, err := s.taskRepo.GetChangeCounter(ownerID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if since > 0 && since < counter.PurgedSeq {
+		return 0, 0, ErrSyncTokenExpired
+	}
+	var tasks []models.Task
+	if projectID == nil {
+		tasks, err = s.taskRepo.GetTaskChanges(userID, since, limit)
+	} else {
+		tasks, err = s.taskRepo.GetSharedTaskChanges(*projectID, userID, since, limit)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, task := range tasks {
+		switch {
+		case task.DeletedAt.Valid:
+			changes.Deleted = append(changes.Deleted, models.TaskTombstone{ID: task.ID, ParentID: task.ParentID, DeletedAt: task.DeletedAt.Time})
+		case task.CreatedSeq > since:
+			changes.Created = append(changes.Created, task)
+		default:
+			changes.Updated = append(changes.Updated, task)
+		}
+	}
+
+	last := since
+	if len(tasks) > 0 {
+		last = tasks[len(tasks)-1].ChangeSeq
+	}
+	if len(tasks) < limit {
+		// Everything up to the counter is covered, including changes left out on purpose, such as
+		// tasks created and deleted before a first sync
+		last = max(last, counter.Seq)
+	}
+	return last, limit - len(tasks), nil
+}
+
+// ApplyMutations applies a batch of offline changes in order. A failed mutation does not stop
+// the batch; each one gets its own result.
+func (s *SyncService) ApplyMutations(userID uuid.UUID, mutations []models.TaskMutation) []models.SyncResult {
+	results := make([]models.SyncResult, len(mutations))
+	for i, mutation := range mutations {
+		results[i] = s.ApplyMutation(userID, mutation)
+	}
+	return results
+}
+
+// ApplyMutation applies one offline change. Updates and deletions made on an older version than
+// the stored one are not applied and are reported as conflicts, with the server copy of the task,
+// as are completions of tasks that are still blocked.
+// Creating a task that already exists is reported as applied, so clients can safely resend.
+func (s *SyncService) ApplyMutation(userID uuid.UUID, mutation models.TaskMutation) models.SyncResult {
+	result := models.SyncResult{ID: mutation.ID, Op: mutation.Op}
+
+	var task *models.Task
+	var err error
+	switch mutation.Op {
+	case models.SyncCreate:
+		task, err = s.create(userID, mutation)
+	case models.SyncUpdate:
+		task, err = s.taskService.PatchTask(mutation.ID, userID, mutation.Patch, repositories.TaskPrecondition{Version: mutation.BaseVersion})
+	case models.SyncDelete:
+		err = s.taskService.DeleteTask(mutation.ID, userID, repositories.TaskPrecondition{Version: mutation.BaseVersion})
+	default:
+		err = errInvalidMutation("unknown op: " + mutation.Op)
+	}
+
+	var invalid errInvalidMutation
+	switch {
+	case err == nil:
+		result.Status = models.SyncApplied
+		result.Task = task
+	case errors.Is(err, repositories.ErrVersionConflict), errors.Is(err, ErrTaskBlocked):
+		result.Status = models.SyncConflict
+		result.Error = err.Error()
+		// The client needs the server copy to resolve the conflict; without it, it just resyncs
+		result.Task, _ = s.taskService.GetTaskByID(mutation.ID, userID)
+	case err.Error() == "task not found or unauthorized":
+		result.Status = models.SyncNotFound
+		result.Error = err.Error()
+	case errors.As(err, &invalid), errors.Is(err, ErrInvalidRecurrence), errors.Is(err, ErrInvalidProject), errors.Is(err, ErrProjectNotFound),
+		errors.Is(err, ErrPermissionDenied), errors.Is(err, ErrInvalidAssignee):
+		result.Status = models.SyncInvalid
+		result.Error = err.Error()
+	default:
+		result.Status = models.SyncFailed
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (s *SyncService) create(userID uuid.UUID, mutation models.TaskMutation) (*models.Task, error) {
+	if mutation.ID == uuid.Nil {
+		return nil, errInvalidMutation("id is required")
+	}
+	if existing, err := s.taskService.GetTaskByID(mutation.ID, userID); err == nil {
+		return existing, nil
+	}
+	if mutation.Patch.Title == nil {
+		return nil, errInvalidMutation("title is required")
+	}
+
+	task := &models.Task{ID: mutation.ID, UserID: userID}
+	mutation.Patch.Apply(task)
+	var err error
+	if mutation.ParentID != nil {
+		err = s.taskService.CreateSubtask(*mutation.ParentID, task, userID)
+	} else {
+		err = s.taskService.CreateTask(task)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mutation.Patch.Completed != nil && *mutation.Patch.Completed {
+		return s.taskService.CompleteTask(task.ID, userID, false, false)
+	}
+	return s.taskService.GetTaskByID(task.ID, userID)
+}
+
+// errInvalidMutation reports a mutation that can never be applied as sent
+type errInvalidMutation string
+
+func (e errInvalidMutation) Error() string { return string(e) }
+
+// syncToken is the position of a client in each of its change streams: its own tasks, and the
+// tasks of each project shared with it. Projects it has not synced yet are at 0.
+type syncToken struct {
+	own      int64
+	projects map[uuid.UUID]int64
+}
+
+// String encodes the token as the own position followed by project:position pairs, all comma
+// separated; projects at 0 are left out
+func (t syncToken) String() string {
+	token := strconv.FormatInt(t.own, 10)
+	ids := make([]uuid.UUID, 0, len(t.projects))
+	for id, position := range t.projects {
+		if position > 0 {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	for _, id := range ids {
+		token += "," + id.String() + ":" + strconv.FormatInt(t.projects[id], 10)
+	}
+	return token
+}
+
+// parseSyncToken reads the stream positions in a token issued by Changes
+func parseSyncToken(token string) (syncToken, error) {
+	parsed := syncToken{projects: make(map[uuid.UUID]int64)}
+	if token == "" {
+		return parsed, nil
+	}
+	parts := strings.Split(token, ",")
+	own, err := parseSyncPosition(parts[0])
+	if err != nil {
+		return parsed, err
+	}
+	parsed.own = own
+	for _, part := range parts[1:] {
+		id, position, found := strings.Cut(part, ":")
+		if !found {
+			return parsed, ErrInvalidSyncToken
+		}
+		projectID, err := uuid.Parse(id)
+		if err != nil {
+			return parsed, ErrInvalidSyncToken
+		}
+		if parsed.projects[projectID], err = parseSyncPosition(position); err != nil {
+			return parsed, err
+		}
+	}
+	return parsed, nil
+}
+
+// parseSyncPosition reads one change sequence number of a sync token
+func parseSyncPosition(s string) (int64, error) {
+	position, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || position < 0 {
+		return 0, ErrInvalidSyncToken
+	}
+	return position, nil
+}
+
+// effectiveSyncLimit is the page size GetTaskChanges uses for limit
+func effectiveSyncLimit(limit int) int {
+	if limit <= 0 {
+		return repositories.DefaultSyncLimit
+	}
+	return min(limit, repositories.MaxSyncLimit)
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestSyncService_Changes(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("sorts changes into created, updated and deleted", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		syncService := NewSyncService(mockTaskRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+		deletedAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+		tasks := []models.Task{
+			{ID: uuid.New(), UserID: userID, Title: "Old", CreatedSeq: 2, ChangeSeq: 6},
+			{ID: uuid.New(), UserID: userID, Title: "New", CreatedSeq: 7, ChangeSeq: 7},
+			{ID: uuid.New(), UserID: userID, Title: "Gone", CreatedSeq: 3, ChangeSeq: 8, DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+		}
+		mockTaskRepo.On("GetSharedProjects", userID).Return([]models.Project{}, nil)
+		mockTaskRepo.On("GetChangeCounter", userID).Return(&models.TaskChangeCounter{UserID: userID, Seq: 8, PurgedSeq: 3}, nil).Once()
+		mockTaskRepo.On("GetTaskChanges", userID, int64(5), repositories.DefaultSyncLimit).Return(tasks, nil).Once()
+
+		changes, err := syncService.Changes(userID, "5", 0)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, changes.Created, 1)
+		assert.Equal(t, "New", changes.Created[0].Title)
+		assert.Len(t, changes.Updated, 1)
+		assert.Equal(t, "Old", changes.Updated[0].Title)
+		assert.Equal(t, []models.TaskTombstone{{ID: tasks[2].ID, DeletedAt: deletedAt}}, changes.Deleted)
+		assert.Equal(t, "8", changes.Token)
+		assert.False(t, changes.HasMore)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("reports more when the page is full", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		syncService := NewSyncService(mockTaskRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+		mockTaskRepo.On("GetSharedProjects", userID).Return([]models.Project{}, nil)
+		mockTaskRepo.On("GetChangeCounter", userID).Return(&models.TaskChangeCounter{UserID: userID, Seq: 3}, nil).Twice()
+		mockTaskRepo.On("GetTaskChanges", userID, int64(0), 1).Return([]models.Task{{ID: uuid.New(), CreatedSeq: 1, ChangeSeq: 1}}, nil).Once()
+		mockTaskRepo.On("GetTaskChanges", userID, int64(1), 1).Return([]models.Task{}, nil).Once()
+
+		changes, err := syncService.Changes(userID, "", 1)
+		assert.NoError(t, err)
+		assert.True(t, changes.HasMore)
+		assert.Equal(t, "1", changes.Token)
+
+		changes, err = syncService.Changes(userID, changes.Token, 1)
+		assert.NoError(t, err)
+		assert.False(t, changes.HasMore)
+		assert.Equal(t, "3", changes.Token, "the changes left out of the listing are covered")
+	})
+
+	t.Run("keeps a position per project shared with the user", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		syncService := NewSyncService(mockTaskRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+		ownerID := uuid.New()
+		synced := models.Project{ID: uuid.MustParse("10000000-0000-0000-0000-000000000000"), UserID: ownerID}
+		joined := models.Project{ID: uuid.MustParse("20000000-0000-0000-0000-000000000000"), UserID: ownerID}
+		mockTaskRepo.On("GetSharedProjects", userID).Return([]models.Project{synced, joined}, nil)
+		mockTaskRepo.On("GetChangeCounter", userID).Return(&models.TaskChangeCounter{UserID: userID, Seq: 4}, nil).Once()
+		mockTaskRepo.On("GetChangeCounter", ownerID).Return(&models.TaskChangeCounter{UserID: ownerID, Seq: 30}, nil).Twice()
+		mockTaskRepo.On("GetTaskChanges", userID, int64(4), repositories.DefaultSyncLimit).Return([]models.Task{}, nil).Once()
+		mockTaskRepo.On("GetSharedTaskChanges", synced.ID, userID, int64(20), repositories.DefaultSyncLimit).
+			Return([]models.Task{{ID: uuid.New(), Title: "Shared", CreatedSeq: 12, ChangeSeq: 25}}, nil).Once()
+		mockTaskRepo.On("GetSharedTaskChanges", joined.ID, userID, int64(0), repositories.DefaultSyncLimit-1).
+			Return([]models.Task{{ID: uuid.New(), Title: "Joined", CreatedSeq: 5, ChangeSeq: 5}}, nil).Once()
+
+		changes, err := syncService.Changes(userID, "4,"+synced.ID.String()+":20", 0)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, changes.Updated, 1)
+		assert.Len(t, changes.Created, 1)
+		assert.Equal(t, "Joined", changes.Created[0].Title, "a project joined since the last sync starts from scratch")
+		assert.Equal(t, "4,"+synced.ID.String()+":30,"+joined.ID.String()+":30", changes.Token)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("expires tokens older than purged tombstones", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		syncService := NewSyncService(mockTaskRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+		mockTaskRepo.On("GetSharedProjects", userID).Return([]models.Project{}, nil)
+		mockTaskRepo.On("GetChangeCounter", userID).Return(&models.TaskChangeCounter{UserID: userID, Seq: 12, PurgedSeq: 9}, nil).Once()
+
+		_, err := syncService.Changes(userID, "8", 0)
+		assert.ErrorIs(t, err, ErrSyncTokenExpired)
+		mockTaskRepo.AssertNotCalled(t, "GetTaskChanges", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("expires tokens of projects the user left or tasks left since", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		syncService := NewSyncService(mockTaskRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+		ownerID := uuid.New()
+		project := models.Project{ID: uuid.New(), UserID: ownerID, LeftSeq: 25}
+		mockTaskRepo.On("GetSharedProjects", userID).Return([]models.Project{project}, nil)
+		mockTaskRepo.On("GetChangeCounter", userID).Return(&models.TaskChangeCounter{UserID: userID, Seq: 4}, nil)
+		mockTaskRepo.On("GetChangeCounter", ownerID).Return(&models.TaskChangeCounter{UserID: ownerID, Seq: 30}, nil)
+		mockTaskRepo.On("GetTaskChanges", userID, int64(4), repositories.DefaultSyncLimit).Return([]models.Task{}, nil)
+		mockTaskRepo.On("GetSharedTaskChanges", project.ID, userID, mock.Anything, repositories.DefaultSyncLimit).Return([]models.Task{}, nil)
+
+		_, err := syncService.Changes(userID, "4,"+project.ID.String()+":20", 0)
+		assert.ErrorIs(t, err, ErrSyncTokenExpired, "a task left the project after the client synced it")
+		_, err = syncService.Changes(userID, "4,"+project.ID.String()+":25", 0)
+		assert.NoError(t, err)
+		_, err = syncService.Changes(userID, "4,"+project.ID.String()+":25,"+uuid.NewString()+":7", 0)
+		assert.ErrorIs(t, err, ErrSyncTokenExpired, "the user left a project they synced")
+	})
+
+	t.Run("rejects malformed tokens", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		syncService := NewSyncService(mockTaskRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+
+		for _, token := range []string{"abc", "-1", "1.5", "1,2", "1,abc:2", "1," + uuid.NewString() + ":-2"} {
+			_, err := syncService.Changes(userID, token, 0)
+			assert.ErrorIs(t, err, ErrInvalidSyncToken, token)
+		}
+		mockTaskRepo.AssertNotCalled(t, "GetTaskChanges", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSyncService_ApplyMutation(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+	title := "Offline task"
+
+	t.Run("creates a task with the client's ID", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		syncService := NewSyncService(mockTaskRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
+		mockTaskRepo.On("CreateTask", mock.MatchedBy(func(task *models.Task) bool {
+			return task.ID == taskID && task.UserID == userID && task.Title == title && task.Priority == "medium"
+		}), mock.Anything).Return(nil).Once()
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID, Title: title}, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil).Once()
+
+		result := syncService.ApplyMutation(userID, models.TaskMutation{Op: models.SyncCreate, ID: taskID, Patch: models.TaskPatch{Title: &title}})
+		assert.Equal(t, models.SyncApplied, result.Status)
+		if assert.NotNil(t, result.Task) {
+			assert.Equal(t, taskID, result.Task.ID)
+		}
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("treats a resent create as applied", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		syncService := NewSyncService(mockTaskRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID, Title: title}, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil).Once()
+
+		result := syncService.ApplyMutation(userID, models.TaskMutation{Op: models.SyncCreate, ID: taskID, Patch: models.TaskPatch{Title: &title}})
+		assert.Equal(t, models.SyncApplied, result.Status)
+		mockTaskRepo.AssertNotCalled(t, "CreateTask", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a create without a title", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		syncService := NewSyncService(mockTaskRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result := syncService.ApplyMutation(userID, models.TaskMutation{Op: models.SyncCreate, ID: taskID})
+		assert.Equal(t, models.SyncInvalid, result.Status)
+		assert.Equal(t, "title is required", result.Error)
+		mockTaskRepo.AssertNotCalled(t, "CreateTask", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reports a stale update as a conflict with the server copy", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		syncService := NewSyncService(mockTaskRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+		stored := &models.Task{ID: taskID, UserID: userID, Title: "Server title", Version: 3}
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(stored, nil).Twice()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil).Once()
+
+		result := syncService.ApplyMutation(userID, models.TaskMutation{Op: models.SyncUpdate, ID: taskID, BaseVersion: 2, Patch: models.TaskPatch{Title: &title}})
+		assert.Equal(t, models.SyncConflict, result.Status)
+		assert.Equal(t, repositories.ErrVersionConflict.Error(), result.Error)
+		if assert.NotNil(t, result.Task) {
+			assert.Equal(t, "Server title", result.Task.Title)
+		}
+		mockTaskRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reports deleting a missing task as not found", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		syncService := NewSyncService(mockTaskRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result := syncService.ApplyMutation(userID, models.TaskMutation{Op: models.SyncDelete, ID: taskID})
+		assert.Equal(t, models.SyncNotFound, result.Status)
+		assert.Nil(t, result.Task)
+	})
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxTagNameLength is the longest tag name, in characters
+const maxTagNameLength = 50
+
+var (
+	// ErrTagNotFound is returned for tags that do not exist or belong to another user
+	ErrTagNotFound = errors.New("tag not found or unauthorized")
+	// ErrTagExists is returned when a tag would get the name of another of the user's tags
+	ErrTagExists = errors.New("a tag with this name already exists")
+	// ErrInvalidTag is wrapped by the validation errors of tags
+	ErrInvalidTag = errors.New("invalid tag")
+)
+
+// colorPattern matches the "#rrggbb" colours of tags and projects
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// TagService handles the tags users put on their tasks
+type TagService struct {
+	tagRepo     repositories.TagRepositoryInterface
+	taskService *TaskService
+}
+
+// NewTagService creates a new TagService
+func NewTagService(tagRepo repositories.TagRepositoryInterface, taskService *TaskService) *TagService {
+	return &TagService{tagRepo: tagRepo, taskService: taskService}
+}
+
+// GetTags retrieves all of the user's tags, sorted by name
+func (s *TagService) GetTags(userID uuid.UUID) ([]models.Tag, error) {
+	return s.tagRepo.GetTagsByUserID(userID)
+}
+
+// GetTag retrieves one of the user's tags
+func (s *TagService) GetTag(id uuid.UUID, userID uuid.UUID) (*models.Tag, error) {
+	tag, err := s.tagRepo.GetTagByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+// CreateTag creates a tag. Names are trimmed and must be unique per user, ignoring case.
+func (s *TagService) CreateTag(tag *models.Tag) error {
+	name, err := normalizeTagName(tag.Name)
+	if err != nil {
+		return err
+	}
+	if err := validateTagColor(tag.Color); err != nil {
+		return err
+	}
+	if err := s.checkNameFree(name, tag.UserID, uuid.Nil); err != nil {
+		return err
+	}
+	tag.ID = uuid.Nil
+	tag.Name = name
+	return s.tagRepo.CreateTag(tag)
+}
+
+// UpdateTag renames or recolours a tag; nil fields are left unchanged
+func (s *TagService) UpdateTag(id uuid.UUID, userID uuid.UUID, name *string, color *string) (*models.Tag, error) {
+	tag, err := s.GetTag(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		normalized, err := normalizeTagName(*name)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkNameFree(normalized, userID, id); err != nil {
+			return nil, err
+		}
+		tag.Name = normalized
+	}
+	if color != nil {
+		if err := validateTagColor(*color); err != nil {
+			return nil, err
+		}
+		tag.Color = *color
+	}
+
+	if err := s.tagRepo.UpdateTag(tag); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+// DeleteTag deletes a tag, taking it off every task
+func (s *TagService) DeleteTag(id uuid.UUID, userID uuid.UUID) error {
+	err := s.tagRepo.DeleteTag(id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTagNotFound
+	}
+	return err
+}
+
+// AddTaskTags puts the named tags on a task, creating the ones the user does not have yet, and
+// returns the task
+func (s *TagService) AddTaskTags(taskID uuid.UUID, userID uuid.UUID, names []string) (*models.Task, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: no tags given", ErrInvalidTag)
+	}
+	task, err := s.taskService.editableTask(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.ensureTags(names, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIDs := make([]uuid.UUID, len(tags))
+	for i, tag := range tags {
+		tagIDs[i] = tag.ID
+	}
+	if err := s.tagRepo.AddTaskTags(taskID, tagIDs, task.UserID); err != nil {
+		return nil, err
+	}
+	return s.taskService.GetTaskByID(taskID, userID)
+}
+
+// RemoveTaskTag takes a tag off a task
+func (s *TagService) RemoveTaskTag(taskID uuid.UUID, tagID uuid.UUID, userID uuid.UUID) error {
+	task, err := s.taskService.editableTask(taskID, userID)
+	if err != nil {
+		return err
+	}
+	err = s.tagRepo.RemoveTaskTag(taskID, tagID, task.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTagNotFound
+	}
+	return err
+}
+
+// ensureTags returns the user's tags with the given names, creating the missing ones
+func (s *TagService) ensureTags(names []string, userID uuid.UUID) ([]models.Tag, error) {
+	wanted := make(map[string]string) // lowercased name -> name
+	var order []string
+	for _, name := range names {
+		normalized, err := normalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(normalized)
+		if _, ok := wanted[key]; !ok {
+			wanted[key] = normalized
+			order = append(order, normalized)
+		}
+	}
+
+	tags, err := s.tagRepo.GetTagsByNames(order, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		delete(wanted, strings.ToLower(tag.Name))
+	}
+	for _, name := range order {
+		if _, missing := wanted[strings.ToLower(name)]; !missing {
+			continue
+		}
+		tag := models.Tag{UserID: userID, Name: name}
+		if err := s.tagRepo.CreateTag(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// checkNameFree fails with ErrTagExists when a tag other than exceptID has the name
+func (s *TagService) checkNameFree(name string, userID uuid.UUID, exceptID uuid.UUID) error {
+	existing, err := s.tagRepo.GetTagsByNames([]string{name}, userID)
+	if err != nil {
+		return err
+	}
+	for _, tag := range existing {
+		if tag.ID != exceptID {
+			return ErrTagExists
+		}
+	}
+	return nil
+}
+
+// normalizeTagName trims a tag name, drops a leading '#' and collapses runs of spaces. Commas
+// are not allowed, since they separate names in filters.
+func normalizeTagName(name string) (string, error) {
+	name = strings.Join(strings.Fields(strings.TrimPrefix(strings.TrimSpace(name), "#")), " ")
+	switch {
+	case name == "":
+		return "", fmt.Errorf("%w: name is required", ErrInvalidTag)
+	case utf8.RuneCountInString(name) > maxTagNameLength:
+		return "", fmt.Errorf("%w: name is longer than %d characters", ErrInvalidTag, maxTagNameLength)
+	case strings.Contains(name, ","):
+		return "", fmt.Errorf("%w: name must not contain commas", ErrInvalidTag)
+	}
+	return name, nil
+}
+
+// validateTagColor accepts "#rrggbb" colours and the empty string
+func validateTagColor(color string) error {
+	if color != "" && !colorPattern.MatchString(color) {
+		return fmt.Errorf("%w: color must look like #1e90ff", ErrInvalidTag)
+	}
+	return nil
+}
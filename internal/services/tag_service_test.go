@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"todo-backend/internal/llm"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTagRepository is a mock implementation of TagRepositoryInterface
+type MockTagRepository struct {
+	mock.Mock
+}
+
+func (m *MockTagRepository) CreateTag(tag *models.Tag) error {
+	args := m.Called(tag)
+	if tag.ID == uuid.Nil {
+		tag.ID = uuid.New()
+	}
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) GetTagByID(id uuid.UUID, userID uuid.UUID) (*models.Tag, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) GetTagsByNames(names []string, userID uuid.UUID) ([]models.Tag, error) {
+	args := m.Called(names, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) GetTagsByUserID(userID uuid.UUID) ([]models.Tag, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) UpdateTag(tag *models.Tag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) DeleteTag(id uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) AddTaskTags(taskID uuid.UUID, tagIDs []uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(taskID, tagIDs, userID)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) RemoveTaskTag(taskID uuid.UUID, tagID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(taskID, tagID, userID)
+	return args.Error(0)
+}
+
+func TestTagService_CreateTag(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("trims the name and creates the tag", func(t *testing.T) {
+		mockTagRepo := new(MockTagRepository)
+		tagService := NewTagService(mockTagRepo, NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+		mockTagRepo.On("GetTagsByNames", []string{"Deep work"}, userID).Return([]models.Tag{}, nil).Once()
+		mockTagRepo.On("CreateTag", mock.Anything).Return(nil).Once()
+
+		tag := &models.Tag{UserID: userID, Name: " #Deep   work ", Color: "#AABBCC"}
+		assert.NoError(t, tagService.CreateTag(tag))
+		assert.Equal(t, "Deep work", tag.Name)
+		mockTagRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects names the user already has", func(t *testing.T) {
+		mockTagRepo := new(MockTagRepository)
+		tagService := NewTagService(mockTagRepo, NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+		mockTagRepo.On("GetTagsByNames", []string{"work"}, userID).Return([]models.Tag{{ID: uuid.New(), UserID: userID, Name: "Work"}}, nil).Once()
+
+		err := tagService.CreateTag(&models.Tag{UserID: userID, Name: "work"})
+		assert.ErrorIs(t, err, ErrTagExists)
+		mockTagRepo.AssertNotCalled(t, "CreateTag", mock.Anything)
+	})
+
+	t.Run("rejects invalid names and colours", func(t *testing.T) {
+		mockTagRepo := new(MockTagRepository)
+		tagService := NewTagService(mockTagRepo, NewTaskService(new(MockTaskRepository), new(MockLLMExtractor)))
+
+		for _, tag := range []models.Tag{
+			{UserID: userID, Name: "  "},
+			{UserID: userID, Name: "home,work"},
+			{UserID: userID, Name: "This tag name is far too long to be shown next to a task title"},
+			{UserID: userID, Name: "Home", Color: "red"},
+		} {
+			assert.ErrorIs(t, tagService.CreateTag(&tag), ErrInvalidTag, tag.Name)
+		}
+		mockTagRepo.AssertNotCalled(t, "CreateTag", mock.Anything)
+	})
+}
+
+func TestTagService_AddTaskTags(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+	work := models.Tag{ID: uuid.New(), UserID: userID, Name: "Work"}
+
+	mockTaskRepo := new(MockTaskRepository)
+	mockTagRepo := new(MockTagRepository)
+	tagService := NewTagService(mockTagRepo, NewTaskService(mockTaskRepo, new(MockLLMExtractor)))
+
+	mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID}, nil)
+	mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil)
+	mockTagRepo.On("GetTagsByNames", []string{"work", "Errands"}, userID).Return([]models.Tag{work}, nil).Once()
+	var created *models.Tag
+	mockTagRepo.On("CreateTag", mock.MatchedBy(func(tag *models.Tag) bool { return tag.Name == "Errands" })).Run(func(args mock.Arguments) {
+		created = args.Get(0).(*models.Tag)
+	}).Return(nil).Once()
+	mockTagRepo.On("AddTaskTags", taskID, mock.Anything, userID).Return(nil).Once()
+
+	_, err := tagService.AddTaskTags(taskID, userID, []string{"work", "Errands", "WORK"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	// The existing tag is reused and only the missing one is created
+	mockTagRepo.AssertCalled(t, "AddTaskTags", taskID, []uuid.UUID{work.ID, created.ID}, userID)
+	mockTagRepo.AssertExpectations(t)
+}
+
+func TestTaskService_ExtractAndCreateTasksWithTags(t *testing.T) {
+	userID := uuid.New()
+	errands := models.Tag{ID: uuid.New(), UserID: userID, Name: "Errands"}
+	work := models.Tag{ID: uuid.New(), UserID: userID, Name: "Work"}
+
+	mockTaskRepo := new(MockTaskRepository)
+	mockLLMExtractor := new(MockLLMExtractor)
+	mockTagRepo := new(MockTagRepository)
+	taskService := NewTaskService(mockTaskRepo, mockLLMExtractor)
+	taskService.SetTagService(NewTagService(mockTagRepo, taskService))
+
+	mockTagRepo.On("GetTagsByUserID", userID).Return([]models.Tag{errands, work}, nil).Once()
+	mockLLMExtractor.On("ExtractTasks", mock.Anything, "Buy stamps", mock.MatchedBy(func(opts llm.ExtractOptions) bool {
+		return assert.ObjectsAreEqual([]string{"Errands", "Work"}, opts.Tags)
+	})).Return([]llm.Task{{Title: "Buy stamps", Tags: []string{"errands", "Shopping"}}}, nil).Once()
+	mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task"), mock.Anything).Return(nil).Once()
+
+	tasks, err := taskService.ExtractAndCreateTasks(context.Background(), "Buy stamps", userID)
+	if !assert.NoError(t, err) || !assert.Len(t, tasks, 1) {
+		return
+	}
+	// Only existing tags are kept; the made-up "Shopping" is dropped
+	assert.Equal(t, []models.Tag{errands}, tasks[0].Tags)
+	mockLLMExtractor.AssertExpectations(t)
+}
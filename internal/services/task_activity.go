@@ -0,0 +1,17 @@
+package services
+
+import (
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// GetTaskActivity retrieves the activity stream of a task: the events recorded with each change
+// to it, oldest first
+func (s *TaskService) GetTaskActivity(id uuid.UUID, userID uuid.UUID) ([]models.TaskEvent, error) {
+	task, err := s.getTask(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.taskRepo.GetTaskEvents(id, task.UserID)
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"todo-backend/internal/models"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidAssignee is wrapped by the errors for users a task cannot be assigned to
+var ErrInvalidAssignee = errors.New("invalid assignee")
+
+// checkAssignee makes sure a task is assigned only to a member of its project. Nobody but their
+// owner sees inbox tasks, so those can only be assigned to the owner.
+func (s *TaskService) checkAssignee(task *models.Task) error {
+	if task.AssigneeID == nil {
+		return nil
+	}
+	if task.ProjectID == nil {
+		if *task.AssigneeID != task.UserID {
+			return fmt.Errorf("%w: inbox tasks can only be assigned to their owner", ErrInvalidAssignee)
+		}
+		return nil
+	}
+	if s.projectService == nil {
+		return nil
+	}
+	if _, err := s.projectService.getMember(*task.ProjectID, *task.AssigneeID); err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			return fmt.Errorf("%w: the assignee is not a member of the project", ErrInvalidAssignee)
+		}
+		return err
+	}
+	return nil
+}
+
+// patchAssignee checks the assignee of a patched task. A task moved to another project without a
+// new assignee is left unassigned when its assignee is not a member there.
+func (s *TaskService) patchAssignee(task *models.Task, patch models.TaskPatch) error {
+	moved := patch.ProjectID != nil || patch.ClearProject
+	if patch.AssigneeID == nil && !moved {
+		return nil
+	}
+	err := s.checkAssignee(task)
+	if err != nil && patch.AssigneeID == nil && errors.Is(err, ErrInvalidAssignee) {
+		task.AssigneeID = nil
+		return nil
+	}
+	return err
+}
+
+// memberName is the name a project member goes by in text, taken from their email:
+// priya.sharma@example.com is "Priya Sharma"
+func memberName(email string) string {
+	local, _, _ := strings.Cut(email, "@")
+	local, _, _ = strings.Cut(local, "+")
+	words := strings.FieldsFunc(local, func(r rune) bool {
+		return r == '.' || r == '_' || r == '-'
+	})
+	for i, word := range words {
+		word = strings.ToLower(word)
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(first)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// memberNames returns the names of the members of a project, as offered to the extractor
+func memberNames(members []models.ProjectMember) []string {
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		if name := memberName(member.Email); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// pickAssignee returns the ID of the member with the given name, ignoring case. A first name on
+// its own is enough when only one member has it. Names of nobody in the project give nil.
+func pickAssignee(members []models.ProjectMember, name string) *uuid.UUID {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return nil
+	}
+	var byFirstName []uuid.UUID
+	for i := range members {
+		full := strings.ToLower(memberName(members[i].Email))
+		if full == "" {
+			continue
+		}
+		if full == name {
+			return &members[i].UserID
+		}
+		if first, _, _ := strings.Cut(full, " "); first == name {
+			byFirstName = append(byFirstName, members[i].UserID)
+		}
+	}
+	if len(byFirstName) == 1 {
+		return &byFirstName[0]
+	}
+	return nil
+}
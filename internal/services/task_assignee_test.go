@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"todo-backend/internal/llm"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTaskService_Assignees(t *testing.T) {
+	ownerID := uuid.New()
+	memberID := uuid.New()
+	outsiderID := uuid.New()
+	projectID := uuid.New()
+	members := []models.ProjectMember{
+		{ProjectID: projectID, UserID: ownerID, Email: "owner@example.com", Role: models.ProjectRoleOwner},
+		{ProjectID: projectID, UserID: memberID, Email: "priya.sharma@example.com", Role: models.ProjectRoleEditor},
+	}
+
+	setup := func() (*TaskService, *MockTaskRepository, *MockProjectRepository) {
+		mockTaskRepo := new(MockTaskRepository)
+		mockProjectRepo := new(MockProjectRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		taskService.SetProjectService(NewProjectService(mockProjectRepo, new(MockUserRepository), taskService))
+		mockProjectRepo.On("GetProjectByID", projectID, ownerID).Return(&models.Project{ID: projectID, UserID: ownerID, Role: models.ProjectRoleOwner}, nil)
+		mockProjectRepo.On("GetProjectMembers", projectID).Return(members, nil)
+		return taskService, mockTaskRepo, mockProjectRepo
+	}
+
+	t.Run("assigns tasks only to members of their project", func(t *testing.T) {
+		taskService, mockTaskRepo, _ := setup()
+		mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task"), mock.Anything).Return(nil).Once()
+
+		task := &models.Task{UserID: ownerID, ProjectID: &projectID, Title: "Book the room", Priority: "low", AssigneeID: &outsiderID}
+		assert.ErrorIs(t, taskService.CreateTask(task), ErrInvalidAssignee)
+		task.AssigneeID = &memberID
+		assert.NoError(t, taskService.CreateTask(task))
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("assigns inbox tasks only to their owner", func(t *testing.T) {
+		taskService, mockTaskRepo, _ := setup()
+		mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task"), mock.Anything).Return(nil).Once()
+
+		task := &models.Task{UserID: ownerID, Title: "Draft the invite", Priority: "low", AssigneeID: &memberID}
+		assert.ErrorIs(t, taskService.CreateTask(task), ErrInvalidAssignee)
+		task.AssigneeID = &ownerID
+		assert.NoError(t, taskService.CreateTask(task))
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("unassigns tasks moved where their assignee cannot follow", func(t *testing.T) {
+		taskService, mockTaskRepo, mockProjectRepo := setup()
+		task := &models.Task{ID: uuid.New(), UserID: ownerID, ProjectID: &projectID, Title: "Print flyers", Priority: "low", AssigneeID: &memberID, Version: 1}
+		mockTaskRepo.On("GetTaskByID", task.ID, ownerID).Return(task, nil)
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{task.ID}, ownerID).Return([]models.Task{}, nil)
+		mockTaskRepo.On("UpdateTask", mock.MatchedBy(func(updated *models.Task) bool {
+			return updated.ProjectID == nil && updated.AssigneeID == nil
+		}), mock.Anything, mock.Anything).Return(nil).Once()
+		mockProjectRepo.On("MoveTasks", []uuid.UUID{task.ID}, (*uuid.UUID)(nil), ownerID).Return(nil)
+
+		_, err := taskService.PatchTask(task.ID, ownerID, models.TaskPatch{ClearProject: true}, repositories.TaskPrecondition{})
+		assert.NoError(t, err)
+		mockTaskRepo.AssertExpectations(t)
+		mockProjectRepo.AssertCalled(t, "MoveTasks", []uuid.UUID{task.ID}, (*uuid.UUID)(nil), ownerID)
+	})
+
+	t.Run("rejects a new assignee who is not a member", func(t *testing.T) {
+		taskService, mockTaskRepo, _ := setup()
+		task := &models.Task{ID: uuid.New(), UserID: ownerID, ProjectID: &projectID, Title: "Print flyers", Priority: "low", Version: 1}
+		mockTaskRepo.On("GetTaskByID", task.ID, ownerID).Return(task, nil)
+
+		_, err := taskService.PatchTask(task.ID, ownerID, models.TaskPatch{AssigneeID: &outsiderID}, repositories.TaskPrecondition{})
+		assert.ErrorIs(t, err, ErrInvalidAssignee)
+		mockTaskRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("extraction assigns tasks to the members the text names", func(t *testing.T) {
+		taskService, mockTaskRepo, _ := setup()
+		mockLLMExtractor := new(MockLLMExtractor)
+		taskService.llmExtractor = mockLLMExtractor
+		text := "Ask Priya to send the slides. Ask Jo to bring snacks"
+		mockLLMExtractor.On("ExtractTasks", mock.Anything, text, mock.MatchedBy(func(opts llm.ExtractOptions) bool {
+			return assert.ObjectsAreEqual([]string{"Owner", "Priya Sharma"}, opts.Members)
+		})).Return([]llm.Task{
+			{Title: "Send the slides", Assignee: "Priya"},
+			{Title: "Bring snacks", Assignee: "Jo"},
+		}, nil).Once()
+		mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task"), mock.Anything).Return(nil).Twice()
+
+		created, err := taskService.ExtractAndCreateProjectTasks(context.Background(), text, ownerID, &projectID)
+		assert.NoError(t, err)
+		if assert.Len(t, created, 2) {
+			assert.Equal(t, &projectID, created[0].ProjectID)
+			assert.Equal(t, &memberID, created[0].AssigneeID)
+			assert.Nil(t, created[1].AssigneeID)
+		}
+		mockLLMExtractor.AssertExpectations(t)
+	})
+}
+
+func TestMemberName(t *testing.T) {
+	assert.Equal(t, "Priya Sharma", memberName("priya.sharma@example.com"))
+	assert.Equal(t, "Sam Lee", memberName("SAM_LEE+todo@example.com"))
+	assert.Equal(t, "Jo", memberName("jo@example.com"))
+	assert.Equal(t, "Émile Zola", memberName("émile.zola@example.com"))
+	assert.Equal(t, "", memberName("@example.com"))
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTaskBlocked is returned when completing a task that still waits on open tasks
+	ErrTaskBlocked = errors.New("task is blocked by open tasks")
+	// ErrDependencyNotFound is returned when removing a blocker the task does not wait on
+	ErrDependencyNotFound = errors.New("dependency not found")
+	// ErrInvalidDependency is wrapped by the validation errors of dependencies
+	ErrInvalidDependency = errors.New("invalid dependency")
+)
+
+// AddBlockers makes a task wait on other tasks of its owner and returns it with its subtasks.
+// repositories.ErrDependencyCycle is returned when a blocker already waits on the task.
+func (s *TaskService) AddBlockers(taskID uuid.UUID, userID uuid.UUID, blockerIDs []uuid.UUID) (*models.Task, error) {
+	if len(blockerIDs) == 0 {
+		return nil, fmt.Errorf("%w: no blockers given", ErrInvalidDependency)
+	}
+	existing, err := s.editableTask(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	task := &models.Task{ID: taskID, UserID: existing.UserID, BlockedBy: blockerIDs}
+	if err := s.checkBlockers(task, userID); err != nil {
+		return nil, err
+	}
+	if err := s.taskRepo.AddTaskBlockers(taskID, task.BlockedBy, task.UserID); err != nil {
+		return nil, err
+	}
+	return s.GetTaskByID(taskID, userID)
+}
+
+// RemoveBlocker stops a task from waiting on one of its blockers
+func (s *TaskService) RemoveBlocker(taskID uuid.UUID, blockerID uuid.UUID, userID uuid.UUID) error {
+	task, err := s.editableTask(taskID, userID)
+	if err != nil {
+		return err
+	}
+	err = s.taskRepo.RemoveTaskBlocker(taskID, blockerID, task.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDependencyNotFound
+	}
+	return err
+}
+
+// checkCompletable fails with ErrTaskBlocked when the task is open and waits on open tasks
+func checkCompletable(task *models.Task) error {
+	if !task.Completed && task.Blocked {
+		return ErrTaskBlocked
+	}
+	return nil
+}
+
+// checkBlockers drops duplicates from task.BlockedBy, checks that the blockers are other tasks the
+// user can see, that are not in the trash and have the same owner as the task, and sets task.Blocked
+func (s *TaskService) checkBlockers(task *models.Task, userID uuid.UUID) error {
+	task.Blocked = false
+	if len(task.BlockedBy) == 0 {
+		return nil
+	}
+	seen := make(map[uuid.UUID]bool, len(task.BlockedBy))
+	var ids []uuid.UUID
+	for _, id := range task.BlockedBy {
+		if id == task.ID {
+			return fmt.Errorf("%w: a task cannot wait on itself", ErrInvalidDependency)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	task.BlockedBy = ids
+
+	blockers, err := s.taskRepo.GetTasksByIDs(ids, userID)
+	if err != nil {
+		return err
+	}
+	if len(blockers) != len(ids) {
+		return fmt.Errorf("%w: blocker not found", ErrInvalidDependency)
+	}
+	for _, blocker := range blockers {
+		if blocker.UserID != task.UserID {
+			return fmt.Errorf("%w: a task can only wait on tasks of the user who owns it", ErrInvalidDependency)
+		}
+		task.Blocked = task.Blocked || !blocker.Completed
+	}
+	return nil
+}
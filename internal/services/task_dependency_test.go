@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"todo-backend/internal/llm"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTaskService_AddBlockers(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+	blocker := models.Task{ID: uuid.New(), UserID: userID, Title: "Buy paint"}
+
+	t.Run("drops duplicates and adds the blockers", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID}, nil)
+		mockTaskRepo.On("GetTasksByIDs", []uuid.UUID{blocker.ID}, userID).Return([]models.Task{blocker}, nil).Once()
+		mockTaskRepo.On("AddTaskBlockers", taskID, []uuid.UUID{blocker.ID}, userID).Return(nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil).Once()
+
+		_, err := taskService.AddBlockers(taskID, userID, []uuid.UUID{blocker.ID, blocker.ID})
+		assert.NoError(t, err)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects the task itself and unknown tasks", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		unknownID := uuid.New()
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID}, nil)
+		mockTaskRepo.On("GetTasksByIDs", []uuid.UUID{blocker.ID, unknownID}, userID).Return([]models.Task{blocker}, nil).Once()
+
+		_, err := taskService.AddBlockers(taskID, userID, []uuid.UUID{taskID})
+		assert.ErrorIs(t, err, ErrInvalidDependency)
+		_, err = taskService.AddBlockers(taskID, userID, []uuid.UUID{blocker.ID, unknownID})
+		assert.ErrorIs(t, err, ErrInvalidDependency)
+		mockTaskRepo.AssertNotCalled(t, "AddTaskBlockers", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestTaskService_CompleteBlockedTask(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+	blockerID := uuid.New()
+
+	t.Run("refuses a task that waits on open tasks", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		task := &models.Task{ID: taskID, UserID: userID, BlockedBy: []uuid.UUID{blockerID}, Blocked: true}
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(task, nil)
+
+		_, err := taskService.CompleteTask(taskID, userID, false, false)
+		assert.ErrorIs(t, err, ErrTaskBlocked)
+		completed := true
+		_, err = taskService.PatchTask(taskID, userID, models.TaskPatch{Completed: &completed}, repositories.TaskPrecondition{})
+		assert.ErrorIs(t, err, ErrTaskBlocked)
+		mockTaskRepo.AssertNotCalled(t, "SetTasksCompleted", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockTaskRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("completes it when forced", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		task := &models.Task{ID: taskID, UserID: userID, BlockedBy: []uuid.UUID{blockerID}, Blocked: true}
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(task, nil)
+		mockTaskRepo.On("SetTasksCompleted", []uuid.UUID{taskID}, userID, true, mock.Anything).Return(nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil).Once()
+
+		_, err := taskService.CompleteTask(taskID, userID, false, true)
+		assert.NoError(t, err)
+		mockTaskRepo.AssertExpectations(t)
+	})
+}
+
+func TestTaskService_ExtractAndCreateTasksWithDependencies(t *testing.T) {
+	userID := uuid.New()
+	mockTaskRepo := new(MockTaskRepository)
+	mockLLMExtractor := new(MockLLMExtractor)
+	taskService := NewTaskService(mockTaskRepo, mockLLMExtractor)
+
+	mockLLMExtractor.On("ExtractTasks", mock.Anything, "Buy paint, then paint the fence", mock.Anything).Return([]llm.Task{
+		{Title: "Buy paint"},
+		{Title: "Paint the fence", DependsOn: []int{0, 0, 1, 7}},
+	}, nil).Once()
+	mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task"), mock.Anything).Return(nil).Twice()
+
+	tasks, err := taskService.ExtractAndCreateTasks(context.Background(), "Buy paint, then paint the fence", userID)
+	if !assert.NoError(t, err) || !assert.Len(t, tasks, 2) {
+		return
+	}
+	// Only earlier tasks can be blockers; the task itself and unknown positions are dropped
+	assert.Empty(t, tasks[0].BlockedBy)
+	assert.False(t, tasks[0].Blocked)
+	assert.Equal(t, []uuid.UUID{tasks[0].ID}, tasks[1].BlockedBy)
+	assert.True(t, tasks[1].Blocked)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"todo-backend/internal/models"
+	"todo-backend/internal/recurrence"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidRecurrence is wrapped by the errors for recurrence rules outside the supported subset
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+// applyRecurrence stores a task's recurrence rule in canonical form. A repeating task without a
+// due date gets the first occurrence from today on, in the user's time zone, as its due date.
+func (s *TaskService) applyRecurrence(task *models.Task) error {
+	if task.Recurrence == "" {
+		return nil
+	}
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+	task.Recurrence = rule.String()
+	if task.DueDate != nil {
+		return nil
+	}
+
+	settings, err := s.userSettings(task.UserID)
+	if err != nil {
+		return err
+	}
+	now := settings.Now()
+	due := rule.First(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()))
+	task.DueDate = &due
+	return nil
+}
+
+// createNextOccurrence creates the occurrence that follows a completed repeating task: a copy of
+// it and its subtasks, not completed, due at the next occurrence of its rule. Occurrences that
+// are already past, missed while the task was overdue, are skipped. Nothing is created once the
+// series has ended, or when the successor was already created by an earlier completion.
+func (s *TaskService) createNextOccurrence(completed *models.Task) error {
+	rule, err := recurrence.Parse(completed.Recurrence)
+	if err != nil {
+		// Rules are validated when they are set, so this is a rule that can no longer be parsed
+		return nil
+	}
+	settings, err := s.userSettings(completed.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Occurrences are computed on the user's wall clock, so they keep their time of day
+	now := settings.Now()
+	loc := now.Location()
+	var prev time.Time
+	if completed.DueDate != nil {
+		prev = completed.DueDate.In(loc)
+	} else {
+		prev = rule.First(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc))
+	}
+	index := completed.RecurrenceIndex
+	due := prev
+	for {
+		next, ok := rule.Next(due, index)
+		if !ok {
+			return nil
+		}
+		due = next
+		index++
+		if due.After(now) {
+			break
+		}
+	}
+
+	seriesID := completed.ID
+	if completed.SeriesID != nil {
+		seriesID = *completed.SeriesID
+	}
+	occurrence := copyTaskTree(*completed, due.Sub(prev))
+	occurrence.ParentID = completed.ParentID
+	occurrence.Position = completed.Position
+	occurrence.Recurrence = completed.Recurrence
+	occurrence.SeriesID = &seriesID
+	occurrence.RecurrenceIndex = index
+	utc := due.UTC()
+	occurrence.DueDate = &utc
+
+	created, err := s.taskRepo.CreateNextOccurrence(&occurrence)
+	if err != nil || !created || s.reminderService == nil {
+		return err
+	}
+	return s.reminderService.OccurrenceCreated(completed, &occurrence)
+}
+
+// copyTaskTree copies a task and its subtasks, with their project, assignee and tags, as new,
+// open tasks, moving their due dates by shift
+func copyTaskTree(task models.Task, shift time.Duration) models.Task {
+	occurrence := models.Task{
+		ID:          uuid.New(),
+		UserID:      task.UserID,
+		Title:       task.Title,
+		Description: task.Description,
+		Priority:    task.Priority,
+		RawText:     task.RawText,
+		Position:    task.Position,
+		ProjectID:   task.ProjectID,
+		AssigneeID:  task.AssigneeID,
+		Tags:        task.Tags,
+	}
+	if task.DueDate != nil {
+		due := task.DueDate.Add(shift)
+		occurrence.DueDate = &due
+	}
+	for _, subtask := range task.Subtasks {
+		occurrence.Subtasks = append(occurrence.Subtasks, copyTaskTree(subtask, shift))
+	}
+	return occurrence
+}
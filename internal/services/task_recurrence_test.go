@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-backend/internal/llm"
+	"todo-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTaskService_CreateRecurringTask(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("stores the rule in canonical form and dates the first occurrence", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		task := &models.Task{UserID: userID, Title: "Gym", Recurrence: "RRULE:freq=weekly;byday=fr,mo"}
+		mockTaskRepo.On("CreateTask", task, mock.Anything).Return(nil).Once()
+
+		err := taskService.CreateTask(task)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,FR", task.Recurrence)
+		if assert.NotNil(t, task.DueDate) {
+			assert.Contains(t, []time.Weekday{time.Monday, time.Friday}, task.DueDate.Weekday())
+			assert.WithinDuration(t, time.Now(), *task.DueDate, 7*24*time.Hour)
+		}
+	})
+
+	t.Run("rejects rules outside the supported subset", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+
+		err := taskService.CreateTask(&models.Task{UserID: userID, Title: "Taxes", Recurrence: "FREQ=YEARLY"})
+		assert.ErrorIs(t, err, ErrInvalidRecurrence)
+		mockTaskRepo.AssertNotCalled(t, "CreateTask", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTaskService_CompleteRecurringTask(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+	subtaskID := uuid.New()
+
+	// expectCompletion sets up completing task, which has the given subtasks
+	expectCompletion := func(mockTaskRepo *MockTaskRepository, task *models.Task, subtasks []models.Task) {
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(task, nil).Twice()
+		mockTaskRepo.On("SetTasksCompleted", []uuid.UUID{taskID}, userID, true, mock.Anything).Return(nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return(subtasks, nil).Once()
+		if len(subtasks) > 0 {
+			mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{subtaskID}, userID).Return([]models.Task{}, nil).Once()
+		}
+	}
+
+	t.Run("creates the next occurrence with its subtasks", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		due := time.Date(2100, time.January, 4, 9, 0, 0, 0, time.UTC)
+		subtaskDue := due.Add(-time.Hour)
+		task := &models.Task{ID: taskID, UserID: userID, Title: "Team sync", Priority: "high", DueDate: &due, Recurrence: "FREQ=WEEKLY"}
+		subtasks := []models.Task{{ID: subtaskID, UserID: userID, ParentID: &taskID, Title: "Agenda", Completed: true, DueDate: &subtaskDue}}
+		expectCompletion(mockTaskRepo, task, subtasks)
+
+		var next *models.Task
+		mockTaskRepo.On("CreateNextOccurrence", mock.AnythingOfType("*models.Task")).
+			Run(func(args mock.Arguments) { next = args.Get(0).(*models.Task) }).
+			Return(true, nil).Once()
+
+		_, err := taskService.CompleteTask(taskID, userID, false, false)
+		if !assert.NoError(t, err) || !assert.NotNil(t, next) {
+			return
+		}
+		assert.NotEqual(t, taskID, next.ID)
+		assert.Equal(t, "Team sync", next.Title)
+		assert.Equal(t, "high", next.Priority)
+		assert.Equal(t, "FREQ=WEEKLY", next.Recurrence)
+		assert.Equal(t, &taskID, next.SeriesID)
+		assert.Equal(t, 1, next.RecurrenceIndex)
+		assert.False(t, next.Completed)
+		assert.Equal(t, due.AddDate(0, 0, 7), *next.DueDate)
+		if assert.Len(t, next.Subtasks, 1) {
+			assert.Equal(t, "Agenda", next.Subtasks[0].Title)
+			assert.False(t, next.Subtasks[0].Completed)
+			assert.Equal(t, subtaskDue.AddDate(0, 0, 7), *next.Subtasks[0].DueDate)
+		}
+	})
+
+	t.Run("skips the occurrences missed while overdue", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		seriesID := uuid.New()
+		due := time.Now().UTC().AddDate(0, 0, -10)
+		task := &models.Task{ID: taskID, UserID: userID, Title: "Vitamins", DueDate: &due, Recurrence: "FREQ=DAILY", SeriesID: &seriesID, RecurrenceIndex: 4}
+		expectCompletion(mockTaskRepo, task, nil)
+
+		var next *models.Task
+		mockTaskRepo.On("CreateNextOccurrence", mock.AnythingOfType("*models.Task")).
+			Run(func(args mock.Arguments) { next = args.Get(0).(*models.Task) }).
+			Return(true, nil).Once()
+
+		_, err := taskService.CompleteTask(taskID, userID, false, false)
+		if !assert.NoError(t, err) || !assert.NotNil(t, next) {
+			return
+		}
+		assert.Equal(t, &seriesID, next.SeriesID)
+		assert.Equal(t, 4+11, next.RecurrenceIndex)
+		assert.True(t, next.DueDate.After(time.Now()))
+		assert.Equal(t, due.AddDate(0, 0, 11), *next.DueDate)
+	})
+
+	t.Run("ends the series after COUNT occurrences", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		due := time.Date(2100, time.January, 4, 9, 0, 0, 0, time.UTC)
+		task := &models.Task{ID: taskID, UserID: userID, Title: "Physio", DueDate: &due, Recurrence: "FREQ=WEEKLY;COUNT=3", RecurrenceIndex: 2}
+		expectCompletion(mockTaskRepo, task, nil)
+
+		_, err := taskService.CompleteTask(taskID, userID, false, false)
+		assert.NoError(t, err)
+		mockTaskRepo.AssertNotCalled(t, "CreateNextOccurrence", mock.Anything)
+	})
+
+	t.Run("does nothing for a task that was already completed", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		due := time.Date(2100, time.January, 4, 9, 0, 0, 0, time.UTC)
+		task := &models.Task{ID: taskID, UserID: userID, Title: "Team sync", DueDate: &due, Recurrence: "FREQ=WEEKLY", Completed: true}
+		expectCompletion(mockTaskRepo, task, nil)
+
+		_, err := taskService.CompleteTask(taskID, userID, false, false)
+		assert.NoError(t, err)
+		mockTaskRepo.AssertNotCalled(t, "CreateNextOccurrence", mock.Anything)
+	})
+}
+
+func TestTaskService_ExtractRecurringTasks(t *testing.T) {
+	mockTaskRepo := new(MockTaskRepository)
+	mockLLMExtractor := new(MockLLMExtractor)
+	taskService := NewTaskService(mockTaskRepo, mockLLMExtractor)
+	userID := uuid.New()
+
+	mockLLMExtractor.On("ExtractTasks", mock.Anything, "pay rent on the 1st of every month; water plants every fortnight", mock.Anything).Return([]llm.Task{
+		{Title: "Pay rent", Recurrence: "FREQ=MONTHLY;BYMONTHDAY=1"},
+		{Title: "Water plants", Recurrence: "FREQ=FORTNIGHTLY"},
+	}, nil).Once()
+	mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task"), mock.Anything).Return(nil).Twice()
+
+	tasks, err := taskService.ExtractAndCreateTasks(context.Background(), "pay rent on the 1st of every month; water plants every fortnight", userID)
+	if !assert.NoError(t, err) || !assert.Len(t, tasks, 2) {
+		return
+	}
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=1", tasks[0].Recurrence)
+	if assert.NotNil(t, tasks[0].DueDate) {
+		assert.Equal(t, 1, tasks[0].DueDate.Day())
+	}
+	assert.Empty(t, tasks[1].Recurrence, "an unsupported rule is dropped")
+	assert.Nil(t, tasks[1].DueDate)
+}
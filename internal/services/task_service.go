@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"todo-backend/internal/llm"
 	"todo-backend/internal/models"
 	"todo-backend/internal/repositories"
@@ -17,6 +18,11 @@ import (
 type TaskService struct {
 	taskRepo    repositories.TaskRepositoryInterface
 	llmExtractor llm.TaskExtractor
+	settingsService   *SettingsService   // optional; without it every user gets the default settings
+	reminderService   *ReminderService   // optional; keeps reminders in step with due dates
+	tagService        *TagService        // optional; lets extraction tag tasks with the user's tags
+	projectService    *ProjectService    // optional; checks the user's role in the projects of shared tasks
+	attachmentService *AttachmentService // optional; removes the files attached to purged tasks
 }
 
 // NewTaskService creates a new TaskService
@@ -27,12 +33,143 @@ func NewTaskService(taskRepo repositories.TaskRepositoryInterface, llmExtractor
 	}
 }
 
-// CreateTask creates a new task
+// SetSettingsService makes the service honour each user's time zone, week start and default priority
+func (s *TaskService) SetSettingsService(settingsService *SettingsService) {
+	s.settingsService = settingsService
+}
+
+// SetReminderService moves reminders relative to a task's due date when the due date changes
+func (s *TaskService) SetReminderService(reminderService *ReminderService) {
+	s.reminderService = reminderService
+}
+
+// SetTagService lets extraction put the user's existing tags on the tasks it creates
+func (s *TaskService) SetTagService(tagService *TagService) {
+	s.tagService = tagService
+}
+
+// SetProjectService makes the service check the projects tasks are put in, and the user's role in
+// the project of a task before changing it
+func (s *TaskService) SetProjectService(projectService *ProjectService) {
+	s.projectService = projectService
+}
+
+// SetAttachmentService makes the service remove the files attached to tasks when they are purged
+// from the trash
+func (s *TaskService) SetAttachmentService(attachmentService *AttachmentService) {
+	s.attachmentService = attachmentService
+}
+
+// checkProject makes sure a new task is put only in a project the user may add tasks to, and only
+// if it is a top-level task. The task then belongs to the project's owner, like the rest of the
+// project's tasks.
+func (s *TaskService) checkProject(task *models.Task, userID uuid.UUID) error {
+	if task.ProjectID == nil {
+		return nil
+	}
+	if task.ParentID != nil {
+		return fmt.Errorf("%w: subtasks are in their parent's project", ErrInvalidProject)
+	}
+	if s.projectService == nil {
+		return nil
+	}
+	project, err := s.projectService.authorize(*task.ProjectID, userID, accessEdit)
+	if err != nil {
+		return err
+	}
+	task.UserID = project.UserID
+	return nil
+}
+
+// checkMove makes sure a patch moves only a top-level task, and only between the inbox and
+// projects of the user who owns it, where the user may change tasks
+func (s *TaskService) checkMove(task *models.Task, patch models.TaskPatch, userID uuid.UUID) error {
+	if patch.ProjectID == nil && !patch.ClearProject {
+		return nil
+	}
+	if task.ParentID != nil {
+		return fmt.Errorf("%w: subtasks are in their parent's project", ErrInvalidProject)
+	}
+	if patch.ClearProject {
+		// Nobody but its owner could see the task in their inbox
+		if task.UserID != userID {
+			return ErrPermissionDenied
+		}
+		return nil
+	}
+	if s.projectService == nil {
+		return nil
+	}
+	project, err := s.projectService.authorize(*patch.ProjectID, userID, accessEdit)
+	if err != nil {
+		return err
+	}
+	if project.UserID != task.UserID {
+		return fmt.Errorf("%w: tasks can only move between projects of the user who owns them", ErrInvalidProject)
+	}
+	return nil
+}
+
+// dueDateChanged lets the reminder service reschedule a task's reminders, if its due date moved
+func (s *TaskService) dueDateChanged(task *models.Task, previous *time.Time) error {
+	if s.reminderService == nil || sameTime(previous, task.DueDate) {
+		return nil
+	}
+	return s.reminderService.TaskDueDateChanged(task)
+}
+
+// sameTime reports whether two optional times are both unset or the same instant
+func sameTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// userSettings returns the settings of a user, or the defaults when no settings service is configured
+func (s *TaskService) userSettings(userID uuid.UUID) (*models.UserSettings, error) {
+	if s.settingsService == nil {
+		return models.NewUserSettings(userID), nil
+	}
+	return s.settingsService.GetSettings(userID)
+}
+
+// applyDefaultPriority gives a task without a priority the user's default one
+func (s *TaskService) applyDefaultPriority(task *models.Task) error {
+	if task.Priority != "" {
+		return nil
+	}
+	settings, err := s.userSettings(task.UserID)
+	if err != nil {
+		return err
+	}
+	task.Priority = settings.DefaultPriority
+	return nil
+}
+
+// CreateTask creates a new task for task.UserID, waiting on the tasks in task.BlockedBy. A task
+// put in a shared project belongs to the project's owner and can be assigned to its members.
 func (s *TaskService) CreateTask(task *models.Task) error {
-	return s.taskRepo.CreateTask(task)
+	userID := task.UserID
+	if err := s.checkProject(task, userID); err != nil {
+		return err
+	}
+	if err := s.checkAssignee(task); err != nil {
+		return err
+	}
+	if err := s.checkBlockers(task, userID); err != nil {
+		return err
+	}
+	if err := s.applyDefaultPriority(task); err != nil {
+		return err
+	}
+	if err := s.applyRecurrence(task); err != nil {
+		return err
+	}
+	return s.taskRepo.CreateTask(task, userID)
 }
 
-// GetTaskByID retrieves a task by its ID
+// GetTaskByID retrieves a task by its ID, with its subtasks nested below it
 func (s *TaskService) GetTaskByID(id uuid.UUID, userID uuid.UUID) (*models.Task, error) {
 	task, err := s.taskRepo.GetTaskByID(id, userID)
 	if err != nil {
@@ -41,59 +178,477 @@ func (s *TaskService) GetTaskByID(id uuid.UUID, userID uuid.UUID) (*models.Task,
 		}
 		return nil, err
 	}
-	return task, nil
+
+	tree := []models.Task{*task}
+	if err := s.attachSubtasks(tree, userID); err != nil {
+		return nil, err
+	}
+	return &tree[0], nil
 }
 
-// GetTasksByUserID retrieves all tasks for a given user ID
+// GetTasksByUserID retrieves all top-level tasks the user can see, each with its subtasks nested below it
 func (s *TaskService) GetTasksByUserID(userID uuid.UUID) ([]models.Task, error) {
-	return s.taskRepo.GetTasksByUserID(userID)
+	tasks, err := s.taskRepo.GetTasksByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachSubtasks(tasks, userID); err != nil {
+		return nil, err
+	}
+	return tasks, nil
 }
 
-// UpdateTask updates an existing task
+// ListTasks retrieves one page of the top-level tasks the user can see matching query, each with its
+// subtasks nested below it, and the cursor of the next page (empty on the last page)
+func (s *TaskService) ListTasks(query repositories.TaskQuery) ([]models.Task, string, error) {
+	tasks, next, err := s.taskRepo.ListTasks(query)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.attachSubtasks(tasks, query.UserID); err != nil {
+		return nil, "", err
+	}
+	return tasks, next, nil
+}
+
+// SearchTasks retrieves the tasks the user can see, subtasks included, that match a full-text search
+func (s *TaskService) SearchTasks(search repositories.TaskSearch) ([]models.TaskSearchResult, error) {
+	return s.taskRepo.SearchTasks(search)
+}
+
+// GetTasksByIDs retrieves the tasks with the given IDs that the user can see
+func (s *TaskService) GetTasksByIDs(ids []uuid.UUID, userID uuid.UUID) ([]models.Task, error) {
+	return s.taskRepo.GetTasksByIDs(ids, userID)
+}
+
+// UpdateTask updates an existing task. A non-zero task.Version must match the stored version,
+// or repositories.ErrVersionConflict is returned.
 func (s *TaskService) UpdateTask(task *models.Task, userID uuid.UUID) error {
-	// Ensure the user owns the task
-	existingTask, err := s.taskRepo.GetTaskByID(task.ID, userID)
+	// Ensure the user may change the task
+	existingTask, err := s.editableTask(task.ID, userID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("task not found or unauthorized")
-		}
+		return err
+	}
+	if err := checkVersion(existingTask, task.Version); err != nil {
 		return err
 	}
 
 	// Update fields
+	previousDueDate := existingTask.DueDate
 	existingTask.Title = task.Title
 	existingTask.Description = task.Description
 	existingTask.DueDate = task.DueDate
 	existingTask.Priority = task.Priority
 	existingTask.RawText = task.RawText
+	existingTask.Recurrence = task.Recurrence
+	if err := s.applyDefaultPriority(existingTask); err != nil {
+		return err
+	}
+	if err := s.applyRecurrence(existingTask); err != nil {
+		return err
+	}
+
+	if err := s.taskRepo.UpdateTask(existingTask, userID, repositories.TaskPrecondition{}); err != nil {
+		return err
+	}
+	return s.dueDateChanged(existingTask, previousDueDate)
+}
+
+// PatchTask applies a partial update to a task, completion included, in a single write and returns
+// the result with its subtasks. The task must hold to cond, or repositories.ErrVersionConflict is
+// returned. Completing a repeating task creates its next occurrence.
+func (s *TaskService) PatchTask(id uuid.UUID, userID uuid.UUID, patch models.TaskPatch, cond repositories.TaskPrecondition) (*models.Task, error) {
+	task, err := s.editableTask(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(task, cond.Version); err != nil {
+		return nil, err
+	}
+
+	// A blocked task cannot be completed, so nothing is written
+	if patch.Completed != nil && *patch.Completed {
+		if err := checkCompletable(task); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkMove(task, patch, userID); err != nil {
+		return nil, err
+	}
+
+	previousDueDate, wasCompleted := task.DueDate, task.Completed
+	patch.Apply(task)
+	if patch.Completed != nil {
+		task.Completed = *patch.Completed
+	}
+	if err := s.patchAssignee(task, patch); err != nil {
+		return nil, err
+	}
+	if err := s.applyDefaultPriority(task); err != nil {
+		return nil, err
+	}
+	if err := s.applyRecurrence(task); err != nil {
+		return nil, err
+	}
+	if err := s.taskRepo.UpdateTask(task, userID, cond); err != nil {
+		return nil, err
+	}
+	if err := s.dueDateChanged(task, previousDueDate); err != nil {
+		return nil, err
+	}
+	// Subtasks follow their parent into its new project
+	if (patch.ProjectID != nil || patch.ClearProject) && s.projectService != nil {
+		if err := s.projectService.projectRepo.MoveTasks([]uuid.UUID{id}, task.ProjectID, task.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.GetTaskByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Completed && !wasCompleted && updated.Recurrence != "" {
+		if err := s.createNextOccurrence(updated); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+// DeleteTask deletes a task. The task must hold to cond, as for PatchTask.
+func (s *TaskService) DeleteTask(id uuid.UUID, userID uuid.UUID, cond repositories.TaskPrecondition) error {
+	task, err := s.editableTask(id, userID)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(task, cond.Version); err != nil {
+		return err
+	}
 
-	return s.taskRepo.UpdateTask(existingTask)
+	err = s.taskRepo.DeleteTask(id, task.UserID, cond)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("task not found or unauthorized")
+		}
+		return err
+	}
+	return nil
 }
 
-// DeleteTask deletes a task
-func (s *TaskService) DeleteTask(id uuid.UUID, userID uuid.UUID) error {
-	err := s.taskRepo.DeleteTask(id, userID)
+// GetTrash retrieves the deleted tasks the user can see, those of the projects shared with them
+// included, most recently deleted first
+func (s *TaskService) GetTrash(userID uuid.UUID) ([]models.TrashedTask, error) {
+	tasks, err := s.taskRepo.GetDeletedTasks(userID)
 	if err != nil {
+		return nil, err
+	}
+	trash := make([]models.TrashedTask, len(tasks))
+	for i, task := range tasks {
+		trash[i] = models.TrashedTask{Task: task, DeletedAt: task.DeletedAt.Time}
+	}
+	return trash, nil
+}
+
+// RestoreTask takes a task out of the trash, with the subtasks deleted along with it, and
+// returns it. Subtasks can only be restored once their parent is; until then
+// repositories.ErrParentDeleted is returned.
+func (s *TaskService) RestoreTask(id uuid.UUID, userID uuid.UUID) (*models.Task, error) {
+	task, err := s.trashedTask(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.taskRepo.RestoreTask(id, task.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("task not found or unauthorized")
+		}
+		return nil, err
+	}
+	return s.GetTaskByID(id, userID)
+}
+
+// PurgeTask permanently deletes a task in the trash
+func (s *TaskService) PurgeTask(id uuid.UUID, userID uuid.UUID) error {
+	task, err := s.trashedTask(id, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.taskRepo.PurgeTask(id, task.UserID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errors.New("task not found or unauthorized")
 		}
 		return err
 	}
+	s.tasksPurged()
 	return nil
 }
 
+// EmptyTrash permanently deletes all deleted tasks the user owns and returns how many were
+// removed. Those of projects shared with them are left to their owners.
+func (s *TaskService) EmptyTrash(userID uuid.UUID) (int64, error) {
+	purged, err := s.taskRepo.PurgeDeletedTasks(userID)
+	if err == nil && purged > 0 {
+		s.tasksPurged()
+	}
+	return purged, err
+}
+
+// PurgeExpiredTasks permanently deletes the tasks of all users that were moved to the trash
+// before the given time, and returns how many were removed
+func (s *TaskService) PurgeExpiredTasks(before time.Time) (int64, error) {
+	purged, err := s.taskRepo.PurgeTasksDeletedBefore(before)
+	if err == nil && purged > 0 {
+		s.tasksPurged()
+	}
+	return purged, err
+}
+
+// CreateSubtask creates a task as the last child of the given parent task. It belongs to the
+// parent's owner and is in the parent's project.
+func (s *TaskService) CreateSubtask(parentID uuid.UUID, task *models.Task, userID uuid.UUID) error {
+	parent, err := s.editableTask(parentID, userID)
+	if err != nil {
+		return err
+	}
+	siblings, err := s.taskRepo.GetSubtasksByParentIDs([]uuid.UUID{parentID}, userID)
+	if err != nil {
+		return err
+	}
+
+	task.UserID = parent.UserID
+	task.ParentID = &parentID
+	task.Position = len(siblings)
+	if err := s.checkProject(task, userID); err != nil {
+		return err
+	}
+	task.ProjectID = parent.ProjectID
+	if err := s.checkAssignee(task); err != nil {
+		return err
+	}
+	if err := s.applyDefaultPriority(task); err != nil {
+		return err
+	}
+	if err := s.applyRecurrence(task); err != nil {
+		return err
+	}
+	return s.taskRepo.CreateTask(task, userID)
+}
+
+// GetSubtasks retrieves the direct subtasks of a task, each with its own subtasks nested below it
+func (s *TaskService) GetSubtasks(parentID uuid.UUID, userID uuid.UUID) ([]models.Task, error) {
+	parent, err := s.GetTaskByID(parentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Subtasks == nil {
+		return []models.Task{}, nil
+	}
+	return parent.Subtasks, nil
+}
+
+// GetSubtask retrieves a subtask, making sure it belongs to the given parent
+func (s *TaskService) GetSubtask(parentID uuid.UUID, id uuid.UUID, userID uuid.UUID) (*models.Task, error) {
+	task, err := s.GetTaskByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if task.ParentID == nil || *task.ParentID != parentID {
+		return nil, errors.New("task not found or unauthorized")
+	}
+	return task, nil
+}
+
+// MoveSubtask moves a subtask to the given position among its siblings and renumbers the others
+func (s *TaskService) MoveSubtask(parentID uuid.UUID, id uuid.UUID, position int, userID uuid.UUID) error {
+	parent, err := s.editableTask(parentID, userID)
+	if err != nil {
+		return err
+	}
+	siblings, err := s.taskRepo.GetSubtasksByParentIDs([]uuid.UUID{parentID}, userID)
+	if err != nil {
+		return err
+	}
+
+	ordered := make([]uuid.UUID, 0, len(siblings))
+	found := false
+	for _, sibling := range siblings {
+		if sibling.ID == id {
+			found = true
+			continue
+		}
+		ordered = append(ordered, sibling.ID)
+	}
+	if !found {
+		return errors.New("task not found or unauthorized")
+	}
+
+	if position < 0 {
+		position = 0
+	}
+	if position > len(ordered) {
+		position = len(ordered)
+	}
+	ordered = append(ordered[:position], append([]uuid.UUID{id}, ordered[position:]...)...)
+
+	positions := make(map[uuid.UUID]int)
+	for i, siblingID := range ordered {
+		if siblings[i].ID != siblingID || siblings[i].Position != i {
+			positions[siblingID] = i
+		}
+	}
+	if len(positions) == 0 {
+		return nil
+	}
+	return s.taskRepo.UpdateTaskPositions(positions, parent.UserID)
+}
+
+// CompleteTask marks a task as completed. With cascade, all of its subtasks are completed as well.
+// Completing a repeating task creates its next occurrence. A task that waits on open tasks is
+// refused with ErrTaskBlocked, unless force is set.
+func (s *TaskService) CompleteTask(id uuid.UUID, userID uuid.UUID, cascade bool, force bool) (*models.Task, error) {
+	return s.setCompleted(id, userID, cascade, true, force)
+}
+
+// ReopenTask marks a completed task as not completed. With cascade, all of its subtasks are reopened as well.
+func (s *TaskService) ReopenTask(id uuid.UUID, userID uuid.UUID, cascade bool) (*models.Task, error) {
+	return s.setCompleted(id, userID, cascade, false, false)
+}
+
+func (s *TaskService) setCompleted(id uuid.UUID, userID uuid.UUID, cascade bool, completed bool, force bool) (*models.Task, error) {
+	task, err := s.editableTask(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if completed && !force {
+		if err := checkCompletable(task); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := []uuid.UUID{id}
+	if cascade {
+		descendants, err := s.taskRepo.GetDescendantIDs(id, task.UserID)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, descendants...)
+	}
+	if err := s.taskRepo.SetTasksCompleted(ids, task.UserID, completed, userID); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.GetTaskByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if completed && !task.Completed && updated.Recurrence != "" {
+		if err := s.createNextOccurrence(updated); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+// GetTaskHistory retrieves the log of a task: each time it was completed, reopened or reassigned
+func (s *TaskService) GetTaskHistory(id uuid.UUID, userID uuid.UUID) (*models.TaskHistory, error) {
+	events, err := s.GetTaskActivity(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &models.TaskHistory{TaskID: id, Events: []models.TaskEvent{}}
+	for _, event := range events {
+		switch event.Type {
+		case models.TaskEventCompleted:
+			history.CompletionCount++
+			history.LastCompletedAt = &event.CreatedAt
+		case models.TaskEventReopened, models.TaskEventAssigned:
+		default:
+			continue
+		}
+		history.Events = append(history.Events, event)
+	}
+	return history, nil
+}
+
 // ExtractAndCreateTasks extracts tasks from text and creates them in the database
 func (s *TaskService) ExtractAndCreateTasks(ctx context.Context, text string, userID uuid.UUID) ([]models.Task, error) {
-	extractedLLMTasks, err := s.llmExtractor.ExtractTasks(ctx, text)
+	return s.ExtractAndCreateProjectTasks(ctx, text, userID, nil)
+}
+
+// ExtractAndCreateProjectTasks extracts tasks from text and creates them in a project, or in the
+// user's inbox when projectID is nil. Tasks the text hands to a member of the project by name are
+// assigned to them.
+func (s *TaskService) ExtractAndCreateProjectTasks(ctx context.Context, text string, userID uuid.UUID, projectID *uuid.UUID) ([]models.Task, error) {
+	return s.extractAndCreateTasks(ctx, text, userID, projectID, nil)
+}
+
+// ExtractAndCreateTasksOnce is ExtractAndCreateProjectTasks for background work that may run more
+// than once, such as a job or the processing of an audio upload. The tasks are recorded as coming
+// from sourceID, and when tasks from it exist already they are returned instead of extracting
+// again. A run that stopped partway leaves the tasks it got to, and a rerun returns just those.
+func (s *TaskService) ExtractAndCreateTasksOnce(ctx context.Context, sourceID uuid.UUID, text string, userID uuid.UUID, projectID *uuid.UUID) ([]models.Task, error) {
+	existing, err := s.taskRepo.GetTasksBySource(sourceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return existing, nil
+	}
+	return s.extractAndCreateTasks(ctx, text, userID, projectID, &sourceID)
+}
+
+func (s *TaskService) extractAndCreateTasks(ctx context.Context, text string, userID uuid.UUID, projectID *uuid.UUID, sourceID *uuid.UUID) ([]models.Task, error) {
+	settings, err := s.userSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	ownerID := userID
+	var members []models.ProjectMember
+	if projectID != nil && s.projectService != nil {
+		project, err := s.projectService.authorize(*projectID, userID, accessEdit)
+		if err != nil {
+			return nil, err
+		}
+		ownerID = project.UserID
+		if members, err = s.projectService.projectRepo.GetProjectMembers(*projectID); err != nil {
+			return nil, err
+		}
+	}
+	// The extractor may only suggest tags the tasks' owner already has
+	var tags []models.Tag
+	if s.tagService != nil {
+		if tags, err = s.tagService.GetTags(ownerID); err != nil {
+			return nil, err
+		}
+	}
+	tagNames := make([]string, len(tags))
+	for i, tag := range tags {
+		tagNames[i] = tag.Name
+	}
+	// Relative dates in the text are resolved in the user's time zone
+	extractedLLMTasks, err := s.llmExtractor.ExtractTasks(ctx, text, llm.ExtractOptions{
+		Now:             time.Now(),
+		Location:        settings.Location(),
+		WeekStart:       settings.WeekStartDay(),
+		Locale:          settings.Locale,
+		DefaultPriority: settings.DefaultPriority,
+		Tags:            tagNames,
+		Members:         memberNames(members),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract tasks with LLM: %w", err)
 	}
 
 	var createdTasks []models.Task
-	for _, llmTask := range extractedLLMTasks {
+	createdIDs := make([]uuid.UUID, len(extractedLLMTasks)) // by position in the extracted list; nil if not created
+	for i, llmTask := range extractedLLMTasks {
+		if llmTask.Priority == "" {
+			llmTask.Priority = settings.DefaultPriority
+		}
 		task := &models.Task{
 			ID:          uuid.New(),
-			UserID:      userID,
+			UserID:      ownerID,
 			Title:       llmTask.Title,
 			Description: llmTask.Description,
 			DueDate: func() *time.Time {
@@ -104,13 +659,143 @@ func (s *TaskService) ExtractAndCreateTasks(ctx context.Context, text string, us
 			}(),
 			Priority:    llmTask.Priority,
 			RawText:     text, // Store the raw text that led to this task
+			Recurrence:  llmTask.Recurrence,
+			ProjectID:   projectID,
+			AssigneeID:  pickAssignee(members, llmTask.Assignee),
+			Tags:        pickTags(tags, llmTask.Tags),
+			BlockedBy:   pickBlockers(createdIDs[:i], llmTask.DependsOn),
+			SourceID:    sourceID,
 		}
-		if err := s.taskRepo.CreateTask(task); err != nil {
+		// The blockers were only just created, so they are all open
+		task.Blocked = len(task.BlockedBy) > 0
+		if err := s.applyRecurrence(task); err != nil {
+			if !errors.Is(err, ErrInvalidRecurrence) {
+				return nil, err
+			}
+			// A rule the model made up outside the supported subset is dropped, not the task
+			task.Recurrence = ""
+		}
+		// Subtasks become child rows created in the same transaction as their parent
+		for _, title := range llmTask.Subtasks {
+			if strings.TrimSpace(title) == "" {
+				continue
+			}
+			task.Subtasks = append(task.Subtasks, models.Task{
+				ID:       uuid.New(),
+				UserID:   ownerID,
+				Title:    strings.TrimSpace(title),
+				Priority: llmTask.Priority,
+				RawText:  text,
+				Position: len(task.Subtasks),
+			})
+		}
+		if err := s.taskRepo.CreateTask(task, userID); err != nil {
 			// Log the error but try to continue with other tasks
 			// Or decide if you want to fail all if one fails
 			continue
 		}
+		createdIDs[i] = task.ID
 		createdTasks = append(createdTasks, *task)
 	}
 	return createdTasks, nil
 }
+
+// pickBlockers returns the IDs of the created tasks at the given positions of the extracted list.
+// Positions out of range, and of tasks that were not created, are dropped.
+func pickBlockers(created []uuid.UUID, positions []int) []uuid.UUID {
+	var ids []uuid.UUID
+	seen := make(map[int]bool)
+	for _, position := range positions {
+		if position < 0 || position >= len(created) || created[position] == uuid.Nil || seen[position] {
+			continue
+		}
+		seen[position] = true
+		ids = append(ids, created[position])
+	}
+	return ids
+}
+
+// pickTags returns the tags among the user's tags that are named, ignoring case. Names of tags the
+// user does not have are dropped.
+func pickTags(tags []models.Tag, names []string) []models.Tag {
+	byName := make(map[string]models.Tag, len(tags))
+	for _, tag := range tags {
+		byName[strings.ToLower(tag.Name)] = tag
+	}
+	var picked []models.Tag
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if tag, ok := byName[key]; ok {
+			picked = append(picked, tag)
+			delete(byName, key)
+		}
+	}
+	return picked
+}
+
+// getTask retrieves a task the user can see without its subtasks, mapping a missing row to the
+// not-found error
+func (s *TaskService) getTask(id uuid.UUID, userID uuid.UUID) (*models.Task, error) {
+	task, err := s.taskRepo.GetTaskByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("task not found or unauthorized")
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// trashedTask retrieves a task in the trash, provided the user may change it
+func (s *TaskService) trashedTask(id uuid.UUID, userID uuid.UUID) (*models.Task, error) {
+	task, err := s.taskRepo.GetDeletedTaskByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("task not found or unauthorized")
+		}
+		return nil, err
+	}
+	if err := s.authorizeTask(task, userID, accessEdit); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// checkVersion fails with repositories.ErrVersionConflict unless version is zero or the task's version
+func checkVersion(task *models.Task, version int) error {
+	if version != 0 && version != task.Version {
+		return repositories.ErrVersionConflict
+	}
+	return nil
+}
+
+// attachSubtasks loads the subtask trees below the given tasks, one query per level of depth
+func (s *TaskService) attachSubtasks(tasks []models.Task, userID uuid.UUID) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	parentIDs := make([]uuid.UUID, len(tasks))
+	for i, task := range tasks {
+		parentIDs[i] = task.ID
+	}
+
+	children, err := s.taskRepo.GetSubtasksByParentIDs(parentIDs, userID)
+	if err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		return nil
+	}
+	if err := s.attachSubtasks(children, userID); err != nil {
+		return err
+	}
+
+	byParent := make(map[uuid.UUID][]models.Task)
+	for _, child := range children {
+		byParent[*child.ParentID] = append(byParent[*child.ParentID], child)
+	}
+	for i := range tasks {
+		tasks[i].Subtasks = byParent[tasks[i].ID]
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 	"todo-backend/internal/llm"
 	"todo-backend/internal/models"
+	"todo-backend/internal/repositories"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -19,11 +20,27 @@ type MockTaskRepository struct {
 	mock.Mock
 }
 
-func (m *MockTaskRepository) CreateTask(task *models.Task) error {
-	args := m.Called(task)
+func (m *MockTaskRepository) CreateTask(task *models.Task, actorID uuid.UUID) error {
+	args := m.Called(task, actorID)
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) ListTasks(query repositories.TaskQuery) ([]models.Task, string, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]models.Task), args.String(1), args.Error(2)
+}
+
+func (m *MockTaskRepository) SearchTasks(search repositories.TaskSearch) ([]models.TaskSearchResult, error) {
+	args := m.Called(search)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TaskSearchResult), args.Error(1)
+}
+
 func (m *MockTaskRepository) GetTaskByID(id uuid.UUID, userID uuid.UUID) (*models.Task, error) {
 	args := m.Called(id, userID)
 	if args.Get(0) == nil {
@@ -40,23 +57,156 @@ func (m *MockTaskRepository) GetTasksByUserID(userID uuid.UUID) ([]models.Task,
 	return args.Get(0).([]models.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) UpdateTask(task *models.Task) error {
-	args := m.Called(task)
+func (m *MockTaskRepository) GetTasksBySource(sourceID uuid.UUID, userID uuid.UUID) ([]models.Task, error) {
+	args := m.Called(sourceID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetTasksByIDs(ids []uuid.UUID, userID uuid.UUID) ([]models.Task, error) {
+	args := m.Called(ids, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetSubtasksByParentIDs(parentIDs []uuid.UUID, userID uuid.UUID) ([]models.Task, error) {
+	args := m.Called(parentIDs, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetDescendantIDs(id uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockTaskRepository) UpdateTask(task *models.Task, actorID uuid.UUID, cond repositories.TaskPrecondition) error {
+	args := m.Called(task, actorID, cond)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) UpdateTaskPositions(positions map[uuid.UUID]int, userID uuid.UUID) error {
+	args := m.Called(positions, userID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) SetTasksCompleted(ids []uuid.UUID, userID uuid.UUID, completed bool, actorID uuid.UUID) error {
+	args := m.Called(ids, userID, completed, actorID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetTaskEvents(taskID uuid.UUID, userID uuid.UUID) ([]models.TaskEvent, error) {
+	args := m.Called(taskID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TaskEvent), args.Error(1)
+}
+
+func (m *MockTaskRepository) DeleteTask(id uuid.UUID, userID uuid.UUID, cond repositories.TaskPrecondition) error {
+	args := m.Called(id, userID, cond)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetChangeCounter(userID uuid.UUID) (*models.TaskChangeCounter, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskChangeCounter), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetDeletedTasks(userID uuid.UUID) ([]models.Task, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetDeletedTaskByID(id uuid.UUID, userID uuid.UUID) (*models.Task, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) RestoreTask(id uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(id, userID)
 	return args.Error(0)
 }
 
-func (m *MockTaskRepository) DeleteTask(id uuid.UUID, userID uuid.UUID) error {
+func (m *MockTaskRepository) PurgeTask(id uuid.UUID, userID uuid.UUID) error {
 	args := m.Called(id, userID)
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) PurgeDeletedTasks(userID uuid.UUID) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepository) PurgeTasksDeletedBefore(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepository) CreateNextOccurrence(next *models.Task) (bool, error) {
+	args := m.Called(next)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTaskRepository) AddTaskBlockers(taskID uuid.UUID, blockerIDs []uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(taskID, blockerIDs, userID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) RemoveTaskBlocker(taskID uuid.UUID, blockerID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(taskID, blockerID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetTaskChanges(userID uuid.UUID, since int64, limit int) ([]models.Task, error) {
+	args := m.Called(userID, since, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetSharedTaskChanges(projectID uuid.UUID, userID uuid.UUID, since int64, limit int) ([]models.Task, error) {
+	args := m.Called(projectID, userID, since, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetSharedProjects(userID uuid.UUID) ([]models.Project, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Project), args.Error(1)
+}
+
 // MockLLMExtractor is a mock implementation of llm.TaskExtractor
 type MockLLMExtractor struct {
 	mock.Mock
 }
 
-func (m *MockLLMExtractor) ExtractTasks(ctx context.Context, text string) ([]llm.Task, error) {
-	args := m.Called(ctx, text)
+func (m *MockLLMExtractor) ExtractTasks(ctx context.Context, text string, opts llm.ExtractOptions) ([]llm.Task, error) {
+	args := m.Called(ctx, text, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -70,7 +220,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 
 	t.Run("successfully creates a task", func(t *testing.T) {
 		task := &models.Task{ID: uuid.New(), UserID: uuid.New(), Title: "Test Task"}
-		mockTaskRepo.On("CreateTask", task).Return(nil).Once()
+		mockTaskRepo.On("CreateTask", task, mock.Anything).Return(nil).Once()
 
 		err := taskService.CreateTask(task)
 		assert.NoError(t, err)
@@ -79,7 +229,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 
 	t.Run("returns error if CreateTask fails", func(t *testing.T) {
 		task := &models.Task{ID: uuid.New(), UserID: uuid.New(), Title: "Test Task"}
-		mockTaskRepo.On("CreateTask", task).Return(errors.New("db error")).Once()
+		mockTaskRepo.On("CreateTask", task, mock.Anything).Return(errors.New("db error")).Once()
 
 		err := taskService.CreateTask(task)
 		assert.Error(t, err)
@@ -99,6 +249,7 @@ func TestTaskService_GetTaskByID(t *testing.T) {
 
 	t.Run("successfully retrieves a task by ID", func(t *testing.T) {
 		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(testTask, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil).Once()
 
 		task, err := taskService.GetTaskByID(taskID, userID)
 		assert.NoError(t, err)
@@ -106,6 +257,23 @@ func TestTaskService_GetTaskByID(t *testing.T) {
 		mockTaskRepo.AssertExpectations(t)
 	})
 
+	t.Run("nests subtasks below the task", func(t *testing.T) {
+		childID := uuid.New()
+		grandchildID := uuid.New()
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(testTask, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{{ID: childID, ParentID: &taskID, Title: "Child"}}, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{childID}, userID).Return([]models.Task{{ID: grandchildID, ParentID: &childID, Title: "Grandchild"}}, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{grandchildID}, userID).Return([]models.Task{}, nil).Once()
+
+		task, err := taskService.GetTaskByID(taskID, userID)
+		assert.NoError(t, err)
+		assert.Len(t, task.Subtasks, 1)
+		assert.Equal(t, "Child", task.Subtasks[0].Title)
+		assert.Len(t, task.Subtasks[0].Subtasks, 1)
+		assert.Equal(t, "Grandchild", task.Subtasks[0].Subtasks[0].Title)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
 	t.Run("returns error if task not found", func(t *testing.T) {
 		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
 
@@ -130,6 +298,7 @@ func TestTaskService_GetTasksByUserID(t *testing.T) {
 
 	t.Run("successfully retrieves tasks by user ID", func(t *testing.T) {
 		mockTaskRepo.On("GetTasksByUserID", userID).Return(testTasks, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{testTasks[0].ID, testTasks[1].ID}, userID).Return([]models.Task{}, nil).Once()
 
 		tasks, err := taskService.GetTasksByUserID(userID)
 		assert.NoError(t, err)
@@ -147,6 +316,55 @@ func TestTaskService_GetTasksByUserID(t *testing.T) {
 	})
 }
 
+func TestTaskService_ListTasks(t *testing.T) {
+	mockTaskRepo := new(MockTaskRepository)
+	mockLLMExtractor := new(MockLLMExtractor)
+	taskService := NewTaskService(mockTaskRepo, mockLLMExtractor)
+
+	userID := uuid.New()
+	parentID := uuid.New()
+	query := repositories.TaskQuery{UserID: userID, SortBy: repositories.SortByDueDate, Limit: 1}
+
+	t.Run("returns the page with its subtasks and next cursor", func(t *testing.T) {
+		mockTaskRepo.On("ListTasks", query).Return([]models.Task{{ID: parentID, UserID: userID, Title: "Parent"}}, "next-page", nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{parentID}, userID).Return([]models.Task{{ID: uuid.New(), ParentID: &parentID, Title: "Child"}}, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", mock.Anything, userID).Return([]models.Task{}, nil).Once()
+
+		tasks, next, err := taskService.ListTasks(query)
+		assert.NoError(t, err)
+		assert.Equal(t, "next-page", next)
+		if assert.Len(t, tasks, 1) && assert.Len(t, tasks[0].Subtasks, 1) {
+			assert.Equal(t, "Child", tasks[0].Subtasks[0].Title)
+		}
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("passes repository errors through", func(t *testing.T) {
+		mockTaskRepo.On("ListTasks", query).Return(nil, "", repositories.ErrInvalidCursor).Once()
+
+		tasks, next, err := taskService.ListTasks(query)
+		assert.ErrorIs(t, err, repositories.ErrInvalidCursor)
+		assert.Nil(t, tasks)
+		assert.Empty(t, next)
+		mockTaskRepo.AssertExpectations(t)
+	})
+}
+
+func TestTaskService_SearchTasks(t *testing.T) {
+	mockTaskRepo := new(MockTaskRepository)
+	mockLLMExtractor := new(MockLLMExtractor)
+	taskService := NewTaskService(mockTaskRepo, mockLLMExtractor)
+
+	search := repositories.TaskSearch{UserID: uuid.New(), Text: "dentist"}
+	expected := []models.TaskSearchResult{{Task: models.Task{ID: uuid.New(), Title: "Book dentist"}, Rank: 0.5, Snippet: "Book <mark>dentist</mark>"}}
+	mockTaskRepo.On("SearchTasks", search).Return(expected, nil).Once()
+
+	results, err := taskService.SearchTasks(search)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, results)
+	mockTaskRepo.AssertExpectations(t)
+}
+
 func TestTaskService_UpdateTask(t *testing.T) {
 	mockTaskRepo := new(MockTaskRepository)
 	mockLLMExtractor := new(MockLLMExtractor)
@@ -159,7 +377,7 @@ func TestTaskService_UpdateTask(t *testing.T) {
 
 	t.Run("successfully updates a task", func(t *testing.T) {
 		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(originalTask, nil).Once()
-		mockTaskRepo.On("UpdateTask", mock.AnythingOfType("*models.Task")).Return(nil).Once()
+		mockTaskRepo.On("UpdateTask", mock.AnythingOfType("*models.Task"), mock.Anything, mock.Anything).Return(nil).Once()
 
 		err := taskService.UpdateTask(updatedTaskInput, userID)
 		assert.NoError(t, err)
@@ -167,6 +385,16 @@ func TestTaskService_UpdateTask(t *testing.T) {
 		mockTaskRepo.AssertExpectations(t)
 	})
 
+	t.Run("returns a version conflict for a stale version", func(t *testing.T) {
+		stored := &models.Task{ID: taskID, UserID: userID, Title: "Original", Version: 3}
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(stored, nil).Once()
+
+		err := taskService.UpdateTask(&models.Task{ID: taskID, UserID: userID, Title: "Stale", Version: 2}, userID)
+		assert.ErrorIs(t, err, repositories.ErrVersionConflict)
+		assert.Equal(t, "Original", stored.Title)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
 	t.Run("returns error if task not found for update", func(t *testing.T) {
 		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
 
@@ -177,6 +405,59 @@ func TestTaskService_UpdateTask(t *testing.T) {
 	})
 }
 
+func TestTaskService_PatchTask(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+	dueDate := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("changes only the patched fields", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		stored := &models.Task{ID: taskID, UserID: userID, Title: "Original", Description: "Desc", Priority: "high", DueDate: &dueDate}
+		title := "Renamed"
+		priority := ""
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(stored, nil).Twice()
+		mockTaskRepo.On("UpdateTask", mock.MatchedBy(func(task *models.Task) bool {
+			return task.Title == "Renamed" && task.Description == "Desc" && task.DueDate == nil && task.Priority == "medium"
+		}), mock.Anything, mock.Anything).Return(nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil).Once()
+
+		task, err := taskService.PatchTask(taskID, userID, models.TaskPatch{Title: &title, Priority: &priority, ClearDueDate: true}, repositories.TaskPrecondition{})
+		assert.NoError(t, err)
+		assert.Equal(t, "Renamed", task.Title)
+		mockTaskRepo.AssertExpectations(t)
+		mockTaskRepo.AssertNotCalled(t, "SetTasksCompleted", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("completes the task in the same write as the other changes", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		completed := true
+		title := "Renamed"
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID, Title: "Original", Priority: "low"}, nil).Twice()
+		mockTaskRepo.On("UpdateTask", mock.MatchedBy(func(task *models.Task) bool {
+			return task.Title == "Renamed" && task.Completed
+		}), userID, mock.Anything).Return(nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil).Once()
+
+		_, err := taskService.PatchTask(taskID, userID, models.TaskPatch{Title: &title, Completed: &completed}, repositories.TaskPrecondition{})
+		assert.NoError(t, err)
+		mockTaskRepo.AssertExpectations(t)
+		mockTaskRepo.AssertNotCalled(t, "SetTasksCompleted", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns error if task not found", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		task, err := taskService.PatchTask(taskID, userID, models.TaskPatch{}, repositories.TaskPrecondition{})
+		assert.Nil(t, task)
+		assert.EqualError(t, err, "task not found or unauthorized")
+		mockTaskRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestTaskService_DeleteTask(t *testing.T) {
 	mockTaskRepo := new(MockTaskRepository)
 	mockLLMExtractor := new(MockLLMExtractor)
@@ -186,21 +467,33 @@ func TestTaskService_DeleteTask(t *testing.T) {
 	taskID := uuid.New()
 
 	t.Run("successfully deletes a task", func(t *testing.T) {
-		mockTaskRepo.On("DeleteTask", taskID, userID).Return(nil).Once()
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID}, nil).Once()
+		mockTaskRepo.On("DeleteTask", taskID, userID, mock.Anything).Return(nil).Once()
 
-		err := taskService.DeleteTask(taskID, userID)
+		err := taskService.DeleteTask(taskID, userID, repositories.TaskPrecondition{})
 		assert.NoError(t, err)
 		mockTaskRepo.AssertExpectations(t)
 	})
 
 	t.Run("returns error if task not found for deletion", func(t *testing.T) {
-		mockTaskRepo.On("DeleteTask", taskID, userID).Return(gorm.ErrRecordNotFound).Once()
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
 
-		err := taskService.DeleteTask(taskID, userID)
+		err := taskService.DeleteTask(taskID, userID, repositories.TaskPrecondition{})
 		assert.Error(t, err)
 		assert.EqualError(t, err, "task not found or unauthorized")
 		mockTaskRepo.AssertExpectations(t)
 	})
+
+	t.Run("deletes only at the expected version", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID, Version: 2}, nil).Twice()
+		mockTaskRepo.On("DeleteTask", taskID, userID, mock.Anything).Return(nil).Once()
+
+		assert.ErrorIs(t, taskService.DeleteTask(taskID, userID, repositories.TaskPrecondition{Version: 1}), repositories.ErrVersionConflict)
+		assert.NoError(t, taskService.DeleteTask(taskID, userID, repositories.TaskPrecondition{Version: 2}))
+		mockTaskRepo.AssertExpectations(t)
+	})
 }
 
 func TestTaskService_ExtractAndCreateTasks(t *testing.T) {
@@ -221,8 +514,8 @@ func TestTaskService_ExtractAndCreateTasks(t *testing.T) {
 	}
 
 	t.Run("successfully extracts and creates tasks", func(t *testing.T) {
-		mockLLMExtractor.On("ExtractTasks", mock.AnythingOfType("context.backgroundCtx"), inputText).Return(extractedLLMTasks, nil).Once()
-		mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task")).Return(nil).Once()
+		mockLLMExtractor.On("ExtractTasks", mock.AnythingOfType("context.backgroundCtx"), inputText, mock.AnythingOfType("llm.ExtractOptions")).Return(extractedLLMTasks, nil).Once()
+		mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task"), mock.Anything).Return(nil).Once()
 
 		createdTasks, err := taskService.ExtractAndCreateTasks(context.Background(), inputText, userID)
 		assert.NoError(t, err)
@@ -233,7 +526,7 @@ func TestTaskService_ExtractAndCreateTasks(t *testing.T) {
 	})
 
 	t.Run("returns error if LLM extraction fails", func(t *testing.T) {
-		mockLLMExtractor.On("ExtractTasks", mock.AnythingOfType("context.backgroundCtx"), inputText).Return(nil, errors.New("llm error")).Once()
+		mockLLMExtractor.On("ExtractTasks", mock.AnythingOfType("context.backgroundCtx"), inputText, mock.AnythingOfType("llm.ExtractOptions")).Return(nil, errors.New("llm error")).Once()
 
 		createdTasks, err := taskService.ExtractAndCreateTasks(context.Background(), inputText, userID)
 		assert.Error(t, err)
@@ -248,10 +541,10 @@ func TestTaskService_ExtractAndCreateTasks(t *testing.T) {
 			{Title: "Task 1"},
 			{Title: "Task 2"},
 		}
-		mockLLMExtractor.On("ExtractTasks", mock.AnythingOfType("context.backgroundCtx"), inputText).Return(extractedMultiLLMTasks, nil).Once()
+		mockLLMExtractor.On("ExtractTasks", mock.AnythingOfType("context.backgroundCtx"), inputText, mock.AnythingOfType("llm.ExtractOptions")).Return(extractedMultiLLMTasks, nil).Once()
 		// Simulate one task creation failure
-		mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task")).Return(errors.New("db error")).Once().
-			On("CreateTask", mock.AnythingOfType("*models.Task")).Return(nil).Once()
+		mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task"), mock.Anything).Return(errors.New("db error")).Once().
+			On("CreateTask", mock.AnythingOfType("*models.Task"), mock.Anything).Return(nil).Once()
 
 
 		createdTasks, err := taskService.ExtractAndCreateTasks(context.Background(), inputText, userID)
@@ -261,4 +554,239 @@ func TestTaskService_ExtractAndCreateTasks(t *testing.T) {
 		mockLLMExtractor.AssertExpectations(t)
 		mockTaskRepo.AssertExpectations(t)
 	})
+
+	t.Run("creates extracted subtasks as ordered children of their task", func(t *testing.T) {
+		withSubtasks := []llm.Task{
+			{Title: "Plan trip", Priority: "high", Subtasks: []string{"Book flights", " ", "Reserve hotel"}},
+		}
+		mockLLMExtractor.On("ExtractTasks", mock.AnythingOfType("context.backgroundCtx"), inputText, mock.AnythingOfType("llm.ExtractOptions")).Return(withSubtasks, nil).Once()
+		mockTaskRepo.On("CreateTask", mock.MatchedBy(func(task *models.Task) bool {
+			return task.Title == "Plan trip"
+		}), mock.Anything).Return(nil).Once()
+
+		createdTasks, err := taskService.ExtractAndCreateTasks(context.Background(), inputText, userID)
+		assert.NoError(t, err)
+		assert.Len(t, createdTasks, 1)
+		subtasks := createdTasks[0].Subtasks
+		assert.Len(t, subtasks, 2)
+		assert.Equal(t, "Book flights", subtasks[0].Title)
+		assert.Equal(t, 0, subtasks[0].Position)
+		assert.Equal(t, "Reserve hotel", subtasks[1].Title)
+		assert.Equal(t, 1, subtasks[1].Position)
+		assert.Equal(t, "high", subtasks[1].Priority)
+		assert.Equal(t, userID, subtasks[1].UserID)
+		mockTaskRepo.AssertExpectations(t)
+	})
+}
+
+func TestTaskService_Subtasks(t *testing.T) {
+	userID := uuid.New()
+	parentID := uuid.New()
+	parent := &models.Task{ID: parentID, UserID: userID, Title: "Plan trip"}
+	first := models.Task{ID: uuid.New(), ParentID: &parentID, Title: "Book flights", Position: 0}
+	second := models.Task{ID: uuid.New(), ParentID: &parentID, Title: "Reserve hotel", Position: 1}
+	third := models.Task{ID: uuid.New(), ParentID: &parentID, Title: "Pack", Position: 2}
+
+	t.Run("CreateSubtask appends the subtask after its siblings", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetTaskByID", parentID, userID).Return(parent, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{parentID}, userID).Return([]models.Task{first, second}, nil).Once()
+		mockTaskRepo.On("CreateTask", mock.AnythingOfType("*models.Task"), mock.Anything).Return(nil).Once()
+
+		subtask := &models.Task{Title: "Pack"}
+		err := taskService.CreateSubtask(parentID, subtask, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, &parentID, subtask.ParentID)
+		assert.Equal(t, userID, subtask.UserID)
+		assert.Equal(t, 2, subtask.Position)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("CreateSubtask returns error if the parent is not found", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetTaskByID", parentID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		err := taskService.CreateSubtask(parentID, &models.Task{Title: "Pack"}, userID)
+		assert.EqualError(t, err, "task not found or unauthorized")
+		mockTaskRepo.AssertNotCalled(t, "CreateTask", mock.Anything, mock.Anything)
+	})
+
+	t.Run("GetSubtask rejects a task that belongs to another parent", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		otherParentID := uuid.New()
+		mockTaskRepo.On("GetTaskByID", first.ID, userID).Return(&models.Task{ID: first.ID, ParentID: &otherParentID}, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{first.ID}, userID).Return([]models.Task{}, nil).Once()
+
+		subtask, err := taskService.GetSubtask(parentID, first.ID, userID)
+		assert.Nil(t, subtask)
+		assert.EqualError(t, err, "task not found or unauthorized")
+	})
+
+	t.Run("MoveSubtask renumbers only the siblings whose position changed", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetTaskByID", parentID, userID).Return(parent, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{parentID}, userID).Return([]models.Task{first, second, third}, nil).Once()
+		mockTaskRepo.On("UpdateTaskPositions", map[uuid.UUID]int{third.ID: 0, first.ID: 1, second.ID: 2}, userID).Return(nil).Once()
+
+		err := taskService.MoveSubtask(parentID, third.ID, 0, userID)
+		assert.NoError(t, err)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("MoveSubtask returns error for a task that is not a subtask of the parent", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetTaskByID", parentID, userID).Return(parent, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{parentID}, userID).Return([]models.Task{first}, nil).Once()
+
+		err := taskService.MoveSubtask(parentID, uuid.New(), 0, userID)
+		assert.EqualError(t, err, "task not found or unauthorized")
+	})
+}
+
+func TestTaskService_CompleteTask(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+	childID := uuid.New()
+	grandchildID := uuid.New()
+
+	t.Run("completes only the task by default", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID}, nil).Twice()
+		mockTaskRepo.On("SetTasksCompleted", []uuid.UUID{taskID}, userID, true, mock.Anything).Return(nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil).Once()
+
+		_, err := taskService.CompleteTask(taskID, userID, false, false)
+		assert.NoError(t, err)
+		mockTaskRepo.AssertExpectations(t)
+		mockTaskRepo.AssertNotCalled(t, "GetDescendantIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("cascades to all subtasks when asked to", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID}, nil).Twice()
+		mockTaskRepo.On("GetDescendantIDs", taskID, userID).Return([]uuid.UUID{childID, grandchildID}, nil).Once()
+		mockTaskRepo.On("SetTasksCompleted", []uuid.UUID{taskID, childID, grandchildID}, userID, true, mock.Anything).Return(nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil).Once()
+
+		_, err := taskService.CompleteTask(taskID, userID, true, false)
+		assert.NoError(t, err)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error if task not found", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		task, err := taskService.CompleteTask(taskID, userID, true, false)
+		assert.Nil(t, task)
+		assert.EqualError(t, err, "task not found or unauthorized")
+	})
+}
+
+func TestTaskService_ReopenTask(t *testing.T) {
+	mockTaskRepo := new(MockTaskRepository)
+	taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+	userID := uuid.New()
+	taskID := uuid.New()
+
+	mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID}, nil).Twice()
+	mockTaskRepo.On("SetTasksCompleted", []uuid.UUID{taskID}, userID, false, mock.Anything).Return(nil).Once()
+	mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil).Once()
+
+	_, err := taskService.ReopenTask(taskID, userID, false)
+	assert.NoError(t, err)
+	mockTaskRepo.AssertExpectations(t)
+}
+
+func TestTaskService_GetTaskHistory(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+
+	t.Run("counts completions and reports the latest", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		first := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+		events := []models.TaskEvent{
+			{TaskID: taskID, Type: models.TaskEventCompleted, CreatedAt: first},
+			{TaskID: taskID, Type: models.TaskEventReopened, CreatedAt: first.Add(time.Hour)},
+			{TaskID: taskID, Type: models.TaskEventCompleted, CreatedAt: first.Add(24 * time.Hour)},
+		}
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID}, nil).Once()
+		mockTaskRepo.On("GetTaskEvents", taskID, userID).Return(events, nil).Once()
+
+		history, err := taskService.GetTaskHistory(taskID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, history.CompletionCount)
+		if assert.NotNil(t, history.LastCompletedAt) {
+			assert.Equal(t, first.Add(24*time.Hour), *history.LastCompletedAt)
+		}
+		assert.Len(t, history.Events, 3)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error if task not found", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		history, err := taskService.GetTaskHistory(taskID, userID)
+		assert.Nil(t, history)
+		assert.EqualError(t, err, "task not found or unauthorized")
+		mockTaskRepo.AssertNotCalled(t, "GetTaskEvents", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTaskService_RestoreTask(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+
+	t.Run("returns the restored task", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetDeletedTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID}, nil).Once()
+		mockTaskRepo.On("RestoreTask", taskID, userID).Return(nil).Once()
+		mockTaskRepo.On("GetTaskByID", taskID, userID).Return(&models.Task{ID: taskID, UserID: userID, Title: "Back"}, nil).Once()
+		mockTaskRepo.On("GetSubtasksByParentIDs", []uuid.UUID{taskID}, userID).Return([]models.Task{}, nil).Once()
+
+		task, err := taskService.RestoreTask(taskID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Back", task.Title)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns error if the task is not in the trash", func(t *testing.T) {
+		mockTaskRepo := new(MockTaskRepository)
+		taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+		mockTaskRepo.On("GetDeletedTaskByID", taskID, userID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		task, err := taskService.RestoreTask(taskID, userID)
+		assert.Nil(t, task)
+		assert.EqualError(t, err, "task not found or unauthorized")
+		mockTaskRepo.AssertNotCalled(t, "RestoreTask", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTaskService_GetTrash(t *testing.T) {
+	mockTaskRepo := new(MockTaskRepository)
+	taskService := NewTaskService(mockTaskRepo, new(MockLLMExtractor))
+	userID := uuid.New()
+	deletedAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	mockTaskRepo.On("GetDeletedTasks", userID).Return([]models.Task{
+		{ID: uuid.New(), UserID: userID, Title: "Gone", DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+	}, nil).Once()
+
+	trash, err := taskService.GetTrash(userID)
+	assert.NoError(t, err)
+	if assert.Len(t, trash, 1) {
+		assert.Equal(t, "Gone", trash[0].Title)
+		assert.Equal(t, deletedAt, trash[0].DeletedAt)
+	}
 }
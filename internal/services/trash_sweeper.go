@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TrashSweeper permanently deletes tasks that have been in the trash longer than the retention
+// period. Running it on several server instances at once is harmless.
+type TrashSweeper struct {
+	taskService *TaskService
+	retention   time.Duration
+
+	// Interval is how long the sweeper waits between sweeps
+	Interval time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	now    func() time.Time
+}
+
+// NewTrashSweeper creates a sweeper for tasks deleted more than retention ago
+func NewTrashSweeper(taskService *TaskService, retention time.Duration) *TrashSweeper {
+	return &TrashSweeper{
+		taskService: taskService,
+		retention:   retention,
+		Interval:    time.Hour,
+		now:         time.Now,
+	}
+}
+
+// Start sweeps right away and then every Interval in the background, until ctx is cancelled
+// or Stop is called
+func (s *TrashSweeper) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			if _, err := s.Sweep(); err != nil {
+				log.Error().Err(err).Msg("Trash sweep failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.Interval):
+			}
+		}
+	}()
+	log.Info().Dur("retention", s.retention).Dur("interval", s.Interval).Msg("Trash sweeper started")
+}
+
+// Stop asks the sweeper to exit and waits for a running sweep to finish
+func (s *TrashSweeper) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// Sweep purges the expired tasks once and returns how many were removed
+func (s *TrashSweeper) Sweep() (int64, error) {
+	purged, err := s.taskService.PurgeExpiredTasks(s.now().Add(-s.retention))
+	if err == nil && purged > 0 {
+		log.Info().Int64("tasks", purged).Msg("Purged expired tasks from the trash")
+	}
+	return purged, err
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashSweeper_Sweep(t *testing.T) {
+	mockTaskRepo := new(MockTaskRepository)
+	sweeper := NewTrashSweeper(NewTaskService(mockTaskRepo, new(MockLLMExtractor)), 7*24*time.Hour)
+	now := time.Date(2030, 1, 8, 12, 0, 0, 0, time.UTC)
+	sweeper.now = func() time.Time { return now }
+	mockTaskRepo.On("PurgeTasksDeletedBefore", time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)).Return(int64(4), nil).Once()
+
+	purged, err := sweeper.Sweep()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), purged)
+	mockTaskRepo.AssertExpectations(t)
+}
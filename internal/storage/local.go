@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStore implements the BlobStore interface on the local filesystem.
+// Presigned URLs point at publicURL and are verified with VerifySignedURL.
+type LocalStore struct {
+	root      string
+	publicURL string
+	secret    []byte
+	now       func() time.Time
+}
+
+// NewLocalStore creates a new LocalStore rooted at dir, creating the directory if needed
+func NewLocalStore(dir, publicURL string, secret []byte) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStore{
+		root:      dir,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		secret:    secret,
+		now:       time.Now,
+	}, nil
+}
+
+// Put writes content to the file for key, replacing any existing blob
+func (s *LocalStore) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to store blob: %w", err)
+	}
+	return nil
+}
+
+// Get opens the blob stored under key
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+	return file, nil
+}
+
+// Delete removes the blob stored under key. Deleting a missing blob is not an error.
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	return nil
+}
+
+// PresignedURL returns a time-limited URL under publicURL that grants read access to key
+func (s *LocalStore) PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(s.now().Add(expiry).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+	return s.publicURL + "/" + key + "?" + query.Encode(), nil
+}
+
+// VerifySignedURL checks the expiry and signature of a URL produced by PresignedURL
+func (s *LocalStore) VerifySignedURL(key string, query url.Values) error {
+	expires := query.Get("expires")
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errors.New("invalid expiry")
+	}
+	if s.now().Unix() > expiresAt {
+		return errors.New("url has expired")
+	}
+	if !hmac.Equal([]byte(s.sign(key, expires)), []byte(query.Get("signature"))) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (s *LocalStore) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path maps a key to a file below the store's root directory
+func (s *LocalStore) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), "http://localhost:8080/blobs", []byte("test-secret"))
+	assert.NoError(t, err)
+	ctx := context.Background()
+	key := NewKey("audio", uuid.New(), ".M4A")
+
+	t.Run("should put and get a blob", func(t *testing.T) {
+		assert.True(t, strings.HasPrefix(key, "audio/"))
+		assert.True(t, strings.HasSuffix(key, ".m4a"))
+
+		err := store.Put(ctx, key, strings.NewReader("voice note"), -1, "audio/mp4")
+		assert.NoError(t, err)
+
+		reader, err := store.Get(ctx, key)
+		assert.NoError(t, err)
+		defer reader.Close()
+		content, _ := io.ReadAll(reader)
+		assert.Equal(t, "voice note", string(content))
+	})
+
+	t.Run("should return ErrNotFound for a missing blob", func(t *testing.T) {
+		reader, err := store.Get(ctx, "audio/missing.wav")
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Nil(t, reader)
+	})
+
+	t.Run("should reject keys that escape the root", func(t *testing.T) {
+		for _, badKey := range []string{"", "/etc/passwd", "../secrets", "audio/../../x", "audio//x"} {
+			err := store.Put(ctx, badKey, strings.NewReader("x"), 1, "")
+			assert.Error(t, err, badKey)
+		}
+	})
+
+	t.Run("should sign and verify presigned URLs", func(t *testing.T) {
+		signed, err := store.PresignedURL(ctx, key, time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(signed, "http://localhost:8080/blobs/"+key+"?"))
+
+		parsed, _ := url.Parse(signed)
+		assert.NoError(t, store.VerifySignedURL(key, parsed.Query()))
+		assert.Error(t, store.VerifySignedURL("audio/other.m4a", parsed.Query()))
+
+		store.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		defer func() { store.now = time.Now }()
+		assert.EqualError(t, store.VerifySignedURL(key, parsed.Query()), "url has expired")
+	})
+
+	t.Run("should delete a blob", func(t *testing.T) {
+		assert.NoError(t, store.Delete(ctx, key))
+		_, err := store.Get(ctx, key)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, store.Delete(ctx, key)) // deleting twice is fine
+	})
+}
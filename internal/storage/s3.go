@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3TimeFormat      = "20060102T150405Z"
+	s3DateFormat      = "20060102"
+)
+
+// S3Options configures an S3Store
+type S3Options struct {
+	Endpoint     string // e.g. "https://s3.amazonaws.com" or "http://localhost:9000" for MinIO
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	UsePathStyle bool // address objects as <endpoint>/<bucket>/<key> instead of <bucket>.<host>/<key>
+}
+
+// S3Store implements the BlobStore interface against any S3-compatible object store
+// (AWS S3, MinIO, DigitalOcean Spaces), signing requests with AWS Signature Version 4.
+type S3Store struct {
+	opts       S3Options
+	endpoint   *url.URL
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewS3Store creates a new S3Store. A nil client uses http.DefaultClient.
+func NewS3Store(opts S3Options, client *http.Client) (*S3Store, error) {
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint: %q", opts.Endpoint)
+	}
+	if opts.Bucket == "" {
+		return nil, errors.New("S3 bucket is required")
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &S3Store{
+		opts:       opts,
+		endpoint:   endpoint,
+		httpClient: client,
+		now:        time.Now,
+	}, nil
+}
+
+// Put uploads content to key. A negative size buffers the content to determine its length.
+func (s *S3Store) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return fmt.Errorf("failed to read blob: %w", err)
+		}
+		content = bytes.NewReader(data)
+		size = int64(len(data))
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, content)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get downloads the object stored under key. The caller must close the returned reader.
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete removes the object stored under key. Deleting a missing object is not an error.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// PresignedURL returns a query-signed GET URL for key that is valid for expiry
+func (s *S3Store) PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	now := s.now().UTC()
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.opts.AccessKey+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	objectURL.RawQuery = canonicalQuery(query)
+
+	headers := http.Header{}
+	headers.Set("Host", objectURL.Host)
+	signature := s.signature(now, http.MethodGet, objectURL, headers, s3UnsignedPayload)
+
+	objectURL.RawQuery += "&X-Amz-Signature=" + signature
+	return objectURL.String(), nil
+}
+
+// newRequest builds a header-signed request for the object stored under key
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, objectURL.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	now := s.now().UTC()
+	req.Header.Set("Host", objectURL.Host)
+	req.Header.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	signedHeaders := signedHeaderNames(req.Header)
+	signature := s.signature(now, method, objectURL, req.Header, s3UnsignedPayload)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.opts.AccessKey, s.scope(now), signedHeaders, signature))
+	req.Header.Del("Host") // net/http sets the Host header from req.Host
+	return req, nil
+}
+
+// do sends a request and maps S3 error responses to Go errors
+func (s *S3Store) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to S3: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3 error: status %d, body: %s", resp.StatusCode, respBody)
+	}
+	return resp, nil
+}
+
+// objectURL returns the URL of key in either path-style or virtual-hosted style
+func (s *S3Store) objectURL(key string) (*url.URL, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	objectURL := *s.endpoint
+	basePath := strings.TrimRight(objectURL.Path, "/")
+	if s.opts.UsePathStyle {
+		objectURL.Path = basePath + "/" + s.opts.Bucket + "/" + key
+	} else {
+		objectURL.Host = s.opts.Bucket + "." + objectURL.Host
+		objectURL.Path = basePath + "/" + key
+	}
+	objectURL.RawPath = uriEncode(objectURL.Path, false)
+	return &objectURL, nil
+}
+
+func (s *S3Store) scope(now time.Time) string {
+	return now.Format(s3DateFormat) + "/" + s.opts.Region + "/s3/aws4_request"
+}
+
+// signature computes the SigV4 signature over the canonical form of a request
+func (s *S3Store) signature(now time.Time, method string, u *url.URL, headers http.Header, payloadHash string) string {
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		u.RawQuery,
+		canonicalHeaders(headers),
+		signedHeaderNames(headers),
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3TimeFormat),
+		s.scope(now),
+		hexSHA256(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretKey), now.Format(s3DateFormat))
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func canonicalHeaders(headers http.Header) string {
+	var b strings.Builder
+	for _, name := range sortedHeaderNames(headers) {
+		b.WriteString(name + ":" + strings.TrimSpace(headers.Get(name)) + "\n")
+	}
+	return b.String()
+}
+
+func signedHeaderNames(headers http.Header) string {
+	return strings.Join(sortedHeaderNames(headers), ";")
+}
+
+func sortedHeaderNames(headers http.Header) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		lower := strings.ToLower(name)
+		if lower == "host" || strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// canonicalQuery encodes query parameters sorted by key, as SigV4 requires
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything except unreserved characters (and '/' unless encodeSlash is set)
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
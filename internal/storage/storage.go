@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"todo-backend/internal/config"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned when no blob is stored under the requested key
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore defines the interface for storing binary content such as audio files and attachments.
+// Content is addressed by a slash-separated key that database records can reference.
+type BlobStore interface {
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// NewKey builds a unique key of the form "<prefix>/<owner>/<uuid><ext>"
+func NewKey(prefix string, ownerID uuid.UUID, ext string) string {
+	return path.Join(prefix, ownerID.String(), uuid.New().String()+strings.ToLower(ext))
+}
+
+// New returns the BlobStore selected by cfg.StorageBackend
+func New(cfg *config.Config) (BlobStore, error) {
+	switch cfg.StorageBackend {
+	case "", "local":
+		return NewLocalStore(cfg.StorageLocalDir, cfg.StoragePublicURL, []byte(cfg.JWTSecret))
+	case "s3":
+		return NewS3Store(S3Options{
+			Endpoint:     cfg.S3Endpoint,
+			Region:       cfg.S3Region,
+			Bucket:       cfg.S3Bucket,
+			AccessKey:    cfg.S3AccessKey,
+			SecretKey:    cfg.S3SecretKey,
+			UsePathStyle: cfg.S3UsePathStyle,
+		}, nil)
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.StorageBackend)
+	}
+}
+
+// validateKey rejects keys that could escape the store's namespace
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid blob key: %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid blob key: %q", key)
+		}
+	}
+	return nil
+}
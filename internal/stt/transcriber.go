@@ -0,0 +1,18 @@
+package stt
+
+import (
+	"context"
+	"io"
+)
+
+// Transcript represents the text produced from an audio recording
+type Transcript struct {
+	Text     string  `json:"text"`
+	Language string  `json:"language"`
+	Duration float64 `json:"duration"` // seconds, zero if the backend does not report it
+}
+
+// Transcriber defines the interface for speech-to-text backends
+type Transcriber interface {
+	Transcribe(ctx context.Context, audio io.Reader, filename string) (*Transcript, error)
+}
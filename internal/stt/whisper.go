@@ -0,0 +1,159 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"todo-backend/internal/config"
+)
+
+// WhisperTranscriber implements the Transcriber interface using OpenAI's Whisper API.
+type WhisperTranscriber struct {
+	apiKey     string
+	apiBaseURL string
+	model      string
+	httpClient *http.Client
+}
+
+// NewWhisperTranscriber creates a new WhisperTranscriber.
+func NewWhisperTranscriber(cfg *config.Config) *WhisperTranscriber {
+	return &WhisperTranscriber{
+		apiKey:     cfg.OpenAPIKey,
+		apiBaseURL: "https://api.openai.com/v1",
+		model:      "whisper-1",
+		httpClient: &http.Client{},
+	}
+}
+
+// NewWhisperTranscriberWithClient creates a new WhisperTranscriber with a custom HTTP client and base URL (for testing).
+func NewWhisperTranscriberWithClient(apiKey, apiBaseURL string, client *http.Client) *WhisperTranscriber {
+	return &WhisperTranscriber{
+		apiKey:     apiKey,
+		apiBaseURL: apiBaseURL,
+		model:      "whisper-1",
+		httpClient: client,
+	}
+}
+
+// Transcribe converts audio to text using OpenAI's Whisper model.
+func (t *WhisperTranscriber) Transcribe(ctx context.Context, audio io.Reader, filename string) (*Transcript, error) {
+	body, contentType, err := newTranscriptionBody(audio, filename, map[string]string{
+		"model":           t.model,
+		"response_format": "verbose_json",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.apiBaseURL+"/audio/transcriptions", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to OpenAI: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("whisper api error: status %d, body: %s", resp.StatusCode, respBody)
+	}
+
+	return decodeTranscript(resp.Body)
+}
+
+// WhisperCppTranscriber implements the Transcriber interface against a local whisper.cpp server.
+type WhisperCppTranscriber struct {
+	serverURL  string
+	httpClient *http.Client
+}
+
+// NewWhisperCppTranscriber creates a new WhisperCppTranscriber for the given server URL.
+func NewWhisperCppTranscriber(serverURL string, client *http.Client) *WhisperCppTranscriber {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &WhisperCppTranscriber{
+		serverURL:  serverURL,
+		httpClient: client,
+	}
+}
+
+// Transcribe converts audio to text using the whisper.cpp /inference endpoint.
+func (t *WhisperCppTranscriber) Transcribe(ctx context.Context, audio io.Reader, filename string) (*Transcript, error) {
+	body, contentType, err := newTranscriptionBody(audio, filename, map[string]string{
+		"response_format": "verbose_json",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.serverURL+"/inference", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to whisper.cpp: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("whisper.cpp error: status %d, body: %s", resp.StatusCode, respBody)
+	}
+
+	return decodeTranscript(resp.Body)
+}
+
+// NewTranscriber returns the Transcriber selected by cfg.STTProvider.
+func NewTranscriber(cfg *config.Config) Transcriber {
+	if cfg.STTProvider == "whispercpp" {
+		return NewWhisperCppTranscriber(cfg.WhisperCppURL, nil)
+	}
+	return NewWhisperTranscriber(cfg)
+}
+
+// newTranscriptionBody builds a multipart body holding the audio file and extra form fields.
+func newTranscriptionBody(audio io.Reader, filename string, fields map[string]string) (*bytes.Buffer, string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := io.Copy(part, audio); err != nil {
+		return nil, "", fmt.Errorf("failed to copy audio: %w", err)
+	}
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			return nil, "", fmt.Errorf("failed to write form field %s: %w", key, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	return body, writer.FormDataContentType(), nil
+}
+
+// decodeTranscript decodes a verbose_json transcription response.
+func decodeTranscript(r io.Reader) (*Transcript, error) {
+	var transcript Transcript
+	if err := json.NewDecoder(r).Decode(&transcript); err != nil {
+		return nil, fmt.Errorf("failed to decode transcription response: %w", err)
+	}
+	return &transcript, nil
+}
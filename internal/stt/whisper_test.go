@@ -0,0 +1,80 @@
+package stt
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhisperTranscriber_Transcribe(t *testing.T) {
+	// Mock Whisper API Server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/audio/transcriptions", r.URL.Path)
+		assert.Equal(t, "Bearer test-api-key", r.Header.Get("Authorization"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data"))
+
+		assert.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "whisper-1", r.FormValue("model"))
+		assert.Equal(t, "verbose_json", r.FormValue("response_format"))
+
+		file, header, err := r.FormFile("file")
+		assert.NoError(t, err)
+		defer file.Close()
+		content, _ := io.ReadAll(file)
+
+		if string(content) == "broken" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": {"message": "Invalid file format."}}`))
+			return
+		}
+
+		assert.Equal(t, "note.m4a", header.Filename)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"task": "transcribe", "language": "english", "duration": 3.5, "text": "Tomorrow buy milk and call Raj"}`))
+	}))
+	defer server.Close()
+
+	transcriber := NewWhisperTranscriberWithClient("test-api-key", server.URL+"/v1", server.Client())
+
+	t.Run("should transcribe audio", func(t *testing.T) {
+		transcript, err := transcriber.Transcribe(context.Background(), strings.NewReader("fake audio"), "note.m4a")
+		assert.NoError(t, err)
+		assert.Equal(t, "Tomorrow buy milk and call Raj", transcript.Text)
+		assert.Equal(t, "english", transcript.Language)
+		assert.Equal(t, 3.5, transcript.Duration)
+	})
+
+	t.Run("should handle API errors", func(t *testing.T) {
+		transcript, err := transcriber.Transcribe(context.Background(), strings.NewReader("broken"), "note.m4a")
+		assert.Error(t, err)
+		assert.Nil(t, transcript)
+		assert.Contains(t, err.Error(), "whisper api error")
+	})
+}
+
+func TestWhisperCppTranscriber_Transcribe(t *testing.T) {
+	// Mock whisper.cpp server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/inference", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.NoError(t, r.ParseMultipartForm(1<<20))
+		_, _, err := r.FormFile("file")
+		assert.NoError(t, err)
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"language": "en", "duration": 1.25, "text": " Call the dentist on Friday"}`))
+	}))
+	defer server.Close()
+
+	transcriber := NewWhisperCppTranscriber(server.URL, server.Client())
+
+	transcript, err := transcriber.Transcribe(context.Background(), strings.NewReader("fake audio"), "note.wav")
+	assert.NoError(t, err)
+	assert.Equal(t, " Call the dentist on Friday", transcript.Text)
+	assert.Equal(t, 1.25, transcript.Duration)
+}
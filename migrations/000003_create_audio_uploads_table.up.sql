@@ -0,0 +1,22 @@
+-- +migrate Up
+CREATE TABLE audio_uploads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    storage_key TEXT,
+    filename VARCHAR(255),
+    mime_type VARCHAR(100),
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    duration_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    language VARCHAR(50),
+    transcript TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'uploaded',
+    error_message TEXT,
+    task_ids JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audio_uploads_user_id ON audio_uploads(user_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS audio_uploads;
@@ -0,0 +1,13 @@
+-- +migrate Up
+DROP INDEX IF EXISTS idx_tasks_parent_id_position;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS position,
+    DROP COLUMN IF EXISTS parent_id;
+
+-- +migrate Down
+ALTER TABLE tasks
+    ADD COLUMN parent_id UUID REFERENCES tasks(id) ON DELETE CASCADE,
+    ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_tasks_parent_id_position ON tasks(parent_id, position);
@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE user_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    locale VARCHAR(35) NOT NULL DEFAULT 'en-US',
+    week_start VARCHAR(10) NOT NULL DEFAULT 'monday',
+    default_priority VARCHAR(50) NOT NULL DEFAULT 'medium',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS user_settings;
@@ -0,0 +1,16 @@
+-- +migrate Up
+-- 000002 left out the timestamp of a task's last change, which tasks are listed and synced by
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX idx_tasks_user_id_created_at ON tasks(user_id, created_at, id) WHERE parent_id IS NULL;
+CREATE INDEX idx_tasks_user_id_updated_at ON tasks(user_id, updated_at, id) WHERE parent_id IS NULL;
+CREATE INDEX idx_tasks_user_id_due_date ON tasks(user_id, due_date, id) WHERE parent_id IS NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_tasks_user_id_due_date;
+DROP INDEX IF EXISTS idx_tasks_user_id_updated_at;
+DROP INDEX IF EXISTS idx_tasks_user_id_created_at;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,15 @@
+-- +migrate Up
+DROP INDEX IF EXISTS idx_tasks_search_vector;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS search_vector;
+
+-- +migrate Down
+ALTER TABLE tasks
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(raw_text, '')), 'C')
+    ) STORED;
+
+CREATE INDEX idx_tasks_search_vector ON tasks USING GIN (search_vector);
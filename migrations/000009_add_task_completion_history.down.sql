@@ -0,0 +1,24 @@
+-- +migrate Up
+DROP TABLE IF EXISTS task_events;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS completed_at,
+    DROP COLUMN IF EXISTS completed;
+
+-- +migrate Down
+-- 000002 left out the completed flag, which the completion history is backfilled from
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS completed BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN completed_at TIMESTAMPTZ;
+
+UPDATE tasks SET completed_at = updated_at WHERE completed;
+
+CREATE TABLE task_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_task_events_task_id_created_at ON task_events(task_id, created_at);
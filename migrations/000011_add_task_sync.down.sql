@@ -0,0 +1,32 @@
+-- +migrate Up
+DROP INDEX IF EXISTS idx_tasks_user_id_change_seq;
+DROP INDEX IF EXISTS idx_tasks_deleted_at;
+
+DROP TABLE IF EXISTS task_change_counters;
+
+-- Tombstones are only kept for sync
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS created_seq,
+    DROP COLUMN IF EXISTS change_seq,
+    DROP COLUMN IF EXISTS deleted_at;
+
+-- +migrate Down
+ALTER TABLE tasks
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN change_seq BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN created_seq BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE task_change_counters (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL
+);
+
+-- Existing tasks count as one change, so the first sync of every client picks them up
+UPDATE tasks SET change_seq = 1, created_seq = 1;
+INSERT INTO task_change_counters (user_id, seq)
+    SELECT DISTINCT user_id, 1 FROM tasks;
+
+CREATE INDEX idx_tasks_deleted_at ON tasks(deleted_at);
+CREATE INDEX idx_tasks_user_id_change_seq ON tasks(user_id, change_seq, id);
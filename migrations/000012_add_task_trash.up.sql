@@ -0,0 +1,12 @@
+-- +migrate Up
+ALTER TABLE task_change_counters
+    ADD COLUMN purged_seq BIGINT NOT NULL DEFAULT 0;
+
+-- The trash is listed per user, most recently deleted first
+CREATE INDEX idx_tasks_user_id_deleted_at ON tasks(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_tasks_user_id_deleted_at;
+
+ALTER TABLE task_change_counters
+    DROP COLUMN IF EXISTS purged_seq;
@@ -0,0 +1,16 @@
+-- +migrate Up
+ALTER TABLE tasks
+    ADD COLUMN recurrence VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN series_id UUID,
+    ADD COLUMN recurrence_index INTEGER NOT NULL DEFAULT 0;
+
+-- Each occurrence of a series is generated once, however often the previous one is completed
+CREATE UNIQUE INDEX idx_tasks_series_occurrence ON tasks(series_id, recurrence_index);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_tasks_series_occurrence;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS recurrence_index,
+    DROP COLUMN IF EXISTS series_id,
+    DROP COLUMN IF EXISTS recurrence;
@@ -0,0 +1,27 @@
+-- +migrate Up
+DROP TABLE IF EXISTS reminders;
+
+-- +migrate Down
+CREATE TABLE reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    remind_at TIMESTAMPTZ,
+    minutes_before INTEGER,
+    snoozed_until TIMESTAMPTZ,
+    fire_at TIMESTAMPTZ,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    locked_at TIMESTAMPTZ,
+    locked_by VARCHAR(255),
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reminders_task_id ON reminders(task_id);
+CREATE INDEX idx_reminders_user_id ON reminders(user_id);
+-- The scheduler looks for pending reminders that are due and claimed ones whose lease has expired
+CREATE INDEX idx_reminders_pending_fire_at ON reminders(fire_at) WHERE status = 'pending';
+CREATE INDEX idx_reminders_sending_locked_at ON reminders(locked_at) WHERE status = 'sending';
@@ -0,0 +1,25 @@
+-- +migrate Up
+DROP TABLE IF EXISTS task_tags;
+DROP TABLE IF EXISTS tags;
+
+-- +migrate Down
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Tag names are unique per user, ignoring case
+CREATE UNIQUE INDEX idx_tags_user_id_name ON tags(user_id, LOWER(name));
+
+CREATE TABLE task_tags (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, tag_id)
+);
+
+CREATE INDEX idx_task_tags_tag_id ON task_tags(tag_id);
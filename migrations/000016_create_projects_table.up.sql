@@ -0,0 +1,25 @@
+-- +migrate Up
+CREATE TABLE projects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    color VARCHAR(7),
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_projects_user_id_position ON projects(user_id, position);
+
+-- Tasks without a project are in the user's inbox; only top-level tasks are put in projects
+ALTER TABLE tasks ADD COLUMN project_id UUID REFERENCES projects(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_project_id ON tasks(project_id) WHERE parent_id IS NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_tasks_project_id;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS project_id;
+
+DROP TABLE IF EXISTS projects;
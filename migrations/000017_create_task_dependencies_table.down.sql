@@ -0,0 +1,13 @@
+-- +migrate Down
+DROP TABLE IF EXISTS task_dependencies;
+
+-- +migrate Up
+CREATE TABLE task_dependencies (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    blocker_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, blocker_id),
+    CHECK (task_id <> blocker_id)
+);
+
+CREATE INDEX idx_task_dependencies_blocker_id ON task_dependencies(blocker_id);
@@ -0,0 +1,47 @@
+-- +migrate Down
+UPDATE tasks SET project_id = NULL WHERE parent_id IS NOT NULL;
+
+DROP TABLE IF EXISTS project_invitations;
+
+DROP TABLE IF EXISTS project_members;
+
+-- +migrate Up
+CREATE TABLE project_members (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX idx_project_members_user_id ON project_members(user_id);
+
+CREATE TABLE project_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    inviter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invitee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_project_invitations_project_id ON project_invitations(project_id);
+CREATE INDEX idx_project_invitations_invitee_id ON project_invitations(invitee_id) WHERE status = 'pending';
+
+-- Every existing project is owned by the user who created it
+INSERT INTO project_members (project_id, user_id, role)
+SELECT id, user_id, 'owner' FROM projects;
+
+-- Subtasks now carry their parent's project, so members can see them
+WITH RECURSIVE tree AS (
+    SELECT id, project_id FROM tasks WHERE parent_id IS NULL AND project_id IS NOT NULL
+    UNION ALL
+    SELECT tasks.id, tree.project_id FROM tasks JOIN tree ON tasks.parent_id = tree.id
+)
+UPDATE tasks SET project_id = tree.project_id
+FROM tree
+WHERE tasks.id = tree.id AND tasks.parent_id IS NOT NULL;
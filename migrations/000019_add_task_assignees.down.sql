@@ -0,0 +1,17 @@
+-- +migrate Up
+ALTER TABLE task_events DROP COLUMN IF EXISTS previous_assignee_id;
+ALTER TABLE task_events DROP COLUMN IF EXISTS assignee_id;
+
+DROP INDEX IF EXISTS idx_tasks_assignee_id;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS assignee_id;
+
+-- +migrate Down
+-- Tasks are assigned to a member of their project; unassigned when the user is deleted
+ALTER TABLE tasks ADD COLUMN assignee_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_assignee_id ON tasks(assignee_id) WHERE parent_id IS NULL;
+
+-- Assigned events record who a task was assigned to before and after
+ALTER TABLE task_events ADD COLUMN assignee_id UUID;
+ALTER TABLE task_events ADD COLUMN previous_assignee_id UUID;
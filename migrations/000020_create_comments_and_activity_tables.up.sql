@@ -0,0 +1,26 @@
+-- +migrate Up
+CREATE TABLE comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    edited_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_comments_task_id_created_at ON comments(task_id, created_at);
+
+-- The activity stream of a task is its events: who did what, and what a change was from and to
+ALTER TABLE task_events
+    ADD COLUMN actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN "from" TEXT NOT NULL DEFAULT '',
+    ADD COLUMN "to" TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE task_events
+    DROP COLUMN IF EXISTS "to",
+    DROP COLUMN IF EXISTS "from",
+    DROP COLUMN IF EXISTS actor_id;
+
+DROP TABLE IF EXISTS comments;
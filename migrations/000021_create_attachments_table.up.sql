@@ -0,0 +1,18 @@
+-- +migrate Up
+-- No foreign keys: the record of a file outlives its purged task, so that the file can be removed
+-- from blob storage afterwards
+CREATE TABLE attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    storage_key TEXT NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(255) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_attachments_task_id_created_at ON attachments(task_id, created_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS attachments;
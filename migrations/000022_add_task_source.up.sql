@@ -0,0 +1,12 @@
+-- +migrate Up
+-- The job or audio upload whose extraction created a task, so a rerun finds the tasks it made
+ALTER TABLE tasks
+    ADD COLUMN source_id UUID;
+
+CREATE INDEX idx_tasks_source_id ON tasks(source_id) WHERE source_id IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_tasks_source_id;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS source_id;
@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS left_seq;
+
+-- +migrate Down
+-- When a task last left a project, so members whose sync tokens are older sync again
+ALTER TABLE projects
+    ADD COLUMN left_seq BIGINT NOT NULL DEFAULT 0;
@@ -8,7 +8,21 @@
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
 -- Drop tables if they exist (for clean setup)
+DROP TABLE IF EXISTS attachments CASCADE;
+DROP TABLE IF EXISTS comments CASCADE;
+DROP TABLE IF EXISTS project_invitations CASCADE;
+DROP TABLE IF EXISTS project_members CASCADE;
+DROP TABLE IF EXISTS task_dependencies CASCADE;
+DROP TABLE IF EXISTS task_tags CASCADE;
+DROP TABLE IF EXISTS tags CASCADE;
+DROP TABLE IF EXISTS reminders CASCADE;
+DROP TABLE IF EXISTS task_change_counters CASCADE;
+DROP TABLE IF EXISTS task_events CASCADE;
+DROP TABLE IF EXISTS user_settings CASCADE;
+DROP TABLE IF EXISTS jobs CASCADE;
+DROP TABLE IF EXISTS audio_uploads CASCADE;
 DROP TABLE IF EXISTS tasks CASCADE;
+DROP TABLE IF EXISTS projects CASCADE;
 DROP TABLE IF EXISTS users CASCADE;
 
 -- Create users table
@@ -23,6 +37,88 @@ CREATE TABLE users (
 -- Create index on email for faster lookups
 CREATE INDEX idx_users_email ON users(email);
 
+-- Create user_settings table, one row per user who has changed a setting
+CREATE TABLE user_settings (
+    user_id UUID PRIMARY KEY,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    locale VARCHAR(35) NOT NULL DEFAULT 'en-US',
+    week_start VARCHAR(10) NOT NULL DEFAULT 'monday',
+    default_priority VARCHAR(50) NOT NULL DEFAULT 'medium',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+-- Create projects table, the lists each user groups tasks into
+CREATE TABLE projects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    color VARCHAR(7),
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    left_seq BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_projects_user_id_position ON projects(user_id, position);
+
+-- Create project_members table, the users a project is shared with and their roles
+CREATE TABLE project_members (
+    project_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, user_id),
+    CONSTRAINT fk_project
+        FOREIGN KEY(project_id)
+        REFERENCES projects(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_project_members_user_id ON project_members(user_id);
+
+-- Create project_invitations table, the pending and answered invitations to join projects
+CREATE TABLE project_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL,
+    inviter_id UUID NOT NULL,
+    invitee_id UUID NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_project
+        FOREIGN KEY(project_id)
+        REFERENCES projects(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_inviter
+        FOREIGN KEY(inviter_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_invitee
+        FOREIGN KEY(invitee_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_project_invitations_project_id ON project_invitations(project_id);
+CREATE INDEX idx_project_invitations_invitee_id ON project_invitations(invitee_id) WHERE status = 'pending';
+
 -- Create tasks table
 CREATE TABLE tasks (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -33,12 +129,42 @@ CREATE TABLE tasks (
     priority VARCHAR(50) DEFAULT 'medium',
     raw_text TEXT,
     completed BOOLEAN DEFAULT FALSE,
+    completed_at TIMESTAMP,
+    parent_id UUID,
+    project_id UUID,
+    assignee_id UUID,
+    position INTEGER NOT NULL DEFAULT 0,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP,
+    change_seq BIGINT NOT NULL DEFAULT 0,
+    created_seq BIGINT NOT NULL DEFAULT 0,
+    recurrence VARCHAR(255) NOT NULL DEFAULT '',
+    series_id UUID,
+    recurrence_index INTEGER NOT NULL DEFAULT 0,
+    source_id UUID,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(raw_text, '')), 'C')
+    ) STORED,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     CONSTRAINT fk_user
         FOREIGN KEY(user_id) 
         REFERENCES users(id)
-        ON DELETE CASCADE
+        ON DELETE CASCADE,
+    CONSTRAINT fk_parent_task
+        FOREIGN KEY(parent_id)
+        REFERENCES tasks(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_project
+        FOREIGN KEY(project_id)
+        REFERENCES projects(id)
+        ON DELETE SET NULL,
+    CONSTRAINT fk_assignee
+        FOREIGN KEY(assignee_id)
+        REFERENCES users(id)
+        ON DELETE SET NULL
 );
 
 -- Create indexes on tasks table
@@ -46,6 +172,231 @@ CREATE INDEX idx_tasks_user_id ON tasks(user_id);
 CREATE INDEX idx_tasks_created_at ON tasks(created_at);
 CREATE INDEX idx_tasks_completed ON tasks(completed);
 CREATE INDEX idx_tasks_priority ON tasks(priority);
+CREATE INDEX idx_tasks_parent_id_position ON tasks(parent_id, position);
+CREATE INDEX idx_tasks_user_id_created_at ON tasks(user_id, created_at, id) WHERE parent_id IS NULL;
+CREATE INDEX idx_tasks_user_id_updated_at ON tasks(user_id, updated_at, id) WHERE parent_id IS NULL;
+CREATE INDEX idx_tasks_user_id_due_date ON tasks(user_id, due_date, id) WHERE parent_id IS NULL;
+CREATE INDEX idx_tasks_search_vector ON tasks USING GIN (search_vector);
+CREATE INDEX idx_tasks_deleted_at ON tasks(deleted_at);
+CREATE INDEX idx_tasks_user_id_change_seq ON tasks(user_id, change_seq, id);
+CREATE INDEX idx_tasks_user_id_deleted_at ON tasks(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE UNIQUE INDEX idx_tasks_series_occurrence ON tasks(series_id, recurrence_index);
+CREATE INDEX idx_tasks_project_id ON tasks(project_id) WHERE parent_id IS NULL;
+CREATE INDEX idx_tasks_assignee_id ON tasks(assignee_id) WHERE parent_id IS NULL;
+CREATE INDEX idx_tasks_source_id ON tasks(source_id) WHERE source_id IS NOT NULL;
+
+-- Create task_events table, the activity streams of tasks: who created, changed, completed,
+-- reopened and reassigned them
+CREATE TABLE task_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    actor_id UUID,
+    type VARCHAR(32) NOT NULL,
+    assignee_id UUID,
+    previous_assignee_id UUID,
+    "from" TEXT NOT NULL DEFAULT '',
+    "to" TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_task
+        FOREIGN KEY(task_id)
+        REFERENCES tasks(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_actor
+        FOREIGN KEY(actor_id)
+        REFERENCES users(id)
+        ON DELETE SET NULL
+);
+
+CREATE INDEX idx_task_events_task_id_created_at ON task_events(task_id, created_at);
+
+-- Create task_change_counters table, the last change sequence number issued per user for sync
+CREATE TABLE task_change_counters (
+    user_id UUID PRIMARY KEY,
+    seq BIGINT NOT NULL,
+    purged_seq BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+-- Create reminders table, notifications about tasks at a fixed time or before the due date
+CREATE TABLE reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    remind_at TIMESTAMP,
+    minutes_before INTEGER,
+    snoozed_until TIMESTAMP,
+    fire_at TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    locked_at TIMESTAMP,
+    locked_by VARCHAR(255),
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_task
+        FOREIGN KEY(task_id)
+        REFERENCES tasks(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_reminders_task_id ON reminders(task_id);
+CREATE INDEX idx_reminders_user_id ON reminders(user_id);
+CREATE INDEX idx_reminders_pending_fire_at ON reminders(fire_at) WHERE status = 'pending';
+CREATE INDEX idx_reminders_sending_locked_at ON reminders(locked_at) WHERE status = 'sending';
+
+-- Create tags table, the labels each user puts on tasks
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_tags_user_id_name ON tags(user_id, LOWER(name));
+
+-- Create task_tags table, which tags are on which tasks
+CREATE TABLE task_tags (
+    task_id UUID NOT NULL,
+    tag_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, tag_id),
+    CONSTRAINT fk_task
+        FOREIGN KEY(task_id)
+        REFERENCES tasks(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_tag
+        FOREIGN KEY(tag_id)
+        REFERENCES tags(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_tags_tag_id ON task_tags(tag_id);
+
+-- Create task_dependencies table, which tasks have to be done before which
+CREATE TABLE task_dependencies (
+    task_id UUID NOT NULL,
+    blocker_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, blocker_id),
+    CHECK (task_id <> blocker_id),
+    CONSTRAINT fk_task
+        FOREIGN KEY(task_id)
+        REFERENCES tasks(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_blocker
+        FOREIGN KEY(blocker_id)
+        REFERENCES tasks(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_dependencies_blocker_id ON task_dependencies(blocker_id);
+
+-- Create comments table, the discussion threads on tasks
+CREATE TABLE comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    body TEXT NOT NULL,
+    edited_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_task
+        FOREIGN KEY(task_id)
+        REFERENCES tasks(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_comments_task_id_created_at ON comments(task_id, created_at);
+
+-- Create attachments table, the files attached to tasks. It has no foreign keys: the record of a
+-- file outlives its purged task, so that the file can be removed from blob storage afterwards.
+CREATE TABLE attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    storage_key TEXT NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(255) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_attachments_task_id_created_at ON attachments(task_id, created_at);
+
+-- Create audio_uploads table
+CREATE TABLE audio_uploads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    storage_key TEXT,
+    filename VARCHAR(255),
+    mime_type VARCHAR(100),
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    duration_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    language VARCHAR(50),
+    transcript TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'uploaded',
+    error_message TEXT,
+    task_ids JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_audio_uploads_user_id ON audio_uploads(user_id);
+
+-- Create jobs table for background transcription and extraction work
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_at TIMESTAMP,
+    locked_by VARCHAR(255),
+    last_error TEXT,
+    result JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_jobs_user_id ON jobs(user_id);
+CREATE INDEX idx_jobs_status ON jobs(status);
+CREATE INDEX idx_jobs_queued_run_at ON jobs(run_at) WHERE status = 'queued';
+CREATE INDEX idx_jobs_running_locked_at ON jobs(locked_at) WHERE status = 'running';
 
 -- Create trigger to auto-update updated_at timestamp for users
 CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -59,9 +410,36 @@ $$ language 'plpgsql';
 CREATE TRIGGER update_users_updated_at BEFORE UPDATE ON users
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+CREATE TRIGGER update_user_settings_updated_at BEFORE UPDATE ON user_settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_projects_updated_at BEFORE UPDATE ON projects
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_project_members_updated_at BEFORE UPDATE ON project_members
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_project_invitations_updated_at BEFORE UPDATE ON project_invitations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
 CREATE TRIGGER update_tasks_updated_at BEFORE UPDATE ON tasks
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+CREATE TRIGGER update_audio_uploads_updated_at BEFORE UPDATE ON audio_uploads
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_jobs_updated_at BEFORE UPDATE ON jobs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_reminders_updated_at BEFORE UPDATE ON reminders
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_tags_updated_at BEFORE UPDATE ON tags
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_comments_updated_at BEFORE UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
 -- Verify tables were created
 SELECT table_name FROM information_schema.tables 
 WHERE table_schema = 'public' 